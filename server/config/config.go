@@ -11,6 +11,7 @@ type Config struct {
 	Cache
 	Integrations
 	SMTP
+	Webhooks
 	LLM llm.Config
 	Otel
 }
@@ -25,6 +26,7 @@ func New() *Config {
 		DB:           DataStore(),
 		Integrations: INTEGRATIONS(),
 		SMTP:         NewSMTP(),
+		Webhooks:     NewWebhooks(),
 		LLM:          llm.NewConfig(),
 		Otel:         OTEL(),
 	}
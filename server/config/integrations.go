@@ -6,26 +6,28 @@ type Integrations struct {
 	EnabledFinancialProviders []string `split_words:"true" required:"false"`
 
 	// Teller.io
-	TellerEnvironment        string `split_words:"true" required:"false" default:"sandbox"`
-	TellerBaseUri            string `split_words:"true" required:"false" default:"https://api.teller.io"`
-	TellerCertPath           string `split_words:"true" required:"false"`
-	TellerCertPrivateKeyPath string `split_words:"true" required:"false"`
+	TellerEnvironment        string   `split_words:"true" required:"false" default:"sandbox"`
+	TellerBaseUri            string   `split_words:"true" required:"false" default:"https://api.teller.io"`
+	TellerCertPath           string   `split_words:"true" required:"false"`
+	TellerCertPrivateKeyPath string   `split_words:"true" required:"false"`
+	TellerWebhookSecrets     []string `split_words:"true" required:"false"` // Comma separated, several during a rotation
 
 	// Plaid
 	PlaidEnvironment string `split_words:"true" required:"false" default:"sandbox"`
 	PlaidClientId    string `split_words:"true" required:"false"`
 	PlaidSecret      string `split_words:"true" required:"false"`
-	PlaidBaseUri     string `split_words:"true" required:"false" default:"https://production.plaid.com"`
+	PlaidBaseUri     string `split_words:"true" required:"false"` // Defaults to the host of PlaidEnvironment
 	PlaidWebhookUri  string `split_words:"true" required:"false"`
 
 	// GoCardless
 	GoCardlessSecretId  string `split_words:"true" required:"false"`
 	GoCardlessSecretKey string `split_words:"true" required:"false"`
-	GoCardlessBaseUri   string `split_words:"true" required:"false" default:"https://ob.gocardless.com"`
+	GoCardlessBaseUri   string `split_words:"true" required:"false" default:"https://bankaccountdata.gocardless.com"`
 
 	// Mono.co
-	MonoSecretKey string `split_words:"true" required:"false"`
-	MonoBaseUri   string `split_words:"true" required:"false" default:"https://api.mono.co"`
+	MonoSecretKey     string `split_words:"true" required:"false"`
+	MonoBaseUri       string `split_words:"true" required:"false" default:"https://api.mono.co"`
+	MonoWebhookSecret string `split_words:"true" required:"false"`
 
 	// Brankas
 	BrankasApiKey  string `split_words:"true" required:"false"`
@@ -0,0 +1,16 @@
+package config
+
+import "github.com/kelseyhightower/envconfig"
+
+type Webhooks struct {
+	// Hosts, IPs or CIDR ranges webhooks may be delivered to even though they are internal,
+	// comma separated. Meant for self-hosted setups, leave empty otherwise
+	AllowedDestinations []string `split_words:"true" required:"false"`
+}
+
+func NewWebhooks() Webhooks {
+	var w Webhooks
+	envconfig.MustProcess("WEBHOOK", &w)
+
+	return w
+}
@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE budgets
+ADD COLUMN IF NOT EXISTS rollover_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One row per closed budget period, the rollover_amount of a period is what
+-- gets carried into the next one (negative when the period was overspent)
+CREATE TABLE budget_rollovers (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL, -- Exclusive
+    budgeted_amount NUMERIC(15, 2) NOT NULL,
+    carried_in NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    spent_amount NUMERIC(15, 2) NOT NULL,
+    rollover_amount NUMERIC(15, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT budget_rollovers_unique_period UNIQUE (budget_id, period_start)
+);
+
+CREATE INDEX idx_budget_rollovers_budget_period_end ON budget_rollovers(budget_id, period_end);
+CREATE INDEX idx_budgets_rollover_enabled ON budgets(rollover_enabled) WHERE rollover_enabled = TRUE;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_budgets_rollover_enabled;
+DROP INDEX IF EXISTS idx_budget_rollovers_budget_period_end;
+DROP TABLE IF EXISTS budget_rollovers;
+
+ALTER TABLE budgets
+DROP COLUMN IF EXISTS rollover_enabled;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Track consecutive sync failures so the scheduler can back off from broken connections
+ALTER TABLE user_financial_connections
+ADD COLUMN IF NOT EXISTS sync_failures INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+CREATE INDEX idx_user_financial_connections_status_last_sync ON user_financial_connections (status, last_sync_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_financial_connections_status_last_sync;
+
+ALTER TABLE user_financial_connections
+DROP COLUMN IF EXISTS last_error,
+DROP COLUMN IF EXISTS sync_failures;
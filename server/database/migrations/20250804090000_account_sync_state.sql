@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per account progress of the bank sync, used to only request the window since the last sync
+CREATE TABLE account_sync_states (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    last_seen_at TIMESTAMPTZ, -- Date of the newest transaction received from the provider
+    last_synced_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX idx_transactions_account_provider_transaction_id ON transactions (account_id, provider_transaction_id)
+WHERE provider_transaction_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_account_provider_transaction_id;
+DROP TABLE IF EXISTS account_sync_states;
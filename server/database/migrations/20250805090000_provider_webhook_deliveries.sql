@@ -0,0 +1,18 @@
+-- +goose Up
+-- Inbound webhook deliveries of financial providers, kept to ignore replays
+CREATE TABLE provider_webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    provider_name VARCHAR(50) NOT NULL,
+    delivery_id VARCHAR(255) NOT NULL, -- Event ID, or a hash of the payload for providers without one
+    event_type VARCHAR(100) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT provider_webhook_deliveries_unique UNIQUE (provider_name, delivery_id)
+);
+
+CREATE INDEX idx_user_financial_connections_provider_item ON user_financial_connections (provider_name, item_id);
+CREATE INDEX idx_accounts_provider_account_id ON accounts (provider_account_id) WHERE provider_account_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_accounts_provider_account_id;
+DROP INDEX IF EXISTS idx_user_financial_connections_provider_item;
+DROP TABLE IF EXISTS provider_webhook_deliveries;
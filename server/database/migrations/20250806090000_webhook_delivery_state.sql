@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN disabled_at TIMESTAMPTZ,
+    ADD COLUMN disabled_reason TEXT;
+
+ALTER TABLE webhook_events
+    ADD COLUMN response_status INTEGER,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN delivered_at TIMESTAMPTZ;
+
+-- Events go away with their subscription
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_subscription_id_fkey;
+ALTER TABLE webhook_events
+    ADD CONSTRAINT webhook_events_subscription_id_fkey
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_webhook_events_subscription_created ON webhook_events (subscription_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_events_subscription_created;
+
+ALTER TABLE webhook_events DROP CONSTRAINT webhook_events_subscription_id_fkey;
+ALTER TABLE webhook_events
+    ADD CONSTRAINT webhook_events_subscription_id_fkey
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id);
+
+ALTER TABLE webhook_events
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS response_status;
+
+ALTER TABLE webhook_subscriptions
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS disabled_at,
+    DROP COLUMN IF EXISTS consecutive_failures;
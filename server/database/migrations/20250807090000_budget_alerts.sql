@@ -0,0 +1,15 @@
+-- +goose Up
+-- Remembers the budget periods already reported as exceeded, so that
+-- budget.exceeded is emitted once per period
+CREATE TABLE budget_alerts (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    budgeted_amount NUMERIC(15, 2) NOT NULL,
+    spent_amount NUMERIC(15, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT budget_alerts_unique_period UNIQUE (budget_id, period_start)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS budget_alerts;
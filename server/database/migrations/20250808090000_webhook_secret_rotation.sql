@@ -0,0 +1,11 @@
+-- +goose Up
+-- While a secret is rotated deliveries are signed with both the new and the
+-- previous secret, until the previous one expires
+ALTER TABLE webhook_subscriptions
+ADD COLUMN previous_secret TEXT,
+ADD COLUMN previous_secret_expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE webhook_subscriptions
+DROP COLUMN IF EXISTS previous_secret_expires_at,
+DROP COLUMN IF EXISTS previous_secret;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- One row per HTTP request made to deliver a webhook event, kept for inspection
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    event_id UUID NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    request_url TEXT NOT NULL,
+    request_headers JSONB NOT NULL DEFAULT '{}',
+    response_status INT,
+    response_body TEXT, -- Truncated
+    error_message TEXT,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_subscription ON webhook_delivery_attempts(subscription_id, created_at DESC);
+CREATE INDEX idx_webhook_delivery_attempts_event ON webhook_delivery_attempts(event_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_delivery_attempts_event;
+DROP INDEX IF EXISTS idx_webhook_delivery_attempts_subscription;
+DROP TABLE IF EXISTS webhook_delivery_attempts;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- Column mappings users save for the CSV exports of their banks
+CREATE TABLE import_mappings (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    mapping JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT import_mappings_unique_name UNIQUE (user_id, name)
+);
+
+-- A statement file read into staged transactions, which only become transactions once the
+-- user commits the import
+CREATE TABLE transaction_imports (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'ofx', 'qif', 'camt053')),
+    filename TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'staged' CHECK (status IN ('staged', 'committed')),
+    row_count INT NOT NULL,
+    duplicate_count INT NOT NULL,
+    committed_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    committed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_transaction_imports_user ON transaction_imports(user_id, created_at DESC);
+
+CREATE TABLE staged_transactions (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    import_id UUID NOT NULL REFERENCES transaction_imports(id) ON DELETE CASCADE,
+    line INT NOT NULL,
+    transaction_datetime TIMESTAMPTZ NOT NULL,
+    amount NUMERIC NOT NULL,
+    currency VARCHAR(3),
+    description TEXT,
+    payee TEXT,
+    reference TEXT,
+    duplicate_of UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL -- Set once committed
+);
+
+CREATE INDEX idx_staged_transactions_import ON staged_transactions(import_id, line);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_staged_transactions_import;
+DROP TABLE IF EXISTS staged_transactions;
+DROP INDEX IF EXISTS idx_transaction_imports_user;
+DROP TABLE IF EXISTS transaction_imports;
+DROP TABLE IF EXISTS import_mappings;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Transaction exports requested by users, generated by the export worker into the private bucket
+CREATE TABLE exports (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'json', 'ofx')),
+    filters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    storage_key TEXT,
+    row_count INT,
+    file_size BIGINT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_exports_user ON exports(user_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_exports_user;
+DROP TABLE IF EXISTS exports;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Files attached to transactions (receipts, invoices), stored in the private bucket
+CREATE TABLE transaction_attachments (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    original_filename VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL CHECK (file_size > 0),
+    mime_type VARCHAR(100) NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    bucket_name TEXT NOT NULL,
+    is_encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    encryption_key_id TEXT,
+    -- Pending attachments wait for the client to upload the file
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'uploaded')),
+    uploaded_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    uploaded_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_transaction_attachments_transaction ON transaction_attachments(transaction_id);
+CREATE INDEX idx_transaction_attachments_user ON transaction_attachments(uploaded_by);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transaction_attachments_user;
+DROP INDEX IF EXISTS idx_transaction_attachments_transaction;
+DROP TABLE IF EXISTS transaction_attachments;
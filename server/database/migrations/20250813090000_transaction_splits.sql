@@ -0,0 +1,17 @@
+-- +goose Up
+-- Parts of a transaction spread over several categories, set by the split action of rules
+CREATE TABLE transaction_splits (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    amount NUMERIC NOT NULL CHECK (amount >= 0),
+    percentage NUMERIC NOT NULL CHECK (percentage > 0 AND percentage <= 100),
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX idx_transaction_splits_transaction ON transaction_splits(transaction_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transaction_splits_transaction;
+DROP TABLE IF EXISTS transaction_splits;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Every change a rule made to a transaction, with the values before and after so it can be undone
+CREATE TABLE transaction_rule_applications (
+    id UUID PRIMARY KEY DEFAULT (uuid_generate_v4()),
+    rule_id UUID NOT NULL REFERENCES transaction_rules(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    -- Actions of the rule when it ran
+    actions JSONB NOT NULL,
+    -- Field, before and after of each change
+    changes JSONB NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    undone_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_transaction_rule_applications_rule ON transaction_rule_applications(rule_id, created_at DESC);
+CREATE INDEX idx_transaction_rule_applications_transaction ON transaction_rule_applications(transaction_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transaction_rule_applications_transaction;
+DROP INDEX IF EXISTS idx_transaction_rule_applications_rule;
+DROP TABLE IF EXISTS transaction_rule_applications;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Position in the change feed of providers that sync from a cursor rather than by date window
+ALTER TABLE account_sync_states ADD COLUMN cursor TEXT;
+
+-- +goose Down
+ALTER TABLE account_sync_states DROP COLUMN IF EXISTS cursor;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Deliveries are pruned once providers stopped retrying them
+CREATE INDEX idx_provider_webhook_deliveries_received_at ON provider_webhook_deliveries (received_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_provider_webhook_deliveries_received_at;
@@ -554,3 +554,13 @@ WHERE
     connection_id = $1
     AND created_by = $2 -- user_id
     AND deleted_at IS NULL;
+
+-- name: DetachAccountsFromConnection :exec
+UPDATE accounts
+SET
+    connection_id = NULL,
+    sync_status = 'disconnected',
+    updated_at = current_timestamp
+WHERE
+    connection_id = $1
+    AND created_by = $2;
@@ -0,0 +1,326 @@
+-- name: CountArchiveRecords :one
+SELECT
+    (SELECT count(*) FROM accounts a WHERE a.created_by = sqlc.arg('user_id') AND a.deleted_at IS NULL) AS accounts,
+    (SELECT count(*) FROM transactions t WHERE t.created_by = sqlc.arg('user_id') AND t.deleted_at IS NULL) AS transactions;
+
+-- name: ListArchiveAccounts :many
+SELECT *
+FROM accounts
+WHERE
+    created_by = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListArchiveCategories :many
+SELECT *
+FROM categories
+WHERE
+    created_by = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListArchiveTags :many
+SELECT *
+FROM tags
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY name;
+
+-- name: ListArchiveTransactions :many
+SELECT *
+FROM transactions
+WHERE
+    created_by = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+ORDER BY transaction_datetime, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListArchiveTransactionSplits :many
+SELECT s.*
+FROM transaction_splits s
+JOIN transactions t ON t.id = s.transaction_id
+WHERE
+    t.created_by = sqlc.arg('user_id')
+    AND t.deleted_at IS NULL
+ORDER BY s.transaction_id, s.created_at, s.id;
+
+-- name: ListArchiveRules :many
+SELECT *
+FROM transaction_rules
+WHERE
+    created_by = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+ORDER BY priority DESC, created_at, id;
+
+-- name: ListArchiveRecurringTransactions :many
+SELECT *
+FROM recurring_transactions
+WHERE
+    user_id = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListArchiveBudgets :many
+SELECT *
+FROM budgets
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY start_date, id;
+
+-- name: ListArchiveAttachments :many
+SELECT a.*
+FROM transaction_attachments a
+JOIN transactions t ON t.id = a.transaction_id
+WHERE
+    a.uploaded_by = sqlc.arg('user_id')
+    AND a.status = 'uploaded'
+    AND t.deleted_at IS NULL
+ORDER BY a.created_at, a.id;
+
+-- name: RestoreAccount :exec
+INSERT INTO accounts (
+    id,
+    name,
+    type,
+    subtype,
+    balance,
+    currency,
+    meta,
+    created_by,
+    is_external,
+    created_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('name'),
+    sqlc.arg('type'),
+    sqlc.narg('subtype'),
+    sqlc.arg('balance'),
+    sqlc.arg('currency'),
+    sqlc.arg('meta'),
+    sqlc.arg('created_by'),
+    FALSE,
+    sqlc.arg('created_at')
+);
+
+-- name: RestoreCategory :exec
+INSERT INTO categories (
+    id,
+    name,
+    parent_id,
+    is_default,
+    created_by,
+    type,
+    color,
+    icon
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('name'),
+    sqlc.narg('parent_id'),
+    sqlc.arg('is_default'),
+    sqlc.arg('created_by'),
+    sqlc.arg('type'),
+    sqlc.narg('color'),
+    sqlc.arg('icon')
+);
+
+-- name: RestoreTag :one
+INSERT INTO tags (
+    id,
+    user_id,
+    name,
+    color
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('user_id'),
+    sqlc.arg('name'),
+    sqlc.arg('color')
+)
+ON CONFLICT (user_id, name) DO UPDATE SET color = EXCLUDED.color
+RETURNING id;
+
+-- name: RestoreTransactions :copyfrom
+INSERT INTO transactions (
+    id,
+    amount,
+    type,
+    account_id,
+    category_id,
+    destination_account_id,
+    transaction_datetime,
+    description,
+    details,
+    created_by,
+    is_external,
+    transaction_currency,
+    original_amount,
+    exchange_rate,
+    exchange_rate_date,
+    is_categorized,
+    recurring_transaction_id,
+    recurring_instance_date,
+    created_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('amount'),
+    sqlc.arg('type'),
+    sqlc.arg('account_id'),
+    sqlc.narg('category_id'),
+    sqlc.narg('destination_account_id'),
+    sqlc.arg('transaction_datetime'),
+    sqlc.narg('description'),
+    sqlc.narg('details'),
+    sqlc.arg('created_by'),
+    sqlc.arg('is_external'),
+    sqlc.arg('transaction_currency'),
+    sqlc.arg('original_amount'),
+    sqlc.narg('exchange_rate'),
+    sqlc.narg('exchange_rate_date'),
+    sqlc.arg('is_categorized'),
+    sqlc.narg('recurring_transaction_id'),
+    sqlc.narg('recurring_instance_date'),
+    sqlc.arg('created_at')
+);
+
+-- name: RestoreTransactionSplit :exec
+INSERT INTO transaction_splits (
+    id,
+    transaction_id,
+    category_id,
+    amount,
+    percentage,
+    description,
+    created_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('transaction_id'),
+    sqlc.narg('category_id'),
+    sqlc.arg('amount'),
+    sqlc.arg('percentage'),
+    sqlc.narg('description'),
+    sqlc.arg('created_at')
+);
+
+-- name: RestoreRule :exec
+INSERT INTO transaction_rules (
+    id,
+    name,
+    is_active,
+    priority,
+    conditions,
+    actions,
+    created_by,
+    created_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('name'),
+    sqlc.arg('is_active'),
+    sqlc.arg('priority'),
+    sqlc.arg('conditions'),
+    sqlc.arg('actions'),
+    sqlc.arg('created_by'),
+    sqlc.arg('created_at')
+);
+
+-- name: RestoreRecurringTransaction :exec
+INSERT INTO recurring_transactions (
+    id,
+    user_id,
+    account_id,
+    category_id,
+    destination_account_id,
+    amount,
+    type,
+    description,
+    details,
+    frequency,
+    frequency_interval,
+    frequency_data,
+    start_date,
+    end_date,
+    last_generated_date,
+    next_due_date,
+    auto_post,
+    is_paused,
+    max_occurrences,
+    occurrences_count,
+    template_name,
+    tags
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('user_id'),
+    sqlc.arg('account_id'),
+    sqlc.narg('category_id'),
+    sqlc.narg('destination_account_id'),
+    sqlc.arg('amount'),
+    sqlc.arg('type'),
+    sqlc.narg('description'),
+    sqlc.narg('details'),
+    sqlc.arg('frequency'),
+    sqlc.arg('frequency_interval'),
+    sqlc.narg('frequency_data'),
+    sqlc.arg('start_date'),
+    sqlc.narg('end_date'),
+    sqlc.narg('last_generated_date'),
+    sqlc.arg('next_due_date'),
+    sqlc.arg('auto_post'),
+    sqlc.arg('is_paused'),
+    sqlc.narg('max_occurrences'),
+    sqlc.arg('occurrences_count'),
+    sqlc.narg('template_name'),
+    sqlc.narg('tags')
+);
+
+-- name: RestoreBudget :exec
+INSERT INTO budgets (
+    id,
+    user_id,
+    category_id,
+    name,
+    amount,
+    start_date,
+    end_date,
+    frequency,
+    rollover_enabled
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('user_id'),
+    sqlc.arg('category_id'),
+    sqlc.narg('name'),
+    sqlc.arg('amount'),
+    sqlc.arg('start_date'),
+    sqlc.arg('end_date'),
+    sqlc.arg('frequency'),
+    sqlc.arg('rollover_enabled')
+)
+ON CONFLICT (user_id, category_id, start_date) DO NOTHING;
+
+-- name: RestoreAttachment :exec
+INSERT INTO transaction_attachments (
+    id,
+    transaction_id,
+    filename,
+    original_filename,
+    file_size,
+    mime_type,
+    storage_key,
+    bucket_name,
+    is_encrypted,
+    encryption_key_id,
+    status,
+    uploaded_by,
+    created_at,
+    uploaded_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('transaction_id'),
+    sqlc.arg('filename'),
+    sqlc.arg('original_filename'),
+    sqlc.arg('file_size'),
+    sqlc.arg('mime_type'),
+    sqlc.arg('storage_key'),
+    sqlc.arg('bucket_name'),
+    sqlc.arg('is_encrypted'),
+    sqlc.narg('encryption_key_id'),
+    'uploaded',
+    sqlc.arg('uploaded_by'),
+    sqlc.arg('created_at'),
+    current_timestamp
+);
@@ -0,0 +1,74 @@
+-- name: CreateAttachment :one
+INSERT INTO transaction_attachments (
+    id,
+    transaction_id,
+    filename,
+    original_filename,
+    file_size,
+    mime_type,
+    storage_key,
+    bucket_name,
+    uploaded_by
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('transaction_id'),
+    sqlc.arg('filename'),
+    sqlc.arg('original_filename'),
+    sqlc.arg('file_size'),
+    sqlc.arg('mime_type'),
+    sqlc.arg('storage_key'),
+    sqlc.arg('bucket_name'),
+    sqlc.arg('uploaded_by')
+) RETURNING *;
+
+-- name: GetAttachment :one
+SELECT *
+FROM transaction_attachments
+WHERE
+    id = sqlc.arg('id')
+    AND transaction_id = sqlc.arg('transaction_id')
+    AND uploaded_by = sqlc.arg('user_id');
+
+-- name: ListAttachments :many
+SELECT *
+FROM transaction_attachments
+WHERE
+    transaction_id = sqlc.arg('transaction_id')
+    AND uploaded_by = sqlc.arg('user_id')
+    AND status = 'uploaded'
+ORDER BY created_at;
+
+-- name: CompleteAttachment :one
+UPDATE transaction_attachments
+SET
+    status = 'uploaded',
+    file_size = sqlc.arg('file_size'),
+    mime_type = sqlc.arg('mime_type'),
+    is_encrypted = sqlc.arg('is_encrypted'),
+    encryption_key_id = sqlc.narg('encryption_key_id'),
+    uploaded_at = current_timestamp
+WHERE
+    id = sqlc.arg('id')
+    AND status = 'pending'
+RETURNING *;
+
+-- name: DeleteAttachment :one
+DELETE FROM transaction_attachments
+WHERE
+    id = sqlc.arg('id')
+    AND transaction_id = sqlc.arg('transaction_id')
+    AND uploaded_by = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: DeletePendingAttachments :many
+-- Attachments whose file was never uploaded
+DELETE FROM transaction_attachments
+WHERE
+    status = 'pending'
+    AND created_at < sqlc.arg('created_before')
+RETURNING *;
+
+-- name: DeleteTransactionAttachments :many
+DELETE FROM transaction_attachments
+WHERE transaction_id = ANY(sqlc.arg('transaction_ids')::uuid[])
+RETURNING *;
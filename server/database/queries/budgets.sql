@@ -7,13 +7,13 @@ INSERT INTO budgets (
   start_date,
   end_date,
   frequency,
-  -- rollover_enabled,
+  rollover_enabled,
   user_id
   ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
   ) RETURNING id, created_at, updated_at;
 
--- name: UpdateBudget :exec
+-- name: UpdateBudget :execrows
 UPDATE budgets
 SET
     category_id = $1,
@@ -22,8 +22,112 @@ SET
     start_date = $4,
     end_date = $5,
     frequency = $6,
-    -- rollover_enabled = $7,
-    updated_at = $7
-WHERE id = $8;
+    rollover_enabled = $7,
+    updated_at = $8
+WHERE id = $9 AND user_id = $10;
 
 
+
+-- name: GetBudgetById :one
+SELECT *
+FROM budgets
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id')
+LIMIT 1;
+
+-- name: ListBudgets :many
+SELECT *
+FROM budgets
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY start_date DESC, created_at DESC;
+
+-- name: ListActiveBudgets :many
+SELECT *
+FROM budgets
+WHERE
+    user_id = sqlc.arg('user_id')
+    AND start_date <= sqlc.arg('at')::DATE
+    AND end_date >= sqlc.arg('at')::DATE
+ORDER BY name ASC, created_at ASC;
+
+-- name: DeleteBudget :execrows
+DELETE FROM budgets
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id');
+
+-- name: GetBudgetSpending :one
+-- Sums the expenses of a category over [period_start, period_end) converted
+-- to the user's base currency using the closest prior exchange rate.
+WITH user_base_currency AS (
+    SELECT COALESCE((SELECT currency FROM preferences WHERE user_id = sqlc.arg('user_id') LIMIT 1), 'USD')::TEXT AS base_currency
+)
+SELECT
+    (SELECT base_currency FROM user_base_currency) AS base_currency,
+    COALESCE(SUM(ABS(t.amount) * COALESCE(er.rate, 1.0)), 0)::DECIMAL AS spent
+FROM transactions t
+LEFT JOIN LATERAL (
+    SELECT rate FROM exchange_rates er
+    WHERE er.from_currency = t.transaction_currency
+      AND er.to_currency = (SELECT base_currency FROM user_base_currency)
+      AND er.effective_date <= t.transaction_datetime::DATE
+    ORDER BY er.effective_date DESC
+    LIMIT 1
+) er ON TRUE
+WHERE
+    t.created_by = sqlc.arg('user_id')
+    AND t.category_id = sqlc.arg('category_id')
+    AND t.type = 'expense'
+    AND t.deleted_at IS NULL
+    AND t.transaction_datetime >= sqlc.arg('period_start')::TIMESTAMPTZ
+    AND t.transaction_datetime < sqlc.arg('period_end')::TIMESTAMPTZ;
+
+-- name: ListRolloverEnabledBudgets :many
+SELECT *
+FROM budgets
+WHERE
+    rollover_enabled = TRUE
+    AND start_date < sqlc.arg('before')::DATE
+ORDER BY id;
+
+-- name: CreateBudgetRollover :exec
+INSERT INTO budget_rollovers (
+    budget_id,
+    user_id,
+    period_start,
+    period_end,
+    budgeted_amount,
+    carried_in,
+    spent_amount,
+    rollover_amount
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (budget_id, period_start) DO NOTHING;
+
+-- name: GetLatestBudgetRollover :one
+SELECT *
+FROM budget_rollovers
+WHERE budget_id = sqlc.arg('budget_id')
+ORDER BY period_start DESC
+LIMIT 1;
+
+-- name: GetBudgetRolloverByPeriodEnd :one
+SELECT *
+FROM budget_rollovers
+WHERE budget_id = sqlc.arg('budget_id') AND period_end = sqlc.arg('period_end')
+LIMIT 1;
+
+-- name: ListBudgetRollovers :many
+SELECT *
+FROM budget_rollovers
+WHERE budget_id = sqlc.arg('budget_id') AND user_id = sqlc.arg('user_id')
+ORDER BY period_start DESC;
+
+-- name: RecordBudgetAlert :execrows
+INSERT INTO budget_alerts (
+    budget_id,
+    period_start,
+    budgeted_amount,
+    spent_amount
+) VALUES (
+    sqlc.arg('budget_id'), sqlc.arg('period_start'), sqlc.arg('budgeted_amount'), sqlc.arg('spent_amount')
+)
+ON CONFLICT (budget_id, period_start) DO NOTHING;
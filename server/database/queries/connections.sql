@@ -60,6 +60,8 @@ UPDATE user_financial_connections
 SET
     status = $2,
     last_sync_at = $3,
+    sync_failures = 0,
+    last_error = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = sqlc.arg('user_id')
 RETURNING *;
@@ -76,3 +78,44 @@ RETURNING *;
 SELECT * FROM user_financial_connections
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
+
+-- name: ListSyncableConnections :many
+SELECT * FROM user_financial_connections
+WHERE status IN ('active', 'error')
+ORDER BY last_sync_at ASC NULLS FIRST;
+
+-- name: SetConnectionSyncFailed :one
+UPDATE user_financial_connections
+SET
+    status = $2,
+    sync_failures = sync_failures + 1,
+    last_error = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetConnectionByItemID :one
+SELECT * FROM user_financial_connections
+WHERE provider_name = $1
+  AND item_id = $2
+LIMIT 1;
+
+-- name: GetConnectionByProviderAccountID :one
+SELECT c.* FROM user_financial_connections c
+JOIN accounts a ON a.connection_id = c.id
+WHERE c.provider_name = $1
+  AND a.provider_account_id = $2
+  AND a.deleted_at IS NULL
+LIMIT 1;
+
+-- name: SetConnectionStatus :one
+UPDATE user_financial_connections
+SET
+    status = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: LockConnectionSync :exec
+-- Serialises the syncs of a connection until the end of the transaction
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('connection_id')::uuid::text, 0));
@@ -0,0 +1,61 @@
+-- name: CreateExport :one
+INSERT INTO exports (
+    user_id,
+    format,
+    filters
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('format'),
+    sqlc.arg('filters')
+) RETURNING *;
+
+-- name: GetExport :one
+SELECT *
+FROM exports
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id');
+
+-- name: GetExportByID :one
+SELECT *
+FROM exports
+WHERE id = sqlc.arg('id');
+
+-- name: ListExports :many
+SELECT *
+FROM exports
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: StartExport :exec
+UPDATE exports
+SET
+    status = 'processing',
+    error = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: CompleteExport :exec
+UPDATE exports
+SET
+    status = 'completed',
+    storage_key = sqlc.arg('storage_key'),
+    row_count = sqlc.arg('row_count'),
+    file_size = sqlc.arg('file_size'),
+    completed_at = current_timestamp
+WHERE id = sqlc.arg('id');
+
+-- name: FailExport :exec
+UPDATE exports
+SET
+    status = 'failed',
+    error = sqlc.arg('error'),
+    completed_at = current_timestamp
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteExport :one
+DELETE FROM exports
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+RETURNING *;
@@ -0,0 +1,117 @@
+-- name: SaveImportMapping :one
+INSERT INTO import_mappings (
+    user_id,
+    name,
+    mapping
+) VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('name'),
+    sqlc.arg('mapping')
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET
+    mapping = EXCLUDED.mapping,
+    updated_at = current_timestamp
+RETURNING *;
+
+-- name: GetImportMapping :one
+SELECT *
+FROM import_mappings
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id');
+
+-- name: ListImportMappings :many
+SELECT *
+FROM import_mappings
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY name;
+
+-- name: DeleteImportMapping :execrows
+DELETE FROM import_mappings
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id');
+
+-- name: CreateTransactionImport :one
+INSERT INTO transaction_imports (
+    user_id,
+    account_id,
+    format,
+    filename,
+    row_count,
+    duplicate_count
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransactionImport :one
+SELECT *
+FROM transaction_imports
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id');
+
+-- name: ListTransactionImports :many
+SELECT *
+FROM transaction_imports
+WHERE user_id = sqlc.arg('user_id')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CommitTransactionImport :execrows
+-- Only matches a staged import, a concurrent commit waits for the row lock then matches nothing
+UPDATE transaction_imports
+SET
+    status = 'committed',
+    committed_count = sqlc.arg('committed_count'),
+    committed_at = current_timestamp
+WHERE
+    id = sqlc.arg('id')
+    AND status = 'staged';
+
+-- name: DeleteStagedTransactionImport :execrows
+DELETE FROM transaction_imports
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+    AND status = 'staged';
+
+-- name: CreateStagedTransactions :copyfrom
+INSERT INTO staged_transactions (
+    import_id,
+    line,
+    transaction_datetime,
+    amount,
+    currency,
+    description,
+    payee,
+    reference,
+    duplicate_of
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+);
+
+-- name: ListStagedTransactions :many
+SELECT *
+FROM staged_transactions
+WHERE import_id = sqlc.arg('import_id')
+ORDER BY line;
+
+-- name: SetStagedTransactionCommitted :exec
+UPDATE staged_transactions
+SET transaction_id = sqlc.arg('transaction_id')
+WHERE id = sqlc.arg('id');
+
+-- name: ListImportDuplicateCandidates :many
+-- Transactions of the account dated around the statement, to find the lines already recorded
+SELECT
+    id,
+    amount,
+    transaction_datetime,
+    description
+FROM transactions
+WHERE
+    account_id = sqlc.arg('account_id')
+    AND deleted_at IS NULL
+    AND transaction_datetime BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date');
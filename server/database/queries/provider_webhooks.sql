@@ -0,0 +1,20 @@
+-- name: RecordProviderWebhookDelivery :execrows
+-- A delivery already recorded is a replay, unless it was received before replay_window_start
+-- (deliveries identified by a hash of their payload are only replays for a while)
+INSERT INTO provider_webhook_deliveries (
+    provider_name,
+    delivery_id,
+    event_type
+) VALUES (
+    sqlc.arg('provider_name'),
+    sqlc.arg('delivery_id'),
+    sqlc.arg('event_type')
+)
+ON CONFLICT (provider_name, delivery_id) DO UPDATE SET
+    event_type = EXCLUDED.event_type,
+    received_at = current_timestamp
+WHERE provider_webhook_deliveries.received_at < sqlc.narg('replay_window_start')::timestamptz;
+
+-- name: PruneProviderWebhookDeliveries :execrows
+DELETE FROM provider_webhook_deliveries
+WHERE received_at < sqlc.arg('received_before');
@@ -71,14 +71,25 @@ SET
 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 RETURNING *;
 
--- name: UpdateRecurringTransactionAfterGeneration :exec
+-- name: UpdateRecurringTransactionAfterGeneration :execrows
+-- Only the pending instance is generated: no row is updated when a concurrent post or the
+-- daily job already moved the template past due_date
 UPDATE recurring_transactions
 SET
-    last_generated_date = $2,
-    next_due_date = $3,
+    last_generated_date = sqlc.arg('last_generated_date'),
+    next_due_date = sqlc.arg('next_due_date'),
     occurrences_count = occurrences_count + 1,
     updated_at = current_timestamp
-WHERE id = $1;
+WHERE id = sqlc.arg('id') AND next_due_date = sqlc.arg('due_date') AND deleted_at IS NULL;
+
+-- name: SkipRecurringInstance :one
+-- Moves the template past the pending instance at due_date, unless it already moved
+UPDATE recurring_transactions
+SET
+    next_due_date = sqlc.arg('next_due_date'),
+    updated_at = current_timestamp
+WHERE id = sqlc.arg('id') AND next_due_date = sqlc.arg('due_date') AND deleted_at IS NULL
+RETURNING *;
 
 -- name: PauseRecurringTransaction :one
 UPDATE recurring_transactions
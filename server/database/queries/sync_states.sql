@@ -0,0 +1,20 @@
+-- name: GetAccountSyncState :one
+SELECT * FROM account_sync_states
+WHERE account_id = $1
+LIMIT 1;
+
+-- name: UpsertAccountSyncState :one
+INSERT INTO account_sync_states (
+    account_id,
+    last_seen_at,
+    last_synced_at,
+    cursor
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (account_id) DO UPDATE SET
+    last_seen_at = GREATEST(account_sync_states.last_seen_at, EXCLUDED.last_seen_at),
+    last_synced_at = EXCLUDED.last_synced_at,
+    cursor = COALESCE(EXCLUDED.cursor, account_sync_states.cursor),
+    updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,36 @@
+-- name: CreateRuleApplication :one
+INSERT INTO transaction_rule_applications (
+    rule_id,
+    transaction_id,
+    actions,
+    changes,
+    created_by
+) VALUES (
+    sqlc.arg('rule_id'),
+    sqlc.arg('transaction_id'),
+    sqlc.arg('actions'),
+    sqlc.arg('changes'),
+    sqlc.arg('created_by')
+) RETURNING *;
+
+-- name: GetRuleApplication :one
+SELECT *
+FROM transaction_rule_applications
+WHERE id = sqlc.arg('id') AND created_by = sqlc.arg('user_id');
+
+-- name: ListRuleApplications :many
+SELECT
+    a.*,
+    t.description AS transaction_description,
+    t.transaction_datetime
+FROM transaction_rule_applications a
+JOIN transactions t ON t.id = a.transaction_id
+WHERE a.rule_id = sqlc.arg('rule_id') AND a.created_by = sqlc.arg('user_id')
+ORDER BY a.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: MarkRuleApplicationUndone :one
+UPDATE transaction_rule_applications
+SET undone_at = current_timestamp
+WHERE id = sqlc.arg('id') AND undone_at IS NULL
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: CreateTransactionSplit :one
+INSERT INTO transaction_splits (
+    transaction_id,
+    category_id,
+    amount,
+    percentage,
+    description
+) VALUES (
+    sqlc.arg('transaction_id'),
+    sqlc.arg('category_id'),
+    sqlc.arg('amount'),
+    sqlc.arg('percentage'),
+    sqlc.narg('description')
+) RETURNING *;
+
+-- name: ListTransactionSplits :many
+SELECT *
+FROM transaction_splits
+WHERE transaction_id = ANY(sqlc.arg('transaction_ids')::uuid[])
+ORDER BY transaction_id, created_at, percentage DESC;
+
+-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits
+WHERE transaction_id = sqlc.arg('transaction_id');
@@ -118,7 +118,8 @@ WHERE
          )
     )
 ORDER BY
-    t.transaction_datetime DESC
+    t.transaction_datetime DESC,
+    t.id DESC
 LIMIT
     sqlc.arg('limit')
 OFFSET
@@ -229,22 +230,24 @@ SET deleted_at = current_timestamp
 WHERE id = sqlc.arg('id')
 RETURNING *;
 
--- name: BulkDeleteTransactions :exec
+-- name: BulkDeleteTransactions :many
 UPDATE transactions
 SET deleted_at = current_timestamp
 WHERE id = ANY(sqlc.arg('ids')::uuid[])
-    AND created_by = sqlc.arg('user_id');
+    AND created_by = sqlc.arg('user_id')
+RETURNING *;
 
--- name: BulkUpdateTransactionCategories :exec
+-- name: BulkUpdateTransactionCategories :many
 UPDATE transactions
 SET 
     category_id = sqlc.arg('category_id'),
     updated_by = sqlc.arg('updated_by')
 WHERE id = ANY(sqlc.arg('ids')::uuid[])
     AND created_by = sqlc.arg('user_id')
-    AND deleted_at IS NULL;
+    AND deleted_at IS NULL
+RETURNING *;
 
--- name: BulkUpdateManualTransactions :exec
+-- name: BulkUpdateManualTransactions :many
 UPDATE transactions
 SET 
     category_id = coalesce(sqlc.narg('category_id'), category_id),
@@ -254,7 +257,8 @@ SET
 WHERE id = ANY(sqlc.arg('ids')::uuid[])
     AND created_by = sqlc.arg('user_id')
     AND is_external = false
-    AND deleted_at IS NULL;
+    AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetTransactionStats :one
 SELECT
@@ -283,3 +287,112 @@ WHERE
     AND c.deleted_at IS NULL
 GROUP BY c.id, c.name
 ORDER BY total_amount DESC;
+
+-- name: ListProviderTransactions :many
+-- Includes soft deleted rows so transactions removed by the user aren't imported again
+SELECT
+    id,
+    provider_transaction_id::text AS provider_transaction_id,
+    amount,
+    transaction_datetime,
+    COALESCE(details->>'payment_status', '')::text AS payment_status,
+    deleted_at
+FROM transactions
+WHERE
+    account_id = sqlc.arg('account_id')
+    AND provider_transaction_id = ANY(sqlc.arg('provider_transaction_ids')::text[]);
+
+-- name: ListPendingProviderTransactions :many
+SELECT
+    id,
+    provider_transaction_id::text AS provider_transaction_id,
+    amount,
+    transaction_datetime,
+    description,
+    COALESCE(details->>'merchant', '')::text AS merchant
+FROM transactions
+WHERE
+    account_id = sqlc.arg('account_id')
+    AND provider_transaction_id IS NOT NULL
+    AND deleted_at IS NULL
+    AND details->>'payment_status' = 'pending'
+    AND (sqlc.narg('since')::timestamptz IS NULL OR transaction_datetime >= sqlc.narg('since')::timestamptz);
+
+-- name: ReconcileProviderTransaction :exec
+UPDATE transactions
+SET
+    provider_transaction_id = sqlc.arg('provider_transaction_id'),
+    amount = sqlc.arg('amount'),
+    original_amount = sqlc.arg('amount'),
+    transaction_datetime = sqlc.arg('transaction_datetime'),
+    details = jsonb_set(COALESCE(details, '{}'::jsonb), '{payment_status}', to_jsonb(sqlc.arg('payment_status')::text)),
+    updated_at = current_timestamp
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteDroppedProviderTransactions :exec
+UPDATE transactions
+SET deleted_at = current_timestamp
+WHERE
+    account_id = sqlc.arg('account_id')
+    AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: DeleteRemovedProviderTransactions :exec
+UPDATE transactions
+SET deleted_at = current_timestamp
+WHERE
+    account_id = sqlc.arg('account_id')
+    AND provider_transaction_id = ANY(sqlc.arg('provider_transaction_ids')::text[])
+    AND deleted_at IS NULL;
+
+-- name: FindTransferCounterpart :one
+-- The other side of a transfer recorded as an expense and an income: same amount with the
+-- opposite sign on another account of the user, closest in time
+SELECT *
+FROM transactions
+WHERE
+    created_by = sqlc.arg('user_id')
+    AND deleted_at IS NULL
+    AND id <> sqlc.arg('id')
+    AND account_id <> sqlc.arg('account_id')
+    AND type = sqlc.arg('type')
+    AND amount = sqlc.arg('amount')
+    AND transaction_currency = sqlc.arg('currency')
+    AND transaction_datetime BETWEEN sqlc.arg('start_date') AND sqlc.arg('end_date')
+ORDER BY abs(extract(epoch FROM transaction_datetime - sqlc.arg('transaction_datetime')::timestamptz))
+LIMIT 1;
+
+-- name: LinkTransfer :one
+UPDATE transactions
+SET
+    type = 'transfer',
+    amount = sqlc.arg('amount'),
+    account_id = sqlc.arg('account_id'),
+    destination_account_id = sqlc.arg('destination_account_id'),
+    updated_by = sqlc.arg('updated_by')
+WHERE
+    id = sqlc.arg('id')
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RevertTransaction :one
+-- Puts back the fields rules changed as they were, unlike UpdateTransaction null values are kept
+UPDATE transactions
+SET
+    type = sqlc.arg('type'),
+    amount = sqlc.arg('amount'),
+    account_id = sqlc.arg('account_id'),
+    destination_account_id = sqlc.narg('destination_account_id'),
+    category_id = sqlc.arg('category_id'),
+    description = sqlc.narg('description'),
+    details = sqlc.narg('details'),
+    updated_by = sqlc.arg('updated_by')
+WHERE
+    id = sqlc.arg('id')
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreTransaction :one
+UPDATE transactions
+SET deleted_at = NULL
+WHERE id = sqlc.arg('id') AND deleted_at IS NOT NULL
+RETURNING *;
@@ -17,7 +17,12 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE id = $1 LIMIT 1;
 
@@ -29,7 +34,12 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE user_id = $1
 ORDER BY created_at DESC;
@@ -42,18 +52,48 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE $1 = ANY(event)
 ORDER BY created_at;
 
+-- name: ListActiveWebhookSubscriptionsForEvent :many
+SELECT
+    id,
+    user_id,
+    event,
+    active,
+    endpoint_url,
+    secret,
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
+FROM webhook_subscriptions
+WHERE
+    user_id = sqlc.arg('user_id')
+    AND active
+    AND (sqlc.arg('event_type')::text = ANY(event) OR '*' = ANY(event))
+ORDER BY created_at;
+
 -- name: UpdateWebhookSubscription :one
 UPDATE webhook_subscriptions
 SET
     event = coalesce(sqlc.narg('event'), event),
     endpoint_url = coalesce(sqlc.narg('endpoint_url'), endpoint_url),
     secret = coalesce(sqlc.narg('secret'), secret),
-    active = coalesce(sqlc.narg('active'), active)
+    active = coalesce(sqlc.narg('active'), active),
+    -- Reactivating a subscription gives it a fresh start
+    consecutive_failures = CASE WHEN sqlc.narg('active')::boolean THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE WHEN sqlc.narg('active')::boolean THEN NULL ELSE disabled_at END,
+    disabled_reason = CASE WHEN sqlc.narg('active')::boolean THEN NULL ELSE disabled_reason END
 WHERE
     id = sqlc.arg('id')
     AND user_id = sqlc.arg('user_id')
@@ -99,7 +139,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE id = $1 LIMIT 1;
 
@@ -112,7 +155,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE subscription_id = $1
 ORDER BY created_at DESC;
@@ -126,7 +172,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE
     status IN ('pending', 'retrying')
@@ -135,6 +184,70 @@ WHERE
 ORDER BY created_at
 LIMIT $1;
 
+-- name: ListWebhookEventsBySubscription :many
+SELECT
+    id,
+    subscription_id,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_attempt,
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
+FROM webhook_events
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: RecordWebhookEventAttempt :one
+UPDATE webhook_events
+SET
+    status = $2,
+    attempts = attempts + 1,
+    last_attempt = now(),
+    response_status = $3,
+    last_error = $4,
+    delivered_at = CASE WHEN $2 = 'sent' THEN now() ELSE delivered_at END
+WHERE id = $1
+RETURNING *;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1;
+
+-- name: RotateWebhookSubscriptionSecret :one
+-- The current secret keeps signing deliveries, next to the new one, until previous_secret_expires_at
+UPDATE webhook_subscriptions
+SET
+    previous_secret = secret,
+    previous_secret_expires_at = sqlc.arg('previous_secret_expires_at'),
+    secret = sqlc.arg('secret')
+WHERE
+    id = sqlc.arg('id')
+    AND user_id = sqlc.arg('user_id')
+RETURNING *;
+
+-- name: RecordWebhookSubscriptionFailure :one
+-- Counts a failed delivery and disables the subscription once max_failures is reached
+UPDATE webhook_subscriptions
+SET
+    consecutive_failures = consecutive_failures + 1,
+    active = CASE WHEN consecutive_failures + 1 >= sqlc.arg('max_failures')::int THEN false ELSE active END,
+    disabled_at = CASE
+        WHEN active AND consecutive_failures + 1 >= sqlc.arg('max_failures')::int THEN now()
+        ELSE disabled_at
+    END,
+    disabled_reason = CASE
+        WHEN active AND consecutive_failures + 1 >= sqlc.arg('max_failures')::int THEN sqlc.narg('disabled_reason')
+        ELSE disabled_reason
+    END
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
 -- name: UpdateWebhookEventStatus :one
 UPDATE webhook_events
 SET
@@ -153,3 +266,50 @@ DELETE FROM webhook_events
 WHERE
     created_at < now() - INTERVAL '30 days'
     AND status IN ('sent', 'failed');
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (
+    event_id,
+    subscription_id,
+    attempt,
+    request_url,
+    request_headers,
+    response_status,
+    response_body,
+    error_message,
+    duration_ms
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+);
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT
+    a.id,
+    a.event_id,
+    e.event_type,
+    a.attempt,
+    a.request_url,
+    a.request_headers,
+    a.response_status,
+    a.response_body,
+    a.error_message,
+    a.duration_ms,
+    a.created_at
+FROM webhook_delivery_attempts a
+JOIN webhook_events e ON e.id = a.event_id
+WHERE
+    a.subscription_id = sqlc.arg('subscription_id')
+    AND (sqlc.narg('event_id')::uuid IS NULL OR a.event_id = sqlc.narg('event_id')::uuid)
+ORDER BY a.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ResetWebhookEventForRedelivery :one
+-- Only finished deliveries, a pending or retrying one would race with its next attempt
+UPDATE webhook_events
+SET
+    status = 'pending',
+    last_error = NULL
+WHERE
+    id = $1
+    AND status IN ('sent', 'failed')
+RETURNING *;
@@ -7,11 +7,18 @@ var (
 	ErrAccountTypeInvalid       = errors.New("accounts.account_invalid")
 	ErrAccountQueryParamInvalid = errors.New("accounts.invalid_start_date")
 	ErrEndDateBeforeStart       = errors.New("accounts.end_before_start")
+	ErrConnectionNotFound       = errors.New("accounts.connection_not_found")
+	ErrConnectionReauthRequired = errors.New("accounts.connection_reauth_required")
+	ErrConnectionDisconnected   = errors.New("accounts.connection_disconnected")
+	ErrInvalidSyncType          = errors.New("accounts.invalid_sync_type")
+	ErrSyncTooFrequent          = errors.New("accounts.sync_too_frequent")
 )
 
 var (
 	MonoLinkedMessage   = "accounts.mono.success"
 	TellerLinkedMessage = "accounts.teller.success"
+	PlaidLinkedMessage  = "accounts.plaid.success"
+	SyncQueuedMessage   = "accounts.sync.queued"
 )
 
 // var TellerLinkedMessage =  "Teller connection successful. Accounts are being processed."
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/accounts"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/request"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+)
+
+func (h *Handler) GetConnections(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	connections, err := h.service.ListConnections(ctx, userID)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details: map[string]any{
+				"requestUrl": r.RequestURI,
+				"operation":  "ListConnections",
+			},
+		})
+		return
+	}
+
+	respond.Json(w, http.StatusOK, connections, h.logger)
+}
+
+func (h *Handler) GetConnectionAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	connectionID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	accs, err := h.service.GetConnectionAccounts(ctx, userID, connectionID)
+	if err != nil {
+		h.connectionError(w, r, err, connectionID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, accs, h.logger)
+}
+
+func (h *Handler) ReconnectConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	connectionID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	connection, err := h.service.ReconnectConnection(ctx, userID, connectionID)
+	if err != nil {
+		h.connectionError(w, r, err, connectionID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, connection, h.logger)
+}
+
+func (h *Handler) DeleteConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	connectionID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if err = h.service.DeleteConnection(ctx, userID, connectionID); err != nil {
+		h.connectionError(w, r, err, connectionID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+// SyncConnection queues a sync of the connection. The optional "type" query parameter
+// selects a "full" or "incremental" (default) sync
+func (h *Handler) SyncConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	connectionID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	syncType := r.URL.Query().Get("type")
+	if syncType == "" {
+		syncType = "incremental"
+	}
+
+	if err = h.service.SyncConnection(ctx, userID, connectionID, syncType); err != nil {
+		h.connectionError(w, r, err, connectionID)
+		return
+	}
+
+	respond.Response(w, r, http.StatusAccepted, accounts.SyncQueuedMessage, nil)
+}
+
+// connectionError maps the errors of the connection management service to a response
+func (h *Handler) connectionError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, accounts.ErrConnectionNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = accounts.ErrConnectionNotFound
+	case errors.Is(err, accounts.ErrInvalidSyncType):
+		statusCode = http.StatusBadRequest
+		clientErr = accounts.ErrInvalidSyncType
+	case errors.Is(err, accounts.ErrConnectionReauthRequired):
+		statusCode = http.StatusConflict
+		clientErr = accounts.ErrConnectionReauthRequired
+	case errors.Is(err, accounts.ErrConnectionDisconnected):
+		statusCode = http.StatusConflict
+		clientErr = accounts.ErrConnectionDisconnected
+	case errors.Is(err, accounts.ErrSyncTooFrequent):
+		statusCode = http.StatusTooManyRequests
+		clientErr = accounts.ErrSyncTooFrequent
+	case errors.Is(err, finance.ErrRateLimitExceeded):
+		statusCode = http.StatusTooManyRequests
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
@@ -356,6 +356,19 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = h.service.DeleteAccount(ctx, accountID); err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusNotFound,
+				ClientErr:  accounts.ErrAccountNotFound,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    accountID,
+			})
+			return
+		}
+
 		respond.Error(respond.ErrorOptions{
 			W:          w,
 			R:          r,
@@ -675,204 +688,131 @@ func (h *Handler) MonoConnect(w http.ResponseWriter, r *http.Request) {
 	respond.Response(w, r, http.StatusOK, accounts.MonoLinkedMessage, nil)
 }
 
-// func (h *Handler) MonoWebhook(w http.ResponseWriter, r *http.Request) {
-// 	ctx := r.Context()
-//
-// 	var webhook struct {
-// 		Event string `json:"event"`
-// 		Data  struct {
-// 			ID       string `json:"id"`
-// 			Customer string `json:"customer"`
-// 			Meta     struct {
-// 				DataStatus string `json:"data_status"`
-// 				AuthMethod string `json:"auth_method"`
-// 			} `json:"meta"`
-// 			Account *struct {
-// 				ID            string  `json:"_id"`
-// 				Name          string  `json:"name"`
-// 				Currency      string  `json:"currency"`
-// 				Type          string  `json:"type"`
-// 				AccountNumber string  `json:"accountNumber"`
-// 				Balance       float64 `json:"balance"`
-// 				Institution   struct {
-// 					Name     string `json:"name"`
-// 					BankCode string `json:"bankCode"`
-// 					Type     string `json:"type"`
-// 				} `json:"institution"`
-// 				BVN string `json:"bvn"`
-// 			} `json:"account"`
-// 		} `json:"data"`
-// 	}
-//
-// 	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
-// 		respond.Error(respond.ErrorOptions{
-// 			W:          w,
-// 			R:          r,
-// 			StatusCode: http.StatusBadRequest,
-// 			ClientErr:  message.ErrBadRequest,
-// 			ActualErr:  err,
-// 			Logger:     h.logger,
-// 			Details:    nil,
-// 		})
-// 		return
-// 	}
-//
-// 	switch webhook.Event {
-// 	case "mono.events.account_connected":
-// 		// Account has been linked successfully
-// 		h.logger.Info().
-// 			Str("account_id", webhook.Data.ID).
-// 			Str("customer", webhook.Data.Customer).
-// 			Msg("Mono account connected")
-//
-// 	case "mono.events.account_updated":
-// 		// Account data status has been updated
-// 		if webhook.Data.Meta.DataStatus == "AVAILABLE" && webhook.Data.Account != nil {
-// 			// Data is now available, create the account in our system
-// 			err := h.createMonoAccount(ctx, webhook.Data.ID, *webhook.Data.Account)
-// 			if err != nil {
-// 				h.logger.Error().Err(err).
-// 					Str("account_id", webhook.Data.ID).
-// 					Msg("Failed to create account from Mono webhook")
-// 			}
-// 		}
-// 	}
-//
-// 	// Acknowledge webhook
-// 	w.WriteHeader(http.StatusOK)
-// }
-//
-// func (h *Handler) createMonoAccount(ctx context.Context, monoAccountID string, monoAccount struct {
-// 	ID            string  `json:"_id"`
-// 	Name          string  `json:"name"`
-// 	Currency      string  `json:"currency"`
-// 	Type          string  `json:"type"`
-// 	AccountNumber string  `json:"accountNumber"`
-// 	Balance       float64 `json:"balance"`
-// 	Institution   struct {
-// 		Name     string `json:"name"`
-// 		BankCode string `json:"bankCode"`
-// 		Type     string `json:"type"`
-// 	} `json:"institution"`
-// 	BVN string `json:"bvn"`
-// },
-// ) error {
-// 	// Find the user associated with this Mono account
-// 	connection, err := h.repo.GetConnectionByProviderAccountID(ctx, "mono", monoAccountID)
-// 	if err != nil {
-// 		return fmt.Errorf("failed to find connection: %w", err)
-// 	}
-//
-// 	// Map Mono account type to your internal account type
-// 	accountType, err := mapMonoAccountType(monoAccount.Type)
-// 	if err != nil {
-// 		return fmt.Errorf("failed to map account type: %w", err)
-// 	}
-//
-// 	// Create account in your system
-// 	params := repository.CreateAccountParams{
-// 		CreatedBy:         &connection.UserID,
-// 		Name:              fmt.Sprintf("%s - %s", monoAccount.Institution.Name, monoAccount.Name),
-// 		Type:              accountType,
-// 		Balance:           types.Numeric(monoAccount.Balance / 100), // Mono returns balance in kobo/cents
-// 		Currency:          monoAccount.Currency,
-// 		ProviderAccountID: &monoAccountID,
-// 		Provider:          stringPtr("mono"),
-// 		AccountNumber:     &monoAccount.AccountNumber,
-// 		InstitutionName:   &monoAccount.Institution.Name,
-// 		Color:             "blue", // Default color, you might want to make this configurable
-// 		Meta: map[string]interface{}{
-// 			"mono_account_id":  monoAccount.ID,
-// 			"bank_code":        monoAccount.Institution.BankCode,
-// 			"institution_type": monoAccount.Institution.Type,
-// 			"bvn":              monoAccount.BVN,
-// 		},
-// 	}
-//
-// 	var account repository.Account
-// 	if monoAccount.Balance == 0 {
-// 		account, err = h.repo.CreateAccount(ctx, params)
-// 	} else {
-// 		account, err = h.repo.CreateAccountWInitalTrs(ctx, params)
-// 	}
-//
-// 	if err != nil {
-// 		return fmt.Errorf("failed to create account: %w", err)
-// 	}
-//
-// 	h.logger.Info().
-// 		Str("account_id", account.ID).
-// 		Str("mono_account_id", monoAccountID).
-// 		Str("user_id", connection.UserID).
-// 		Msg("Successfully created account from Mono")
-//
-// 	return nil
-// }
+func (h *Handler) CreatePlaidLinkToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-// func (h *Handler) PlaidConnect(w http.ResponseWriter, r *http.Request) {
-// 	ctx := r.Context()
-//
-// 	userID, err := jwt.GetUserID(r)
-// 	if err != nil {
-// 		respond.Error(respond.ErrorOptions{
-// 			W:          w,
-// 			R:          r,
-// 			StatusCode: http.StatusInternalServerError,
-// 			ClientErr:  message.ErrInternalError,
-// 			ActualErr:  err,
-// 			Logger:     h.logger,
-// 			Details:    userID,
-// 		})
-// 		return
-// 	}
-//
-// plaidClientID := os.Getenv("PLAID_CLIENT_ID")
-// plaidSecret := os.Getenv("PLAID_SECRET")
-// if plaidClientID == "" || plaidSecret == "" {
-// 	http.Error(w, "Plaid credentials not configured", http.StatusInternalServerError)
-// 	return
-// }
-//
-// reqBody := models.CreateLinkTokenRequest{
-// 	PlaidClientID: plaidClientID,
-// 	PlaidSecret:   plaidSecret,
-// 	ClientName:    "Personal Finance Manager", // Your app name
-// 	Language:      "en",
-// 	CountryCodes:  []string{"US"},
-// 	User: struct {
-// 		ClientUserID string `json:"client_user_id"`
-// 	}{
-// 		ClientUserID: userID.String(), // Pass your internal user ID to Plaid
-// 	},
-// 	Products: []string{"transactions"}, // Or other products like "assets", "investments", "balance"
-// }
-//
-// jsonReqBody, _ := json.Marshal(reqBody)
-//
-// resp, err := http.Post(getPlaidEnvURL()+"/link/token/create", "application/json", bytes.NewBuffer(jsonReqBody))
-// if err != nil {
-// 	log.Printf("Error calling Plaid /link/token/create: %v", err)
-// 	http.Error(w, "Failed to connect to Plaid", http.StatusInternalServerError)
-// 	return
-// }
-// defer resp.Body.Close()
-//
-// if resp.StatusCode != http.StatusOK {
-// 	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-// 	log.Printf("Plaid /link/token/create returned non-OK status: %d, body: %s", resp.StatusCode, string(bodyBytes))
-// 	http.Error(w, fmt.Sprintf("Plaid error: %s", string(bodyBytes)), resp.StatusCode)
-// 	return
-// }
-//
-// var linkTokenRes models.CreateLinkTokenResponse
-// if err := json.NewDecoder(resp.Body).Decode(&linkTokenRes); err != nil {
-// 	log.Printf("Error decoding Plaid link token response: %v", err)
-// 	http.Error(w, "Failed to parse Plaid response", http.StatusInternalServerError)
-// 	return
-// }
-//
-// json.NewEncoder(w).Encode(linkTokenRes)
-// }
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    userID,
+		})
+		return
+	}
+
+	var req accounts.PlaidLinkTokenRequest
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	linkToken, err := h.service.CreatePlaidLinkToken(ctx, userID, req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    userID,
+		})
+		return
+	}
+
+	respond.Json(w, http.StatusOK, linkToken, h.logger)
+}
+
+func (h *Handler) ExchangePlaidToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    userID,
+		})
+		return
+	}
+
+	var req accounts.PlaidConnectRequest
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req.Institution,
+		})
+		return
+	}
+
+	err = h.service.LinkPlaid(ctx, userID, req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    userID,
+		})
+		return
+	}
+
+	respond.Response(w, r, http.StatusOK, accounts.PlaidLinkedMessage, nil)
+}
 
 // TODO: Interesting
 
@@ -29,26 +29,22 @@ func RegisterHTTPHandlers(service service.Account, validator *validation.Validat
 
 	// Connection management
 	// router.Post("/connections", h.CreateConnection)
-	// router.Get("/connections", h.GetConnections)
-	// router.Put("/connections/{id}/reconnect", h.ReconnectConnection)
-	// router.Delete("/connections/{id}", h.DeleteConnection)
+	router.Get("/connections", h.GetConnections)
+	router.Put("/connections/{id}/reconnect", h.ReconnectConnection)
+	router.Delete("/connections/{id}", h.DeleteConnection)
 
 	// Account management
-	// router.Get("/connections/{id}/accounts", h.GetConnectionAccounts)
-	// router.Post("/connections/{id}/sync", h.SyncTransactions)
+	router.Get("/connections/{id}/accounts", h.GetConnectionAccounts)
+	router.Post("/connections/{id}/sync", h.SyncConnection)
 	// protectedRoutes.HandleFunc("/plaid/items", handlers.GetPlaidItems).Methods("GET") // New endpoint
 	// protectedRoutes.HandleFunc("/plaid/sync", handlers.SyncPlaidData).Methods("POST") // New endpoint
 
 	// Provider-specific endpoints
-	// router.Post("/plaid/link-token", h.CreatePlaidLinkToken)
-	// router.Post("/plaid/exchange-token", h.ExchangePlaidToken)
+	router.Post("/plaid/link-token", h.CreatePlaidLinkToken)
+	router.Post("/plaid/exchange-token", h.ExchangePlaidToken)
 	router.Post("/teller/connect", h.TellerConnect)
-	// router.Post("/plaid/connect", h.PlaidConnect)
 	router.Post("/mono/connect", h.MonoConnect)
-	// router.Post("/mono/webhook", h.MonoConnect)
 
-	// router.Post("/plaid/create_link_token", handlers.CreateLinkToken)
-	// router.Post("/plaid/exchange_public_token", handlers.ExchangePublicToken)
 	// router.Post("/plaid/webhook", handlers.HandlePlaidWebhook)
 
 	// Complex queries
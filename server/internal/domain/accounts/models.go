@@ -43,3 +43,25 @@ type UserFinancialConnection struct {
 	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 }
+
+// NewUserFinancialConnection converts a stored connection into its API representation
+func NewUserFinancialConnection(c repository.UserFinancialConnection) UserFinancialConnection {
+	status := ""
+	if c.Status != nil {
+		status = *c.Status
+	}
+
+	return UserFinancialConnection{
+		ID:              c.ID,
+		UserID:          c.UserID,
+		ProviderName:    c.ProviderName,
+		ItemID:          c.ItemID,
+		InstitutionID:   c.InstitutionID,
+		InstitutionName: c.InstitutionName,
+		Status:          status,
+		LastSyncAt:      c.LastSyncAt,
+		ExpiresAt:       c.ExpiresAt,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}
+}
@@ -22,6 +22,8 @@ type Account interface {
 	UpdateAccount(ctx context.Context, account repository.UpdateAccountParams) (repository.Account, error)
 	DeleteAccount(ctx context.Context, id uuid.UUID) error
 	UpdateAccountBalance(ctx context.Context, params repository.UpdateAccountBalanceParams) error
+	GetAccountsByConnectionID(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) ([]repository.GetAccountsByConnectionIDRow, error)
+	DetachAccountsFromConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) error
 
 	// GetAccountsBTimeline
 	GetAccountsBTimeline(ctx context.Context, userID uuid.UUID) ([]repository.GetAccountsBalanceTimelineRow, error)
@@ -87,6 +89,20 @@ func (r *repo) UpdateAccountBalance(ctx context.Context, params repository.Updat
 	return r.queries.UpdateAccountBalance(ctx, params)
 }
 
+func (r *repo) GetAccountsByConnectionID(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) ([]repository.GetAccountsByConnectionIDRow, error) {
+	return r.queries.GetAccountsByConnectionID(ctx, repository.GetAccountsByConnectionIDParams{
+		ConnectionID: &connectionID,
+		CreatedBy:    &userID,
+	})
+}
+
+func (r *repo) DetachAccountsFromConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) error {
+	return r.queries.DetachAccountsFromConnection(ctx, repository.DetachAccountsFromConnectionParams{
+		ConnectionID: &connectionID,
+		CreatedBy:    &userID,
+	})
+}
+
 func (r *repo) GetAccountsBTimeline(ctx context.Context, userID uuid.UUID) ([]repository.GetAccountsBalanceTimelineRow, error) {
 	return r.queries.GetAccountsBalanceTimeline(ctx, userID)
 }
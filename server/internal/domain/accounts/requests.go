@@ -51,6 +51,22 @@ type TellerConnectRequest struct {
 	} `json:"user"`
 }
 
+// PlaidLinkTokenRequest represents the request to initialize Plaid Link
+type PlaidLinkTokenRequest struct {
+	Products     []string `json:"products,omitempty"`
+	CountryCodes []string `json:"country_codes,omitempty" validate:"omitempty,dive,len=2"`
+	RedirectURI  string   `json:"redirect_uri,omitempty" validate:"omitempty,url"`
+}
+
+// PlaidConnectRequest carries the public token and metadata returned by Plaid Link on success
+type PlaidConnectRequest struct {
+	PublicToken string `json:"public_token" validate:"required"`
+	Institution struct {
+		ID   string `json:"institution_id"`
+		Name string `json:"name"`
+	} `json:"institution"`
+}
+
 type MonoConnectRequest struct {
 	Code          string `json:"code" validate:"required"`
 	Institution   string `json:"institution" validate:"required"`
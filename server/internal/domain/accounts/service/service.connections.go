@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/accounts"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (a *AccountService) ListConnections(ctx context.Context, userID uuid.UUID) ([]accounts.UserFinancialConnection, error) {
+	rows, err := a.repo.GetConnectionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := make([]accounts.UserFinancialConnection, 0, len(rows))
+	for _, row := range rows {
+		connections = append(connections, accounts.NewUserFinancialConnection(row))
+	}
+
+	return connections, nil
+}
+
+func (a *AccountService) GetConnectionAccounts(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) ([]repository.GetAccountsByConnectionIDRow, error) {
+	if _, err := a.getUserConnection(ctx, userID, connectionID); err != nil {
+		return nil, err
+	}
+
+	return a.repo.GetAccountsByConnectionID(ctx, userID, connectionID)
+}
+
+// ReconnectConnection asks the provider to refresh the connection and, when it is healthy again,
+// marks it active and queues an incremental sync
+func (a *AccountService) ReconnectConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) (accounts.UserFinancialConnection, error) {
+	connection, err := a.getUserConnection(ctx, userID, connectionID)
+	if err != nil {
+		return accounts.UserFinancialConnection{}, err
+	}
+
+	provider, err := a.openFinanceManager.GetProvider(connection.ProviderName)
+	if err != nil {
+		return accounts.UserFinancialConnection{}, err
+	}
+
+	accessToken, err := a.encrypt.Decrypt(connection.AccessTokenEncrypted)
+	if err != nil {
+		return accounts.UserFinancialConnection{}, fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	err = provider.RefreshConnection(ctx, string(accessToken))
+	if err == nil {
+		var healthy bool
+		if healthy, err = provider.GetConnectionStatus(ctx, string(accessToken)); err == nil && !healthy {
+			err = finance.ErrAuthenticationFailed
+		}
+	}
+
+	if err != nil {
+		if !errors.Is(err, finance.ErrAuthenticationFailed) {
+			return accounts.UserFinancialConnection{}, err
+		}
+
+		status := jobs.ConnectionStatusReauthRequired
+		flagged, statusErr := a.repo.SetConnectionErrorStatus(ctx, repository.SetConnectionErrorStatusParams{
+			ID:     connection.ID,
+			UserID: userID,
+			Status: &status,
+		})
+		if statusErr != nil {
+			a.logger.Error().Err(statusErr).Any("connection_id", connection.ID).Msg("Failed to flag connection for re-authentication")
+		} else {
+			a.events.Publish(ctx, userID, events.ConnectionReauthRequired, events.ConnectionData{
+				Connection: events.NewConnection(flagged),
+			})
+		}
+
+		return accounts.UserFinancialConnection{}, fmt.Errorf("%w: %w", accounts.ErrConnectionReauthRequired, err)
+	}
+
+	status := jobs.ConnectionStatusActive
+	updated, err := a.repo.UpdateConnection(ctx, repository.UpdateConnectionParams{
+		ID:         connection.ID,
+		UserID:     userID,
+		Status:     &status,
+		LastSyncAt: nullTimestamptz(connection.LastSyncAt),
+		ExpiresAt:  nullTimestamptz(connection.ExpiresAt),
+	})
+	if err != nil {
+		return accounts.UserFinancialConnection{}, err
+	}
+
+	if err := a.scheduler.EnqueueBankSync(ctx, userID, connection.ID, "incremental"); err != nil {
+		a.logger.Error().Err(err).Msg("Failed to schedule bank sync")
+	}
+
+	return accounts.NewUserFinancialConnection(updated), nil
+}
+
+// DeleteConnection revokes the connection at the provider and removes it locally.
+// Accounts linked to it are kept, with their history, but no longer synced
+func (a *AccountService) DeleteConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) error {
+	connection, err := a.getUserConnection(ctx, userID, connectionID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.removeProviderConnection(ctx, connection); err != nil {
+		return err
+	}
+
+	tx, err := a.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			a.logger.Error().Err(rbErr).Msg("Failed to rollback connection deletion")
+		}
+	}()
+
+	repo := a.repo.WithTx(tx)
+
+	if err := repo.DetachAccountsFromConnection(ctx, userID, connection.ID); err != nil {
+		return err
+	}
+
+	if err := repo.DeleteConnection(ctx, repository.DeleteConnectionParams{
+		ID:     connection.ID,
+		UserID: userID,
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	disconnected := events.NewConnection(connection)
+	disconnected.Status = jobs.ConnectionStatusDisconnected
+	a.events.Publish(ctx, userID, events.ConnectionDisconnected, events.ConnectionData{
+		Connection: disconnected,
+	})
+
+	return nil
+}
+
+func (a *AccountService) SyncConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID, syncType string) error {
+	if syncType != "full" && syncType != "incremental" {
+		return accounts.ErrInvalidSyncType
+	}
+
+	connection, err := a.getUserConnection(ctx, userID, connectionID)
+	if err != nil {
+		return err
+	}
+
+	if connection.Status != nil {
+		switch *connection.Status {
+		case jobs.ConnectionStatusDisconnected:
+			return accounts.ErrConnectionDisconnected
+		case jobs.ConnectionStatusReauthRequired:
+			return accounts.ErrConnectionReauthRequired
+		}
+	}
+
+	if !jobs.ManualBankSyncAllowed(connection, time.Now()) {
+		return accounts.ErrSyncTooFrequent
+	}
+
+	return a.scheduler.EnqueueBankSync(ctx, userID, connection.ID, syncType)
+}
+
+// getUserConnection loads a connection and makes sure it belongs to the user
+func (a *AccountService) getUserConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) (repository.UserFinancialConnection, error) {
+	connection, err := a.repo.GetConnectionByID(ctx, connectionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.UserFinancialConnection{}, accounts.ErrConnectionNotFound
+		}
+		return repository.UserFinancialConnection{}, err
+	}
+
+	if connection.UserID != userID {
+		return repository.UserFinancialConnection{}, accounts.ErrConnectionNotFound
+	}
+
+	return connection, nil
+}
+
+// removeProviderConnection revokes the access at the provider. Tokens the provider no longer
+// recognises, or providers that are not configured anymore, don't block the local deletion
+func (a *AccountService) removeProviderConnection(ctx context.Context, connection repository.UserFinancialConnection) error {
+	provider, err := a.openFinanceManager.GetProvider(connection.ProviderName)
+	if err != nil {
+		a.logger.Warn().Err(err).Str("provider", connection.ProviderName).Msg("Provider unavailable, removing connection locally only")
+		return nil
+	}
+
+	accessToken, err := a.encrypt.Decrypt(connection.AccessTokenEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	err = provider.RemoveConnection(ctx, string(accessToken))
+	if err != nil && !errors.Is(err, finance.ErrAuthenticationFailed) && !errors.Is(err, finance.ErrAccountNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func nullTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{Valid: false}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
 	"github.com/google/uuid"
@@ -39,6 +40,15 @@ type Account interface {
 	// Linking
 	LinkTeller(ctx context.Context, userID uuid.UUID, req accounts.TellerConnectRequest) error
 	LinkMono(ctx context.Context, userID uuid.UUID, req accounts.MonoConnectRequest) error
+	CreatePlaidLinkToken(ctx context.Context, userID uuid.UUID, req accounts.PlaidLinkTokenRequest) (*finance.LinkTokenResponse, error)
+	LinkPlaid(ctx context.Context, userID uuid.UUID, req accounts.PlaidConnectRequest) error
+
+	// Connection management
+	ListConnections(ctx context.Context, userID uuid.UUID) ([]accounts.UserFinancialConnection, error)
+	GetConnectionAccounts(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) ([]repository.GetAccountsByConnectionIDRow, error)
+	ReconnectConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) (accounts.UserFinancialConnection, error)
+	DeleteConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID) error
+	SyncConnection(ctx context.Context, userID uuid.UUID, connectionID uuid.UUID, syncType string) error
 
 	// Sync job management
 	// CreateSyncJob(ctx context.Context, job FinancialSyncJob) (*FinancialSyncJob, error)
@@ -54,10 +64,11 @@ type AccountService struct {
 	encrypt            *encrypt.Encrypter
 	openFinanceManager *finance.ProviderManager
 	scheduler          *jobs.Service
+	events             *events.Bus
 	logger             *zerolog.Logger
 }
 
-func New(db *pgxpool.Pool, encrypt *encrypt.Encrypter, opfn *finance.ProviderManager, scheduler *jobs.Service, repo accRepo.Account, trcRepo trcRepo.Transactions, ctgRepo ctgRepo.Category, logger *zerolog.Logger) *AccountService {
+func New(db *pgxpool.Pool, encrypt *encrypt.Encrypter, opfn *finance.ProviderManager, scheduler *jobs.Service, bus *events.Bus, repo accRepo.Account, trcRepo trcRepo.Transactions, ctgRepo ctgRepo.Category, logger *zerolog.Logger) *AccountService {
 	return &AccountService{
 		repo:               repo,
 		trcRepo:            trcRepo,
@@ -66,6 +77,7 @@ func New(db *pgxpool.Pool, encrypt *encrypt.Encrypter, opfn *finance.ProviderMan
 		encrypt:            encrypt,
 		openFinanceManager: opfn,
 		scheduler:          scheduler,
+		events:             bus,
 		logger:             logger,
 	}
 }
@@ -106,6 +118,7 @@ func (a *AccountService) CreateAccount(ctx context.Context, hasBalance bool, par
 		if err = tx.Commit(ctx); err != nil {
 			return repository.Account{}, err
 		}
+		a.publishAccount(ctx, events.AccountCreated, account)
 		return account, nil
 	}
 
@@ -150,6 +163,8 @@ func (a *AccountService) CreateAccount(ctx context.Context, hasBalance bool, par
 		return repository.Account{}, err
 	}
 
+	a.publishAccount(ctx, events.AccountCreated, account)
+
 	return account, nil
 }
 
@@ -192,11 +207,17 @@ func (a *AccountService) LinkTeller(ctx context.Context, userID uuid.UUID, req a
 	providerName := provider.GetProviderName()
 	isExternal := true
 
+	// Teller webhooks reference the enrollment
+	var enrollmentID *string
+	if req.Enrollment.ID != "" {
+		enrollmentID = &req.Enrollment.ID
+	}
+
 	connParams := repository.CreateConnectionParams{
 		UserID:               userID,
 		ProviderName:         providerName,
 		AccessTokenEncrypted: encryptedAccessToken,
-		ItemID:               nil, // Teller itemId is the accessID
+		ItemID:               enrollmentID,
 		InstitutionID:        institutionID,
 		InstitutionName:      institutionName,
 		Status:               &status,
@@ -278,7 +299,7 @@ func (a *AccountService) LinkMono(ctx context.Context, userID uuid.UUID, req acc
 		UserID:               userID,
 		ProviderName:         providerName,
 		AccessTokenEncrypted: encryptedMonoIdentifier,
-		ItemID:               nil,
+		ItemID:               &monoItemID, // Mono webhooks reference the account id
 		InstitutionID:        &req.InstitutionID,
 		InstitutionName:      &req.Institution,
 		Status:               &status,
@@ -291,10 +312,153 @@ func (a *AccountService) LinkMono(ctx context.Context, userID uuid.UUID, req acc
 	return err
 }
 
-func (r *AccountService) UpdateAccount(ctx context.Context, account repository.UpdateAccountParams) (repository.Account, error) {
-	return r.repo.UpdateAccount(ctx, account)
+func (a *AccountService) CreatePlaidLinkToken(ctx context.Context, userID uuid.UUID, req accounts.PlaidLinkTokenRequest) (*finance.LinkTokenResponse, error) {
+	provider, err := a.openFinanceManager.GetProvider("plaid")
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.CreateLinkToken(ctx, finance.LinkTokenRequest{
+		UserID:       userID.String(),
+		Products:     req.Products,
+		CountryCodes: req.CountryCodes,
+		RedirectURI:  req.RedirectURI,
+	})
+}
+
+func (a *AccountService) LinkPlaid(ctx context.Context, userID uuid.UUID, req accounts.PlaidConnectRequest) error {
+	provider, err := a.openFinanceManager.GetProvider("plaid")
+	if err != nil {
+		return err
+	}
+
+	exchangeResp, err := provider.ExchangePublicToken(ctx, finance.ExchangeTokenRequest{
+		PublicToken: req.PublicToken,
+		UserID:      userID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	accounts, err := provider.GetAccounts(ctx, exchangeResp.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	encryptedAccessToken, err := a.encrypt.Encrypt([]byte(exchangeResp.AccessToken))
+	if err != nil {
+		return err
+	}
+
+	institutionID := &req.Institution.ID
+	institutionName := &req.Institution.Name
+
+	if len(accounts) > 0 {
+		if req.Institution.ID == "" {
+			institutionID = &accounts[0].InstitutionID
+		}
+		if req.Institution.Name == "" {
+			institutionName = &accounts[0].InstitutionName
+		}
+	}
+
+	status := "active"
+	providerName := provider.GetProviderName()
+	isExternal := true
+
+	connection, err := a.repo.CreateConnection(ctx, repository.CreateConnectionParams{
+		UserID:               userID,
+		ProviderName:         providerName,
+		AccessTokenEncrypted: encryptedAccessToken,
+		ItemID:               &exchangeResp.ItemID,
+		InstitutionID:        institutionID,
+		InstitutionName:      institutionName,
+		Status:               &status,
+		LastSyncAt:           pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ExpiresAt:            pgtype.Timestamptz{Valid: false},
+	})
+	if err != nil {
+		return err
+	}
+
+	var accountCreationErrors []error
+
+	for _, providerAccount := range accounts {
+		_, err := a.repo.CreateAccount(ctx, repository.CreateAccountParams{
+			CreatedBy:         &userID,
+			Name:              providerAccount.Name,
+			Type:              providerAccount.Type,
+			Balance:           types.FloatToNullDecimal(providerAccount.Balance),
+			ProviderAccountID: &providerAccount.ProviderAccountID,
+			ProviderName:      &providerName,
+			IsExternal:        &isExternal,
+			Currency:          providerAccount.Currency,
+			ConnectionID:      &connection.ID,
+			Meta: dto.AccountMeta{
+				InstitutionName: *institutionName,
+			},
+		})
+		if err != nil {
+			accountCreationErrors = append(accountCreationErrors, fmt.Errorf("account %s (%s): %w", providerAccount.Name, providerAccount.ID, err))
+			continue
+		}
+	}
+
+	if len(accountCreationErrors) > 0 {
+		a.logger.Warn().Errs("errors", accountCreationErrors).Msg("Some accounts could not be created from Plaid")
+	}
+
+	if err = a.scheduler.EnqueueBankSync(ctx, userID, connection.ID, "full"); err != nil {
+		a.logger.Error().Err(err).Msg("Failed to schedule bank sync")
+	}
+
+	return err
+}
+
+func (r *AccountService) UpdateAccount(ctx context.Context, params repository.UpdateAccountParams) (repository.Account, error) {
+	account, err := r.repo.UpdateAccount(ctx, params)
+	if err != nil {
+		return repository.Account{}, err
+	}
+
+	r.publishAccount(ctx, events.AccountUpdated, account)
+
+	return account, nil
 }
 
 func (r *AccountService) DeleteAccount(ctx context.Context, id uuid.UUID) error {
-	return r.repo.DeleteAccount(ctx, id)
+	account, err := r.repo.GetAccountByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.repo.DeleteAccount(ctx, id); err != nil {
+		return err
+	}
+
+	if account.CreatedBy != nil {
+		r.events.Publish(ctx, *account.CreatedBy, events.AccountDeleted, events.AccountData{
+			Account: events.Account{
+				ID:           account.ID,
+				Name:         account.Name,
+				Type:         string(account.Type),
+				Balance:      types.PgtypeNumericToDecimal(account.Balance),
+				Currency:     account.Currency,
+				IsExternal:   account.IsExternal != nil && *account.IsExternal,
+				ConnectionID: account.ConnectionID,
+				UpdatedAt:    account.UpdatedAt,
+			},
+		})
+	}
+
+	return nil
+}
+
+// publishAccount notifies the owner's subscribers about a committed account change
+func (r *AccountService) publishAccount(ctx context.Context, eventType string, account repository.Account) {
+	if account.CreatedBy == nil {
+		return
+	}
+
+	r.events.Publish(ctx, *account.CreatedBy, eventType, events.NewAccountData(account))
 }
@@ -0,0 +1,9 @@
+package budgets
+
+import "errors"
+
+var (
+	ErrBudgetNotFound     = errors.New("budgets.not_found")
+	ErrInvalidDate        = errors.New("budgets.invalid_date")
+	ErrEndDateBeforeStart = errors.New("budgets.end_before_start")
+)
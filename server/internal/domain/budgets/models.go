@@ -7,30 +7,28 @@ import (
 )
 
 type Budget struct {
-	ID                 uuid.UUID  `json:"id"`
-	UserID             uuid.UUID  `json:"user_id"`
-	SharedFinanceID    *uuid.UUID `json:"shared_finance_id,omitempty"` // Nullable
-	CategoryID         uuid.UUID  `json:"category_id"`
-	Amount             float64    `json:"amount"`
-	Name               string     `json:"name"` // Added name for clarity
-	StartDate          time.Time  `json:"start_date"`
-	EndDate            time.Time  `json:"end_date"`
-	Frequency          string     `json:"frequency"`
-	RolloverEnabled    bool       `json:"rollover_enabled"`     // NEW
-	LastRolloverAmount float64    `json:"last_rollover_amount"` // NEW
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	SharedFinanceID *uuid.UUID `json:"shared_finance_id,omitempty"` // Nullable
+	CategoryID      uuid.UUID  `json:"category_id"`
+	Amount          float64    `json:"amount"`
+	Name            string     `json:"name"` // Added name for clarity
+	StartDate       time.Time  `json:"start_date"`
+	EndDate         time.Time  `json:"end_date"`
+	Frequency       string     `json:"frequency"`
+	RolloverEnabled bool       `json:"rollover_enabled"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type CreateBudgetRequest struct {
-	CategoryID uuid.UUID `json:"category_id" validate:"required"`
-	Amount     float64   `json:"amount" validate:"required,gte=0"`
-	Name       string    `json:"name"` // Added name for clarity
-	StartDate  time.Time `json:"start_date" validate:"required"`
-	EndDate    time.Time `json:"end_date" validate:"required"`
-	Frequency  string    `json:"frequency" validate:"required"`
-	// RolloverEnabled    bool      `json:"rollover_enabled"`     // NEW
-	// LastRolloverAmount float64   `json:"last_rollover_amount"` // NEW
+	CategoryID      uuid.UUID `json:"category_id" validate:"required"`
+	Amount          float64   `json:"amount" validate:"required,gte=0"`
+	Name            string    `json:"name"` // Added name for clarity
+	StartDate       time.Time `json:"start_date" validate:"required"`
+	EndDate         time.Time `json:"end_date" validate:"required"`
+	Frequency       string    `json:"frequency" validate:"required,oneof=weekly monthly yearly"`
+	RolloverEnabled bool      `json:"rollover_enabled"`
 }
 
 type BudgetProgressItem struct {
@@ -41,7 +39,24 @@ type BudgetProgressItem struct {
 	SpentAmount     float64   `json:"spent_amount"`
 	RemainingAmount float64   `json:"remaining_amount"`
 	PercentageUsed  float64   `json:"percentage_used"`
-	// NEW: Rollover details for frontend display
-	// RolloverEnabled   bool    `json:"rollover_enabled"`
-	// RolloverCarryover float64 `json:"rollover_carryover"` // Amount carried over this period
+	Frequency       string    `json:"frequency"`
+	Currency        string    `json:"currency"` // User's base currency
+	PeriodStart     time.Time `json:"period_start"`
+	PeriodEnd       time.Time `json:"period_end"` // Exclusive
+	// Rollover details for frontend display
+	RolloverEnabled   bool    `json:"rollover_enabled"`
+	RolloverCarryover float64 `json:"rollover_carryover"` // Amount carried over this period, negative if the previous one was overspent
+}
+
+// BudgetRollover is the closing record of a budget period
+type BudgetRollover struct {
+	ID             uuid.UUID `json:"id"`
+	BudgetID       uuid.UUID `json:"budget_id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"` // Exclusive
+	BudgetedAmount float64   `json:"budgeted_amount"`
+	CarriedIn      float64   `json:"carried_in"`
+	SpentAmount    float64   `json:"spent_amount"`
+	RolloverAmount float64   `json:"rollover_amount"`
+	CreatedAt      time.Time `json:"created_at"`
 }
@@ -0,0 +1,91 @@
+package budgets
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// Monitor watches transaction events and emits budget.exceeded the first time the spending of a
+// budget period goes over its amount
+type Monitor struct {
+	repo   Repository
+	events *events.Bus
+	logger *zerolog.Logger
+}
+
+func NewMonitor(repo Repository, bus *events.Bus, logger *zerolog.Logger) *Monitor {
+	return &Monitor{repo, bus, logger}
+}
+
+// Subscribe registers the monitor on the transaction events that can change a budget spending
+func (m *Monitor) Subscribe() {
+	m.events.Subscribe(events.TransactionCreated, m.HandleTransaction)
+	m.events.Subscribe(events.TransactionUpdated, m.HandleTransaction)
+}
+
+func (m *Monitor) HandleTransaction(ctx context.Context, envelope events.Envelope) error {
+	var data events.TransactionData
+	if err := json.Unmarshal(envelope.Data, &data); err != nil {
+		return err
+	}
+
+	transaction := data.Transaction
+	if transaction.Type != "expense" || transaction.CategoryID == nil {
+		return nil
+	}
+
+	budgets, err := m.repo.ListActiveBudgets(ctx, repository.ListActiveBudgetsParams{
+		UserID: envelope.UserID,
+		At:     pgtype.Date{Time: transaction.TransactionDatetime, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, b := range budgets {
+		if b.CategoryID != *transaction.CategoryID {
+			continue
+		}
+
+		p, ok, err := progressAt(ctx, m.repo, b, transaction.TransactionDatetime)
+		if err != nil {
+			return err
+		}
+
+		if !ok || !p.Spent.GreaterThan(p.Budgeted) {
+			continue
+		}
+
+		isNew, err := m.repo.RecordBudgetAlert(ctx, repository.RecordBudgetAlertParams{
+			BudgetID:       b.ID,
+			PeriodStart:    pgtype.Date{Time: p.Period.Start, Valid: true},
+			BudgetedAmount: p.Budgeted,
+			SpentAmount:    p.Spent,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !isNew {
+			continue
+		}
+
+		m.events.Publish(ctx, envelope.UserID, events.BudgetExceeded, events.BudgetExceededData{
+			BudgetID:    b.ID,
+			Name:        b.Name,
+			CategoryID:  b.CategoryID,
+			PeriodStart: p.Period.Start,
+			PeriodEnd:   p.Period.End,
+			Budgeted:    p.Budgeted,
+			Spent:       p.Spent,
+			Currency:    p.Currency,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package budgets
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/budgetperiod"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// periodProgress is the state of a budget over one of its periods
+type periodProgress struct {
+	Period    budgetperiod.Period
+	Budgeted  decimal.Decimal // Budget amount plus the carryover
+	Spent     decimal.Decimal
+	Carryover decimal.Decimal
+	Currency  string
+}
+
+// progressAt computes the progress of a budget over the period that contains the given date.
+// It returns false when the budget doesn't cover that date.
+func progressAt(ctx context.Context, repo Repository, b repository.Budget, at time.Time) (periodProgress, bool, error) {
+	period, ok := budgetperiod.At(b.StartDate.Time, b.EndDate.Time, b.Frequency, at)
+	if !ok {
+		return periodProgress{}, false, nil
+	}
+
+	spending, err := repo.GetBudgetSpending(ctx, repository.GetBudgetSpendingParams{
+		UserID:      b.UserID,
+		CategoryID:  b.CategoryID,
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+	})
+	if err != nil {
+		return periodProgress{}, false, err
+	}
+
+	// The closing record of the previous period holds what is carried into this one
+	carryover := decimal.Zero
+	if b.RolloverEnabled {
+		rollover, err := repo.GetBudgetRolloverByPeriodEnd(ctx, repository.GetBudgetRolloverByPeriodEndParams{
+			BudgetID:  b.ID,
+			PeriodEnd: pgtype.Date{Time: period.Start, Valid: true},
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return periodProgress{}, false, err
+		}
+
+		if err == nil {
+			carryover = types.PgtypeNumericToDecimal(rollover.RolloverAmount)
+		}
+	}
+
+	return periodProgress{
+		Period:    period,
+		Budgeted:  types.PgtypeNumericToDecimal(b.Amount).Add(carryover),
+		Spent:     types.PgtypeNumericToDecimal(spending.Spent),
+		Carryover: carryover,
+		Currency:  spending.BaseCurrency,
+	}, true, nil
+}
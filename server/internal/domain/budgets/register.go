@@ -5,16 +5,19 @@ import (
 
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
 	"github.com/Fantasy-Programming/nuts/server/pkg/router"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
 
-func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn *jwt.Service, logger *zerolog.Logger) http.Handler {
+func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn *jwt.Service, bus *events.Bus, logger *zerolog.Logger) http.Handler {
 	queries := repository.New(db)
 	repo := NewRepository(queries, db)
 
+	NewMonitor(repo, bus, logger).Subscribe()
+
 	h := NewHandler(validate, tkn, repo, logger)
 
 	// Create the auth verify middleware
@@ -23,11 +26,13 @@ func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn
 	router := router.NewRouter()
 	router.Use(middleware.Verify)
 
-	router.Post("/budgets", h.CreateBudget)
-	router.Get("/budgets/{id}", h.GetBudget)
-	router.Put("/budgets/{id}", h.UpdateBudget)
-	router.Delete("/budgets/{id}", h.DeleteBudget)
-	router.Get("/budgets/progress", h.GetBudgetProgress)
+	router.Get("/", h.ListBudgets)
+	router.Post("/", h.CreateBudget)
+	router.Get("/progress", h.GetBudgetProgress)
+	router.Get("/{id}", h.GetBudget)
+	router.Put("/{id}", h.UpdateBudget)
+	router.Delete("/{id}", h.DeleteBudget)
+	router.Get("/{id}/rollovers", h.ListBudgetRollovers)
 
 	return router
 }
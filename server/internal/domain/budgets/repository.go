@@ -4,12 +4,22 @@ import (
 	"context"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Repository defines the interface for account data operations
+// Repository defines the interface for budget data operations
 type Repository interface {
 	CreateBudget(ctx context.Context, params repository.CreateBudgetParams) (repository.CreateBudgetRow, error)
+	GetBudget(ctx context.Context, id, userID uuid.UUID) (repository.Budget, error)
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]repository.Budget, error)
+	ListActiveBudgets(ctx context.Context, params repository.ListActiveBudgetsParams) ([]repository.Budget, error)
+	UpdateBudget(ctx context.Context, params repository.UpdateBudgetParams) (int64, error)
+	DeleteBudget(ctx context.Context, id, userID uuid.UUID) (int64, error)
+	GetBudgetRolloverByPeriodEnd(ctx context.Context, params repository.GetBudgetRolloverByPeriodEndParams) (repository.BudgetRollover, error)
+	ListBudgetRollovers(ctx context.Context, params repository.ListBudgetRolloversParams) ([]repository.BudgetRollover, error)
+	GetBudgetSpending(ctx context.Context, params repository.GetBudgetSpendingParams) (repository.GetBudgetSpendingRow, error)
+	RecordBudgetAlert(ctx context.Context, params repository.RecordBudgetAlertParams) (bool, error)
 }
 
 type repo struct {
@@ -24,7 +34,59 @@ func NewRepository(queries *repository.Queries, db *pgxpool.Pool) Repository {
 	}
 }
 
-// GetAccounts retrieves all accounts for a specific user
+// CreateBudget creates a new budget
 func (r *repo) CreateBudget(ctx context.Context, params repository.CreateBudgetParams) (repository.CreateBudgetRow, error) {
 	return r.queries.CreateBudget(ctx, params)
 }
+
+// GetBudget retrieves a single budget owned by the user
+func (r *repo) GetBudget(ctx context.Context, id, userID uuid.UUID) (repository.Budget, error) {
+	return r.queries.GetBudgetById(ctx, repository.GetBudgetByIdParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+// ListBudgets retrieves all budgets of a user
+func (r *repo) ListBudgets(ctx context.Context, userID uuid.UUID) ([]repository.Budget, error) {
+	return r.queries.ListBudgets(ctx, userID)
+}
+
+// ListActiveBudgets retrieves the budgets of a user that cover the given date
+func (r *repo) ListActiveBudgets(ctx context.Context, params repository.ListActiveBudgetsParams) ([]repository.Budget, error) {
+	return r.queries.ListActiveBudgets(ctx, params)
+}
+
+// UpdateBudget updates a budget and returns the number of affected rows
+func (r *repo) UpdateBudget(ctx context.Context, params repository.UpdateBudgetParams) (int64, error) {
+	return r.queries.UpdateBudget(ctx, params)
+}
+
+// DeleteBudget deletes a budget and returns the number of affected rows
+func (r *repo) DeleteBudget(ctx context.Context, id, userID uuid.UUID) (int64, error) {
+	return r.queries.DeleteBudget(ctx, repository.DeleteBudgetParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+// GetBudgetSpending sums the expenses of a category over a period in the user's base currency
+func (r *repo) GetBudgetSpending(ctx context.Context, params repository.GetBudgetSpendingParams) (repository.GetBudgetSpendingRow, error) {
+	return r.queries.GetBudgetSpending(ctx, params)
+}
+
+// GetBudgetRolloverByPeriodEnd retrieves the rollover of the period that ended at the given date
+func (r *repo) GetBudgetRolloverByPeriodEnd(ctx context.Context, params repository.GetBudgetRolloverByPeriodEndParams) (repository.BudgetRollover, error) {
+	return r.queries.GetBudgetRolloverByPeriodEnd(ctx, params)
+}
+
+// ListBudgetRollovers retrieves the rollover history of a budget
+func (r *repo) ListBudgetRollovers(ctx context.Context, params repository.ListBudgetRolloversParams) ([]repository.BudgetRollover, error) {
+	return r.queries.ListBudgetRollovers(ctx, params)
+}
+
+// RecordBudgetAlert remembers that a budget period was exceeded, it returns false when it already was
+func (r *repo) RecordBudgetAlert(ctx context.Context, params repository.RecordBudgetAlertParams) (bool, error) {
+	rows, err := r.queries.RecordBudgetAlert(ctx, params)
+	return rows > 0, err
+}
@@ -0,0 +1,9 @@
+package exports
+
+import "errors"
+
+var (
+	ErrExportNotFound   = errors.New("exports.not_found")
+	ErrExportNotReady   = errors.New("exports.not_ready")
+	ErrExportInProgress = errors.New("exports.in_progress")
+)
@@ -0,0 +1,278 @@
+package exports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/request"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/export"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+	"github.com/rs/zerolog"
+)
+
+type Handler struct {
+	v       *validation.Validator
+	service *Service
+	logger  *zerolog.Logger
+}
+
+func NewHandler(validator *validation.Validator, service *Service, logger *zerolog.Logger) *Handler {
+	return &Handler{validator, service, logger}
+}
+
+// CreateExport queues an export, clients poll it until it is completed
+func (h *Handler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	var req ExportRequest
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	valErr, err := h.v.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	exp, err := h.service.Create(ctx, userID, req.Format, req.Filters)
+	if err != nil {
+		h.exportError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusAccepted, exp, h.logger)
+}
+
+func (h *Handler) ListExports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	exports, err := h.service.List(ctx, userID, page, limit)
+	if err != nil {
+		h.exportError(w, r, err, nil)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, exports, h.logger)
+}
+
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	exportID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	exp, err := h.service.Get(ctx, exportID, userID)
+	if err != nil {
+		h.exportError(w, r, err, exportID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, exp, h.logger)
+}
+
+// DownloadExport serves the file of an export through the API, for storages without presigned links
+func (h *Handler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	exportID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	exp, file, err := h.service.Download(ctx, exportID, userID)
+	if err != nil {
+		h.exportError(w, r, err, exportID)
+		return
+	}
+	defer file.Close()
+
+	format := export.Format(exp.Format)
+	filename := fmt.Sprintf("transactions-%s%s", exp.CreatedAt.Format("2006-01-02"), format.Extension())
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if exp.FileSize != nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(*exp.FileSize, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.Error().Err(err).Str("export_id", exp.ID.String()).Msg("Failed to send export file")
+	}
+}
+
+func (h *Handler) DeleteExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	exportID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if err := h.service.Delete(ctx, exportID, userID); err != nil {
+		h.exportError(w, r, err, exportID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+func (h *Handler) exportError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, ErrExportNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = err
+	case errors.Is(err, ErrExportNotReady),
+		errors.Is(err, ErrExportInProgress):
+		statusCode = http.StatusConflict
+		clientErr = err
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
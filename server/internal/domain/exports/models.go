@@ -0,0 +1,10 @@
+package exports
+
+import "github.com/Fantasy-Programming/nuts/server/internal/repository"
+
+// Export is an export with, once completed, a temporary link to its file. Storages that can't
+// presign links leave it empty, the file is then served by the download endpoint
+type Export struct {
+	repository.Export
+	DownloadURL *string `json:"download_url,omitempty"`
+}
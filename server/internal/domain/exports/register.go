@@ -0,0 +1,34 @@
+package exports
+
+import (
+	"net/http"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+	"github.com/Fantasy-Programming/nuts/server/pkg/router"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn *jwt.Service, scheduler *jobs.Service, store storage.Storage, bucket string, logger *zerolog.Logger) http.Handler {
+	queries := repository.New(db)
+	repo := NewRepository(queries)
+	service := NewService(db, repo, scheduler, store, bucket, logger)
+	h := NewHandler(validate, service, logger)
+
+	// Create the auth verify middleware
+	middleware := jwt.NewMiddleware(tkn)
+
+	router := router.NewRouter()
+	router.Use(middleware.Verify)
+	router.Get("/", h.ListExports)
+	router.Post("/", h.CreateExport)
+	router.Get("/{id}", h.GetExport)
+	router.Get("/{id}/download", h.DownloadExport)
+	router.Delete("/{id}", h.DeleteExport)
+
+	return router
+}
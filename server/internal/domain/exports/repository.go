@@ -0,0 +1,57 @@
+package exports
+
+import (
+	"context"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository defines the interface for export data operations
+type Repository interface {
+	CreateExport(ctx context.Context, params repository.CreateExportParams) (repository.Export, error)
+	GetExport(ctx context.Context, id, userID uuid.UUID) (repository.Export, error)
+	ListExports(ctx context.Context, params repository.ListExportsParams) ([]repository.Export, error)
+	DeleteExport(ctx context.Context, id, userID uuid.UUID) (repository.Export, error)
+	WithTx(tx pgx.Tx) Repository
+}
+
+type repo struct {
+	queries *repository.Queries
+}
+
+// NewRepository creates a new export repository
+func NewRepository(queries *repository.Queries) Repository {
+	return &repo{
+		queries: queries,
+	}
+}
+
+func (r *repo) WithTx(tx pgx.Tx) Repository {
+	return &repo{
+		queries: r.queries.WithTx(tx),
+	}
+}
+
+func (r *repo) CreateExport(ctx context.Context, params repository.CreateExportParams) (repository.Export, error) {
+	return r.queries.CreateExport(ctx, params)
+}
+
+func (r *repo) GetExport(ctx context.Context, id, userID uuid.UUID) (repository.Export, error) {
+	return r.queries.GetExport(ctx, repository.GetExportParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+func (r *repo) ListExports(ctx context.Context, params repository.ListExportsParams) ([]repository.Export, error) {
+	return r.queries.ListExports(ctx, params)
+}
+
+func (r *repo) DeleteExport(ctx context.Context, id, userID uuid.UUID) (repository.Export, error) {
+	return r.queries.DeleteExport(ctx, repository.DeleteExportParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
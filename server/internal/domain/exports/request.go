@@ -0,0 +1,8 @@
+package exports
+
+import "github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+
+type ExportRequest struct {
+	Format  string            `json:"format" validate:"required,oneof=csv json ofx"`
+	Filters dto.ExportFilters `json:"filters"`
+}
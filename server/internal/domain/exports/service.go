@@ -0,0 +1,160 @@
+package exports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// How long the download link of an export stays valid, clients poll the export for a new one
+const downloadURLExpiry = 15 * time.Minute
+
+// Service records export requests and queues their generation
+type Service struct {
+	db        *pgxpool.Pool
+	repo      Repository
+	scheduler *jobs.Service
+	storage   storage.Storage
+	bucket    string
+	logger    *zerolog.Logger
+}
+
+func NewService(db *pgxpool.Pool, repo Repository, scheduler *jobs.Service, store storage.Storage, bucket string, logger *zerolog.Logger) *Service {
+	return &Service{db, repo, scheduler, store, bucket, logger}
+}
+
+// Create records an export and queues it in the same transaction, so no export stays pending
+// without a job
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, format string, filters dto.ExportFilters) (*Export, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error().Err(err).Msg("Failed to rollback export creation")
+		}
+	}()
+
+	exp, err := s.repo.WithTx(tx).CreateExport(ctx, repository.CreateExportParams{
+		UserID:  userID,
+		Format:  format,
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduler.EnqueueExportTx(ctx, tx, exp.ID, userID); err != nil {
+		return nil, fmt.Errorf("failed to enqueue export: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &Export{Export: exp}, nil
+}
+
+// Get returns an export, with a fresh download link once it is completed
+func (s *Service) Get(ctx context.Context, id, userID uuid.UUID) (*Export, error) {
+	exp, err := s.repo.GetExport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	result := &Export{Export: exp}
+
+	if exp.Status != jobs.ExportStatusCompleted || exp.StorageKey == nil {
+		return result, nil
+	}
+
+	url, err := s.storage.GenerateGetSignedURL(ctx, s.bucket, *exp.StorageKey, downloadURLExpiry)
+	if err != nil {
+		if errors.Is(err, storage.ErrOperationNotSupported) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("failed to sign export download: %w", err)
+	}
+
+	result.DownloadURL = &url
+
+	return result, nil
+}
+
+func (s *Service) List(ctx context.Context, userID uuid.UUID, page, limit int) ([]repository.Export, error) {
+	return s.repo.ListExports(ctx, repository.ListExportsParams{
+		UserID: userID,
+		Limit:  int64(limit),
+		Offset: int64((page - 1) * limit),
+	})
+}
+
+// Download opens the file of a completed export, the caller closes it
+func (s *Service) Download(ctx context.Context, id, userID uuid.UUID) (repository.Export, io.ReadCloser, error) {
+	exp, err := s.repo.GetExport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return exp, nil, ErrExportNotFound
+		}
+		return exp, nil, err
+	}
+
+	if exp.Status != jobs.ExportStatusCompleted || exp.StorageKey == nil {
+		return exp, nil, ErrExportNotReady
+	}
+
+	file, err := s.storage.Download(ctx, s.bucket, *exp.StorageKey)
+	if err != nil {
+		return exp, nil, fmt.Errorf("failed to download export: %w", err)
+	}
+
+	return exp, file, nil
+}
+
+// Delete removes an export and its file. Exports being generated can't be deleted, the worker
+// would upload a file nothing points to
+func (s *Service) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	exp, err := s.repo.GetExport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExportNotFound
+		}
+		return err
+	}
+
+	if exp.Status == jobs.ExportStatusProcessing {
+		return ErrExportInProgress
+	}
+
+	exp, err = s.repo.DeleteExport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExportNotFound
+		}
+		return err
+	}
+
+	if exp.StorageKey != nil {
+		if err := s.storage.Delete(ctx, s.bucket, *exp.StorageKey); err != nil {
+			s.logger.Error().Err(err).Str("export_id", exp.ID.String()).Msg("Failed to delete export file")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package integrations
+
+import "errors"
+
+var (
+	ErrUnsupportedProvider  = errors.New("integrations.unsupported_provider")
+	ErrInvalidSignature     = errors.New("integrations.invalid_signature")
+	ErrInvalidPayload       = errors.New("integrations.invalid_payload")
+	ErrWebhookNotConfigured = errors.New("integrations.webhook_not_configured")
+)
@@ -0,0 +1,176 @@
+package integrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+)
+
+// webhookEvent is a provider webhook translated into the changes it implies for our connections
+type webhookEvent struct {
+	// Unique per delivery, used to ignore replays
+	DeliveryID string
+	// How long a delivery with the same id is a replay, zero for as long as deliveries are kept
+	ReplayWindow time.Duration
+	Type         string
+	Updates      []connectionUpdate
+}
+
+// Mono retries a failed delivery within hours, an identical payload received later is a new event
+// (a second reauthorisation request for the same account)
+const monoReplayWindow = 24 * time.Hour
+
+// connectionUpdate targets a connection either by its provider item id or by one of its accounts
+type connectionUpdate struct {
+	ItemID            string
+	ProviderAccountID string
+
+	// Status to set on the connection, empty keeps the current one
+	Status string
+
+	// Whether new data is available and a sync should be enqueued
+	Sync bool
+}
+
+type tellerEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload struct {
+		EnrollmentID string `json:"enrollment_id"`
+		Reason       string `json:"reason"`
+		Transactions []struct {
+			AccountID string `json:"account_id"`
+		} `json:"transactions"`
+	} `json:"payload"`
+}
+
+// parseTellerEvent translates a Teller webhook, see https://teller.io/docs/api/webhooks
+func parseTellerEvent(body []byte) (webhookEvent, error) {
+	var event tellerEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return webhookEvent{}, ErrInvalidPayload
+	}
+
+	if event.ID == "" || event.Type == "" {
+		return webhookEvent{}, ErrInvalidPayload
+	}
+
+	result := webhookEvent{
+		DeliveryID: event.ID,
+		Type:       event.Type,
+	}
+
+	switch event.Type {
+	case "enrollment.disconnected":
+		if event.Payload.EnrollmentID == "" {
+			return webhookEvent{}, ErrInvalidPayload
+		}
+
+		// A closed enrollment can't be repaired, every other reason needs the user to reconnect
+		status := jobs.ConnectionStatusReauthRequired
+		if event.Payload.Reason == "disconnected.enrollment_closed" {
+			status = jobs.ConnectionStatusDisconnected
+		}
+
+		result.Updates = append(result.Updates, connectionUpdate{
+			ItemID: event.Payload.EnrollmentID,
+			Status: status,
+		})
+
+	case "transactions.processed":
+		seen := make(map[string]bool)
+		for _, transaction := range event.Payload.Transactions {
+			if transaction.AccountID == "" || seen[transaction.AccountID] {
+				continue
+			}
+			seen[transaction.AccountID] = true
+
+			result.Updates = append(result.Updates, connectionUpdate{
+				ProviderAccountID: transaction.AccountID,
+				Sync:              true,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+type monoAccount struct {
+	ID string `json:"_id"`
+}
+
+type monoEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		ID      string       `json:"id"`
+		Account *monoAccount `json:"account"`
+		Meta    struct {
+			DataStatus string `json:"data_status"`
+		} `json:"meta"`
+	} `json:"data"`
+}
+
+// parseMonoEvent translates a Mono webhook, see https://docs.mono.co/docs/webhooks
+func parseMonoEvent(body []byte) (webhookEvent, error) {
+	var event monoEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return webhookEvent{}, ErrInvalidPayload
+	}
+
+	if event.Event == "" {
+		return webhookEvent{}, ErrInvalidPayload
+	}
+
+	// Mono doesn't send an event id, identical payloads within monoReplayWindow are the same delivery
+	sum := sha256.Sum256(body)
+
+	result := webhookEvent{
+		DeliveryID:   hex.EncodeToString(sum[:]),
+		ReplayWindow: monoReplayWindow,
+		Type:         event.Event,
+	}
+
+	accountID := event.Data.ID
+	if event.Data.Account != nil && event.Data.Account.ID != "" {
+		accountID = event.Data.Account.ID
+	}
+
+	var update connectionUpdate
+
+	switch event.Event {
+	case "mono.events.account_updated":
+		switch strings.ToUpper(event.Data.Meta.DataStatus) {
+		case "AVAILABLE":
+			update = connectionUpdate{Status: jobs.ConnectionStatusActive, Sync: true}
+		case "FAILED":
+			update = connectionUpdate{Status: jobs.ConnectionStatusError}
+		default:
+			// Still processing
+			return result, nil
+		}
+
+	case "mono.events.reauthorisation_required":
+		update = connectionUpdate{Status: jobs.ConnectionStatusReauthRequired}
+
+	case "mono.events.account_unlinked":
+		update = connectionUpdate{Status: jobs.ConnectionStatusDisconnected}
+
+	default:
+		return result, nil
+	}
+
+	if accountID == "" {
+		return webhookEvent{}, ErrInvalidPayload
+	}
+
+	// The Mono account id is stored as the connection item id
+	update.ItemID = accountID
+	update.ProviderAccountID = accountID
+	result.Updates = append(result.Updates, update)
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package integrations
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/rs/zerolog"
+)
+
+// Provider webhooks are small JSON documents
+const maxWebhookBodySize = 1 << 20
+
+type Handler struct {
+	service *Service
+	logger  *zerolog.Logger
+}
+
+func NewHandler(service *Service, logger *zerolog.Logger) *Handler {
+	return &Handler{service, logger}
+}
+
+// ProviderWebhook receives the webhooks of financial providers, it's public and authenticated by
+// the provider signature
+func (h *Handler) ProviderWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    provider,
+		})
+		return
+	}
+
+	if err := h.service.HandleWebhook(r.Context(), provider, r.Header, body); err != nil {
+		h.webhookError(w, r, provider, err)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+func (h *Handler) webhookError(w http.ResponseWriter, r *http.Request, provider string, err error) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, ErrUnsupportedProvider):
+		statusCode = http.StatusNotFound
+		clientErr = ErrUnsupportedProvider
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrWebhookNotConfigured):
+		statusCode = http.StatusUnauthorized
+		clientErr = ErrInvalidSignature
+	case errors.Is(err, ErrInvalidPayload):
+		statusCode = http.StatusBadRequest
+		clientErr = ErrInvalidPayload
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    provider,
+	})
+}
@@ -0,0 +1,26 @@
+package integrations
+
+import (
+	"net/http"
+
+	"github.com/Fantasy-Programming/nuts/server/config"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/Fantasy-Programming/nuts/server/pkg/router"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+func RegisterHTTPHandlers(db *pgxpool.Pool, cfg config.Integrations, scheduler *jobs.Service, bus *events.Bus, logger *zerolog.Logger) http.Handler {
+	queries := repository.New(db)
+	repo := NewRepository(queries)
+	service := NewService(db, repo, scheduler, bus, cfg, logger)
+	h := NewHandler(service, logger)
+
+	// Providers authenticate with their webhook signature, not a user token
+	router := router.NewRouter()
+	router.Post("/{provider}/webhook", h.ProviderWebhook)
+
+	return router
+}
@@ -0,0 +1,59 @@
+package integrations
+
+import (
+	"context"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository defines the data operations needed to process provider webhooks
+type Repository interface {
+	WithTx(tx pgx.Tx) Repository
+	RecordDelivery(ctx context.Context, params repository.RecordProviderWebhookDeliveryParams) (bool, error)
+	GetConnectionByItemID(ctx context.Context, providerName, itemID string) (repository.UserFinancialConnection, error)
+	GetConnectionByProviderAccountID(ctx context.Context, providerName, providerAccountID string) (repository.UserFinancialConnection, error)
+	SetConnectionStatus(ctx context.Context, params repository.SetConnectionStatusParams) (repository.UserFinancialConnection, error)
+}
+
+type repo struct {
+	queries *repository.Queries
+}
+
+// NewRepository creates a new integrations repository
+func NewRepository(queries *repository.Queries) Repository {
+	return &repo{
+		queries: queries,
+	}
+}
+
+func (r *repo) WithTx(tx pgx.Tx) Repository {
+	return &repo{queries: r.queries.WithTx(tx)}
+}
+
+// RecordDelivery stores a delivery and reports whether it is new, replays return false
+func (r *repo) RecordDelivery(ctx context.Context, params repository.RecordProviderWebhookDeliveryParams) (bool, error) {
+	rows, err := r.queries.RecordProviderWebhookDelivery(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *repo) GetConnectionByItemID(ctx context.Context, providerName, itemID string) (repository.UserFinancialConnection, error) {
+	return r.queries.GetConnectionByItemID(ctx, repository.GetConnectionByItemIDParams{
+		ProviderName: providerName,
+		ItemID:       &itemID,
+	})
+}
+
+func (r *repo) GetConnectionByProviderAccountID(ctx context.Context, providerName, providerAccountID string) (repository.UserFinancialConnection, error) {
+	return r.queries.GetConnectionByProviderAccountID(ctx, repository.GetConnectionByProviderAccountIDParams{
+		ProviderName:      providerName,
+		ProviderAccountID: &providerAccountID,
+	})
+}
+
+func (r *repo) SetConnectionStatus(ctx context.Context, params repository.SetConnectionStatusParams) (repository.UserFinancialConnection, error) {
+	return r.queries.SetConnectionStatus(ctx, params)
+}
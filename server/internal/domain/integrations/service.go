@@ -0,0 +1,211 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/config"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+type Service struct {
+	db        *pgxpool.Pool
+	repo      Repository
+	scheduler *jobs.Service
+	events    *events.Bus
+	cfg       config.Integrations
+	logger    *zerolog.Logger
+}
+
+func NewService(db *pgxpool.Pool, repo Repository, scheduler *jobs.Service, bus *events.Bus, cfg config.Integrations, logger *zerolog.Logger) *Service {
+	return &Service{db, repo, scheduler, bus, cfg, logger}
+}
+
+// HandleWebhook verifies and applies a provider webhook. Replayed deliveries are ignored, and
+// nothing is recorded when processing fails so that the provider retries.
+func (s *Service) HandleWebhook(ctx context.Context, provider string, header http.Header, body []byte) error {
+	event, err := s.parseWebhook(provider, header, body)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			s.logger.Error().Err(rbErr).Msg("Failed to rollback provider webhook")
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+
+	delivery := repository.RecordProviderWebhookDeliveryParams{
+		ProviderName: provider,
+		DeliveryID:   event.DeliveryID,
+		EventType:    event.Type,
+	}
+
+	if event.ReplayWindow > 0 {
+		delivery.ReplayWindowStart = pgtype.Timestamptz{Valid: true, Time: time.Now().Add(-event.ReplayWindow)}
+	}
+
+	isNew, err := repo.RecordDelivery(ctx, delivery)
+	if err != nil {
+		return err
+	}
+
+	if !isNew {
+		s.logger.Debug().Str("provider", provider).Str("delivery_id", event.DeliveryID).Msg("Ignoring replayed provider webhook")
+		return nil
+	}
+
+	handled := make(map[uuid.UUID]bool)
+	var changed []repository.UserFinancialConnection
+
+	for _, update := range event.Updates {
+		connection, err := s.findConnection(ctx, repo, provider, update)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Warn().
+					Str("provider", provider).
+					Str("event", event.Type).
+					Str("item_id", update.ItemID).
+					Str("provider_account_id", update.ProviderAccountID).
+					Msg("Provider webhook for an unknown connection")
+				continue
+			}
+			return err
+		}
+
+		if handled[connection.ID] {
+			continue
+		}
+		handled[connection.ID] = true
+
+		updated, err := s.applyUpdate(ctx, tx, repo, connection, update)
+		if err != nil {
+			return err
+		}
+
+		if updated != nil {
+			changed = append(changed, *updated)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.publishStatusChanges(ctx, changed)
+
+	return nil
+}
+
+// publishStatusChanges tells subscribers about connections that now need their attention
+func (s *Service) publishStatusChanges(ctx context.Context, connections []repository.UserFinancialConnection) {
+	for _, connection := range connections {
+		if connection.Status == nil {
+			continue
+		}
+
+		var eventType string
+
+		switch *connection.Status {
+		case jobs.ConnectionStatusReauthRequired:
+			eventType = events.ConnectionReauthRequired
+		case jobs.ConnectionStatusDisconnected:
+			eventType = events.ConnectionDisconnected
+		default:
+			continue
+		}
+
+		s.events.Publish(ctx, connection.UserID, eventType, events.ConnectionData{
+			Connection: events.NewConnection(connection),
+		})
+	}
+}
+
+func (s *Service) parseWebhook(provider string, header http.Header, body []byte) (webhookEvent, error) {
+	switch provider {
+	case "teller":
+		if err := verifyTellerSignature(header.Get("Teller-Signature"), body, s.cfg.TellerWebhookSecrets, time.Now()); err != nil {
+			return webhookEvent{}, err
+		}
+		return parseTellerEvent(body)
+
+	case "mono":
+		if err := verifyMonoSignature(header.Get("mono-webhook-secret"), s.cfg.MonoWebhookSecret); err != nil {
+			return webhookEvent{}, err
+		}
+		return parseMonoEvent(body)
+
+	default:
+		return webhookEvent{}, ErrUnsupportedProvider
+	}
+}
+
+func (s *Service) findConnection(ctx context.Context, repo Repository, provider string, update connectionUpdate) (repository.UserFinancialConnection, error) {
+	if update.ItemID != "" {
+		connection, err := repo.GetConnectionByItemID(ctx, provider, update.ItemID)
+		if err == nil || !errors.Is(err, pgx.ErrNoRows) || update.ProviderAccountID == "" {
+			return connection, err
+		}
+	}
+
+	if update.ProviderAccountID == "" {
+		return repository.UserFinancialConnection{}, pgx.ErrNoRows
+	}
+
+	return repo.GetConnectionByProviderAccountID(ctx, provider, update.ProviderAccountID)
+}
+
+// applyUpdate returns the connection when its status changed
+func (s *Service) applyUpdate(ctx context.Context, tx pgx.Tx, repo Repository, connection repository.UserFinancialConnection, update connectionUpdate) (*repository.UserFinancialConnection, error) {
+	status := ""
+	if connection.Status != nil {
+		status = *connection.Status
+	}
+
+	// A connection removed by the user stays removed
+	if status == jobs.ConnectionStatusDisconnected {
+		return nil, nil
+	}
+
+	// The first sync of a connection has to fetch its whole history
+	syncType := "incremental"
+	if status == jobs.ConnectionStatusPending || connection.LastSyncAt == nil {
+		syncType = "full"
+	}
+
+	var changed *repository.UserFinancialConnection
+
+	if update.Status != "" && update.Status != status {
+		updated, err := repo.SetConnectionStatus(ctx, repository.SetConnectionStatusParams{
+			ID:     connection.ID,
+			Status: &update.Status,
+		})
+		if err != nil {
+			return nil, err
+		}
+		changed = &updated
+		status = update.Status
+	}
+
+	if !update.Sync || status == jobs.ConnectionStatusReauthRequired {
+		return changed, nil
+	}
+
+	return changed, s.scheduler.EnqueueBankSyncTx(ctx, tx, connection.UserID, connection.ID, syncType)
+}
@@ -0,0 +1,87 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Teller rejects signatures older than this to prevent replays
+const tellerSignatureTolerance = 3 * time.Minute
+
+// verifyTellerSignature checks a Teller-Signature header ("t=<unix>,v1=<hex>[,v1=<hex>]"). The
+// signature is an HMAC-SHA256 of "<t>.<body>", several secrets are accepted during a rotation.
+func verifyTellerSignature(header string, body []byte, secrets []string, now time.Time) error {
+	if len(secrets) == 0 {
+		return ErrWebhookNotConfigured
+	}
+
+	var timestamp string
+	var signatures [][]byte
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if now.Sub(time.Unix(unix, 0)).Abs() > tellerSignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		expected := mac.Sum(nil)
+
+		for _, signature := range signatures {
+			if hmac.Equal(expected, signature) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// verifyMonoSignature checks the mono-webhook-secret header against the configured secret
+func verifyMonoSignature(header string, secret string) error {
+	if secret == "" {
+		return ErrWebhookNotConfigured
+	}
+
+	if subtle.ConstantTimeCompare([]byte(header), []byte(secret)) != 1 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package integrations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyTellerSignature(t *testing.T) {
+	body := []byte(`{"id":"wh_1","type":"webhook.test","payload":{}}`)
+	now := time.Unix(1700000000, 0)
+
+	// HMAC-SHA256("secret_a", "1700000000." + body)
+	const valid = "t=1700000000,v1=8014277f29c48fc4a233ef45d5dd751f77b78328134058842d52c4eb3a26e197"
+	const forged = "t=1700000000,v1=0f2e2a4d4d3dcfb8f70e0b7d0a8a1b5bc8a50c67b6a9c1b8be1fc8b6be2bd2c0"
+
+	tests := []struct {
+		name    string
+		header  string
+		secrets []string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid", header: valid, secrets: []string{"secret_a"}, now: now},
+		{name: "rotated secret", header: valid, secrets: []string{"secret_b", "secret_a"}, now: now},
+		{name: "one of several signatures", header: "t=1700000000,v1=00ff," + valid[len("t=1700000000,"):], secrets: []string{"secret_a"}, now: now},
+		{name: "wrong secret", header: valid, secrets: []string{"secret_b"}, now: now, wantErr: ErrInvalidSignature},
+		{name: "forged signature", header: forged, secrets: []string{"secret_a"}, now: now, wantErr: ErrInvalidSignature},
+		{name: "expired", header: valid, secrets: []string{"secret_a"}, now: now.Add(4 * time.Minute), wantErr: ErrInvalidSignature},
+		{name: "missing timestamp", header: valid[len("t=1700000000,"):], secrets: []string{"secret_a"}, now: now, wantErr: ErrInvalidSignature},
+		{name: "empty header", header: "", secrets: []string{"secret_a"}, now: now, wantErr: ErrInvalidSignature},
+		{name: "not configured", header: valid, now: now, wantErr: ErrWebhookNotConfigured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTellerSignature(tt.header, body, tt.secrets, tt.now)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("tampered body", func(t *testing.T) {
+		err := verifyTellerSignature(valid, []byte(`{"id":"wh_2"}`), []string{"secret_a"}, now)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestVerifyMonoSignature(t *testing.T) {
+	assert.NoError(t, verifyMonoSignature("sec_123", "sec_123"))
+	assert.ErrorIs(t, verifyMonoSignature("sec_124", "sec_123"), ErrInvalidSignature)
+	assert.ErrorIs(t, verifyMonoSignature("", "sec_123"), ErrInvalidSignature)
+	assert.ErrorIs(t, verifyMonoSignature("", ""), ErrWebhookNotConfigured)
+}
+
+func TestParseTellerEvent(t *testing.T) {
+	event, err := parseTellerEvent([]byte(`{"id":"wh_1","type":"enrollment.disconnected","payload":{"enrollment_id":"enr_1","reason":"disconnected.credentials_invalid"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "wh_1", event.DeliveryID)
+	assert.Equal(t, []connectionUpdate{{ItemID: "enr_1", Status: jobs.ConnectionStatusReauthRequired}}, event.Updates)
+
+	event, err = parseTellerEvent([]byte(`{"id":"wh_2","type":"enrollment.disconnected","payload":{"enrollment_id":"enr_1","reason":"disconnected.enrollment_closed"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, jobs.ConnectionStatusDisconnected, event.Updates[0].Status)
+
+	event, err = parseTellerEvent([]byte(`{"id":"wh_3","type":"transactions.processed","payload":{"transactions":[{"account_id":"acc_1"},{"account_id":"acc_2"},{"account_id":"acc_1"}]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []connectionUpdate{
+		{ProviderAccountID: "acc_1", Sync: true},
+		{ProviderAccountID: "acc_2", Sync: true},
+	}, event.Updates)
+
+	event, err = parseTellerEvent([]byte(`{"id":"wh_4","type":"webhook.test","payload":{}}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.Updates)
+
+	_, err = parseTellerEvent([]byte(`{"type":"webhook.test"}`))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestParseMonoEvent(t *testing.T) {
+	body := []byte(`{"event":"mono.events.account_updated","data":{"account":{"_id":"mono_1"},"meta":{"data_status":"AVAILABLE"}}}`)
+	event, err := parseMonoEvent(body)
+	require.NoError(t, err)
+	assert.Len(t, event.DeliveryID, 64)
+	assert.Equal(t, monoReplayWindow, event.ReplayWindow)
+	assert.Equal(t, []connectionUpdate{{ItemID: "mono_1", ProviderAccountID: "mono_1", Status: jobs.ConnectionStatusActive, Sync: true}}, event.Updates)
+
+	// Replays of the same payload share the delivery id
+	replay, err := parseMonoEvent(body)
+	require.NoError(t, err)
+	assert.Equal(t, event.DeliveryID, replay.DeliveryID)
+
+	event, err = parseMonoEvent([]byte(`{"event":"mono.events.account_updated","data":{"account":{"_id":"mono_1"},"meta":{"data_status":"PROCESSING"}}}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.Updates)
+
+	event, err = parseMonoEvent([]byte(`{"event":"mono.events.reauthorisation_required","data":{"account":{"_id":"mono_1"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, jobs.ConnectionStatusReauthRequired, event.Updates[0].Status)
+
+	event, err = parseMonoEvent([]byte(`{"event":"mono.events.account_unlinked","data":{"id":"mono_1"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, connectionUpdate{ItemID: "mono_1", ProviderAccountID: "mono_1", Status: jobs.ConnectionStatusDisconnected}, event.Updates[0])
+
+	_, err = parseMonoEvent([]byte(`{"event":"mono.events.account_unlinked","data":{}}`))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
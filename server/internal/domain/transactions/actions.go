@@ -0,0 +1,168 @@
+package transactions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultTransferWindowDays is how far from a transaction link_transfer looks for the other
+	// side of the transfer when the action has no value
+	DefaultTransferWindowDays = 3
+	maxTransferWindowDays     = 31
+)
+
+// RuleSplit is a part of a split action, the percentages of the parts add up to 100
+type RuleSplit struct {
+	CategoryID  uuid.UUID       `json:"category_id"`
+	Percentage  decimal.Decimal `json:"percentage"`
+	Description *string         `json:"description,omitempty"`
+}
+
+// ParseRuleSplits reads the parts of a split action
+func ParseRuleSplits(value any) ([]RuleSplit, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var splits []RuleSplit
+	if err := json.Unmarshal(data, &splits); err != nil {
+		return nil, errors.New("split needs a list of category_id and percentage")
+	}
+
+	if len(splits) < 2 {
+		return nil, errors.New("split needs at least two parts")
+	}
+
+	total := decimal.Zero
+	for _, split := range splits {
+		if split.CategoryID == uuid.Nil {
+			return nil, errors.New("split part needs a category_id")
+		}
+		if !split.Percentage.IsPositive() {
+			return nil, errors.New("split percentages must be greater than 0")
+		}
+		total = total.Add(split.Percentage)
+	}
+
+	if !total.Equal(decimal.NewFromInt(100)) {
+		return nil, fmt.Errorf("split percentages must add up to 100, got %s", total)
+	}
+
+	return splits, nil
+}
+
+// TransferWindowDays reads the value of a link_transfer action
+func TransferWindowDays(value any) (int, error) {
+	var days int
+
+	switch v := value.(type) {
+	case nil:
+		return DefaultTransferWindowDays, nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, errors.New("link_transfer needs a whole number of days")
+		}
+		days = int(v)
+	case int:
+		days = v
+	default:
+		return 0, fmt.Errorf("unsupported link_transfer value type: %T", v)
+	}
+
+	if days < 0 || days > maxTransferWindowDays {
+		return 0, fmt.Errorf("link_transfer looks between 0 and %d days around a transaction", maxTransferWindowDays)
+	}
+
+	return days, nil
+}
+
+// Validate checks the type of the action and the shape of its value
+func (ra RuleAction) Validate() error {
+	switch ra.Type {
+	case ActionTypeSetCategory:
+		value, ok := ra.Value.(string)
+		if _, err := uuid.Parse(value); !ok || err != nil {
+			return errors.New("set_category needs a category ID")
+		}
+	case ActionTypeSetDescription, ActionTypeSetNote:
+		if _, ok := ra.Value.(string); !ok {
+			return fmt.Errorf("%s needs a string value", ra.Type)
+		}
+	case ActionTypeSetMerchant:
+		merchant, ok := ra.Value.(string)
+		if !ok || strings.TrimSpace(merchant) == "" {
+			return errors.New("set_merchant needs a merchant name")
+		}
+	case ActionTypeSetTags:
+		switch v := ra.Value.(type) {
+		case string, []string:
+		case []any:
+			for _, tag := range v {
+				if _, ok := tag.(string); !ok {
+					return errors.New("set_tags needs a list of strings")
+				}
+			}
+		default:
+			return errors.New("set_tags needs a list of strings")
+		}
+	case ActionTypeSetTaxDeductible, ActionTypeSetBusinessExpense:
+		if _, ok := ra.Value.(bool); !ok {
+			return fmt.Errorf("%s needs a boolean value", ra.Type)
+		}
+	case ActionTypeSplit:
+		if _, err := ParseRuleSplits(ra.Value); err != nil {
+			return err
+		}
+	case ActionTypeLinkTransfer:
+		if _, err := TransferWindowDays(ra.Value); err != nil {
+			return err
+		}
+	case ActionTypeStopProcessing:
+		// Takes no value
+	default:
+		return fmt.Errorf("unsupported action type: %s", ra.Type)
+	}
+
+	return nil
+}
+
+// ValidateActions checks each action and that they can run together in one rule
+func ValidateActions(actions []RuleAction) error {
+	counts := map[ActionType]int{}
+
+	for _, action := range actions {
+		if err := action.Validate(); err != nil {
+			return err
+		}
+		counts[action.Type]++
+	}
+
+	switch {
+	case counts[ActionTypeSplit] > 1:
+		return errors.New("a rule can only split a transaction once")
+	case counts[ActionTypeLinkTransfer] > 1:
+		return errors.New("a rule can only link a transfer once")
+	case counts[ActionTypeSplit] > 0 && counts[ActionTypeLinkTransfer] > 0:
+		return errors.New("a transfer can't be split")
+	}
+
+	return nil
+}
+
+// StopsProcessing reports whether rules of lower priority are skipped after these actions
+func StopsProcessing(actions []RuleAction) bool {
+	for _, action := range actions {
+		if action.Type == ActionTypeStopProcessing {
+			return true
+		}
+	}
+
+	return false
+}
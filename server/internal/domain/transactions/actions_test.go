@@ -0,0 +1,84 @@
+package transactions_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestValidateActions(t *testing.T) {
+	split := `{"type": "split", "value": [
+		{"category_id": "8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10", "percentage": 60},
+		{"category_id": "0b6a2d10-4f2e-4c55-9d1b-3c1f8f8c1f5e", "percentage": "40", "description": "Household"}
+	]}`
+
+	tests := []struct {
+		name    string
+		actions string
+		err     string // Empty when the actions are valid
+	}{
+		{"category", `[{"type": "set_category", "value": "8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10"}]`, ""},
+		{"category name", `[{"type": "set_category", "value": "groceries"}]`, "category ID"},
+		{"tags", `[{"type": "set_tags", "value": ["work", "travel"]}]`, ""},
+		{"merchant", `[{"type": "set_merchant", "value": "Uber"}, {"type": "stop_processing"}]`, ""},
+		{"blank merchant", `[{"type": "set_merchant", "value": " "}]`, "merchant name"},
+		{"flags", `[{"type": "set_tax_deductible", "value": true}, {"type": "set_business_expense", "value": false}]`, ""},
+		{"flag as string", `[{"type": "set_tax_deductible", "value": "yes"}]`, "boolean"},
+		{"split", `[` + split + `]`, ""},
+		{"split twice", `[` + split + `, ` + split + `]`, "split a transaction once"},
+		{"split under 100", `[{"type": "split", "value": [
+			{"category_id": "8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10", "percentage": 60},
+			{"category_id": "0b6a2d10-4f2e-4c55-9d1b-3c1f8f8c1f5e", "percentage": 30}
+		]}]`, "add up to 100"},
+		{"split in one part", `[{"type": "split", "value": [{"category_id": "8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10", "percentage": 100}]}]`, "two parts"},
+		{"transfer", `[{"type": "link_transfer", "value": null}]`, ""},
+		{"transfer window", `[{"type": "link_transfer", "value": 45}]`, "between 0 and 31"},
+		{"split transfer", `[` + split + `, {"type": "link_transfer", "value": 3}]`, "can't be split"},
+		{"unknown", `[{"type": "delete", "value": true}]`, "unsupported action type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions []transactions.RuleAction
+			if err := json.Unmarshal([]byte(tt.actions), &actions); err != nil {
+				t.Fatalf("Expected no error decoding, got %v", err)
+			}
+
+			err := transactions.ValidateActions(actions)
+
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Expected the actions to be valid, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestUpdateTransactionRuleRequest_ValidatesActions(t *testing.T) {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := transactions.RegisterValidations(validate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var req transactions.UpdateTransactionRuleRequest
+	if err := json.Unmarshal([]byte(`{"actions": [{"type": "set_business_expense", "value": 1}]}`), &req); err != nil {
+		t.Fatalf("Expected no error decoding, got %v", err)
+	}
+
+	if err := validate.Struct(req); err == nil {
+		t.Errorf("Expected invalid actions to be rejected")
+	}
+
+	if err := validate.Struct(transactions.UpdateTransactionRuleRequest{}); err != nil {
+		t.Errorf("Expected a request without actions to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package attachments validates the files attached to transactions and keeps them in the storage.
+package attachments
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// MaxSize is the largest attachment accepted
+const MaxSize = 10 << 20
+
+// maxFilenameLength matches the filename columns
+const maxFilenameLength = 255
+
+// types lists the accepted MIME types, receipts come as photos or PDF documents
+var types = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+var (
+	ErrUnsupportedType = errors.New("unsupported attachment type")
+	ErrTooLarge        = errors.New("attachment too large")
+	ErrEmpty           = errors.New("attachment is empty")
+	ErrContentMismatch = errors.New("attachment content doesn't match its type")
+)
+
+// Types returns the accepted MIME types
+func Types() []string {
+	list := make([]string, 0, len(types))
+	for t := range types {
+		list = append(list, t)
+	}
+
+	return list
+}
+
+// NormalizeType lowercases a MIME type and drops its parameters
+func NormalizeType(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(mimeType))
+	}
+
+	return mediaType
+}
+
+// Validate checks the type and size announced for an attachment
+func Validate(mimeType string, size int64) error {
+	if !types[NormalizeType(mimeType)] {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, mimeType)
+	}
+
+	if size <= 0 {
+		return ErrEmpty
+	}
+
+	if size > MaxSize {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrTooLarge, size, MaxSize)
+	}
+
+	return nil
+}
+
+// Check sniffs the content of an uploaded file, clients announce the type of the file but
+// nothing stops them from uploading something else
+func Check(content []byte, mimeType string) error {
+	if len(content) == 0 {
+		return ErrEmpty
+	}
+
+	if len(content) > MaxSize {
+		return fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, MaxSize)
+	}
+
+	detected := NormalizeType(http.DetectContentType(content))
+	if detected != NormalizeType(mimeType) {
+		return fmt.Errorf("%w: announced %s, got %s", ErrContentMismatch, mimeType, detected)
+	}
+
+	return nil
+}
+
+// SanitizeFilename keeps the base name of a client filename without control characters
+func SanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) > 16 {
+			ext = nil
+		}
+		name = string(runes[:maxFilenameLength-len(ext)]) + string(ext)
+	}
+
+	return name
+}
+
+// Key is the storage key of an attachment
+func Key(userID, transactionID, attachmentID uuid.UUID) string {
+	return fmt.Sprintf("attachments/%s/%s/%s", userID, transactionID, attachmentID)
+}
@@ -0,0 +1,87 @@
+package attachments_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf = []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, attachments.Validate("image/png", 1024))
+	assert.NoError(t, attachments.Validate("Application/PDF; charset=binary", 1024))
+
+	assert.ErrorIs(t, attachments.Validate("application/x-msdownload", 1024), attachments.ErrUnsupportedType)
+	assert.ErrorIs(t, attachments.Validate("image/png", attachments.MaxSize+1), attachments.ErrTooLarge)
+	assert.ErrorIs(t, attachments.Validate("image/png", 0), attachments.ErrEmpty)
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, attachments.Check(png, "image/png"))
+	assert.NoError(t, attachments.Check(pdf, "application/pdf"))
+
+	assert.ErrorIs(t, attachments.Check(pdf, "image/png"), attachments.ErrContentMismatch)
+	assert.ErrorIs(t, attachments.Check([]byte("MZ\x90\x00"), "application/pdf"), attachments.ErrContentMismatch)
+	assert.ErrorIs(t, attachments.Check(nil, "image/png"), attachments.ErrEmpty)
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	assert.Equal(t, "receipt.pdf", attachments.SanitizeFilename("../../etc/receipt.pdf"))
+	assert.Equal(t, "receipt.pdf", attachments.SanitizeFilename(`C:\Users\jane\receipt.pdf`))
+	assert.Equal(t, "receipt.pdf", attachments.SanitizeFilename("rece\x00ipt\".pdf"))
+	assert.Equal(t, "attachment", attachments.SanitizeFilename(""))
+
+	long := attachments.SanitizeFilename(strings.Repeat("a", 300) + ".jpeg")
+	assert.Len(t, long, 255)
+	assert.True(t, strings.HasSuffix(long, ".jpeg"))
+}
+
+func TestStoreEncryptsFiles(t *testing.T) {
+	ctx := context.Background()
+
+	fs, err := storage.NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	encrypter, err := encrypt.NewEncrypter(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+
+	store := attachments.NewStore(fs, "private", encrypter)
+	key := attachments.Key(uuid.New(), uuid.New(), uuid.New())
+
+	keyID, err := store.Write(ctx, key, pdf)
+	require.NoError(t, err)
+	assert.Equal(t, encrypter.KeyID(), keyID)
+
+	stored, err := store.ReadUpload(ctx, key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "%PDF")
+
+	content, err := store.Read(ctx, key, &keyID)
+	require.NoError(t, err)
+	assert.Equal(t, pdf, content)
+
+	other := "0123456789abcdef"
+	_, err = store.Read(ctx, key, &other)
+	assert.ErrorIs(t, err, attachments.ErrUnknownKey)
+
+	_, _, err = store.PresignDownload(ctx, key, &keyID)
+	assert.ErrorIs(t, err, attachments.ErrNotPresignable)
+
+	_, _, err = store.PresignUpload(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrOperationNotSupported)
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.ReadUpload(ctx, key)
+	assert.Error(t, err)
+}
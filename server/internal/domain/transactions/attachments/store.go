@@ -0,0 +1,124 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+)
+
+// URLExpiry is the lifetime of presigned upload and download links
+const URLExpiry = 15 * time.Minute
+
+var (
+	// ErrNotPresignable is returned for files encrypted by the API, only the API can serve them
+	ErrNotPresignable = errors.New("attachment can't be downloaded through a presigned link")
+	ErrUnknownKey     = errors.New("attachment was encrypted with another key")
+)
+
+// Store keeps attachments in the private bucket. Files uploaded through presigned links rely on
+// the server-side encryption of the bucket, files sent through the API are encrypted with the
+// server key before being stored and carry the ID of that key.
+type Store struct {
+	storage   storage.Storage
+	bucket    string
+	encrypter *encrypt.Encrypter
+}
+
+func NewStore(storage storage.Storage, bucket string, encrypter *encrypt.Encrypter) *Store {
+	return &Store{storage: storage, bucket: bucket, encrypter: encrypter}
+}
+
+func (s *Store) Bucket() string {
+	return s.bucket
+}
+
+// PresignUpload returns a link the client puts the file to, storage.ErrOperationNotSupported
+// when the storage can't presign links
+func (s *Store) PresignUpload(ctx context.Context, key string) (string, time.Time, error) {
+	url, err := s.storage.GeneratePutSignedURL(ctx, s.bucket, key, URLExpiry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return url, time.Now().Add(URLExpiry), nil
+}
+
+// PresignDownload returns a link to download a file uploaded through a presigned link
+func (s *Store) PresignDownload(ctx context.Context, key string, keyID *string) (string, time.Time, error) {
+	if keyID != nil {
+		return "", time.Time{}, ErrNotPresignable
+	}
+
+	url, err := s.storage.GenerateGetSignedURL(ctx, s.bucket, key, URLExpiry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return url, time.Now().Add(URLExpiry), nil
+}
+
+// ReadUpload reads a file the client uploaded, at most one byte over MaxSize so callers can
+// reject larger files
+func (s *Store) ReadUpload(ctx context.Context, key string) ([]byte, error) {
+	f, err := s.storage.Download(ctx, s.bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, MaxSize+1))
+}
+
+// Write encrypts and stores a file, it returns the ID of the key used
+func (s *Store) Write(ctx context.Context, key string, content []byte) (string, error) {
+	ciphertext, err := s.encrypter.Encrypt(content)
+	if err != nil {
+		return "", fmt.Errorf("encrypt attachment: %w", err)
+	}
+
+	if err := s.storage.Upload(ctx, s.bucket, key, int64(len(ciphertext)), bytes.NewReader(ciphertext)); err != nil {
+		return "", fmt.Errorf("upload attachment: %w", err)
+	}
+
+	return s.encrypter.KeyID(), nil
+}
+
+// Read returns the content of a stored file, decrypted when keyID is set
+func (s *Store) Read(ctx context.Context, key string, keyID *string) ([]byte, error) {
+	if keyID != nil && *keyID != s.encrypter.KeyID() {
+		return nil, ErrUnknownKey
+	}
+
+	f, err := s.storage.Download(ctx, s.bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Encrypted files carry the nonce and tag on top of the content
+	content, err := io.ReadAll(io.LimitReader(f, MaxSize+1024))
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID == nil {
+		return content, nil
+	}
+
+	plaintext, err := s.encrypter.Decrypt(content)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt attachment: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.storage.Delete(ctx, s.bucket, key)
+}
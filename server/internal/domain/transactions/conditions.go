@@ -0,0 +1,257 @@
+package transactions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Logic gates of condition groups
+const (
+	LogicGateAnd = "AND"
+	LogicGateOr  = "OR"
+	LogicGateNot = "NOT" // Negates its single condition
+)
+
+// Deepest nesting of condition groups a rule can have
+const maxConditionDepth = 8
+
+var (
+	conditionTypes = []ConditionType{
+		ConditionTypeDescription, ConditionTypeAmount, ConditionTypeAccount, ConditionTypeDirection,
+		ConditionTypeType, ConditionTypeCategory, ConditionTypeDate, ConditionTypeWeekday,
+		ConditionTypeDayOfMonth, ConditionTypeMerchant, ConditionTypeTags, ConditionTypeCurrency,
+		ConditionTypePaymentMedium, ConditionTypeMerchantCategoryCode,
+	}
+
+	conditionOperators = []ConditionOperator{
+		OperatorEquals, OperatorNotEquals, OperatorContains, OperatorNotContains, OperatorStartsWith,
+		OperatorEndsWith, OperatorGreaterThan, OperatorGreaterEqual, OperatorLessThan, OperatorLessEqual,
+		OperatorMatchesRegex, OperatorIn, OperatorBetween,
+	}
+)
+
+// ConditionNode is a node of the condition tree of a rule. A group combines its Conditions with
+// its LogicGate, a leaf holds a single Condition:
+//
+//	{"logic_gate": "AND", "conditions": [
+//	  {"logic_gate": "OR", "conditions": [A, B]},
+//	  {"logic_gate": "NOT", "conditions": [C]}
+//	]}
+type ConditionNode struct {
+	LogicGate  string          `json:"logic_gate,omitempty"`
+	Conditions []ConditionNode `json:"conditions,omitempty"`
+	Condition  *RuleCondition  `json:"-"`
+}
+
+// MatchAll returns the tree of conditions that must all match
+func MatchAll(conditions ...RuleCondition) ConditionNode {
+	node := ConditionNode{LogicGate: LogicGateAnd, Conditions: make([]ConditionNode, len(conditions))}
+	for i := range conditions {
+		node.Conditions[i] = ConditionNode{Condition: &conditions[i]}
+	}
+
+	return node
+}
+
+// IsEmpty reports whether the tree has no condition
+func (n ConditionNode) IsEmpty() bool {
+	return n.Condition == nil && len(n.Conditions) == 0
+}
+
+// Leaves returns the conditions of the tree in order
+func (n ConditionNode) Leaves() []RuleCondition {
+	if n.Condition != nil {
+		return []RuleCondition{*n.Condition}
+	}
+
+	leaves := []RuleCondition{}
+	for _, child := range n.Conditions {
+		leaves = append(leaves, child.Leaves()...)
+	}
+
+	return leaves
+}
+
+// Validate checks the shape of the tree and that its conditions can be evaluated
+func (n ConditionNode) Validate() error {
+	return n.validate(1)
+}
+
+func (n ConditionNode) validate(depth int) error {
+	if depth > maxConditionDepth {
+		return fmt.Errorf("conditions can't be nested more than %d levels deep", maxConditionDepth)
+	}
+
+	if n.Condition != nil {
+		if n.LogicGate != "" || len(n.Conditions) > 0 {
+			return errors.New("a condition can't also be a group")
+		}
+		return n.Condition.Validate()
+	}
+
+	switch strings.ToUpper(n.LogicGate) {
+	case LogicGateAnd, LogicGateOr:
+		if len(n.Conditions) == 0 {
+			return fmt.Errorf("%s group needs at least one condition", strings.ToUpper(n.LogicGate))
+		}
+	case LogicGateNot:
+		if len(n.Conditions) != 1 {
+			return errors.New("NOT group needs exactly one condition")
+		}
+	case "":
+		if len(n.Conditions) == 0 {
+			return errors.New("rule needs at least one condition")
+		}
+		return errors.New("condition group needs a logic_gate of AND, OR or NOT")
+	default:
+		return fmt.Errorf("unsupported logic gate: %s", n.LogicGate)
+	}
+
+	for _, child := range n.Conditions {
+		if err := child.validate(depth + 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the type and operator of the condition and the shape of its value
+func (rc RuleCondition) Validate() error {
+	if !slices.Contains(conditionTypes, rc.Type) {
+		return fmt.Errorf("unsupported condition type: %s", rc.Type)
+	}
+
+	if !slices.Contains(conditionOperators, rc.Operator) {
+		return fmt.Errorf("unsupported operator: %s", rc.Operator)
+	}
+
+	if rc.Value == nil {
+		return fmt.Errorf("%s condition needs a value", rc.Type)
+	}
+
+	switch rc.Operator {
+	case OperatorIn, OperatorBetween:
+		var count int
+		switch v := rc.Value.(type) {
+		case []any:
+			count = len(v)
+		case []string:
+			count = len(v)
+		default:
+			return fmt.Errorf("%s needs a list value", rc.Operator)
+		}
+		if rc.Operator == OperatorBetween && count != 2 {
+			return errors.New("between needs a [min, max] value")
+		}
+	case OperatorMatchesRegex:
+		pattern, ok := rc.Value.(string)
+		if !ok {
+			return errors.New("matches_regex needs a string value")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (n ConditionNode) MarshalJSON() ([]byte, error) {
+	if n.Condition != nil {
+		return json.Marshal(n.Condition)
+	}
+
+	type group ConditionNode
+	return json.Marshal(group(n))
+}
+
+// UnmarshalJSON reads a tree, or the flat list of conditions rules were first saved as, where
+// the logic_gate of each condition combined it with the next one from left to right
+func (n *ConditionNode) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*n = ConditionNode{}
+		return nil
+	case bytes.HasPrefix(data, []byte("[")):
+		return n.unmarshalList(data)
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	_, hasConditions := keys["conditions"]
+	_, hasGate := keys["logic_gate"]
+	_, hasType := keys["type"]
+
+	if hasConditions || (hasGate && !hasType) {
+		type group ConditionNode
+		var g group
+		if err := json.Unmarshal(data, &g); err != nil {
+			return err
+		}
+
+		*n = ConditionNode(g)
+		return nil
+	}
+
+	var condition RuleCondition
+	if err := json.Unmarshal(data, &condition); err != nil {
+		return err
+	}
+
+	*n = ConditionNode{Condition: &condition}
+
+	return nil
+}
+
+func (n *ConditionNode) unmarshalList(data []byte) error {
+	var conditions []RuleCondition
+	if err := json.Unmarshal(data, &conditions); err != nil {
+		return err
+	}
+
+	var gates []struct {
+		LogicGate string `json:"logic_gate"`
+	}
+	if err := json.Unmarshal(data, &gates); err != nil {
+		return err
+	}
+
+	if len(conditions) == 0 {
+		*n = ConditionNode{}
+		return nil
+	}
+
+	tree := ConditionNode{LogicGate: LogicGateAnd, Conditions: []ConditionNode{{Condition: &conditions[0]}}}
+
+	for i := 1; i < len(conditions); i++ {
+		// Gates other than OR were read as AND
+		gate := LogicGateAnd
+		if strings.EqualFold(gates[i-1].LogicGate, LogicGateOr) {
+			gate = LogicGateOr
+		}
+
+		leaf := ConditionNode{Condition: &conditions[i]}
+
+		if tree.LogicGate == gate || len(tree.Conditions) == 1 {
+			tree.LogicGate = gate
+			tree.Conditions = append(tree.Conditions, leaf)
+		} else {
+			tree = ConditionNode{LogicGate: gate, Conditions: []ConditionNode{tree, leaf}}
+		}
+	}
+
+	*n = tree
+
+	return nil
+}
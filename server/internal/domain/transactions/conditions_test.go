@@ -0,0 +1,157 @@
+package transactions_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestConditionNode_UnmarshalLegacyList(t *testing.T) {
+	// Saved before condition trees: ((A OR B) AND C) OR D, gates read from left to right
+	data := `[
+		{"type": "description", "operator": "contains", "value": "a", "logic_gate": "OR"},
+		{"type": "description", "operator": "contains", "value": "b", "logic_gate": "AND"},
+		{"type": "description", "operator": "contains", "value": "c", "logic_gate": "or"},
+		{"type": "description", "operator": "contains", "value": "d"}
+	]`
+
+	var node transactions.ConditionNode
+	if err := json.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := describe(node); got != "OR(AND(OR(a,b),c),d)" {
+		t.Errorf("Expected ((a OR b) AND c) OR d, got %s", got)
+	}
+
+	if err := node.Validate(); err != nil {
+		t.Errorf("Expected a legacy list to be valid, got %v", err)
+	}
+}
+
+func TestConditionNode_UnmarshalTree(t *testing.T) {
+	data := `{
+		"logic_gate": "AND",
+		"conditions": [
+			{"logic_gate": "OR", "conditions": [
+				{"type": "merchant", "operator": "equals", "value": "a"},
+				{"type": "merchant", "operator": "equals", "value": "b"}
+			]},
+			{"logic_gate": "NOT", "conditions": [
+				{"type": "tags", "operator": "contains", "value": "c"}
+			]}
+		]
+	}`
+
+	var node transactions.ConditionNode
+	if err := json.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := describe(node); got != "AND(OR(a,b),NOT(c))" {
+		t.Fatalf("Expected (a OR b) AND NOT c, got %s", got)
+	}
+
+	encoded, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded transactions.ConditionNode
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := describe(decoded); got != "AND(OR(a,b),NOT(c))" {
+		t.Errorf("Expected the tree to survive a round trip, got %s from %s", got, encoded)
+	}
+}
+
+func TestConditionNode_Validate(t *testing.T) {
+	leaf := `{"type": "description", "operator": "contains", "value": "a"}`
+
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"empty list", `[]`, "at least one condition"},
+		{"empty group", `{"logic_gate": "OR", "conditions": []}`, "at least one condition"},
+		{"missing gate", `{"conditions": [` + leaf + `]}`, "needs a logic_gate"},
+		{"unknown gate", `{"logic_gate": "XOR", "conditions": [` + leaf + `]}`, "unsupported logic gate"},
+		{"NOT of two", `{"logic_gate": "NOT", "conditions": [` + leaf + `,` + leaf + `]}`, "exactly one condition"},
+		{"unknown type", `{"type": "colour", "operator": "equals", "value": "red"}`, "unsupported condition type"},
+		{"unknown operator", `{"type": "amount", "operator": "around", "value": 10}`, "unsupported operator"},
+		{"missing value", `{"type": "amount", "operator": "equals"}`, "needs a value"},
+		{"between one bound", `{"type": "amount", "operator": "between", "value": [10]}`, "[min, max]"},
+		{"in without list", `{"type": "currency", "operator": "in", "value": "EUR"}`, "list value"},
+		{"invalid regex", `{"type": "description", "operator": "matches_regex", "value": "(a"}`, "invalid regular expression"},
+		{"too deep", strings.Repeat(`{"logic_gate": "NOT", "conditions": [`, 9) + leaf + strings.Repeat(`]}`, 9), "nested"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node transactions.ConditionNode
+			if err := json.Unmarshal([]byte(tt.data), &node); err != nil {
+				t.Fatalf("Expected no error decoding, got %v", err)
+			}
+
+			err := node.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCreateTransactionRuleRequest_ValidatesConditions(t *testing.T) {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := transactions.RegisterValidations(validate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	actions := `"actions": [{"type": "set_category", "value": "8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10"}]`
+
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{"tree", `{"name": "Tree", "conditions": {"logic_gate": "OR", "conditions": [{"type": "amount", "operator": "greater_than", "value": 10}]}, ` + actions + `}`, true},
+		{"legacy list", `{"name": "List", "conditions": [{"type": "amount", "operator": "greater_than", "value": 10}], ` + actions + `}`, true},
+		{"missing conditions", `{"name": "None", ` + actions + `}`, false},
+		{"malformed tree", `{"name": "Bad", "conditions": {"logic_gate": "NOT", "conditions": []}, ` + actions + `}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req transactions.CreateTransactionRuleRequest
+			if err := json.Unmarshal([]byte(tt.data), &req); err != nil {
+				t.Fatalf("Expected no error decoding, got %v", err)
+			}
+
+			err := validate.Struct(req)
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid to be %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+// describe writes a tree as GATE(children...) with the value of each condition
+func describe(node transactions.ConditionNode) string {
+	if node.Condition != nil {
+		value, _ := node.Condition.Value.(string)
+		return value
+	}
+
+	children := make([]string, len(node.Conditions))
+	for i, child := range node.Conditions {
+		children[i] = describe(child)
+	}
+
+	return strings.ToUpper(node.LogicGate) + "(" + strings.Join(children, ",") + ")"
+}
@@ -9,3 +9,35 @@ var (
 	ErrDestAccNotFound = errors.New("destination account not found")
 	ErrLowBalance      = errors.New("insufficient balance")
 )
+
+var (
+	ErrRecurringNotFound     = errors.New("recurring transaction not found")
+	ErrInvalidRecurring      = errors.New("invalid recurring transaction")
+	ErrRecurringPaused       = errors.New("recurring transaction is paused")
+	ErrRecurringCompleted    = errors.New("recurring transaction has no pending instance left")
+	ErrInstanceNotPending    = errors.New("instance is not the pending one of the recurring transaction")
+	ErrMissingCategory       = errors.New("a category is required to post the instance")
+	ErrUnsupportedUpdateMode = errors.New("next_only updates must be done by modifying the pending instance")
+)
+
+var (
+	ErrImportNotFound        = errors.New("import not found")
+	ErrImportCommitted       = errors.New("import was already committed")
+	ErrImportMappingNotFound = errors.New("import mapping not found")
+	ErrImportCurrency        = errors.New("imported lines must be in the currency of the account")
+)
+
+var (
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrAttachmentNotPending = errors.New("attachment was already uploaded")
+	ErrAttachmentMissing    = errors.New("attachment file was not uploaded")
+)
+
+var (
+	ErrRuleNotFound            = errors.New("rule not found")
+	ErrRuleApplicationNotFound = errors.New("rule application not found")
+	ErrRuleApplicationUndone   = errors.New("rule application was already undone")
+	ErrRuleApplicationConflict = errors.New("transaction changed since the rule was applied")
+)
+
+var RulesQueuedMessage = "transactions.rules.queued"
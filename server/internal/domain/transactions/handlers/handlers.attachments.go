@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/request"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+	"github.com/Fantasy-Programming/nuts/server/pkg/llm"
+	"github.com/google/uuid"
+)
+
+// CreateAttachment records a pending attachment and returns where to upload its file
+func (h *Handler) CreateAttachment(w http.ResponseWriter, r *http.Request) {
+	var req transactions.UploadAttachmentRequest
+	ctx := r.Context()
+
+	trscID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	req.TransactionID = trscID.String()
+
+	upload, err := h.service.CreateAttachmentUpload(ctx, userID, req)
+	if err != nil {
+		h.attachmentError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusCreated, upload, h.logger)
+}
+
+// CompleteAttachment checks the file uploaded to the presigned link of a pending attachment
+func (h *Handler) CompleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	att, err := h.service.CompleteAttachmentUpload(r.Context(), userID, trscID, attachmentID)
+	if err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, att, h.logger)
+}
+
+// UploadAttachmentContent receives the file of a pending attachment as the raw request body,
+// for storages that can't presign links
+func (h *Handler) UploadAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, attachments.MaxSize)
+
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			h.attachmentError(w, r, attachments.ErrTooLarge, attachmentID)
+			return
+		}
+
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    attachmentID,
+		})
+		return
+	}
+
+	att, err := h.service.UploadAttachmentContent(r.Context(), userID, trscID, attachmentID, content)
+	if err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, att, h.logger)
+}
+
+func (h *Handler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trscID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	list, err := h.service.ListAttachments(ctx, userID, trscID)
+	if err != nil {
+		h.attachmentError(w, r, err, trscID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, list, h.logger)
+}
+
+// GetAttachmentDownload returns a presigned link to download an attachment
+func (h *Handler) GetAttachmentDownload(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	download, err := h.service.GetAttachmentDownload(r.Context(), userID, trscID, attachmentID)
+	if err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, download, h.logger)
+}
+
+// GetAttachmentContent sends the decrypted file of an attachment
+func (h *Handler) GetAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	att, content, err := h.service.ReadAttachment(r.Context(), userID, trscID, attachmentID)
+	if err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	w.Header().Set("Content-Type", att.MimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", att.Filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(content); err != nil {
+		h.logger.Error().Err(err).Str("attachment_id", attachmentID.String()).Msg("Failed to send attachment")
+	}
+}
+
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(r.Context(), userID, trscID, attachmentID); err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+// ParseAttachmentReceipt reads the receipt of an attachment into a draft transaction, the
+// optional timezone query parameter is used to read the dates printed on it
+func (h *Handler) ParseAttachmentReceipt(w http.ResponseWriter, r *http.Request) {
+	userID, trscID, attachmentID, ok := h.attachmentParams(w, r)
+	if !ok {
+		return
+	}
+
+	var timezone *string
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		timezone = &tz
+	}
+
+	draft, err := h.service.ParseAttachmentReceipt(r.Context(), userID, trscID, attachmentID, timezone)
+	if err != nil {
+		h.attachmentError(w, r, err, attachmentID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, draft, h.logger)
+}
+
+// attachmentParams reads the user and the IDs of the attachment routes, it responds itself
+// when one is missing
+func (h *Handler) attachmentParams(w http.ResponseWriter, r *http.Request) (userID, trscID, attachmentID uuid.UUID, ok bool) {
+	trscID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	attachmentID, err = request.ParseUUID(r, "attachment_id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err = jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	return userID, trscID, attachmentID, true
+}
+
+func (h *Handler) attachmentError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, transactions.ErrAttachmentNotFound),
+		errors.Is(err, transactions.ErrNoTransactions):
+		statusCode = http.StatusNotFound
+		clientErr = err
+	case errors.Is(err, transactions.ErrAttachmentNotPending),
+		errors.Is(err, attachments.ErrNotPresignable):
+		statusCode = http.StatusConflict
+		clientErr = err
+	case errors.Is(err, transactions.ErrAttachmentMissing):
+		statusCode = http.StatusConflict
+		clientErr = transactions.ErrAttachmentMissing
+	case errors.Is(err, attachments.ErrTooLarge):
+		statusCode = http.StatusRequestEntityTooLarge
+		clientErr = attachments.ErrTooLarge
+	case errors.Is(err, attachments.ErrUnsupportedType):
+		statusCode = http.StatusUnsupportedMediaType
+		clientErr = attachments.ErrUnsupportedType
+	case errors.Is(err, attachments.ErrEmpty),
+		errors.Is(err, attachments.ErrContentMismatch):
+		statusCode = http.StatusBadRequest
+		clientErr = err
+	case errors.Is(err, llm.ErrUnsupportedDocument):
+		statusCode = http.StatusUnprocessableEntity
+		clientErr = llm.ErrUnsupportedDocument
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/pkg/llm"
 	"github.com/Fantasy-Programming/nuts/server/pkg/telemetry"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog"
 	"github.com/shopspring/decimal"
@@ -630,6 +632,20 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = h.service.DeleteTransaction(ctx, trscID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			metrics.End(http.StatusNotFound)
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusNotFound,
+				ClientErr:  message.ErrNoRecord,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    trscID,
+			})
+			return
+		}
+
 		telemetry.RecordError(ctx, "delete_transaction_error", "transactions.Delete")
 		telemetry.RecordTransactionEvent(ctx, "delete", false)
 		metrics.End(http.StatusInternalServerError)
@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/statements"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/request"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+	"github.com/google/uuid"
+)
+
+// maxStatementSize bounds the statement files accepted for import
+const maxStatementSize = 10 << 20
+
+// StageImport reads an uploaded statement into a staged import the user reviews before committing
+func (h *Handler) StageImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize+1<<20)
+
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    "Failed to parse form",
+		})
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    "No statement file found in request",
+		})
+		return
+	}
+	defer file.Close()
+
+	req := transactions.StageImportRequest{
+		AccountID:     r.FormValue("account_id"),
+		Format:        r.FormValue("format"),
+		MappingID:     r.FormValue("mapping_id"),
+		SaveMappingAs: r.FormValue("save_mapping_as"),
+	}
+
+	// The CSV mapping comes as a JSON encoded form value
+	if raw := r.FormValue("mapping"); raw != "" {
+		var mapping dto.CSVMapping
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    raw,
+			})
+			return
+		}
+		req.Mapping = &mapping
+	}
+
+	if err := h.validator.Validator.Struct(&req); err != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  validation.TranslateErrors(ctx, err),
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    header.Filename,
+		})
+		return
+	}
+
+	params := transactions.StageImportParams{
+		UserID:    userID,
+		AccountID: uuid.MustParse(req.AccountID),
+		Filename:  header.Filename,
+		Format:    req.Format,
+		Data:      data,
+		Mapping:   req.Mapping,
+	}
+
+	if req.MappingID != "" {
+		mappingID := uuid.MustParse(req.MappingID)
+		params.MappingID = &mappingID
+	}
+
+	if req.SaveMappingAs != "" {
+		params.SaveMappingAs = &req.SaveMappingAs
+	}
+
+	preview, err := h.service.StageImport(ctx, params)
+	if err != nil {
+		h.importError(w, r, err, header.Filename)
+		return
+	}
+
+	respond.Json(w, http.StatusCreated, preview, h.logger)
+}
+
+func (h *Handler) ListImports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	imports, err := h.service.ListImports(ctx, userID, page, limit)
+	if err != nil {
+		h.importError(w, r, err, nil)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, imports, h.logger)
+}
+
+func (h *Handler) GetImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	importID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	preview, err := h.service.GetImport(ctx, importID, userID)
+	if err != nil {
+		h.importError(w, r, err, importID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, preview, h.logger)
+}
+
+// CommitImport creates the transactions of a staged import once the user confirmed it
+func (h *Handler) CommitImport(w http.ResponseWriter, r *http.Request) {
+	var req transactions.CommitImportRequest
+	ctx := r.Context()
+
+	importID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	params := transactions.CommitImportParams{
+		CategoryID:        uuid.MustParse(req.CategoryID),
+		IncludeDuplicates: req.IncludeDuplicates,
+	}
+
+	for _, id := range req.SkipIDs {
+		params.SkipIDs = append(params.SkipIDs, uuid.MustParse(id))
+	}
+
+	preview, err := h.service.CommitImport(ctx, importID, userID, params)
+	if err != nil {
+		h.importError(w, r, err, importID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, preview, h.logger)
+}
+
+// DiscardImport drops a staged import without creating any transaction
+func (h *Handler) DiscardImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	importID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if err := h.service.DiscardImport(ctx, importID, userID); err != nil {
+		h.importError(w, r, err, importID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+func (h *Handler) ListImportMappings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	mappings, err := h.service.ListImportMappings(ctx, userID)
+	if err != nil {
+		h.importError(w, r, err, nil)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, mappings, h.logger)
+}
+
+// SaveImportMapping creates a CSV column mapping, or replaces the one with the same name
+func (h *Handler) SaveImportMapping(w http.ResponseWriter, r *http.Request) {
+	var req transactions.SaveImportMappingRequest
+	ctx := r.Context()
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	mapping, err := h.service.SaveImportMapping(ctx, userID, req.Name, req.Mapping)
+	if err != nil {
+		h.importError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusCreated, mapping, h.logger)
+}
+
+func (h *Handler) DeleteImportMapping(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	mappingID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if err := h.service.DeleteImportMapping(ctx, mappingID, userID); err != nil {
+		h.importError(w, r, err, mappingID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+func (h *Handler) importError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	var parseErr *statements.ParseError
+
+	switch {
+	case errors.Is(err, transactions.ErrImportNotFound),
+		errors.Is(err, transactions.ErrImportMappingNotFound),
+		errors.Is(err, transactions.ErrSrcAccNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = err
+	case errors.Is(err, transactions.ErrImportCommitted):
+		statusCode = http.StatusConflict
+		clientErr = err
+	case errors.Is(err, statements.ErrUnknownFormat),
+		errors.Is(err, statements.ErrMissingMapping),
+		errors.Is(err, statements.ErrInvalidMapping),
+		errors.Is(err, statements.ErrEmpty),
+		errors.Is(err, transactions.ErrImportCurrency),
+		errors.As(err, &parseErr):
+		statusCode = http.StatusBadRequest
+		clientErr = err
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/request"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+)
+
+func (h *Handler) ListRecurring(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+
+	var filters transactions.RecurringTransactionFilters
+
+	if accountID := q.Get("account_id"); accountID != "" {
+		filters.AccountID = &accountID
+	}
+
+	if categoryID := q.Get("category_id"); categoryID != "" {
+		filters.CategoryID = &categoryID
+	}
+
+	if frequency := q.Get("frequency"); frequency != "" {
+		filters.Frequency = &frequency
+	}
+
+	if templateName := q.Get("template_name"); templateName != "" {
+		filters.TemplateName = &templateName
+	}
+
+	if isPausedStr := q.Get("is_paused"); isPausedStr != "" {
+		isPaused, err := strconv.ParseBool(isPausedStr)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    isPausedStr,
+			})
+			return
+		}
+		filters.IsPaused = &isPaused
+	}
+
+	if autoPostStr := q.Get("auto_post"); autoPostStr != "" {
+		autoPost, err := strconv.ParseBool(autoPostStr)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    autoPostStr,
+			})
+			return
+		}
+		filters.AutoPost = &autoPost
+	}
+
+	recurrings, err := h.service.ListRecurringTransactions(ctx, userID, filters)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    filters,
+		})
+		return
+	}
+
+	respond.Json(w, http.StatusOK, recurrings, h.logger)
+}
+
+func (h *Handler) CreateRecurring(w http.ResponseWriter, r *http.Request) {
+	var req transactions.CreateRecurringTransactionRequest
+	ctx := r.Context()
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	recurring, err := h.service.CreateRecurringTransaction(ctx, req, userID)
+	if err != nil {
+		h.recurringError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusCreated, recurring, h.logger)
+}
+
+func (h *Handler) GetRecurring(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	recurring, err := h.service.GetRecurringTransactionByID(ctx, recurringID, userID)
+	if err != nil {
+		h.recurringError(w, r, err, recurringID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, recurring, h.logger)
+}
+
+func (h *Handler) UpdateRecurring(w http.ResponseWriter, r *http.Request) {
+	var req transactions.UpdateRecurringTransactionRequest
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	recurring, err := h.service.UpdateRecurringTransaction(ctx, recurringID, req, userID)
+	if err != nil {
+		h.recurringError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, recurring, h.logger)
+}
+
+func (h *Handler) DeleteRecurring(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if err := h.service.DeleteRecurringTransaction(ctx, recurringID, userID); err != nil {
+		h.recurringError(w, r, err, recurringID)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+func (h *Handler) PauseRecurring(w http.ResponseWriter, r *http.Request) {
+	h.setRecurringPaused(w, r, true)
+}
+
+func (h *Handler) ResumeRecurring(w http.ResponseWriter, r *http.Request) {
+	h.setRecurringPaused(w, r, false)
+}
+
+func (h *Handler) setRecurringPaused(w http.ResponseWriter, r *http.Request, isPaused bool) {
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	recurring, err := h.service.PauseRecurringTransaction(ctx, recurringID, userID, isPaused)
+	if err != nil {
+		h.recurringError(w, r, err, recurringID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, recurring, h.logger)
+}
+
+// GetRecurringInstances returns the calendar of posted and upcoming instances
+// between start_date and end_date (the next 30 days by default)
+func (h *Handler) GetRecurringInstances(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+
+	now := time.Now().UTC()
+	req := transactions.GetRecurringInstancesRequest{
+		StartDate:        time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		IncludeProjected: true,
+	}
+	req.EndDate = req.StartDate.AddDate(0, 0, 30)
+
+	if startStr := q.Get("start_date"); startStr != "" {
+		req.StartDate, err = time.Parse("2006-01-02", startStr)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    startStr,
+			})
+			return
+		}
+	}
+
+	if endStr := q.Get("end_date"); endStr != "" {
+		endDate, err := time.Parse("2006-01-02", endStr)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    endStr,
+			})
+			return
+		}
+		// Include the whole end day
+		req.EndDate = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	if projectedStr := q.Get("include_projected"); projectedStr != "" {
+		req.IncludeProjected, err = strconv.ParseBool(projectedStr)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          w,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    projectedStr,
+			})
+			return
+		}
+	}
+
+	instances, err := h.service.GetRecurringInstances(ctx, userID, req)
+	if err != nil {
+		h.recurringError(w, r, err, req)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, instances, h.logger)
+}
+
+func (h *Handler) GetRecurringStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	stats, err := h.service.GetRecurringTransactionStats(ctx, userID)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    userID.String(),
+		})
+		return
+	}
+
+	respond.Json(w, http.StatusOK, stats, h.logger)
+}
+
+// ProcessRecurring posts, skips or modifies the pending instance of a recurring transaction
+func (h *Handler) ProcessRecurring(w http.ResponseWriter, r *http.Request) {
+	var req transactions.ProcessRecurringTransactionRequest
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	res, err := h.service.ProcessRecurringInstance(ctx, recurringID, userID, req)
+	if err != nil {
+		h.recurringError(w, r, err, req)
+		return
+	}
+
+	status := http.StatusOK
+	if res.Transaction != nil {
+		status = http.StatusCreated
+	}
+
+	respond.Json(w, status, res, h.logger)
+}
+
+// ListRecurringHistory lists the transactions already posted from a recurring transaction
+func (h *Handler) ListRecurringHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recurringID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	history, err := h.service.GetRecurringTransactionInstances(ctx, userID, recurringID)
+	if err != nil {
+		h.recurringError(w, r, err, recurringID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, history, h.logger)
+}
+
+// recurringError maps the errors of the recurring service to a response
+func (h *Handler) recurringError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, transactions.ErrRecurringNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = transactions.ErrRecurringNotFound
+	case errors.Is(err, transactions.ErrSrcAccNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = transactions.ErrSrcAccNotFound
+	case errors.Is(err, transactions.ErrDestAccNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = transactions.ErrDestAccNotFound
+	case errors.Is(err, transactions.ErrInvalidRecurring),
+		errors.Is(err, transactions.ErrSameAccount),
+		errors.Is(err, transactions.ErrMissingCategory),
+		errors.Is(err, transactions.ErrUnsupportedUpdateMode):
+		statusCode = http.StatusBadRequest
+		clientErr = err
+	case errors.Is(err, transactions.ErrRecurringPaused),
+		errors.Is(err, transactions.ErrRecurringCompleted),
+		errors.Is(err, transactions.ErrInstanceNotPending):
+		statusCode = http.StatusConflict
+		clientErr = err
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
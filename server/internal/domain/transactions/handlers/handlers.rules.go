@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
@@ -363,3 +365,203 @@ func (h *Handler) ApplyRulesToTransaction(w http.ResponseWriter, r *http.Request
 
 	respond.Json(w, http.StatusOK, matches, h.logger)
 }
+
+// ApplyRules runs rules over existing transactions. A dry run returns what each transaction
+// would change, otherwise the changes are applied in the background
+func (h *Handler) ApplyRules(w http.ResponseWriter, r *http.Request) {
+	var req transactions.ApplyRulesRequest
+	ctx := r.Context()
+
+	valErr, err := h.validator.ParseAndValidate(ctx, r, &req)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if valErr != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  valErr,
+			Logger:     h.logger,
+			Details:    req,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	if req.DryRun {
+		preview, err := h.service.PreviewRuleApplication(ctx, userID, req)
+		if err != nil {
+			h.ruleError(w, r, err, req)
+			return
+		}
+
+		respond.Json(w, http.StatusOK, preview, h.logger)
+		return
+	}
+
+	if err := h.service.QueueRuleApplication(ctx, userID, req); err != nil {
+		h.ruleError(w, r, err, req)
+		return
+	}
+
+	respond.Response(w, r, http.StatusAccepted, transactions.RulesQueuedMessage, nil)
+}
+
+func (h *Handler) ListRuleHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ruleID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	history, err := h.service.ListRuleHistory(ctx, ruleID, userID, page, limit)
+	if err != nil {
+		h.ruleError(w, r, err, ruleID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, history, h.logger)
+}
+
+func (h *Handler) UndoRuleApplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ruleID, err := request.ParseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	applicationID, err := request.ParseUUID(r, "application_id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	application, err := h.service.UndoRuleApplication(ctx, ruleID, applicationID, userID)
+	if err != nil {
+		h.ruleError(w, r, err, applicationID)
+		return
+	}
+
+	respond.Json(w, http.StatusOK, application, h.logger)
+}
+
+func (h *Handler) ruleError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, transactions.ErrRuleNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = transactions.ErrRuleNotFound
+	case errors.Is(err, transactions.ErrRuleApplicationNotFound):
+		statusCode = http.StatusNotFound
+		clientErr = err
+	case errors.Is(err, transactions.ErrRuleApplicationUndone),
+		errors.Is(err, transactions.ErrRuleApplicationConflict):
+		statusCode = http.StatusConflict
+		clientErr = err
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
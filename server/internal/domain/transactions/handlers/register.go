@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/service"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
@@ -13,6 +14,10 @@ import (
 func RegisterHTTPHandlers(service service.Transactions, tkn *jwt.Service, validator *validation.Validator, logger *zerolog.Logger) http.Handler {
 	h := NewHandler(service, validator, logger)
 
+	if err := transactions.RegisterValidations(validator.Validator); err != nil {
+		logger.Panic().Err(err).Msg("Failed to setup validator")
+	}
+
 	middleware := jwt.NewMiddleware(tkn)
 
 	router := router.NewRouter()
@@ -26,6 +31,16 @@ func RegisterHTTPHandlers(service service.Transactions, tkn *jwt.Service, valida
 	router.Put("/{id}", h.Update)
 	router.Delete("/{id}", h.Delete)
 
+	// Attachments
+	router.Get("/{id}/attachments", h.ListAttachments)
+	router.Post("/{id}/attachments", h.CreateAttachment)
+	router.Post("/{id}/attachments/{attachment_id}/complete", h.CompleteAttachment)
+	router.Put("/{id}/attachments/{attachment_id}/content", h.UploadAttachmentContent)
+	router.Get("/{id}/attachments/{attachment_id}/content", h.GetAttachmentContent)
+	router.Get("/{id}/attachments/{attachment_id}/download", h.GetAttachmentDownload)
+	router.Post("/{id}/attachments/{attachment_id}/receipt", h.ParseAttachmentReceipt)
+	router.Delete("/{id}/attachments/{attachment_id}", h.DeleteAttachment)
+
 	// Bulk operations
 	router.Post("/bulk", h.BulkCreateTransactions)
 	router.Delete("/bulk", h.BulkDelete)
@@ -40,6 +55,34 @@ func RegisterHTTPHandlers(service service.Transactions, tkn *jwt.Service, valida
 	router.Delete("/rules/{id}", h.DeleteRule)                  // DELETE /rules/{id}
 	router.Post("/rules/toggle/{id}", h.ToggleRule)             // POST /rules/{id}/toggle
 	router.Post("/rules/apply/{id}", h.ApplyRulesToTransaction) // POST /rules/apply/{transactionId}
+	router.Post("/rules/apply", h.ApplyRules)                   // POST /rules/apply
+
+	// Rule history
+	router.Get("/rules/{id}/history", h.ListRuleHistory)                            // GET /rules/{id}/history
+	router.Post("/rules/{id}/history/{application_id}/undo", h.UndoRuleApplication) // POST /rules/{id}/history/{applicationId}/undo
+
+	// Recurring
+	router.Get("/recurring", h.ListRecurring)
+	router.Post("/recurring", h.CreateRecurring)
+	router.Get("/recurring/instances", h.GetRecurringInstances)
+	router.Get("/recurring/stats", h.GetRecurringStats)
+	router.Get("/recurring/{id}", h.GetRecurring)
+	router.Put("/recurring/{id}", h.UpdateRecurring)
+	router.Delete("/recurring/{id}", h.DeleteRecurring)
+	router.Post("/recurring/{id}/pause", h.PauseRecurring)
+	router.Post("/recurring/{id}/resume", h.ResumeRecurring)
+	router.Post("/recurring/{id}/process", h.ProcessRecurring)
+	router.Get("/recurring/{id}/transactions", h.ListRecurringHistory)
+
+	// Imports
+	router.Get("/imports", h.ListImports)
+	router.Post("/imports", h.StageImport)
+	router.Get("/imports/mappings", h.ListImportMappings)
+	router.Post("/imports/mappings", h.SaveImportMapping)
+	router.Delete("/imports/mappings/{id}", h.DeleteImportMapping)
+	router.Get("/imports/{id}", h.GetImport)
+	router.Delete("/imports/{id}", h.DiscardImport)
+	router.Post("/imports/{id}/commit", h.CommitImport)
 
 	// ai
 	router.Post("/neural-input", h.ParseTransactions)
@@ -146,18 +146,20 @@ type TransactionTag struct {
 
 // Attachment represents a file attached to a transaction
 type Attachment struct {
-	ID               uuid.UUID `json:"id" db:"id"`
-	TransactionID    uuid.UUID `json:"transaction_id" db:"transaction_id"`
-	Filename         string    `json:"filename" db:"filename"`
-	OriginalFilename string    `json:"original_filename" db:"original_filename"`
-	FileSize         int64     `json:"file_size" db:"file_size"`
-	MimeType         string    `json:"mime_type" db:"mime_type"`
-	StorageKey       string    `json:"storage_key" db:"storage_key"`
-	BucketName       string    `json:"bucket_name" db:"bucket_name"`
-	IsEncrypted      bool      `json:"is_encrypted" db:"is_encrypted"`
-	EncryptionKeyID  *string   `json:"encryption_key_id,omitempty" db:"encryption_key_id"`
-	UploadedBy       uuid.UUID `json:"uploaded_by" db:"uploaded_by"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	TransactionID    uuid.UUID  `json:"transaction_id" db:"transaction_id"`
+	Filename         string     `json:"filename" db:"filename"`
+	OriginalFilename string     `json:"original_filename" db:"original_filename"`
+	FileSize         int64      `json:"file_size" db:"file_size"`
+	MimeType         string     `json:"mime_type" db:"mime_type"`
+	StorageKey       string     `json:"storage_key" db:"storage_key"`
+	BucketName       string     `json:"bucket_name" db:"bucket_name"`
+	IsEncrypted      bool       `json:"is_encrypted" db:"is_encrypted"`
+	EncryptionKeyID  *string    `json:"encryption_key_id,omitempty" db:"encryption_key_id"`
+	Status           string     `json:"status" db:"status"`
+	UploadedBy       uuid.UUID  `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UploadedAt       *time.Time `json:"uploaded_at,omitempty" db:"uploaded_at"`
 
 	// Computed fields
 	PresignedURL *string `json:"presigned_url,omitempty"` // Temporary download URL
@@ -279,7 +281,16 @@ type GetRecurringInstancesRequest struct {
 
 type ProcessRecurringTransactionRequest struct {
 	Action             string                    `json:"action" validate:"required,oneof=post skip modify"`
-	TransactionRequest *CreateTransactionRequest `json:"transaction_request,omitempty"`
+	DueDate            *time.Time                `json:"due_date,omitempty"` // Defaults to the next due date
+	TransactionRequest *CreateTransactionRequest `json:"transaction_request,omitempty" validate:"required_if=Action modify"`
+}
+
+// ProcessRecurringTransactionResponse is the outcome of an action on a pending instance
+type ProcessRecurringTransactionResponse struct {
+	Action      string                  `json:"action"`
+	DueDate     time.Time               `json:"due_date"`
+	Transaction *repository.Transaction `json:"transaction,omitempty"` // Nil when the instance was skipped
+	Recurring   *RecurringTransaction   `json:"recurring"`
 }
 
 // Response structs for recurring transactions
@@ -363,13 +374,15 @@ type Tags []string
 
 // RecurringInstance represents a projected or actual instance of a recurring transaction
 type RecurringInstance struct {
-	DueDate       time.Time       `json:"due_date"`
-	Amount        decimal.Decimal `json:"amount"`
-	Description   *string         `json:"description,omitempty"`
-	TransactionID *uuid.UUID      `json:"transaction_id,omitempty"` // If already posted
-	Status        string          `json:"status"`                   // "pending", "posted", "skipped", "failed"
-	IsProjected   bool            `json:"is_projected"`             // True if not yet saved to DB
-	CanModify     bool            `json:"can_modify"`               // Whether this instance can be modified
+	RecurringTransactionID uuid.UUID       `json:"recurring_transaction_id"`
+	TemplateName           *string         `json:"template_name,omitempty"`
+	DueDate                time.Time       `json:"due_date"`
+	Amount                 decimal.Decimal `json:"amount"`
+	Description            *string         `json:"description,omitempty"`
+	TransactionID          *uuid.UUID      `json:"transaction_id,omitempty"` // If already posted
+	Status                 string          `json:"status"`                   // "pending", "posted", "skipped", "failed"
+	IsProjected            bool            `json:"is_projected"`             // True if not yet saved to DB
+	CanModify              bool            `json:"can_modify"`               // Whether this instance can be modified
 }
 
 // RecurringTransactionStats holds statistics about recurring transactions
@@ -421,6 +434,15 @@ const (
 	ConditionTypeDirection   ConditionType = "direction"
 	ConditionTypeType        ConditionType = "type"
 	ConditionTypeCategory    ConditionType = "category"
+
+	ConditionTypeDate                 ConditionType = "date"         // Day of the transaction, in UTC
+	ConditionTypeWeekday              ConditionType = "weekday"      // sunday to saturday, or 0 to 6
+	ConditionTypeDayOfMonth           ConditionType = "day_of_month" // 1 to 31
+	ConditionTypeMerchant             ConditionType = "merchant"     // Merchant or payee
+	ConditionTypeTags                 ConditionType = "tags"
+	ConditionTypeCurrency             ConditionType = "currency"
+	ConditionTypePaymentMedium        ConditionType = "payment_medium"
+	ConditionTypeMerchantCategoryCode ConditionType = "merchant_category_code"
 )
 
 // ConditionOperator represents the operator for the condition
@@ -437,6 +459,9 @@ const (
 	OperatorGreaterEqual ConditionOperator = "greater_equal"
 	OperatorLessThan     ConditionOperator = "less_than"
 	OperatorLessEqual    ConditionOperator = "less_equal"
+	OperatorMatchesRegex ConditionOperator = "matches_regex" // Case insensitive
+	OperatorIn           ConditionOperator = "in"            // Value is a list
+	OperatorBetween      ConditionOperator = "between"       // Value is a [min, max] list, bounds included
 )
 
 // ActionType represents the type of action
@@ -447,14 +472,20 @@ const (
 	ActionTypeSetDescription ActionType = "set_description"
 	ActionTypeSetTags        ActionType = "set_tags"
 	ActionTypeSetNote        ActionType = "set_note"
+
+	ActionTypeSetMerchant        ActionType = "set_merchant"
+	ActionTypeSetTaxDeductible   ActionType = "set_tax_deductible"   // Value is a boolean
+	ActionTypeSetBusinessExpense ActionType = "set_business_expense" // Value is a boolean
+	ActionTypeSplit              ActionType = "split"                // Value is a list of RuleSplit
+	ActionTypeLinkTransfer       ActionType = "link_transfer"        // Value is the days to look for the other side, 3 when null
+	ActionTypeStopProcessing     ActionType = "stop_processing"      // Lower priority rules don't run
 )
 
 // RuleCondition represents a single condition in a rule
 type RuleCondition struct {
-	Type      ConditionType     `json:"type"`
-	Operator  ConditionOperator `json:"operator"`
-	Value     interface{}       `json:"value"`
-	LogicGate string            `json:"logic_gate,omitempty"` // "AND" or "OR" - used to combine with next condition
+	Type     ConditionType     `json:"type"`
+	Operator ConditionOperator `json:"operator"`
+	Value    interface{}       `json:"value"`
 }
 
 // RuleAction represents a single action in a rule
@@ -465,17 +496,34 @@ type RuleAction struct {
 
 // TransactionRule represents a rule for automatically categorizing transactions
 type TransactionRule struct {
-	ID         uuid.UUID       `json:"id"`
-	Name       string          `json:"name"`
-	IsActive   bool            `json:"is_active"`
-	Priority   int             `json:"priority"`
-	Conditions []RuleCondition `json:"conditions"`
-	Actions    []RuleAction    `json:"actions"`
-	CreatedBy  uuid.UUID       `json:"created_by"`
-	UpdatedBy  *uuid.UUID      `json:"updated_by,omitempty"`
-	CreatedAt  time.Time       `json:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at"`
-	DeletedAt  *time.Time      `json:"deleted_at,omitempty"`
+	ID         uuid.UUID     `json:"id"`
+	Name       string        `json:"name"`
+	IsActive   bool          `json:"is_active"`
+	Priority   int           `json:"priority"`
+	Conditions ConditionNode `json:"conditions"`
+	Actions    []RuleAction  `json:"actions"`
+	CreatedBy  uuid.UUID     `json:"created_by"`
+	UpdatedBy  *uuid.UUID    `json:"updated_by,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	DeletedAt  *time.Time    `json:"deleted_at,omitempty"`
+
+	// Computed fields
+	MatchCount    int64      `json:"match_count"`               // Transactions the rule changed
+	LastMatchedAt *time.Time `json:"last_matched_at,omitempty"` // Last time the rule changed a transaction
+}
+
+// RuleApplication records the changes a rule made to a transaction
+type RuleApplication struct {
+	ID                  uuid.UUID     `json:"id"`
+	RuleID              uuid.UUID     `json:"rule_id"`
+	TransactionID       uuid.UUID     `json:"transaction_id"`
+	Description         *string       `json:"description,omitempty"`
+	TransactionDatetime *time.Time    `json:"transaction_datetime,omitempty"`
+	Actions             []RuleAction  `json:"actions"`
+	Changes             []FieldChange `json:"changes"`
+	CreatedAt           time.Time     `json:"created_at"`
+	UndoneAt            *time.Time    `json:"undone_at,omitempty"`
 }
 
 // TransactionData represents the data available for rule evaluation
@@ -493,6 +541,36 @@ type TransactionData struct {
 	TransactionCurrency  string          `json:"transaction_currency"`
 	IsExternal           bool            `json:"is_external"`
 	Tags                 []string        `json:"tags,omitempty"`
+	Merchant             *string         `json:"merchant,omitempty"`
+	PaymentMedium        *string         `json:"payment_medium,omitempty"`
+	MerchantCategoryCode *string         `json:"merchant_category_code,omitempty"`
+
+	// Time zone of the user, date, weekday and day of month conditions read the day in it. UTC when nil
+	Location *time.Location `json:"-"`
+}
+
+// LocalDatetime is the time of the transaction in the time zone of the user
+func (td *TransactionData) LocalDatetime() time.Time {
+	if td.Location == nil {
+		return td.TransactionDatetime.UTC()
+	}
+	return td.TransactionDatetime.In(td.Location)
+}
+
+// SetDetails fills the fields rules read from the details of the transaction
+func (td *TransactionData) SetDetails(details *dto.Details) {
+	td.Tags = []string{}
+	if details == nil {
+		return
+	}
+
+	if details.Tags != nil {
+		td.Tags = details.Tags
+	}
+
+	td.Merchant = details.Merchant
+	td.PaymentMedium = details.PaymentMedium
+	td.MerchantCategoryCode = details.MerchantCategoryCode
 }
 
 // RuleMatch represents the result of applying a rule to a transaction
@@ -507,20 +585,66 @@ type RuleMatch struct {
 
 // CreateTransactionRuleRequest represents the request to create a new rule
 type CreateTransactionRuleRequest struct {
-	Name       string          `json:"name" validate:"required,min=1,max=255"`
-	IsActive   bool            `json:"is_active"`
-	Priority   int             `json:"priority"`
-	Conditions []RuleCondition `json:"conditions" validate:"required,min=1"`
-	Actions    []RuleAction    `json:"actions" validate:"required,min=1"`
+	Name       string        `json:"name" validate:"required,min=1,max=255"`
+	IsActive   bool          `json:"is_active"`
+	Priority   int           `json:"priority"`
+	Conditions ConditionNode `json:"conditions" validate:"rule_conditions"`
+	Actions    []RuleAction  `json:"actions" validate:"required,min=1,rule_actions"`
 }
 
 // UpdateTransactionRuleRequest represents the request to update an existing rule
 type UpdateTransactionRuleRequest struct {
-	Name       *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	IsActive   *bool            `json:"is_active,omitempty"`
-	Priority   *int             `json:"priority,omitempty"`
-	Conditions *[]RuleCondition `json:"conditions,omitempty" validate:"omitempty,min=1"`
-	Actions    *[]RuleAction    `json:"actions,omitempty" validate:"omitempty,min=1"`
+	Name       *string        `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	IsActive   *bool          `json:"is_active,omitempty"`
+	Priority   *int           `json:"priority,omitempty"`
+	Conditions *ConditionNode `json:"conditions,omitempty" validate:"omitempty,rule_conditions"`
+	Actions    *[]RuleAction  `json:"actions,omitempty" validate:"omitempty,min=1,rule_actions"`
+}
+
+// ApplyRulesRequest runs rules over existing transactions
+type ApplyRulesRequest struct {
+	RuleIDs []uuid.UUID       `json:"rule_ids,omitempty"` // All active rules when empty
+	Filters dto.ExportFilters `json:"filters"`            // Filters of the transaction list
+	DryRun  bool              `json:"dry_run"`
+}
+
+// Fields a rule changes on a transaction
+const (
+	RuleFieldCategory        = "category"
+	RuleFieldDescription     = "description"
+	RuleFieldTags            = "tags"
+	RuleFieldNote            = "note"
+	RuleFieldMerchant        = "merchant"
+	RuleFieldTaxDeductible   = "tax_deductible"
+	RuleFieldBusinessExpense = "business_expense"
+	RuleFieldSplits          = "splits"
+	RuleFieldTransfer        = "transfer" // Before is the type, amount and account, After the ID of the other side, removed when linked
+)
+
+// FieldChange is the value of a transaction field before and after a rule runs
+type FieldChange struct {
+	Field  string    `json:"field"`
+	Before any       `json:"before"`
+	After  any       `json:"after"`
+	RuleID uuid.UUID `json:"rule_id"` // Rule that made the change, the first matching rule wins a field
+}
+
+// RuleChange lists what matching rules change on a transaction, the rule is the first of them
+type RuleChange struct {
+	TransactionID       uuid.UUID     `json:"transaction_id"`
+	Description         *string       `json:"description,omitempty"`
+	TransactionDatetime time.Time     `json:"transaction_datetime"`
+	RuleID              uuid.UUID     `json:"rule_id"`
+	RuleName            string        `json:"rule_name"`
+	Changes             []FieldChange `json:"changes"`
+}
+
+// RuleApplicationPreview is the dry run of ApplyRulesRequest
+type RuleApplicationPreview struct {
+	Scanned      int          `json:"scanned"`
+	Matched      int          `json:"matched"`
+	Truncated    bool         `json:"truncated"` // Transactions holds the first matches only
+	Transactions []RuleChange `json:"transactions"`
 }
 
 // Custom JSON marshaling for RuleCondition to handle interface{} values
@@ -564,3 +688,32 @@ func (ra *RuleAction) UnmarshalJSON(data []byte) error {
 	}
 	return json.Unmarshal(data, aux)
 }
+
+// Statement imports
+const (
+	ImportStatusStaged    = "staged"
+	ImportStatusCommitted = "committed"
+)
+
+// ImportPreview is an import with its staged lines, shown to the user before they commit it
+type ImportPreview struct {
+	repository.TransactionImport
+	Transactions []repository.StagedTransaction `json:"transactions"`
+}
+
+type StageImportParams struct {
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	Filename      string
+	Format        string // Detected when empty
+	Data          []byte
+	Mapping       *dto.CSVMapping
+	MappingID     *uuid.UUID
+	SaveMappingAs *string
+}
+
+type CommitImportParams struct {
+	CategoryID        uuid.UUID
+	SkipIDs           []uuid.UUID
+	IncludeDuplicates bool
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+)
+
+func (r *repo) CreateAttachment(ctx context.Context, params repository.CreateAttachmentParams) (repository.TransactionAttachment, error) {
+	return r.Queries.CreateAttachment(ctx, params)
+}
+
+func (r *repo) GetAttachment(ctx context.Context, id uuid.UUID, transactionID uuid.UUID, userID uuid.UUID) (repository.TransactionAttachment, error) {
+	return r.Queries.GetAttachment(ctx, repository.GetAttachmentParams{
+		ID:            id,
+		TransactionID: transactionID,
+		UserID:        userID,
+	})
+}
+
+func (r *repo) ListAttachments(ctx context.Context, transactionID uuid.UUID, userID uuid.UUID) ([]repository.TransactionAttachment, error) {
+	return r.Queries.ListAttachments(ctx, repository.ListAttachmentsParams{
+		TransactionID: transactionID,
+		UserID:        userID,
+	})
+}
+
+func (r *repo) CompleteAttachment(ctx context.Context, params repository.CompleteAttachmentParams) (repository.TransactionAttachment, error) {
+	return r.Queries.CompleteAttachment(ctx, params)
+}
+
+func (r *repo) DeleteAttachment(ctx context.Context, id uuid.UUID, transactionID uuid.UUID, userID uuid.UUID) (repository.TransactionAttachment, error) {
+	return r.Queries.DeleteAttachment(ctx, repository.DeleteAttachmentParams{
+		ID:            id,
+		TransactionID: transactionID,
+		UserID:        userID,
+	})
+}
+
+// DeleteTransactionAttachments removes the attachments of the transactions and returns them so
+// their files can be removed from storage
+func (r *repo) DeleteTransactionAttachments(ctx context.Context, transactionIDs []uuid.UUID) ([]repository.TransactionAttachment, error) {
+	return r.Queries.DeleteTransactionAttachments(ctx, transactionIDs)
+}
@@ -22,11 +22,18 @@ type Transactions interface {
 	CreateTransaction(ctx context.Context, params repository.CreateTransactionParams) (repository.Transaction, error)
 	UpdateTransaction(ctx context.Context, params repository.UpdateTransactionParams) (repository.Transaction, error)
 	DeleteTransaction(ctx context.Context, id uuid.UUID) error
+	FindTransferCounterpart(ctx context.Context, params repository.FindTransferCounterpartParams) (repository.Transaction, error)
+	LinkTransfer(ctx context.Context, params repository.LinkTransferParams) (repository.Transaction, error)
+
+	// Splits
+	ListTransactionSplits(ctx context.Context, transactionIDs []uuid.UUID) ([]repository.TransactionSplit, error)
+	CreateTransactionSplit(ctx context.Context, params repository.CreateTransactionSplitParams) (repository.TransactionSplit, error)
+	DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error
 
 	// Bulk operations
-	BulkDeleteTransactions(ctx context.Context, params repository.BulkDeleteTransactionsParams) error
-	BulkUpdateTransactionCategories(ctx context.Context, params repository.BulkUpdateTransactionCategoriesParams) error
-	BulkUpdateManualTransactions(ctx context.Context, params repository.BulkUpdateManualTransactionsParams) error
+	BulkDeleteTransactions(ctx context.Context, params repository.BulkDeleteTransactionsParams) ([]repository.Transaction, error)
+	BulkUpdateTransactionCategories(ctx context.Context, params repository.BulkUpdateTransactionCategoriesParams) ([]repository.Transaction, error)
+	BulkUpdateManualTransactions(ctx context.Context, params repository.BulkUpdateManualTransactionsParams) ([]repository.Transaction, error)
 
 	// Rules
 	CreateRule(ctx context.Context, params CreateRuleParams) (*transactions.TransactionRule, error)
@@ -37,16 +44,53 @@ type Transactions interface {
 	DeleteRule(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	ToggleRuleActive(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.TransactionRule, error)
 
+	// Time zone rules read dates in
+	GetPreferencesByUserId(ctx context.Context, userID uuid.UUID) (repository.GetPreferencesByUserIdRow, error)
+
+	// Rule applications
+	CreateRuleApplication(ctx context.Context, params repository.CreateRuleApplicationParams) (repository.TransactionRuleApplication, error)
+	GetRuleApplication(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.TransactionRuleApplication, error)
+	ListRuleApplications(ctx context.Context, params repository.ListRuleApplicationsParams) ([]repository.ListRuleApplicationsRow, error)
+	MarkRuleApplicationUndone(ctx context.Context, id uuid.UUID) (repository.TransactionRuleApplication, error)
+	RevertTransaction(ctx context.Context, params repository.RevertTransactionParams) (repository.Transaction, error)
+	RestoreTransaction(ctx context.Context, id uuid.UUID) (repository.Transaction, error)
+
 	CreateRecurringTransaction(ctx context.Context, req transactions.CreateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error)
 	GetRecurringTransactionByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.RecurringTransaction, error)
 	ListRecurringTransactions(ctx context.Context, userID uuid.UUID, filters transactions.RecurringTransactionFilters) ([]transactions.RecurringTransaction, error)
 	UpdateRecurringTransaction(ctx context.Context, id uuid.UUID, req transactions.UpdateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error)
 	DeleteRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	PauseRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID, isPaused bool) (*transactions.RecurringTransaction, error)
+	MarkRecurringInstanceGenerated(ctx context.Context, id uuid.UUID, generatedDate, nextDueDate time.Time) error
+	SetRecurringNextDueDate(ctx context.Context, id uuid.UUID, nextDueDate time.Time) (*transactions.RecurringTransaction, error)
+	SkipRecurringInstance(ctx context.Context, id uuid.UUID, dueDate, nextDueDate time.Time) (*transactions.RecurringTransaction, error)
 	GetDueRecurringTransactions(ctx context.Context, dueDate time.Time) ([]transactions.RecurringTransaction, error)
 	GetRecurringTransactionStats(ctx context.Context, userID uuid.UUID) (*transactions.RecurringTransactionStats, error)
 	GetUpcomingRecurringTransactions(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]transactions.RecurringTransaction, error)
 	GetRecurringTransactionInstances(ctx context.Context, userID uuid.UUID, recurringID uuid.UUID) ([]repository.Transaction, error)
+
+	// Statement imports
+	SaveImportMapping(ctx context.Context, params repository.SaveImportMappingParams) (repository.ImportMapping, error)
+	GetImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.ImportMapping, error)
+	ListImportMappings(ctx context.Context, userID uuid.UUID) ([]repository.ImportMapping, error)
+	DeleteImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	CreateImport(ctx context.Context, params repository.CreateTransactionImportParams) (repository.TransactionImport, error)
+	StageTransactions(ctx context.Context, rows []repository.CreateStagedTransactionsParams) error
+	GetImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.TransactionImport, error)
+	ListImports(ctx context.Context, params repository.ListTransactionImportsParams) ([]repository.TransactionImport, error)
+	ListStagedTransactions(ctx context.Context, importID uuid.UUID) ([]repository.StagedTransaction, error)
+	LinkStagedTransaction(ctx context.Context, stagedID uuid.UUID, transactionID uuid.UUID) error
+	MarkImportCommitted(ctx context.Context, id uuid.UUID, committedCount int32) (bool, error)
+	DeleteStagedImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	ListDuplicateCandidates(ctx context.Context, accountID uuid.UUID, start, end time.Time) ([]repository.ListImportDuplicateCandidatesRow, error)
+
+	// Attachments
+	CreateAttachment(ctx context.Context, params repository.CreateAttachmentParams) (repository.TransactionAttachment, error)
+	GetAttachment(ctx context.Context, id uuid.UUID, transactionID uuid.UUID, userID uuid.UUID) (repository.TransactionAttachment, error)
+	ListAttachments(ctx context.Context, transactionID uuid.UUID, userID uuid.UUID) ([]repository.TransactionAttachment, error)
+	CompleteAttachment(ctx context.Context, params repository.CompleteAttachmentParams) (repository.TransactionAttachment, error)
+	DeleteAttachment(ctx context.Context, id uuid.UUID, transactionID uuid.UUID, userID uuid.UUID) (repository.TransactionAttachment, error)
+	DeleteTransactionAttachments(ctx context.Context, transactionIDs []uuid.UUID) ([]repository.TransactionAttachment, error)
 }
 
 type repo struct {
@@ -91,14 +135,34 @@ func (r *repo) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
 	return r.Queries.DeleteTransaction(ctx, id)
 }
 
-func (r *repo) BulkDeleteTransactions(ctx context.Context, params repository.BulkDeleteTransactionsParams) error {
+func (r *repo) FindTransferCounterpart(ctx context.Context, params repository.FindTransferCounterpartParams) (repository.Transaction, error) {
+	return r.Queries.FindTransferCounterpart(ctx, params)
+}
+
+func (r *repo) LinkTransfer(ctx context.Context, params repository.LinkTransferParams) (repository.Transaction, error) {
+	return r.Queries.LinkTransfer(ctx, params)
+}
+
+func (r *repo) ListTransactionSplits(ctx context.Context, transactionIDs []uuid.UUID) ([]repository.TransactionSplit, error) {
+	return r.Queries.ListTransactionSplits(ctx, transactionIDs)
+}
+
+func (r *repo) CreateTransactionSplit(ctx context.Context, params repository.CreateTransactionSplitParams) (repository.TransactionSplit, error) {
+	return r.Queries.CreateTransactionSplit(ctx, params)
+}
+
+func (r *repo) DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error {
+	return r.Queries.DeleteTransactionSplits(ctx, transactionID)
+}
+
+func (r *repo) BulkDeleteTransactions(ctx context.Context, params repository.BulkDeleteTransactionsParams) ([]repository.Transaction, error) {
 	return r.Queries.BulkDeleteTransactions(ctx, params)
 }
 
-func (r *repo) BulkUpdateTransactionCategories(ctx context.Context, params repository.BulkUpdateTransactionCategoriesParams) error {
+func (r *repo) BulkUpdateTransactionCategories(ctx context.Context, params repository.BulkUpdateTransactionCategoriesParams) ([]repository.Transaction, error) {
 	return r.Queries.BulkUpdateTransactionCategories(ctx, params)
 }
 
-func (r *repo) BulkUpdateManualTransactions(ctx context.Context, params repository.BulkUpdateManualTransactionsParams) error {
+func (r *repo) BulkUpdateManualTransactions(ctx context.Context, params repository.BulkUpdateManualTransactionsParams) ([]repository.Transaction, error) {
 	return r.Queries.BulkUpdateManualTransactions(ctx, params)
 }
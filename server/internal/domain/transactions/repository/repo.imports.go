@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+)
+
+func (r *repo) SaveImportMapping(ctx context.Context, params repository.SaveImportMappingParams) (repository.ImportMapping, error) {
+	return r.Queries.SaveImportMapping(ctx, params)
+}
+
+func (r *repo) GetImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.ImportMapping, error) {
+	return r.Queries.GetImportMapping(ctx, repository.GetImportMappingParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+func (r *repo) ListImportMappings(ctx context.Context, userID uuid.UUID) ([]repository.ImportMapping, error) {
+	return r.Queries.ListImportMappings(ctx, userID)
+}
+
+// DeleteImportMapping reports whether the user had such a mapping
+func (r *repo) DeleteImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	deleted, err := r.Queries.DeleteImportMapping(ctx, repository.DeleteImportMappingParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return deleted > 0, err
+}
+
+func (r *repo) CreateImport(ctx context.Context, params repository.CreateTransactionImportParams) (repository.TransactionImport, error) {
+	return r.Queries.CreateTransactionImport(ctx, params)
+}
+
+func (r *repo) StageTransactions(ctx context.Context, rows []repository.CreateStagedTransactionsParams) error {
+	_, err := r.Queries.CreateStagedTransactions(ctx, rows)
+	return err
+}
+
+func (r *repo) GetImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.TransactionImport, error) {
+	return r.Queries.GetTransactionImport(ctx, repository.GetTransactionImportParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+func (r *repo) ListImports(ctx context.Context, params repository.ListTransactionImportsParams) ([]repository.TransactionImport, error) {
+	return r.Queries.ListTransactionImports(ctx, params)
+}
+
+func (r *repo) ListStagedTransactions(ctx context.Context, importID uuid.UUID) ([]repository.StagedTransaction, error) {
+	return r.Queries.ListStagedTransactions(ctx, importID)
+}
+
+func (r *repo) LinkStagedTransaction(ctx context.Context, stagedID uuid.UUID, transactionID uuid.UUID) error {
+	return r.Queries.SetStagedTransactionCommitted(ctx, repository.SetStagedTransactionCommittedParams{
+		ID:            stagedID,
+		TransactionID: &transactionID,
+	})
+}
+
+// MarkImportCommitted reports false when the import was committed in the meantime
+func (r *repo) MarkImportCommitted(ctx context.Context, id uuid.UUID, committedCount int32) (bool, error) {
+	updated, err := r.Queries.CommitTransactionImport(ctx, repository.CommitTransactionImportParams{
+		ID:             id,
+		CommittedCount: committedCount,
+	})
+	return updated > 0, err
+}
+
+// DeleteStagedImport reports false when the user has no such import left to commit
+func (r *repo) DeleteStagedImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	deleted, err := r.Queries.DeleteStagedTransactionImport(ctx, repository.DeleteStagedTransactionImportParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return deleted > 0, err
+}
+
+func (r *repo) ListDuplicateCandidates(ctx context.Context, accountID uuid.UUID, start, end time.Time) ([]repository.ListImportDuplicateCandidatesRow, error) {
+	return r.Queries.ListImportDuplicateCandidates(ctx, repository.ListImportDuplicateCandidatesParams{
+		AccountID: accountID,
+		StartDate: start,
+		EndDate:   end,
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shopspring/decimal"
 )
@@ -218,7 +219,10 @@ func (r *repo) UpdateRecurringTransaction(ctx context.Context, id uuid.UUID, req
 		endDate = pgtype.Timestamptz{Valid: true, Time: *req.EndDate}
 	}
 
-	amount := decimal.NewNullDecimal(*req.Amount)
+	var amount decimal.NullDecimal
+	if req.Amount != nil {
+		amount = decimal.NewNullDecimal(*req.Amount)
+	}
 
 	// Update the recurring transaction
 	dbRecurring, err := r.Queries.UpdateRecurringTransaction(ctx, repository.UpdateRecurringTransactionParams{
@@ -270,6 +274,54 @@ func (r *repo) PauseRecurringTransaction(ctx context.Context, id uuid.UUID, user
 	return convertDBRecurringToModel(dbRecurring), nil
 }
 
+// MarkRecurringInstanceGenerated records that the pending instance due at generatedDate was posted and moves the template to its next due date.
+// It fails with pgx.ErrNoRows when the template is no longer due at generatedDate, the instance was posted or skipped meanwhile
+func (r *repo) MarkRecurringInstanceGenerated(ctx context.Context, id uuid.UUID, generatedDate, nextDueDate time.Time) error {
+	updated, err := r.Queries.UpdateRecurringTransactionAfterGeneration(ctx, repository.UpdateRecurringTransactionAfterGenerationParams{
+		ID:                id,
+		LastGeneratedDate: pgtype.Timestamptz{Valid: true, Time: generatedDate},
+		NextDueDate:       pgtype.Timestamptz{Valid: true, Time: nextDueDate},
+		DueDate:           pgtype.Timestamptz{Valid: true, Time: generatedDate},
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// SkipRecurringInstance moves the template past the pending instance due at dueDate without generating it.
+// It fails with pgx.ErrNoRows when the template is no longer due at dueDate
+func (r *repo) SkipRecurringInstance(ctx context.Context, id uuid.UUID, dueDate, nextDueDate time.Time) (*transactions.RecurringTransaction, error) {
+	dbRecurring, err := r.Queries.SkipRecurringInstance(ctx, repository.SkipRecurringInstanceParams{
+		ID:          id,
+		DueDate:     pgtype.Timestamptz{Valid: true, Time: dueDate},
+		NextDueDate: pgtype.Timestamptz{Valid: true, Time: nextDueDate},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return convertDBRecurringToModel(dbRecurring), nil
+}
+
+// SetRecurringNextDueDate moves the template to another due date without generating an instance
+func (r *repo) SetRecurringNextDueDate(ctx context.Context, id uuid.UUID, nextDueDate time.Time) (*transactions.RecurringTransaction, error) {
+	dbRecurring, err := r.Queries.UpdateRecurringTransactionNextDueDate(ctx, repository.UpdateRecurringTransactionNextDueDateParams{
+		ID:          id,
+		NextDueDate: pgtype.Timestamptz{Valid: true, Time: nextDueDate},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return convertDBRecurringToModel(dbRecurring), nil
+}
+
 func (r *repo) GetDueRecurringTransactions(ctx context.Context, dueDate time.Time) ([]transactions.RecurringTransaction, error) {
 	dbRecurrings, err := r.Queries.GetDueRecurringTransactions(ctx, pgtype.Timestamptz{Valid: true, Time: dueDate})
 	if err != nil {
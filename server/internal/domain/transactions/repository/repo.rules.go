@@ -9,40 +9,41 @@ import (
 	"time"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
 type CreateRuleParams struct {
-	Name       string                       `json:"name"`
-	IsActive   bool                         `json:"is_active"`
-	Priority   int                          `json:"priority"`
-	Conditions []transactions.RuleCondition `json:"conditions"`
-	Actions    []transactions.RuleAction    `json:"actions"`
-	CreatedBy  uuid.UUID                    `json:"created_by"`
+	Name       string                     `json:"name"`
+	IsActive   bool                       `json:"is_active"`
+	Priority   int                        `json:"priority"`
+	Conditions transactions.ConditionNode `json:"conditions"`
+	Actions    []transactions.RuleAction  `json:"actions"`
+	CreatedBy  uuid.UUID                  `json:"created_by"`
 }
 
 // UpdateRuleParams represents the parameters for updating a rule
 type UpdateRuleParams struct {
-	ID         uuid.UUID                     `json:"id"`
-	Name       *string                       `json:"name,omitempty"`
-	IsActive   *bool                         `json:"is_active,omitempty"`
-	Priority   *int                          `json:"priority,omitempty"`
-	Conditions *[]transactions.RuleCondition `json:"conditions,omitempty"`
-	Actions    *[]transactions.RuleAction    `json:"actions,omitempty"`
-	UpdatedBy  uuid.UUID                     `json:"updated_by"`
+	ID         uuid.UUID                   `json:"id"`
+	Name       *string                     `json:"name,omitempty"`
+	IsActive   *bool                       `json:"is_active,omitempty"`
+	Priority   *int                        `json:"priority,omitempty"`
+	Conditions *transactions.ConditionNode `json:"conditions,omitempty"`
+	Actions    *[]transactions.RuleAction  `json:"actions,omitempty"`
+	UpdatedBy  uuid.UUID                   `json:"updated_by"`
 }
 
 // ConditionsJSON is a helper type for JSON marshaling/unmarshaling
-type ConditionsJSON []transactions.RuleCondition
+type ConditionsJSON transactions.ConditionNode
 
 func (c ConditionsJSON) Value() (driver.Value, error) {
-	return json.Marshal(c)
+	return json.Marshal(transactions.ConditionNode(c))
 }
 
 func (c *ConditionsJSON) Scan(value any) error {
 	if value == nil {
-		*c = nil
+		*c = ConditionsJSON{}
 		return nil
 	}
 
@@ -51,7 +52,7 @@ func (c *ConditionsJSON) Scan(value any) error {
 		return fmt.Errorf("cannot scan %T into ConditionsJSON", value)
 	}
 
-	return json.Unmarshal(bytes, c)
+	return json.Unmarshal(bytes, (*transactions.ConditionNode)(c))
 }
 
 // ActionsJSON is a helper type for JSON marshaling/unmarshaling
@@ -147,11 +148,19 @@ func (r *repo) GetRuleByID(ctx context.Context, id uuid.UUID) (*transactions.Tra
 }
 
 func (r *repo) ListRules(ctx context.Context, userID uuid.UUID) ([]transactions.TransactionRule, error) {
+	// Undone applications aren't counted as matches
 	query := `
-		SELECT id, name, is_active, priority, conditions, actions, created_by, updated_by, created_at, updated_at, deleted_at
-		FROM transaction_rules
-		WHERE created_by = $1 AND deleted_at IS NULL
-		ORDER BY priority DESC, created_at DESC
+		SELECT r.id, r.name, r.is_active, r.priority, r.conditions, r.actions, r.created_by, r.updated_by, r.created_at, r.updated_at, r.deleted_at,
+			coalesce(s.match_count, 0), s.last_matched_at
+		FROM transaction_rules r
+		LEFT JOIN (
+			SELECT rule_id, count(*) AS match_count, max(created_at) AS last_matched_at
+			FROM transaction_rule_applications
+			WHERE created_by = $1 AND undone_at IS NULL
+			GROUP BY rule_id
+		) s ON s.rule_id = r.id
+		WHERE r.created_by = $1 AND r.deleted_at IS NULL
+		ORDER BY r.priority DESC, r.created_at DESC
 	`
 
 	rows, err := r.db.Query(ctx, query, userID)
@@ -168,6 +177,7 @@ func (r *repo) ListRules(ctx context.Context, userID uuid.UUID) ([]transactions.
 
 		err := rows.Scan(
 			&rule.ID, &rule.Name, &rule.IsActive, &rule.Priority, &conditionsRaw, &actionsRaw, &rule.CreatedBy, &rule.UpdatedBy, &rule.CreatedAt, &rule.UpdatedAt, &rule.DeletedAt,
+			&rule.MatchCount, &rule.LastMatchedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan rule: %w", err)
@@ -368,3 +378,34 @@ func (r *repo) ToggleRuleActive(ctx context.Context, id uuid.UUID, userID uuid.U
 
 	return &rule, nil
 }
+
+func (r *repo) GetPreferencesByUserId(ctx context.Context, userID uuid.UUID) (repository.GetPreferencesByUserIdRow, error) {
+	return r.Queries.GetPreferencesByUserId(ctx, userID)
+}
+
+func (r *repo) CreateRuleApplication(ctx context.Context, params repository.CreateRuleApplicationParams) (repository.TransactionRuleApplication, error) {
+	return r.Queries.CreateRuleApplication(ctx, params)
+}
+
+func (r *repo) GetRuleApplication(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.TransactionRuleApplication, error) {
+	return r.Queries.GetRuleApplication(ctx, repository.GetRuleApplicationParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+func (r *repo) ListRuleApplications(ctx context.Context, params repository.ListRuleApplicationsParams) ([]repository.ListRuleApplicationsRow, error) {
+	return r.Queries.ListRuleApplications(ctx, params)
+}
+
+func (r *repo) MarkRuleApplicationUndone(ctx context.Context, id uuid.UUID) (repository.TransactionRuleApplication, error) {
+	return r.Queries.MarkRuleApplicationUndone(ctx, id)
+}
+
+func (r *repo) RevertTransaction(ctx context.Context, params repository.RevertTransactionParams) (repository.Transaction, error) {
+	return r.Queries.RevertTransaction(ctx, params)
+}
+
+func (r *repo) RestoreTransaction(ctx context.Context, id uuid.UUID) (repository.Transaction, error) {
+	return r.Queries.RestoreTransaction(ctx, id)
+}
@@ -168,7 +168,7 @@ type CreateTagRequest struct {
 }
 
 type UploadAttachmentRequest struct {
-	TransactionID string `json:"transaction_id" validate:"required,uuid"`
+	TransactionID string `json:"-"` // Taken from the path
 	Filename      string `json:"filename" validate:"required"`
 	FileSize      int64  `json:"file_size" validate:"required,gt=0"`
 	MimeType      string `json:"mime_type" validate:"required"`
@@ -182,7 +182,7 @@ type TransactionResponse struct {
 
 type AttachmentUploadResponse struct {
 	AttachmentID uuid.UUID `json:"attachment_id"`
-	UploadURL    string    `json:"upload_url"` // Presigned URL for upload
+	UploadURL    string    `json:"upload_url"` // Presigned URL for upload, empty when the file goes through the API
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
@@ -191,6 +191,26 @@ type AttachmentDownloadResponse struct {
 	ExpiresAt   time.Time `json:"expires_at"`
 }
 
+// ReceiptDraft is a transaction read from a receipt attachment, for the client to review
+// before saving it
+type ReceiptDraft struct {
+	AttachmentID        uuid.UUID       `json:"attachment_id"`
+	TransactionID       uuid.UUID       `json:"transaction_id"`
+	Amount              decimal.Decimal `json:"amount"`
+	Type                string          `json:"type"`
+	Description         *string         `json:"description,omitempty"`
+	CategoryHint        *string         `json:"category_hint,omitempty"`
+	MerchantName        *string         `json:"merchant_name,omitempty"`
+	TransactionDatetime *time.Time      `json:"transaction_datetime,omitempty"`
+	CurrencyCode        string          `json:"currency_code"`
+	Details             Details         `json:"details"`
+	Confidence          float64         `json:"confidence"`
+	Model               string          `json:"model"`
+	Provider            string          `json:"provider"`
+	Source              string          `json:"source"` // vision or ocr
+	ParsedAt            time.Time       `json:"parsed_at"`
+}
+
 type EnhancedTransaction struct {
 	repository.ListTransactionsRow
 	DestinationAccount *repository.GetAccountsRow `json:"destination_account,omitempty"`
@@ -202,3 +222,23 @@ type Group struct {
 	Total        float64               `json:"total"` // e.g., "$700.00"
 	Transactions []EnhancedTransaction `json:"transactions"`
 }
+
+// StageImportRequest holds the form fields sent along a statement file
+type StageImportRequest struct {
+	AccountID     string          `validate:"required,uuid"`
+	Format        string          `validate:"omitempty,oneof=csv ofx qif camt053"`
+	MappingID     string          `validate:"omitempty,uuid"`
+	Mapping       *dto.CSVMapping `validate:"omitempty"`
+	SaveMappingAs string          `validate:"omitempty,max=100"`
+}
+
+type CommitImportRequest struct {
+	CategoryID        string   `json:"category_id" validate:"required,uuid"` // Until rules assign another one
+	SkipIDs           []string `json:"skip_ids" validate:"omitempty,dive,uuid"`
+	IncludeDuplicates bool     `json:"include_duplicates"`
+}
+
+type SaveImportMappingRequest struct {
+	Name    string         `json:"name" validate:"required,max=100"`
+	Mapping dto.CSVMapping `json:"mapping"`
+}
@@ -0,0 +1,312 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// Reader reads the transactions rules run on and what split and transfer actions compare them with
+type Reader interface {
+	ListTransactions(ctx context.Context, arg repository.ListTransactionsParams) ([]repository.ListTransactionsRow, error)
+	ListTransactionSplits(ctx context.Context, transactionIds []uuid.UUID) ([]repository.TransactionSplit, error)
+	FindTransferCounterpart(ctx context.Context, arg repository.FindTransferCounterpartParams) (repository.Transaction, error)
+}
+
+// Writer saves the changes of rules, it should run in a database transaction
+type Writer interface {
+	UpdateTransaction(ctx context.Context, params repository.UpdateTransactionParams) (repository.Transaction, error)
+	LinkTransfer(ctx context.Context, arg repository.LinkTransferParams) (repository.Transaction, error)
+	DeleteTransaction(ctx context.Context, id uuid.UUID) error
+	DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error
+	CreateTransactionSplit(ctx context.Context, arg repository.CreateTransactionSplitParams) (repository.TransactionSplit, error)
+	CreateRuleApplication(ctx context.Context, arg repository.CreateRuleApplicationParams) (repository.TransactionRuleApplication, error)
+}
+
+// TransferBefore is the transaction a link_transfer action turned into a transfer as it was, the
+// value before of the transfer change
+type TransferBefore struct {
+	Type      string          `json:"type"`
+	Amount    decimal.Decimal `json:"amount"`
+	AccountID uuid.UUID       `json:"account_id"`
+}
+
+// Applied is what saving the changes of rules wrote
+type Applied struct {
+	Transaction repository.Transaction
+	Deleted     *repository.Transaction // The other side of a linked transfer
+}
+
+// Resolve completes the changes of split and transfer actions, which depend on more than the
+// transaction: splits equal to the ones the transaction has are left out, and the other side of
+// a transfer is looked up. Transactions in claimed are already changed by other rules and are
+// never used as the other side
+func Resolve(ctx context.Context, reader Reader, userID uuid.UUID, transaction *transactions.TransactionData, changes *Changes, claimed map[uuid.UUID]bool) error {
+	if changes.Transfer != nil {
+		counterpart, err := findCounterpart(ctx, reader, userID, transaction, changes.Transfer.Window)
+		if err != nil {
+			return err
+		}
+
+		if counterpart == nil || claimed[counterpart.ID] {
+			changes.Transfer = nil
+		} else {
+			changes.Transfer.Counterpart = counterpart
+			before := TransferBefore{Type: transaction.Type, Amount: transaction.Amount, AccountID: transaction.AccountID}
+			changes.set(transactions.RuleFieldTransfer, changes.Transfer.RuleID, before, counterpart.ID)
+
+			// A transfer isn't split
+			changes.Splits = nil
+			changes.drop(transactions.RuleFieldSplits)
+		}
+	}
+
+	if changes.Splits == nil {
+		return nil
+	}
+
+	rows, err := reader.ListTransactionSplits(ctx, []uuid.UUID{transaction.ID})
+	if err != nil {
+		return fmt.Errorf("failed to list transaction splits: %w", err)
+	}
+
+	existing := splitsFromRows(rows)
+
+	if sameSplits(existing, changes.Splits) {
+		changes.Splits = nil
+		changes.drop(transactions.RuleFieldSplits)
+		return nil
+	}
+
+	for i, change := range changes.Fields {
+		if change.Field == transactions.RuleFieldSplits && len(existing) > 0 {
+			changes.Fields[i].Before = existing
+		}
+	}
+
+	return nil
+}
+
+// Apply saves the changes of rules on a transaction, with a record of what each of the matching
+// rules changed
+func Apply(ctx context.Context, writer Writer, transactionID uuid.UUID, userID uuid.UUID, matches []transactions.RuleMatch, changes Changes) (Applied, error) {
+	var applied Applied
+
+	transaction, err := writer.UpdateTransaction(ctx, repository.UpdateTransactionParams{
+		ID:          transactionID,
+		CategoryID:  changes.CategoryID,
+		Description: changes.Description,
+		Details:     changes.Details,
+		UpdatedBy:   &userID,
+	})
+	if err != nil {
+		return applied, fmt.Errorf("failed to update transaction %s: %w", transactionID, err)
+	}
+
+	if transfer := changes.Transfer; transfer != nil && transfer.Counterpart != nil {
+		transaction, err = linkTransfer(ctx, writer, transaction, *transfer.Counterpart, userID)
+		if err != nil {
+			return applied, err
+		}
+
+		applied.Deleted = transfer.Counterpart
+	}
+
+	if changes.Splits != nil {
+		if err := replaceSplits(ctx, writer, transactionID, changes.Splits); err != nil {
+			return applied, err
+		}
+	}
+
+	for _, match := range ChangedBy(matches, changes) {
+		if err := record(ctx, writer, transactionID, userID, match, changes); err != nil {
+			return applied, err
+		}
+	}
+
+	applied.Transaction = transaction
+
+	return applied, nil
+}
+
+// findCounterpart looks for the income an expense was paid into, or the expense an income came
+// from, on another account within window days
+func findCounterpart(ctx context.Context, reader Reader, userID uuid.UUID, transaction *transactions.TransactionData, window int) (*repository.Transaction, error) {
+	counterpartType := "income"
+	if transaction.Type == "income" {
+		counterpartType = "expense"
+	}
+
+	counterpart, err := reader.FindTransferCounterpart(ctx, repository.FindTransferCounterpartParams{
+		UserID:              &userID,
+		ID:                  transaction.ID,
+		AccountID:           transaction.AccountID,
+		Type:                counterpartType,
+		Amount:              transaction.Amount.Neg(),
+		Currency:            transaction.TransactionCurrency,
+		StartDate:           transaction.TransactionDatetime.AddDate(0, 0, -window),
+		EndDate:             transaction.TransactionDatetime.AddDate(0, 0, window),
+		TransactionDatetime: pgtype.Timestamptz{Time: transaction.TransactionDatetime, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the other side of transfer %s: %w", transaction.ID, err)
+	}
+
+	return &counterpart, nil
+}
+
+// linkTransfer turns a transaction into a transfer from the account of the expense to the account
+// of the income, and deletes the other side. Balances stay the same as the transfer moves the
+// amount the two transactions did
+func linkTransfer(ctx context.Context, writer Writer, transaction, counterpart repository.Transaction, userID uuid.UUID) (repository.Transaction, error) {
+	source, destination := transaction.AccountID, counterpart.AccountID
+	if transaction.Type == "income" {
+		source, destination = destination, source
+	}
+
+	amount := types.PgtypeNumericToDecimal(transaction.Amount)
+
+	linked, err := writer.LinkTransfer(ctx, repository.LinkTransferParams{
+		ID:                   transaction.ID,
+		Amount:               amount.Abs().Neg(),
+		AccountID:            source,
+		DestinationAccountID: &destination,
+		UpdatedBy:            &userID,
+	})
+	if err != nil {
+		return linked, fmt.Errorf("failed to link transfer %s: %w", transaction.ID, err)
+	}
+
+	if err := writer.DeleteTransaction(ctx, counterpart.ID); err != nil {
+		return linked, fmt.Errorf("failed to delete the other side of transfer %s: %w", transaction.ID, err)
+	}
+
+	return linked, nil
+}
+
+// record saves the actions of a rule and the changes it made to a transaction
+func record(ctx context.Context, writer Writer, transactionID uuid.UUID, userID uuid.UUID, match transactions.RuleMatch, changes Changes) error {
+	fields := []transactions.FieldChange{}
+	for _, change := range changes.Fields {
+		if change.RuleID == match.RuleID {
+			fields = append(fields, change)
+		}
+	}
+
+	actionsJSON, err := json.Marshal(match.Actions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	changesJSON, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes: %w", err)
+	}
+
+	_, err = writer.CreateRuleApplication(ctx, repository.CreateRuleApplicationParams{
+		RuleID:        match.RuleID,
+		TransactionID: transactionID,
+		Actions:       actionsJSON,
+		Changes:       changesJSON,
+		CreatedBy:     userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record rule %s on transaction %s: %w", match.RuleID, transactionID, err)
+	}
+
+	return nil
+}
+
+// splitWriter replaces the splits of a transaction
+type splitWriter interface {
+	DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error
+	CreateTransactionSplit(ctx context.Context, arg repository.CreateTransactionSplitParams) (repository.TransactionSplit, error)
+}
+
+func replaceSplits(ctx context.Context, writer splitWriter, transactionID uuid.UUID, splits []Split) error {
+	if err := writer.DeleteTransactionSplits(ctx, transactionID); err != nil {
+		return fmt.Errorf("failed to delete splits of transaction %s: %w", transactionID, err)
+	}
+
+	for _, split := range splits {
+		_, err := writer.CreateTransactionSplit(ctx, repository.CreateTransactionSplitParams{
+			TransactionID: transactionID,
+			CategoryID:    &split.CategoryID,
+			Amount:        split.Amount,
+			Percentage:    split.Percentage,
+			Description:   split.Description,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to split transaction %s: %w", transactionID, err)
+		}
+	}
+
+	return nil
+}
+
+func splitsFromRows(rows []repository.TransactionSplit) []Split {
+	splits := make([]Split, len(rows))
+	for i, row := range rows {
+		splits[i] = Split{
+			Amount:      types.PgtypeNumericToDecimal(row.Amount),
+			Percentage:  types.PgtypeNumericToDecimal(row.Percentage),
+			Description: row.Description,
+		}
+		if row.CategoryID != nil {
+			splits[i].CategoryID = *row.CategoryID
+		}
+	}
+
+	return splits
+}
+
+// drop removes the change of a field
+func (c *Changes) drop(field string) {
+	c.Fields = slices.DeleteFunc(c.Fields, func(change transactions.FieldChange) bool {
+		return change.Field == field
+	})
+}
+
+// sameSplits reports whether two sets of splits have the same parts, in any order
+func sameSplits(a, b []Split) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	used := make([]bool, len(b))
+	for _, split := range a {
+		found := false
+		for i, other := range b {
+			if !used[i] && sameSplit(split, other) {
+				used[i], found = true, true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameSplit(a, b Split) bool {
+	sameDescription := (a.Description == nil && b.Description == nil) ||
+		(a.Description != nil && b.Description != nil && *a.Description == *b.Description)
+
+	return a.CategoryID == b.CategoryID && a.Amount.Equal(b.Amount) && a.Percentage.Equal(b.Percentage) && sameDescription
+}
@@ -0,0 +1,209 @@
+package rules_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// fakeReader returns a fixed other side of transfers and fixed splits, and pages over transactions
+// given in list order
+type fakeReader struct {
+	counterpart  *repository.Transaction
+	splits       []repository.TransactionSplit
+	lookup       repository.FindTransferCounterpartParams
+	transactions []repository.ListTransactionsRow
+	offsets      []int64
+}
+
+func (f *fakeReader) ListTransactions(ctx context.Context, arg repository.ListTransactionsParams) ([]repository.ListTransactionsRow, error) {
+	f.offsets = append(f.offsets, arg.Offset)
+
+	start := min(int(arg.Offset), len(f.transactions))
+	end := min(start+int(arg.Limit), len(f.transactions))
+
+	return f.transactions[start:end], nil
+}
+
+func (f *fakeReader) ListTransactionSplits(ctx context.Context, transactionIds []uuid.UUID) ([]repository.TransactionSplit, error) {
+	return f.splits, nil
+}
+
+func (f *fakeReader) FindTransferCounterpart(ctx context.Context, arg repository.FindTransferCounterpartParams) (repository.Transaction, error) {
+	f.lookup = arg
+	if f.counterpart == nil {
+		return repository.Transaction{}, pgx.ErrNoRows
+	}
+
+	return *f.counterpart, nil
+}
+
+func TestResolve_Transfer(t *testing.T) {
+	userID := uuid.New()
+	counterpart := repository.Transaction{ID: uuid.New(), AccountID: uuid.New(), Type: "income"}
+
+	transactionData := &transactions.TransactionData{
+		ID:                  uuid.New(),
+		Type:                "expense",
+		Amount:              decimal.NewFromInt(-250),
+		AccountID:           uuid.New(),
+		TransactionCurrency: "EUR",
+		TransactionDatetime: time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC),
+	}
+
+	actions := []transactions.RuleAction{
+		{Type: transactions.ActionTypeLinkTransfer, Value: 2.0},
+		{Type: transactions.ActionTypeSplit, Value: []any{
+			map[string]any{"category_id": uuid.NewString(), "percentage": 50},
+			map[string]any{"category_id": uuid.NewString(), "percentage": 50},
+		}},
+	}
+
+	reader := &fakeReader{counterpart: &counterpart}
+	changes := rules.PlanChanges(transactionData, nil, actions)
+
+	if err := rules.Resolve(context.Background(), reader, userID, transactionData, &changes, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reader.lookup.Type != "income" || !reader.lookup.Amount.Equal(decimal.NewFromInt(250)) {
+		t.Errorf("Expected to look for an income of 250, got %s of %s", reader.lookup.Type, reader.lookup.Amount)
+	}
+
+	if !reader.lookup.StartDate.Equal(transactionData.TransactionDatetime.AddDate(0, 0, -2)) {
+		t.Errorf("Expected to look 2 days around the transaction, got %v", reader.lookup.StartDate)
+	}
+
+	if changes.Transfer == nil || changes.Transfer.Counterpart.ID != counterpart.ID {
+		t.Fatalf("Expected the transaction to be linked with %s", counterpart.ID)
+	}
+
+	if changes.Splits != nil || len(changes.Fields) != 1 || changes.Fields[0].Field != transactions.RuleFieldTransfer {
+		t.Errorf("Expected a transfer not to be split, got %v", changes.Fields)
+	}
+
+	// The other side is already changed by another rule
+	changes = rules.PlanChanges(transactionData, nil, actions)
+
+	if err := rules.Resolve(context.Background(), reader, userID, transactionData, &changes, map[uuid.UUID]bool{counterpart.ID: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if changes.Transfer != nil || len(changes.Splits) != 2 {
+		t.Errorf("Expected the transaction to be split instead of linked")
+	}
+}
+
+func TestResolve_SameSplits(t *testing.T) {
+	food := uuid.New()
+	household := uuid.New()
+
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense", Amount: decimal.NewFromInt(-80)}
+
+	reader := &fakeReader{splits: []repository.TransactionSplit{
+		{CategoryID: &household, Amount: types.DecimalToPgtypeNumeric(decimal.NewFromInt(20)), Percentage: types.DecimalToPgtypeNumeric(decimal.NewFromInt(25))},
+		{CategoryID: &food, Amount: types.DecimalToPgtypeNumeric(decimal.NewFromInt(60)), Percentage: types.DecimalToPgtypeNumeric(decimal.NewFromInt(75))},
+	}}
+
+	changes := rules.PlanChanges(transactionData, nil, []transactions.RuleAction{
+		{Type: transactions.ActionTypeSplit, Value: []any{
+			map[string]any{"category_id": food.String(), "percentage": 75},
+			map[string]any{"category_id": household.String(), "percentage": 25},
+		}},
+	})
+
+	if err := rules.Resolve(context.Background(), reader, uuid.New(), transactionData, &changes, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !changes.Empty() || changes.Splits != nil {
+		t.Errorf("Expected the splits the transaction has to be left out, got %v", changes.Fields)
+	}
+}
+
+// fakeWriter keeps the rule applications it records
+type fakeWriter struct {
+	applications []repository.CreateRuleApplicationParams
+}
+
+func (f *fakeWriter) UpdateTransaction(ctx context.Context, params repository.UpdateTransactionParams) (repository.Transaction, error) {
+	return repository.Transaction{ID: params.ID}, nil
+}
+
+func (f *fakeWriter) LinkTransfer(ctx context.Context, arg repository.LinkTransferParams) (repository.Transaction, error) {
+	return repository.Transaction{ID: arg.ID}, nil
+}
+
+func (f *fakeWriter) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeWriter) DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeWriter) CreateTransactionSplit(ctx context.Context, arg repository.CreateTransactionSplitParams) (repository.TransactionSplit, error) {
+	return repository.TransactionSplit{}, nil
+}
+
+func (f *fakeWriter) CreateRuleApplication(ctx context.Context, arg repository.CreateRuleApplicationParams) (repository.TransactionRuleApplication, error) {
+	f.applications = append(f.applications, arg)
+	return repository.TransactionRuleApplication{}, nil
+}
+
+func TestApply_RecordsRules(t *testing.T) {
+	category := uuid.New()
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense", Amount: decimal.NewFromInt(-12)}
+
+	matches := []transactions.RuleMatch{
+		{RuleID: uuid.New(), Applied: true, Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetCategory, Value: category.String()},
+			{Type: transactions.ActionTypeSetNote, Value: "Lunch"},
+		}},
+		// Loses the category to the first rule
+		{RuleID: uuid.New(), Applied: true, Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetCategory, Value: uuid.NewString()},
+		}},
+		{RuleID: uuid.New(), Applied: true, Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetTags, Value: "work"},
+		}},
+	}
+
+	writer := &fakeWriter{}
+	changes := rules.PlanMatches(transactionData, nil, matches)
+
+	if _, err := rules.Apply(context.Background(), writer, transactionData.ID, uuid.New(), matches, changes); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(writer.applications) != 2 {
+		t.Fatalf("Expected 2 rule applications, got %d", len(writer.applications))
+	}
+
+	first := writer.applications[0]
+	if first.RuleID != matches[0].RuleID || first.TransactionID != transactionData.ID {
+		t.Errorf("Expected the first rule to be recorded on %s, got rule %s on %s", transactionData.ID, first.RuleID, first.TransactionID)
+	}
+
+	var recorded []transactions.FieldChange
+	if err := json.Unmarshal(first.Changes, &recorded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(recorded) != 2 || recorded[0].Field != transactions.RuleFieldCategory || recorded[1].Field != transactions.RuleFieldNote {
+		t.Errorf("Expected the category and note changes, got %v", recorded)
+	}
+
+	if writer.applications[1].RuleID != matches[2].RuleID {
+		t.Errorf("Expected the rule setting tags to be recorded, got %s", writer.applications[1].RuleID)
+	}
+}
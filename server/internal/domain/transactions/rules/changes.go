@@ -0,0 +1,263 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Changes is what the actions of rules change on a transaction
+type Changes struct {
+	CategoryID  *uuid.UUID
+	Description *string
+	Details     *dto.Details // The whole details, set when a field of the details changes
+	Splits      []Split      // Replace the splits of the transaction when set
+	Transfer    *Transfer    // Turns the transaction into a transfer when set
+	Fields      []transactions.FieldChange
+
+	claimed map[string]uuid.UUID // Rule that first set each field, even to the value it had
+}
+
+// Split is a part of a transaction a split action creates
+type Split struct {
+	CategoryID  uuid.UUID       `json:"category_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	Percentage  decimal.Decimal `json:"percentage"`
+	Description *string         `json:"description,omitempty"`
+}
+
+// Transfer links an expense with the income on another account that is the other side of it.
+// Counterpart is looked up within Window days by Resolve
+type Transfer struct {
+	RuleID      uuid.UUID
+	Window      int
+	Counterpart *repository.Transaction
+}
+
+// Empty reports whether the actions leave the transaction as it is
+func (c Changes) Empty() bool {
+	return len(c.Fields) == 0
+}
+
+// Changed reports whether a rule changed a field of the transaction
+func (c Changes) Changed(ruleID uuid.UUID) bool {
+	return slices.ContainsFunc(c.Fields, func(change transactions.FieldChange) bool {
+		return change.RuleID == ruleID
+	})
+}
+
+// Applicable returns the matches whose actions run, lower priority rules are skipped after a
+// rule with a stop_processing action
+func Applicable(matches []transactions.RuleMatch) []transactions.RuleMatch {
+	for i, match := range matches {
+		if transactions.StopsProcessing(match.Actions) {
+			return matches[:i+1]
+		}
+	}
+
+	return matches
+}
+
+// PlanChanges computes the changes of the actions of a rule on a transaction. Values the
+// transaction already has are left out, running a rule twice changes nothing the second time
+func PlanChanges(transaction *transactions.TransactionData, details *dto.Details, actions []transactions.RuleAction) Changes {
+	return PlanMatches(transaction, details, []transactions.RuleMatch{{Actions: actions, Applied: true}})
+}
+
+// PlanMatches computes the changes of matching rules given in priority order. The first rule
+// that sets a field wins it, later rules can only change the other fields
+func PlanMatches(transaction *transactions.TransactionData, details *dto.Details, matches []transactions.RuleMatch) Changes {
+	changes := Changes{claimed: map[string]uuid.UUID{}}
+
+	next := dto.Details{}
+	if details != nil {
+		next = *details
+	}
+
+	for _, match := range Applicable(matches) {
+		changes.plan(transaction, &next, match.RuleID, match.Actions)
+	}
+
+	return changes
+}
+
+func (c *Changes) plan(transaction *transactions.TransactionData, next *dto.Details, ruleID uuid.UUID, actions []transactions.RuleAction) {
+	for _, action := range actions {
+		switch action.Type {
+		case transactions.ActionTypeSetCategory:
+			value, ok := action.Value.(string)
+			if !ok {
+				continue
+			}
+
+			categoryID, err := uuid.Parse(value)
+			if err != nil || !c.claim(transactions.RuleFieldCategory, ruleID) || (transaction.CategoryID != nil && *transaction.CategoryID == categoryID) {
+				continue
+			}
+
+			c.CategoryID = &categoryID
+			c.set(transactions.RuleFieldCategory, ruleID, transaction.CategoryID, categoryID)
+		case transactions.ActionTypeSetDescription:
+			description, ok := action.Value.(string)
+			if !ok || !c.claim(transactions.RuleFieldDescription, ruleID) || (transaction.Description != nil && *transaction.Description == description) {
+				continue
+			}
+
+			c.Description = &description
+			c.set(transactions.RuleFieldDescription, ruleID, transaction.Description, description)
+		case transactions.ActionTypeSetNote:
+			note, ok := action.Value.(string)
+			if !ok || !c.claim(transactions.RuleFieldNote, ruleID) || (next.Note != nil && *next.Note == note) {
+				continue
+			}
+
+			c.set(transactions.RuleFieldNote, ruleID, next.Note, note)
+			next.Note = &note
+			c.Details = next
+		case transactions.ActionTypeSetTags:
+			tags, ok := tagsValue(action.Value)
+			if !ok || !c.claim(transactions.RuleFieldTags, ruleID) || slices.Equal(next.Tags, tags) {
+				continue
+			}
+
+			c.set(transactions.RuleFieldTags, ruleID, next.Tags, tags)
+			next.Tags = tags
+			c.Details = next
+		case transactions.ActionTypeSetMerchant:
+			merchant, ok := action.Value.(string)
+			merchant = strings.TrimSpace(merchant)
+			if !ok || merchant == "" || !c.claim(transactions.RuleFieldMerchant, ruleID) || (next.Merchant != nil && *next.Merchant == merchant) {
+				continue
+			}
+
+			c.set(transactions.RuleFieldMerchant, ruleID, next.Merchant, merchant)
+			next.Merchant = &merchant
+			c.Details = next
+		case transactions.ActionTypeSetTaxDeductible:
+			value, ok := action.Value.(bool)
+			if !ok || !c.claim(transactions.RuleFieldTaxDeductible, ruleID) || (next.TaxDeductible != nil && *next.TaxDeductible == value) {
+				continue
+			}
+
+			c.set(transactions.RuleFieldTaxDeductible, ruleID, next.TaxDeductible, value)
+			next.TaxDeductible = &value
+			c.Details = next
+		case transactions.ActionTypeSetBusinessExpense:
+			value, ok := action.Value.(bool)
+			if !ok || !c.claim(transactions.RuleFieldBusinessExpense, ruleID) || (next.BusinessExpense != nil && *next.BusinessExpense == value) {
+				continue
+			}
+
+			c.set(transactions.RuleFieldBusinessExpense, ruleID, next.BusinessExpense, value)
+			next.BusinessExpense = &value
+			c.Details = next
+		case transactions.ActionTypeSplit:
+			parts, err := transactions.ParseRuleSplits(action.Value)
+			if err != nil || !c.claim(transactions.RuleFieldSplits, ruleID) {
+				continue
+			}
+
+			// Compared with the splits the transaction has by Resolve
+			c.Splits = splitAmount(transaction.Amount, parts)
+			c.set(transactions.RuleFieldSplits, ruleID, nil, c.Splits)
+		case transactions.ActionTypeLinkTransfer:
+			window, err := transactions.TransferWindowDays(action.Value)
+			if err != nil || (transaction.Type != "expense" && transaction.Type != "income") || !c.claim(transactions.RuleFieldTransfer, ruleID) {
+				continue
+			}
+
+			// Recorded as a field change once Resolve finds the other side
+			c.Transfer = &Transfer{RuleID: ruleID, Window: window}
+		}
+	}
+}
+
+// claim reserves a field for the rule that sets it first, and reports whether the rule can set it
+func (c *Changes) claim(field string, ruleID uuid.UUID) bool {
+	if c.claimed == nil {
+		c.claimed = map[string]uuid.UUID{}
+	}
+
+	owner, ok := c.claimed[field]
+	if !ok {
+		c.claimed[field] = ruleID
+		return true
+	}
+
+	return owner == ruleID
+}
+
+// set records the change of a field, a later action of the rule on the same field replaces the
+// earlier one but keeps the value from before the rule
+func (c *Changes) set(field string, ruleID uuid.UUID, before, after any) {
+	for i, change := range c.Fields {
+		if change.Field == field {
+			c.Fields[i].After = after
+			return
+		}
+	}
+
+	c.Fields = append(c.Fields, transactions.FieldChange{Field: field, Before: before, After: after, RuleID: ruleID})
+}
+
+// splitAmount divides the amount of a transaction between the parts of a split, rounded to the
+// cent with the remainder on the last part so they add up to the amount
+func splitAmount(amount decimal.Decimal, parts []transactions.RuleSplit) []Split {
+	total := amount.Abs()
+	left := total
+	splits := make([]Split, len(parts))
+
+	for i, part := range parts {
+		value := left
+		if i < len(parts)-1 {
+			value = total.Mul(part.Percentage).Div(decimal.NewFromInt(100)).Round(2)
+			left = left.Sub(value)
+		}
+
+		splits[i] = Split{
+			CategoryID:  part.CategoryID,
+			Amount:      value,
+			Percentage:  part.Percentage,
+			Description: part.Description,
+		}
+	}
+
+	return splits
+}
+
+// tagsValue reads the tags of a set_tags action, given as a list or a comma separated string
+func tagsValue(value any) ([]string, bool) {
+	var raw []string
+
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []string:
+		raw = v
+	case []any:
+		for _, item := range v {
+			tag, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			raw = append(raw, tag)
+		}
+	default:
+		return nil, false
+	}
+
+	tags := []string{}
+	for _, tag := range raw {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, true
+}
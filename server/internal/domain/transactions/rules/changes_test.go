@@ -0,0 +1,254 @@
+package rules_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestPlanChanges(t *testing.T) {
+	categoryID := uuid.New()
+	newCategoryID := uuid.New()
+	description := "AMZN Mktp"
+	note := "old note"
+	location := "Paris"
+
+	transactionData := &transactions.TransactionData{
+		ID:          uuid.New(),
+		CategoryID:  &categoryID,
+		Description: &description,
+	}
+	details := &dto.Details{Note: &note, Location: &location, Tags: []string{"online"}}
+
+	changes := rules.PlanChanges(transactionData, details, []transactions.RuleAction{
+		{Type: transactions.ActionTypeSetCategory, Value: newCategoryID.String()},
+		{Type: transactions.ActionTypeSetDescription, Value: "Amazon"},
+		{Type: transactions.ActionTypeSetNote, Value: "first note"},
+		{Type: transactions.ActionTypeSetNote, Value: "shopping"},
+		{Type: transactions.ActionTypeSetTags, Value: []any{"online", " shopping ", "online"}},
+	})
+
+	if changes.CategoryID == nil || *changes.CategoryID != newCategoryID {
+		t.Errorf("Expected category %v, got %v", newCategoryID, changes.CategoryID)
+	}
+
+	if changes.Description == nil || *changes.Description != "Amazon" {
+		t.Errorf("Expected description to be changed, got %v", changes.Description)
+	}
+
+	if changes.Details == nil {
+		t.Fatal("Expected details to be changed")
+	}
+
+	if *changes.Details.Note != "shopping" {
+		t.Errorf("Expected the last note to win, got %q", *changes.Details.Note)
+	}
+
+	if changes.Details.Location == nil || *changes.Details.Location != location {
+		t.Errorf("Expected the other details to be kept")
+	}
+
+	if !slices.Equal(changes.Details.Tags, []string{"online", "shopping"}) {
+		t.Errorf("Expected tags to be trimmed and deduplicated, got %v", changes.Details.Tags)
+	}
+
+	if len(changes.Fields) != 4 {
+		t.Fatalf("Expected 4 changed fields, got %d", len(changes.Fields))
+	}
+
+	for _, change := range changes.Fields {
+		if change.Field == transactions.RuleFieldNote && *change.Before.(*string) != "old note" {
+			t.Errorf("Expected the note change to keep the value from before the rule, got %v", change.Before)
+		}
+	}
+
+	if *details.Note != "old note" {
+		t.Errorf("Expected the transaction details to be left untouched")
+	}
+}
+
+func TestPlanChanges_Unchanged(t *testing.T) {
+	categoryID := uuid.New()
+	description := "Netflix"
+	note := "subscription"
+
+	transactionData := &transactions.TransactionData{
+		ID:          uuid.New(),
+		CategoryID:  &categoryID,
+		Description: &description,
+	}
+	details := &dto.Details{Note: &note, Tags: []string{"tv"}}
+
+	changes := rules.PlanChanges(transactionData, details, []transactions.RuleAction{
+		{Type: transactions.ActionTypeSetCategory, Value: categoryID.String()},
+		{Type: transactions.ActionTypeSetDescription, Value: "Netflix"},
+		{Type: transactions.ActionTypeSetNote, Value: "subscription"},
+		{Type: transactions.ActionTypeSetTags, Value: "tv"},
+		{Type: transactions.ActionTypeSetCategory, Value: "not-a-uuid"},
+	})
+
+	if !changes.Empty() {
+		t.Errorf("Expected no changes, got %v", changes.Fields)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	active := transactions.TransactionRule{ID: uuid.New(), Name: "Active", IsActive: true, Priority: 2}
+	inactive := transactions.TransactionRule{ID: uuid.New(), Name: "Inactive", IsActive: false, Priority: 1}
+	all := []transactions.TransactionRule{active, inactive}
+
+	selected, err := rules.Select(all, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(selected) != 1 || selected[0].ID != active.ID {
+		t.Errorf("Expected only the active rule, got %v", selected)
+	}
+
+	selected, err = rules.Select(all, []uuid.UUID{inactive.ID, active.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(selected) != 2 || selected[0].ID != active.ID {
+		t.Fatalf("Expected both rules in priority order, got %v", selected)
+	}
+
+	if !selected[1].IsActive {
+		t.Errorf("Expected a selected rule to run even while disabled")
+	}
+
+	_, err = rules.Select(all, []uuid.UUID{uuid.New()})
+	if !errors.Is(err, transactions.ErrRuleNotFound) {
+		t.Errorf("Expected ErrRuleNotFound, got %v", err)
+	}
+}
+
+func TestPlanMatches_FirstRuleWins(t *testing.T) {
+	first := uuid.New()
+	second := uuid.New()
+	categoryID := uuid.New()
+
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense"}
+
+	changes := rules.PlanMatches(transactionData, nil, []transactions.RuleMatch{
+		{RuleID: first, Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetMerchant, Value: " Uber "},
+			{Type: transactions.ActionTypeSetTaxDeductible, Value: true},
+		}},
+		{RuleID: second, Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetMerchant, Value: "Lyft"},
+			{Type: transactions.ActionTypeSetCategory, Value: categoryID.String()},
+			{Type: transactions.ActionTypeSetBusinessExpense, Value: true},
+		}},
+	})
+
+	if changes.Details == nil || *changes.Details.Merchant != "Uber" {
+		t.Fatalf("Expected the merchant of the first rule, got %v", changes.Details)
+	}
+
+	if changes.Details.TaxDeductible == nil || !*changes.Details.TaxDeductible {
+		t.Errorf("Expected the transaction to be tax deductible")
+	}
+
+	if changes.Details.BusinessExpense == nil || !*changes.Details.BusinessExpense {
+		t.Errorf("Expected the second rule to mark a business expense")
+	}
+
+	if changes.CategoryID == nil || *changes.CategoryID != categoryID {
+		t.Errorf("Expected the second rule to set the category, got %v", changes.CategoryID)
+	}
+
+	for _, change := range changes.Fields {
+		want := first
+		if change.Field == transactions.RuleFieldCategory || change.Field == transactions.RuleFieldBusinessExpense {
+			want = second
+		}
+
+		if change.RuleID != want {
+			t.Errorf("Expected %s to be changed by %s, got %s", change.Field, want, change.RuleID)
+		}
+	}
+}
+
+func TestPlanMatches_UnchangedFieldIsKept(t *testing.T) {
+	merchant := "Uber"
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense"}
+	details := &dto.Details{Merchant: &merchant}
+
+	// The first rule keeps the merchant the transaction has, the second can't change it
+	changes := rules.PlanMatches(transactionData, details, []transactions.RuleMatch{
+		{RuleID: uuid.New(), Actions: []transactions.RuleAction{{Type: transactions.ActionTypeSetMerchant, Value: "Uber"}}},
+		{RuleID: uuid.New(), Actions: []transactions.RuleAction{{Type: transactions.ActionTypeSetMerchant, Value: "Lyft"}}},
+	})
+
+	if !changes.Empty() {
+		t.Errorf("Expected no changes, got %v", changes.Fields)
+	}
+}
+
+func TestPlanMatches_StopProcessing(t *testing.T) {
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense"}
+
+	matches := []transactions.RuleMatch{
+		{RuleID: uuid.New(), Actions: []transactions.RuleAction{
+			{Type: transactions.ActionTypeSetNote, Value: "rent"},
+			{Type: transactions.ActionTypeStopProcessing},
+		}},
+		{RuleID: uuid.New(), Actions: []transactions.RuleAction{{Type: transactions.ActionTypeSetDescription, Value: "Landlord"}}},
+	}
+
+	if applicable := rules.Applicable(matches); len(applicable) != 1 {
+		t.Fatalf("Expected a single rule to run, got %d", len(applicable))
+	}
+
+	changes := rules.PlanMatches(transactionData, nil, matches)
+
+	if changes.Description != nil {
+		t.Errorf("Expected the rules after stop_processing to be skipped")
+	}
+
+	if len(changes.Fields) != 1 || changes.Fields[0].Field != transactions.RuleFieldNote {
+		t.Errorf("Expected only the note to change, got %v", changes.Fields)
+	}
+}
+
+func TestPlanChanges_Split(t *testing.T) {
+	food := uuid.New()
+	household := uuid.New()
+	other := uuid.New()
+
+	transactionData := &transactions.TransactionData{ID: uuid.New(), Type: "expense", Amount: decimal.RequireFromString("-100.01")}
+
+	changes := rules.PlanChanges(transactionData, nil, []transactions.RuleAction{
+		{Type: transactions.ActionTypeSplit, Value: []any{
+			map[string]any{"category_id": food.String(), "percentage": 33.33},
+			map[string]any{"category_id": household.String(), "percentage": 33.33},
+			map[string]any{"category_id": other.String(), "percentage": "33.34"},
+		}},
+	})
+
+	if len(changes.Splits) != 3 {
+		t.Fatalf("Expected 3 splits, got %d", len(changes.Splits))
+	}
+
+	want := []string{"33.33", "33.33", "33.35"}
+	total := decimal.Zero
+	for i, split := range changes.Splits {
+		if split.Amount.String() != want[i] {
+			t.Errorf("Expected split %d to be %s, got %s", i, want[i], split.Amount)
+		}
+		total = total.Add(split.Amount)
+	}
+
+	if !total.Equal(decimal.RequireFromString("100.01")) {
+		t.Errorf("Expected the splits to add up to the amount, got %s", total)
+	}
+}
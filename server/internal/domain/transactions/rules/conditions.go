@@ -0,0 +1,365 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// evaluateNumberCondition evaluates amounts and other numeric values
+func (re *RuleEvaluator) evaluateNumberCondition(condition transactions.RuleCondition, value decimal.Decimal) (bool, error) {
+	switch condition.Operator {
+	case transactions.OperatorIn:
+		values, err := list(condition.Value)
+		if err != nil {
+			return false, err
+		}
+
+		for _, v := range values {
+			n, err := decimalValue(v)
+			if err != nil {
+				return false, err
+			}
+			if value.Equal(n) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case transactions.OperatorBetween:
+		bounds, err := rangeValue(condition.Value)
+		if err != nil {
+			return false, err
+		}
+
+		low, err := decimalValue(bounds[0])
+		if err != nil {
+			return false, err
+		}
+		high, err := decimalValue(bounds[1])
+		if err != nil {
+			return false, err
+		}
+
+		return value.GreaterThanOrEqual(low) && value.LessThanOrEqual(high), nil
+	}
+
+	conditionValue, err := decimalValue(condition.Value)
+	if err != nil {
+		return false, err
+	}
+
+	switch condition.Operator {
+	case transactions.OperatorEquals:
+		return value.Equal(conditionValue), nil
+	case transactions.OperatorNotEquals:
+		return !value.Equal(conditionValue), nil
+	case transactions.OperatorGreaterThan:
+		return value.GreaterThan(conditionValue), nil
+	case transactions.OperatorGreaterEqual:
+		return value.GreaterThanOrEqual(conditionValue), nil
+	case transactions.OperatorLessThan:
+		return value.LessThan(conditionValue), nil
+	case transactions.OperatorLessEqual:
+		return value.LessThanOrEqual(conditionValue), nil
+	default:
+		return false, fmt.Errorf("unsupported operator for %s condition: %s", condition.Type, condition.Operator)
+	}
+}
+
+// evaluateDateCondition compares the day of the transaction, in the time zone of datetime, with
+// dates given as YYYY-MM-DD or RFC 3339
+func (re *RuleEvaluator) evaluateDateCondition(condition transactions.RuleCondition, datetime time.Time) (bool, error) {
+	day := datetime.Format(time.DateOnly)
+
+	if condition.Operator == transactions.OperatorBetween {
+		bounds, err := rangeValue(condition.Value)
+		if err != nil {
+			return false, err
+		}
+
+		start, err := dateValue(bounds[0], datetime.Location())
+		if err != nil {
+			return false, err
+		}
+		end, err := dateValue(bounds[1], datetime.Location())
+		if err != nil {
+			return false, err
+		}
+
+		return day >= start && day <= end, nil
+	}
+
+	// Days in the YYYY-MM-DD format compare as strings
+	conditionDay, err := dateValue(condition.Value, datetime.Location())
+	if err != nil {
+		return false, err
+	}
+
+	switch condition.Operator {
+	case transactions.OperatorEquals:
+		return day == conditionDay, nil
+	case transactions.OperatorNotEquals:
+		return day != conditionDay, nil
+	case transactions.OperatorGreaterThan:
+		return day > conditionDay, nil
+	case transactions.OperatorGreaterEqual:
+		return day >= conditionDay, nil
+	case transactions.OperatorLessThan:
+		return day < conditionDay, nil
+	case transactions.OperatorLessEqual:
+		return day <= conditionDay, nil
+	default:
+		return false, fmt.Errorf("unsupported operator for date condition: %s", condition.Operator)
+	}
+}
+
+// evaluateWeekdayCondition evaluates the day of the week of the transaction, in the time zone of datetime
+func (re *RuleEvaluator) evaluateWeekdayCondition(condition transactions.RuleCondition, datetime time.Time) (bool, error) {
+	weekday := datetime.Weekday()
+
+	switch condition.Operator {
+	case transactions.OperatorIn:
+		values, err := list(condition.Value)
+		if err != nil {
+			return false, err
+		}
+
+		for _, v := range values {
+			day, err := weekdayValue(v)
+			if err != nil {
+				return false, err
+			}
+			if weekday == day {
+				return true, nil
+			}
+		}
+		return false, nil
+	case transactions.OperatorEquals, transactions.OperatorNotEquals:
+		day, err := weekdayValue(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return (weekday == day) == (condition.Operator == transactions.OperatorEquals), nil
+	default:
+		return false, fmt.Errorf("unsupported operator for weekday condition: %s", condition.Operator)
+	}
+}
+
+// evaluateTagsCondition checks the tags of the transaction: contains looks for a tag, in for any
+// of a list, matches_regex for any tag matching the pattern
+func (re *RuleEvaluator) evaluateTagsCondition(condition transactions.RuleCondition, tags []string) (bool, error) {
+	hasTag := func(tag string) bool {
+		return slices.ContainsFunc(tags, func(t string) bool {
+			return strings.EqualFold(t, tag)
+		})
+	}
+
+	switch condition.Operator {
+	case transactions.OperatorContains, transactions.OperatorNotContains:
+		tag, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("condition value must be a string")
+		}
+		return hasTag(tag) == (condition.Operator == transactions.OperatorContains), nil
+	case transactions.OperatorIn:
+		values, err := stringList(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return slices.ContainsFunc(values, hasTag), nil
+	case transactions.OperatorMatchesRegex:
+		value, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("condition value must be a string")
+		}
+
+		pattern, err := re.regexp(value)
+		if err != nil {
+			return false, err
+		}
+		return slices.ContainsFunc(tags, pattern.MatchString), nil
+	default:
+		return false, fmt.Errorf("unsupported operator for tags condition: %s", condition.Operator)
+	}
+}
+
+// evaluateMerchantCategoryCodeCondition compares codes as strings, or as numbers for between as
+// merchant categories are ranges of codes
+func (re *RuleEvaluator) evaluateMerchantCategoryCodeCondition(condition transactions.RuleCondition, code *string) (bool, error) {
+	if condition.Operator != transactions.OperatorBetween {
+		return re.evaluateStringCondition(condition, code)
+	}
+
+	if code == nil {
+		return false, nil
+	}
+
+	value, err := decimal.NewFromString(strings.TrimSpace(*code))
+	if err != nil {
+		return false, nil
+	}
+
+	return re.evaluateNumberCondition(condition, value)
+}
+
+// evaluateIDOrNameIn matches an account or a category against a list of IDs and names
+func (re *RuleEvaluator) evaluateIDOrNameIn(condition transactions.RuleCondition, id *uuid.UUID, name string) (bool, error) {
+	values, err := stringList(condition.Value)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range values {
+		if conditionID, err := uuid.Parse(v); err == nil {
+			if id != nil && *id == conditionID {
+				return true, nil
+			}
+		} else if strings.EqualFold(v, name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// regexp compiles a matches_regex pattern once, patterns are case insensitive. The cache is
+// bounded by maxCachedRegexps as evaluators live as long as the server
+func (re *RuleEvaluator) regexp(pattern string) (*regexp.Regexp, error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	if compiled, ok := re.regexps[pattern]; ok {
+		return compiled, nil
+	}
+
+	compiled, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	if len(re.regexps) >= maxCachedRegexps {
+		clear(re.regexps)
+	}
+	re.regexps[pattern] = compiled
+
+	return compiled, nil
+}
+
+// list reads the value of an in or between condition
+func list(value any) ([]any, error) {
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case []string:
+		values := make([]any, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("condition value must be a list")
+	}
+}
+
+func stringList(value any) ([]string, error) {
+	values, err := list(value)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("condition value must be a list of strings")
+		}
+		strs = append(strs, s)
+	}
+
+	return strs, nil
+}
+
+// rangeValue reads the [min, max] value of a between condition
+func rangeValue(value any) ([]any, error) {
+	values, err := list(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 2 {
+		return nil, fmt.Errorf("between needs a [min, max] value")
+	}
+
+	return values, nil
+}
+
+func decimalValue(value any) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case string:
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid amount value: %v", err)
+		}
+		return d, nil
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	case int:
+		return decimal.NewFromInt(int64(v)), nil
+	case int64:
+		return decimal.NewFromInt(v), nil
+	default:
+		return decimal.Zero, fmt.Errorf("unsupported amount value type: %T", v)
+	}
+}
+
+// dateValue reads a date as YYYY-MM-DD, RFC 3339 timestamps are converted to their day in location
+func dateValue(value any, location *time.Location) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("date value must be a string")
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.Format(time.DateOnly), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid date value %q, use YYYY-MM-DD", s)
+	}
+
+	return t.In(location).Format(time.DateOnly), nil
+}
+
+// weekdayValue reads a day of the week by name, or as a number from 0 for Sunday to 6
+func weekdayValue(value any) (time.Weekday, error) {
+	switch v := value.(type) {
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return weekdayValue(n)
+		}
+
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(v, day.String()) || strings.EqualFold(v, day.String()[:3]) {
+				return day, nil
+			}
+		}
+		return 0, fmt.Errorf("invalid weekday %q", v)
+	case float64:
+		return weekdayValue(int(v))
+	case int:
+		if v < 0 || v > 6 {
+			return 0, fmt.Errorf("invalid weekday %d, use 0 for Sunday to 6", v)
+		}
+		return time.Weekday(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported weekday value type: %T", v)
+	}
+}
@@ -0,0 +1,359 @@
+package rules_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// conditionTest is a condition with whether it should match the test transaction
+type conditionTest struct {
+	name      string
+	condition transactions.RuleCondition
+	want      bool
+}
+
+func testTransaction() *transactions.TransactionData {
+	description := "CARD PAYMENT Uber *Trip 4412"
+	merchant := "Uber"
+	paymentMedium := "credit_card"
+	mcc := "4121"
+	categoryID := uuid.MustParse("8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10")
+
+	return &transactions.TransactionData{
+		ID:                   uuid.New(),
+		Amount:               decimal.NewFromFloat(23.50),
+		Type:                 "expense",
+		AccountID:            uuid.New(),
+		AccountName:          "Checking",
+		CategoryID:           &categoryID,
+		CategoryName:         "Transport",
+		Description:          &description,
+		TransactionDatetime:  time.Date(2025, time.March, 14, 22, 30, 0, 0, time.UTC), // A Friday
+		TransactionCurrency:  "EUR",
+		Tags:                 []string{"Travel", "work"},
+		Merchant:             &merchant,
+		PaymentMedium:        &paymentMedium,
+		MerchantCategoryCode: &mcc,
+	}
+}
+
+func runConditionTests(t *testing.T, tests []conditionTest) {
+	t.Helper()
+
+	evaluator := rules.NewRuleEvaluator()
+	transactionData := testTransaction()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &transactions.TransactionRule{
+				ID:         uuid.New(),
+				Name:       tt.name,
+				IsActive:   true,
+				Conditions: transactions.MatchAll(tt.condition),
+			}
+
+			match, err := evaluator.EvaluateRule(rule, transactionData)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if match.Error != "" {
+				t.Fatalf("Expected the condition to be valid, got %s", match.Error)
+			}
+
+			if match.Applied != tt.want {
+				t.Errorf("Expected match to be %v, got %v", tt.want, match.Applied)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluator_MatchesRegexOperator(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "description matches",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDescription, Operator: transactions.OperatorMatchesRegex, Value: `uber \*trip \d+`},
+			want:      true,
+		},
+		{
+			name:      "description anchored",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDescription, Operator: transactions.OperatorMatchesRegex, Value: `^uber`},
+			want:      false,
+		},
+		{
+			name:      "merchant matches",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeMerchant, Operator: transactions.OperatorMatchesRegex, Value: `^(uber|lyft)$`},
+			want:      true,
+		},
+	})
+}
+
+func TestRuleEvaluator_InOperator(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "amount in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeAmount, Operator: transactions.OperatorIn, Value: []any{10.0, "23.50"}},
+			want:      true,
+		},
+		{
+			name:      "type in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeType, Operator: transactions.OperatorIn, Value: []any{"income", "transfer"}},
+			want:      false,
+		},
+		{
+			name:      "category name in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeCategory, Operator: transactions.OperatorIn, Value: []any{"food", "transport"}},
+			want:      true,
+		},
+		{
+			name:      "category ID in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeCategory, Operator: transactions.OperatorIn, Value: []any{"8f8c1f5e-4f2e-4c55-9d1b-3c1f0b6a2d10"}},
+			want:      true,
+		},
+	})
+}
+
+func TestRuleEvaluator_BetweenOperator(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "amount between",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeAmount, Operator: transactions.OperatorBetween, Value: []any{20.0, 30.0}},
+			want:      true,
+		},
+		{
+			name:      "amount on a bound",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeAmount, Operator: transactions.OperatorBetween, Value: []any{"23.50", "40"}},
+			want:      true,
+		},
+		{
+			name:      "amount outside",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeAmount, Operator: transactions.OperatorBetween, Value: []any{30.0, 40.0}},
+			want:      false,
+		},
+	})
+}
+
+func TestRuleEvaluator_DateConditions(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "date in range",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDate, Operator: transactions.OperatorBetween, Value: []any{"2025-03-01", "2025-03-14"}},
+			want:      true,
+		},
+		{
+			name:      "date after",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDate, Operator: transactions.OperatorGreaterThan, Value: "2025-03-14"},
+			want:      false,
+		},
+		{
+			name:      "date from a timestamp",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDate, Operator: transactions.OperatorEquals, Value: "2025-03-14T08:00:00Z"},
+			want:      true,
+		},
+		{
+			name:      "weekday by name",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeWeekday, Operator: transactions.OperatorEquals, Value: "friday"},
+			want:      true,
+		},
+		{
+			name:      "weekend",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeWeekday, Operator: transactions.OperatorIn, Value: []any{"sat", 0.0}},
+			want:      false,
+		},
+		{
+			name:      "day of month",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDayOfMonth, Operator: transactions.OperatorBetween, Value: []any{10.0, 15.0}},
+			want:      true,
+		},
+		{
+			name:      "first days of the month",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDayOfMonth, Operator: transactions.OperatorLessEqual, Value: 5.0},
+			want:      false,
+		},
+	})
+}
+
+func TestRuleEvaluator_DateConditionsInUserTimezone(t *testing.T) {
+	evaluator := rules.NewRuleEvaluator()
+
+	// Friday 22:30 in UTC is already Saturday morning in Tokyo
+	transactionData := testTransaction()
+	transactionData.Location = time.FixedZone("JST", 9*60*60)
+
+	tests := []conditionTest{
+		{
+			name:      "date of the user",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDate, Operator: transactions.OperatorEquals, Value: "2025-03-15"},
+			want:      true,
+		},
+		{
+			name:      "weekday of the user",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeWeekday, Operator: transactions.OperatorEquals, Value: "saturday"},
+			want:      true,
+		},
+		{
+			name:      "day of month of the user",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeDayOfMonth, Operator: transactions.OperatorEquals, Value: 15.0},
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &transactions.TransactionRule{
+				ID:         uuid.New(),
+				Name:       tt.name,
+				IsActive:   true,
+				Conditions: transactions.MatchAll(tt.condition),
+			}
+
+			match, err := evaluator.EvaluateRule(rule, transactionData)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if match.Applied != tt.want {
+				t.Errorf("Expected match to be %v, got %v", tt.want, match.Applied)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluator_MerchantCondition(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "merchant equals",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeMerchant, Operator: transactions.OperatorEquals, Value: "uber"},
+			want:      true,
+		},
+		{
+			name:      "merchant in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeMerchant, Operator: transactions.OperatorIn, Value: []any{"Lyft", "Bolt"}},
+			want:      false,
+		},
+	})
+}
+
+func TestRuleEvaluator_TagsCondition(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "has tag",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeTags, Operator: transactions.OperatorContains, Value: "travel"},
+			want:      true,
+		},
+		{
+			name:      "doesn't have tag",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeTags, Operator: transactions.OperatorNotContains, Value: "work"},
+			want:      false,
+		},
+		{
+			name:      "has any tag",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeTags, Operator: transactions.OperatorIn, Value: []any{"personal", "WORK"}},
+			want:      true,
+		},
+		{
+			name:      "tag matches",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeTags, Operator: transactions.OperatorMatchesRegex, Value: "^trav"},
+			want:      true,
+		},
+	})
+}
+
+func TestRuleEvaluator_CurrencyCondition(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "currency equals",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeCurrency, Operator: transactions.OperatorEquals, Value: "EUR"},
+			want:      true,
+		},
+		{
+			name:      "currency in list",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeCurrency, Operator: transactions.OperatorIn, Value: []any{"USD", "GBP"}},
+			want:      false,
+		},
+	})
+}
+
+func TestRuleEvaluator_DetailsConditions(t *testing.T) {
+	runConditionTests(t, []conditionTest{
+		{
+			name:      "payment medium",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypePaymentMedium, Operator: transactions.OperatorEquals, Value: "credit_card"},
+			want:      true,
+		},
+		{
+			name:      "merchant category code",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeMerchantCategoryCode, Operator: transactions.OperatorIn, Value: []any{"4121", "4111"}},
+			want:      true,
+		},
+		{
+			name:      "merchant category code range",
+			condition: transactions.RuleCondition{Type: transactions.ConditionTypeMerchantCategoryCode, Operator: transactions.OperatorBetween, Value: []any{"5811", "5814"}},
+			want:      false,
+		},
+	})
+}
+
+func TestRuleEvaluator_MissingDetails(t *testing.T) {
+	evaluator := rules.NewRuleEvaluator()
+
+	transactionData := &transactions.TransactionData{
+		ID:     uuid.New(),
+		Amount: decimal.NewFromFloat(10),
+		Type:   "expense",
+	}
+
+	rule := &transactions.TransactionRule{
+		ID:       uuid.New(),
+		Name:     "Merchant Rule",
+		IsActive: true,
+		Conditions: transactions.MatchAll(
+			transactions.RuleCondition{Type: transactions.ConditionTypeMerchantCategoryCode, Operator: transactions.OperatorBetween, Value: []any{"5811", "5814"}},
+		),
+	}
+
+	match, err := evaluator.EvaluateRule(rule, transactionData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if match.Applied || match.Error != "" {
+		t.Errorf("Expected no match and no error without details, got %v %q", match.Applied, match.Error)
+	}
+}
+
+func TestRuleEvaluator_InvalidConditionValues(t *testing.T) {
+	evaluator := rules.NewRuleEvaluator()
+	transactionData := testTransaction()
+
+	conditions := []transactions.RuleCondition{
+		{Type: transactions.ConditionTypeDescription, Operator: transactions.OperatorMatchesRegex, Value: "(unclosed"},
+		{Type: transactions.ConditionTypeAmount, Operator: transactions.OperatorBetween, Value: []any{10.0}},
+		{Type: transactions.ConditionTypeDate, Operator: transactions.OperatorEquals, Value: "14/03/2025"},
+		{Type: transactions.ConditionTypeWeekday, Operator: transactions.OperatorEquals, Value: 7.0},
+		{Type: transactions.ConditionTypeTags, Operator: transactions.OperatorStartsWith, Value: "tr"},
+	}
+
+	for _, condition := range conditions {
+		rule := &transactions.TransactionRule{
+			ID:         uuid.New(),
+			Name:       "Invalid Rule",
+			IsActive:   true,
+			Conditions: transactions.MatchAll(condition),
+		}
+
+		match, err := evaluator.EvaluateRule(rule, transactionData)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if match.Applied || match.Error == "" {
+			t.Errorf("Expected %s %s %v to report an error", condition.Type, condition.Operator, condition.Value)
+		}
+	}
+}
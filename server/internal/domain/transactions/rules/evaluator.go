@@ -1,20 +1,58 @@
 package rules
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 )
 
+// Compiled matches_regex patterns kept by an evaluator, the cache starts over once it is full
+const maxCachedRegexps = 256
+
 // RuleEvaluator handles the evaluation of transaction rules
-type RuleEvaluator struct{}
+type RuleEvaluator struct {
+	mu      sync.Mutex
+	regexps map[string]*regexp.Regexp // Compiled matches_regex patterns, rules run over many transactions
+}
 
 // NewRuleEvaluator creates a new rule evaluator
 func NewRuleEvaluator() *RuleEvaluator {
-	return &RuleEvaluator{}
+	return &RuleEvaluator{regexps: make(map[string]*regexp.Regexp)}
+}
+
+// PreferencesReader reads the preferences of a user
+type PreferencesReader interface {
+	GetPreferencesByUserId(ctx context.Context, userID uuid.UUID) (repository.GetPreferencesByUserIdRow, error)
+}
+
+// UserLocation returns the time zone of the preferences of the user, the one date conditions are
+// evaluated in. Users without preferences, or with a zone that isn't known, get UTC
+func UserLocation(ctx context.Context, prefs PreferencesReader, userID uuid.UUID) (*time.Location, error) {
+	preferences, err := prefs.GetPreferencesByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.UTC, nil
+		}
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	location, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+
+	return location, nil
 }
 
 // EvaluateRule evaluates a single rule against transaction data
@@ -30,26 +68,18 @@ func (re *RuleEvaluator) EvaluateRule(rule *transactions.TransactionRule, transa
 		}, nil
 	}
 
-	// Evaluate all conditions
-	conditionResults := make([]bool, len(rule.Conditions))
-	for i, condition := range rule.Conditions {
-		result, err := re.evaluateCondition(condition, transaction)
-		if err != nil {
-			return &transactions.RuleMatch{
-				RuleID:       rule.ID,
-				RuleName:     rule.Name,
-				RulePriority: rule.Priority,
-				Actions:      rule.Actions,
-				Applied:      false,
-				Error:        fmt.Sprintf("Error evaluating condition %d: %v", i, err),
-			}, nil
-		}
-		conditionResults[i] = result
+	finalResult, err := re.evaluateConditions(rule.Conditions, transaction)
+	if err != nil {
+		return &transactions.RuleMatch{
+			RuleID:       rule.ID,
+			RuleName:     rule.Name,
+			RulePriority: rule.Priority,
+			Actions:      rule.Actions,
+			Applied:      false,
+			Error:        fmt.Sprintf("Error evaluating conditions: %v", err),
+		}, nil
 	}
 
-	// Combine conditions with logic gates
-	finalResult := re.combineConditionResults(conditionResults, rule.Conditions)
-
 	return &transactions.RuleMatch{
 		RuleID:       rule.ID,
 		RuleName:     rule.Name,
@@ -74,6 +104,22 @@ func (re *RuleEvaluator) evaluateCondition(condition transactions.RuleCondition,
 		return re.evaluateTypeCondition(condition, transaction.Type)
 	case transactions.ConditionTypeCategory:
 		return re.evaluateCategoryCondition(condition, transaction.CategoryID, transaction.CategoryName)
+	case transactions.ConditionTypeDate:
+		return re.evaluateDateCondition(condition, transaction.LocalDatetime())
+	case transactions.ConditionTypeWeekday:
+		return re.evaluateWeekdayCondition(condition, transaction.LocalDatetime())
+	case transactions.ConditionTypeDayOfMonth:
+		return re.evaluateNumberCondition(condition, decimal.NewFromInt(int64(transaction.LocalDatetime().Day())))
+	case transactions.ConditionTypeMerchant:
+		return re.evaluateStringCondition(condition, transaction.Merchant)
+	case transactions.ConditionTypeTags:
+		return re.evaluateTagsCondition(condition, transaction.Tags)
+	case transactions.ConditionTypeCurrency:
+		return re.evaluateStringCondition(condition, &transaction.TransactionCurrency)
+	case transactions.ConditionTypePaymentMedium:
+		return re.evaluateStringCondition(condition, transaction.PaymentMedium)
+	case transactions.ConditionTypeMerchantCategoryCode:
+		return re.evaluateMerchantCategoryCodeCondition(condition, transaction.MerchantCategoryCode)
 	default:
 		return false, fmt.Errorf("unsupported condition type: %s", condition.Type)
 	}
@@ -85,11 +131,31 @@ func (re *RuleEvaluator) evaluateStringCondition(condition transactions.RuleCond
 		return false, nil
 	}
 
+	if condition.Operator == transactions.OperatorIn {
+		values, err := stringList(condition.Value)
+		if err != nil {
+			return false, err
+		}
+
+		return slices.ContainsFunc(values, func(v string) bool {
+			return strings.EqualFold(v, *value)
+		}), nil
+	}
+
 	conditionValue, ok := condition.Value.(string)
 	if !ok {
 		return false, fmt.Errorf("condition value must be a string")
 	}
 
+	if condition.Operator == transactions.OperatorMatchesRegex {
+		pattern, err := re.regexp(conditionValue)
+		if err != nil {
+			return false, err
+		}
+
+		return pattern.MatchString(*value), nil
+	}
+
 	valueStr := strings.ToLower(*value)
 	conditionStr := strings.ToLower(conditionValue)
 
@@ -113,45 +179,15 @@ func (re *RuleEvaluator) evaluateStringCondition(condition transactions.RuleCond
 
 // evaluateAmountCondition evaluates amount-based conditions
 func (re *RuleEvaluator) evaluateAmountCondition(condition transactions.RuleCondition, amount decimal.Decimal) (bool, error) {
-	var conditionAmount decimal.Decimal
-	var err error
-
-	switch v := condition.Value.(type) {
-	case string:
-		conditionAmount, err = decimal.NewFromString(v)
-		if err != nil {
-			return false, fmt.Errorf("invalid amount value: %v", err)
-		}
-	case float64:
-		conditionAmount = decimal.NewFromFloat(v)
-	case int:
-		conditionAmount = decimal.NewFromInt(int64(v))
-	case int64:
-		conditionAmount = decimal.NewFromInt(v)
-	default:
-		return false, fmt.Errorf("unsupported amount value type: %T", v)
-	}
-
-	switch condition.Operator {
-	case transactions.OperatorEquals:
-		return amount.Equal(conditionAmount), nil
-	case transactions.OperatorNotEquals:
-		return !amount.Equal(conditionAmount), nil
-	case transactions.OperatorGreaterThan:
-		return amount.GreaterThan(conditionAmount), nil
-	case transactions.OperatorGreaterEqual:
-		return amount.GreaterThanOrEqual(conditionAmount), nil
-	case transactions.OperatorLessThan:
-		return amount.LessThan(conditionAmount), nil
-	case transactions.OperatorLessEqual:
-		return amount.LessThanOrEqual(conditionAmount), nil
-	default:
-		return false, fmt.Errorf("unsupported operator for amount condition: %s", condition.Operator)
-	}
+	return re.evaluateNumberCondition(condition, amount)
 }
 
 // evaluateAccountCondition evaluates account-based conditions
 func (re *RuleEvaluator) evaluateAccountCondition(condition transactions.RuleCondition, accountID uuid.UUID, accountName string) (bool, error) {
+	if condition.Operator == transactions.OperatorIn {
+		return re.evaluateIDOrNameIn(condition, &accountID, accountName)
+	}
+
 	switch v := condition.Value.(type) {
 	case string:
 		if id, err := uuid.Parse(v); err == nil {
@@ -173,11 +209,6 @@ func (re *RuleEvaluator) evaluateAccountCondition(condition transactions.RuleCon
 
 // evaluateDirectionCondition evaluates transaction direction conditions
 func (re *RuleEvaluator) evaluateDirectionCondition(condition transactions.RuleCondition, transactionType string, isExternal bool) (bool, error) {
-	conditionValue, ok := condition.Value.(string)
-	if !ok {
-		return false, fmt.Errorf("condition value must be a string")
-	}
-
 	var direction string
 	switch transactionType {
 	case "income":
@@ -194,6 +225,19 @@ func (re *RuleEvaluator) evaluateDirectionCondition(condition transactions.RuleC
 		direction = "unknown"
 	}
 
+	if condition.Operator == transactions.OperatorIn {
+		values, err := stringList(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(values, direction), nil
+	}
+
+	conditionValue, ok := condition.Value.(string)
+	if !ok {
+		return false, fmt.Errorf("condition value must be a string")
+	}
+
 	switch condition.Operator {
 	case transactions.OperatorEquals:
 		return direction == conditionValue, nil
@@ -206,6 +250,14 @@ func (re *RuleEvaluator) evaluateDirectionCondition(condition transactions.RuleC
 
 // evaluateTypeCondition evaluates transaction type conditions
 func (re *RuleEvaluator) evaluateTypeCondition(condition transactions.RuleCondition, transactionType string) (bool, error) {
+	if condition.Operator == transactions.OperatorIn {
+		values, err := stringList(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(values, transactionType), nil
+	}
+
 	conditionValue, ok := condition.Value.(string)
 	if !ok {
 		return false, fmt.Errorf("condition value must be a string")
@@ -223,6 +275,10 @@ func (re *RuleEvaluator) evaluateTypeCondition(condition transactions.RuleCondit
 
 // evaluateCategoryCondition evaluates category-based conditions
 func (re *RuleEvaluator) evaluateCategoryCondition(condition transactions.RuleCondition, categoryID *uuid.UUID, categoryName string) (bool, error) {
+	if condition.Operator == transactions.OperatorIn {
+		return re.evaluateIDOrNameIn(condition, categoryID, categoryName)
+	}
+
 	switch v := condition.Value.(type) {
 	case string:
 		// Check if it's a UUID string
@@ -245,38 +301,51 @@ func (re *RuleEvaluator) evaluateCategoryCondition(condition transactions.RuleCo
 	}
 }
 
-// combineConditionResults combines multiple condition results using logic gates
-func (re *RuleEvaluator) combineConditionResults(results []bool, conditions []transactions.RuleCondition) bool {
-	if len(results) == 0 {
-		return false
+// evaluateConditions walks the condition tree of a rule, groups stop at the first condition
+// that decides them. An empty tree matches nothing
+func (re *RuleEvaluator) evaluateConditions(node transactions.ConditionNode, transaction *transactions.TransactionData) (bool, error) {
+	if node.Condition != nil {
+		result, err := re.evaluateCondition(*node.Condition, transaction)
+		if err != nil {
+			return false, fmt.Errorf("%s condition: %w", node.Condition.Type, err)
+		}
+		return result, nil
 	}
 
-	if len(results) == 1 {
-		return results[0]
+	if len(node.Conditions) == 0 {
+		return false, nil
 	}
 
-	// Start with the first condition result
-	finalResult := results[0]
-
-	// Apply logic gates between conditions
-	for i := 1; i < len(results); i++ {
-		logicGate := strings.ToUpper(conditions[i-1].LogicGate)
-		if logicGate == "" {
-			logicGate = "AND" // Default to AND
+	switch strings.ToUpper(node.LogicGate) {
+	case transactions.LogicGateAnd:
+		for _, child := range node.Conditions {
+			result, err := re.evaluateConditions(child, transaction)
+			if err != nil || !result {
+				return false, err
+			}
+		}
+		return true, nil
+	case transactions.LogicGateOr:
+		for _, child := range node.Conditions {
+			result, err := re.evaluateConditions(child, transaction)
+			if err != nil || result {
+				return result, err
+			}
+		}
+		return false, nil
+	case transactions.LogicGateNot:
+		if len(node.Conditions) != 1 {
+			return false, fmt.Errorf("NOT group needs exactly one condition")
 		}
 
-		switch logicGate {
-		case "AND":
-			finalResult = finalResult && results[i]
-		case "OR":
-			finalResult = finalResult || results[i]
-		default:
-			// Invalid logic gate, default to AND
-			finalResult = finalResult && results[i]
+		result, err := re.evaluateConditions(node.Conditions[0], transaction)
+		if err != nil {
+			return false, err
 		}
+		return !result, nil
+	default:
+		return false, fmt.Errorf("unsupported logic gate: %s", node.LogicGate)
 	}
-
-	return finalResult
 }
 
 // EvaluateRules evaluates multiple rules against transaction data, returns matches in priority order
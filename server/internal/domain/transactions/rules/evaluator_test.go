@@ -28,13 +28,13 @@ func TestRuleEvaluator_EvaluateRule(t *testing.T) {
 		Name:     "Amazon Rule",
 		IsActive: true,
 		Priority: 1,
-		Conditions: []transactions.RuleCondition{
-			{
+		Conditions: transactions.MatchAll(
+			transactions.RuleCondition{
 				Type:     transactions.ConditionTypeDescription,
 				Operator: transactions.OperatorContains,
 				Value:    "amazon",
 			},
-		},
+		),
 		Actions: []transactions.RuleAction{
 			{
 				Type:  transactions.ActionTypeSetCategory,
@@ -73,13 +73,13 @@ func TestRuleEvaluator_EvaluateAmountCondition(t *testing.T) {
 		Name:     "Large Amount Rule",
 		IsActive: true,
 		Priority: 1,
-		Conditions: []transactions.RuleCondition{
-			{
+		Conditions: transactions.MatchAll(
+			transactions.RuleCondition{
 				Type:     transactions.ConditionTypeAmount,
 				Operator: transactions.OperatorGreaterThan,
 				Value:    50.0,
 			},
-		},
+		),
 		Actions: []transactions.RuleAction{
 			{
 				Type:  transactions.ActionTypeSetCategory,
@@ -116,19 +116,18 @@ func TestRuleEvaluator_EvaluateMultipleConditions(t *testing.T) {
 		Name:     "Grocery Rule",
 		IsActive: true,
 		Priority: 1,
-		Conditions: []transactions.RuleCondition{
-			{
-				Type:      transactions.ConditionTypeDescription,
-				Operator:  transactions.OperatorContains,
-				Value:     "grocery",
-				LogicGate: "AND",
+		Conditions: transactions.MatchAll(
+			transactions.RuleCondition{
+				Type:     transactions.ConditionTypeDescription,
+				Operator: transactions.OperatorContains,
+				Value:    "grocery",
 			},
-			{
+			transactions.RuleCondition{
 				Type:     transactions.ConditionTypeAmount,
 				Operator: transactions.OperatorGreaterThan,
 				Value:    30.0,
 			},
-		},
+		),
 		Actions: []transactions.RuleAction{
 			{
 				Type:  transactions.ActionTypeSetCategory,
@@ -165,13 +164,13 @@ func TestRuleEvaluator_InactiveRule(t *testing.T) {
 		Name:     "Inactive Rule",
 		IsActive: false, // Inactive rule
 		Priority: 1,
-		Conditions: []transactions.RuleCondition{
-			{
+		Conditions: transactions.MatchAll(
+			transactions.RuleCondition{
 				Type:     transactions.ConditionTypeDescription,
 				Operator: transactions.OperatorContains,
 				Value:    "test",
 			},
-		},
+		),
 		Actions: []transactions.RuleAction{
 			{
 				Type:  transactions.ActionTypeSetCategory,
@@ -193,3 +192,87 @@ func TestRuleEvaluator_InactiveRule(t *testing.T) {
 		t.Errorf("Expected error message 'Rule is not active', got %v", match.Error)
 	}
 }
+
+func TestRuleEvaluator_ConditionTree(t *testing.T) {
+	evaluator := rules.NewRuleEvaluator()
+
+	leaf := func(conditionType transactions.ConditionType, operator transactions.ConditionOperator, value any) transactions.ConditionNode {
+		return transactions.ConditionNode{Condition: &transactions.RuleCondition{Type: conditionType, Operator: operator, Value: value}}
+	}
+
+	// (merchant is Uber OR merchant is Lyft) AND NOT tagged personal
+	rule := &transactions.TransactionRule{
+		ID:       uuid.New(),
+		Name:     "Work Rides",
+		IsActive: true,
+		Priority: 1,
+		Conditions: transactions.ConditionNode{
+			LogicGate: transactions.LogicGateAnd,
+			Conditions: []transactions.ConditionNode{
+				{
+					LogicGate: transactions.LogicGateOr,
+					Conditions: []transactions.ConditionNode{
+						leaf(transactions.ConditionTypeMerchant, transactions.OperatorEquals, "uber"),
+						leaf(transactions.ConditionTypeMerchant, transactions.OperatorEquals, "lyft"),
+					},
+				},
+				{
+					LogicGate: transactions.LogicGateNot,
+					Conditions: []transactions.ConditionNode{
+						leaf(transactions.ConditionTypeTags, transactions.OperatorContains, "personal"),
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		merchant string
+		tags     []string
+		want     bool
+	}{
+		{"Uber", nil, true},
+		{"Lyft", []string{"work"}, true},
+		{"Lyft", []string{"personal"}, false},
+		{"Bolt", nil, false},
+	}
+
+	for _, tt := range tests {
+		merchant := tt.merchant
+		transactionData := &transactions.TransactionData{
+			ID:       uuid.New(),
+			Amount:   decimal.NewFromFloat(18),
+			Type:     "expense",
+			Merchant: &merchant,
+			Tags:     tt.tags,
+		}
+
+		match, err := evaluator.EvaluateRule(rule, transactionData)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if match.Applied != tt.want {
+			t.Errorf("Expected %s tagged %v to match %v, got %v (%s)", tt.merchant, tt.tags, tt.want, match.Applied, match.Error)
+		}
+	}
+}
+
+func TestRuleEvaluator_EmptyConditions(t *testing.T) {
+	evaluator := rules.NewRuleEvaluator()
+
+	rule := &transactions.TransactionRule{
+		ID:       uuid.New(),
+		Name:     "Empty Rule",
+		IsActive: true,
+	}
+
+	match, err := evaluator.EvaluateRule(rule, &transactions.TransactionData{ID: uuid.New()})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if match.Applied {
+		t.Errorf("Expected a rule without conditions not to match")
+	}
+}
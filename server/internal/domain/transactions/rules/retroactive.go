@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+)
+
+// Transactions read per query while running rules over existing transactions
+const planPageSize = 500
+
+// Planned is a transaction with the changes of the rules it matches
+type Planned struct {
+	Transaction repository.ListTransactionsRow
+	Matches     []transactions.RuleMatch // Rules that change the transaction, in priority order
+	Changes     Changes
+}
+
+// Select returns the rules with the given IDs in priority order, marked active so a rule can be
+// tried before it is enabled. The active rules are returned when ids is empty
+func Select(all []transactions.TransactionRule, ids []uuid.UUID) ([]transactions.TransactionRule, error) {
+	selected := []transactions.TransactionRule{}
+
+	if len(ids) == 0 {
+		for _, rule := range all {
+			if rule.IsActive {
+				selected = append(selected, rule)
+			}
+		}
+		return selected, nil
+	}
+
+	for _, id := range ids {
+		found := false
+		for _, rule := range all {
+			if rule.ID == id {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", transactions.ErrRuleNotFound, id)
+		}
+	}
+
+	// all is in priority order, keep it
+	for _, rule := range all {
+		for _, id := range ids {
+			if rule.ID == id {
+				rule.IsActive = true
+				selected = append(selected, rule)
+				break
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// Plan evaluates rules against the transactions of a user selected by filters and returns the
+// number of transactions read with the ones matching rules change. Nothing is written,
+// transactions are all read before any change so updates don't move the pages, and pages are
+// ordered by date then id so transactions sharing a timestamp are read once. A transaction is
+// only used once as the other side of a transfer, and is then left out. Dates are read in location
+func (re *RuleEvaluator) Plan(ctx context.Context, reader Reader, userID uuid.UUID, location *time.Location, rules []transactions.TransactionRule, filters dto.ExportFilters) (int, []Planned, error) {
+	planned := []Planned{}
+
+	if len(rules) == 0 {
+		return 0, planned, nil
+	}
+
+	params := repository.ListTransactionsParams{
+		UserID:      &userID,
+		Type:        filters.Type,
+		AccountID:   filters.AccountID,
+		CategoryID:  filters.CategoryID,
+		Currency:    filters.Currency,
+		IsExternal:  filters.IsExternal,
+		IsRecurring: filters.IsRecurring,
+		IsPending:   filters.IsPending,
+		StartDate:   filters.StartDate,
+		EndDate:     filters.EndDate,
+		MinAmount:   types.ToPgNumeric(filters.MinAmount),
+		MaxAmount:   types.ToPgNumeric(filters.MaxAmount),
+		Search:      filters.Search,
+		Tags:        filters.Tags,
+		Limit:       planPageSize,
+	}
+
+	claimed := map[uuid.UUID]bool{}
+
+	scanned := 0
+	for {
+		params.Offset = int64(scanned)
+
+		page, err := reader.ListTransactions(ctx, params)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, transaction := range page {
+			if claimed[transaction.ID] {
+				continue
+			}
+
+			data := NewTransactionData(transaction, location)
+
+			matches, err := re.EvaluateRules(rules, data)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			if len(matches) == 0 {
+				continue
+			}
+
+			changes := PlanMatches(data, transaction.Details, matches)
+			if err := Resolve(ctx, reader, userID, data, &changes, claimed); err != nil {
+				return 0, nil, err
+			}
+
+			if changes.Empty() {
+				continue
+			}
+
+			claimed[transaction.ID] = true
+			if changes.Transfer != nil {
+				claimed[changes.Transfer.Counterpart.ID] = true
+			}
+
+			planned = append(planned, Planned{
+				Transaction: transaction,
+				Matches:     ChangedBy(matches, changes),
+				Changes:     changes,
+			})
+		}
+
+		scanned += len(page)
+		if len(page) < planPageSize {
+			return scanned, planned, nil
+		}
+	}
+}
+
+// ChangedBy returns the matches of the rules that change a transaction
+func ChangedBy(matches []transactions.RuleMatch, changes Changes) []transactions.RuleMatch {
+	changed := []transactions.RuleMatch{}
+	for _, match := range Applicable(matches) {
+		if changes.Changed(match.RuleID) {
+			changed = append(changed, match)
+		}
+	}
+
+	return changed
+}
+
+// NewTransactionData reads the data rules are evaluated on from a transaction of the list, for a
+// user in location
+func NewTransactionData(transaction repository.ListTransactionsRow, location *time.Location) *transactions.TransactionData {
+	data := &transactions.TransactionData{
+		ID:                   transaction.ID,
+		Amount:               types.PgtypeNumericToDecimal(transaction.Amount),
+		Type:                 transaction.Type,
+		AccountID:            transaction.Account.ID,
+		AccountName:          transaction.Account.Name,
+		CategoryID:           &transaction.Category.ID,
+		CategoryName:         transaction.Category.Name,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Description:          transaction.Description,
+		TransactionDatetime:  transaction.TransactionDatetime,
+		TransactionCurrency:  transaction.Account.Currency,
+		IsExternal:           transaction.IsExternal != nil && *transaction.IsExternal,
+		Location:             location,
+	}
+	data.SetDetails(transaction.Details)
+
+	return data
+}
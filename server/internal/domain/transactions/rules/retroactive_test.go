@@ -0,0 +1,82 @@
+package rules_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestPlan_Pages(t *testing.T) {
+	imported := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+	description := "AMAZON MKTP"
+
+	// An import gives every transaction the same timestamp, more than fits in two pages
+	reader := &fakeReader{}
+	for range 1001 {
+		reader.transactions = append(reader.transactions, repository.ListTransactionsRow{
+			ID:                  uuid.New(),
+			Type:                "expense",
+			Amount:              types.DecimalToPgtypeNumeric(decimal.NewFromInt(-20)),
+			TransactionDatetime: imported,
+			Description:         &description,
+			Account:             repository.Account{ID: uuid.New(), Currency: "EUR"},
+			Category:            repository.Category{ID: uuid.New()},
+		})
+	}
+
+	rule := transactions.TransactionRule{
+		ID:       uuid.New(),
+		Name:     "Amazon",
+		IsActive: true,
+		Priority: 1,
+		Conditions: transactions.MatchAll(transactions.RuleCondition{
+			Type:     transactions.ConditionTypeDescription,
+			Operator: transactions.OperatorContains,
+			Value:    "amazon",
+		}),
+		Actions: []transactions.RuleAction{{Type: transactions.ActionTypeSetNote, Value: "Online order"}},
+	}
+
+	scanned, planned, err := rules.NewRuleEvaluator().Plan(context.Background(), reader, uuid.New(), time.UTC, []transactions.TransactionRule{rule}, dto.ExportFilters{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !slices.Equal(reader.offsets, []int64{0, 500, 1000}) {
+		t.Errorf("Expected pages at offsets 0, 500 and 1000, got %v", reader.offsets)
+	}
+
+	if scanned != 1001 || len(planned) != 1001 {
+		t.Fatalf("Expected 1001 transactions scanned and planned, got %d and %d", scanned, len(planned))
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, p := range planned {
+		if seen[p.Transaction.ID] {
+			t.Fatalf("Expected every transaction to be planned once, %s was planned twice", p.Transaction.ID)
+		}
+		seen[p.Transaction.ID] = true
+	}
+
+	// The fake reader pages in list order, so check the query breaks timestamp ties by id
+	queries, err := os.ReadFile("../../../../database/queries/transactions.sql")
+	if err != nil {
+		t.Fatalf("Expected to read the transaction queries, got %v", err)
+	}
+	_, query, _ := strings.Cut(string(queries), "-- name: ListTransactions :many")
+	query, _, _ = strings.Cut(query, "-- name:")
+	if !strings.Contains(query, "t.transaction_datetime DESC,\n    t.id DESC") {
+		t.Errorf("Expected ListTransactions to order by date then id, got %q", query)
+	}
+}
@@ -0,0 +1,229 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Undoer reads and writes what undoing the changes of a rule touches, it should run in a database
+// transaction
+type Undoer interface {
+	ListTransactionSplits(ctx context.Context, transactionIds []uuid.UUID) ([]repository.TransactionSplit, error)
+	DeleteTransactionSplits(ctx context.Context, transactionID uuid.UUID) error
+	CreateTransactionSplit(ctx context.Context, arg repository.CreateTransactionSplitParams) (repository.TransactionSplit, error)
+	RevertTransaction(ctx context.Context, arg repository.RevertTransactionParams) (repository.Transaction, error)
+	RestoreTransaction(ctx context.Context, id uuid.UUID) (repository.Transaction, error)
+}
+
+// Reverted is how undoing the changes of a rule leaves a transaction
+type Reverted struct {
+	Params        repository.RevertTransactionParams
+	SplitsChanged bool // Splits replace the splits of the transaction, removed when empty
+	Splits        []Split
+	Restore       *uuid.UUID // The other side of a transfer to restore
+}
+
+// Undone is what undoing the changes of a rule wrote
+type Undone struct {
+	Transaction repository.Transaction
+	Restored    *repository.Transaction // The other side of an unlinked transfer
+}
+
+// Undo puts back the values a rule changed on a transaction
+func Undo(ctx context.Context, undoer Undoer, transaction repository.Transaction, userID uuid.UUID, changes []transactions.FieldChange) (Undone, error) {
+	var undone Undone
+
+	rows, err := undoer.ListTransactionSplits(ctx, []uuid.UUID{transaction.ID})
+	if err != nil {
+		return undone, fmt.Errorf("failed to list transaction splits: %w", err)
+	}
+
+	reverted, err := Revert(transaction, splitsFromRows(rows), changes)
+	if err != nil {
+		return undone, err
+	}
+
+	reverted.Params.UpdatedBy = &userID
+
+	undone.Transaction, err = undoer.RevertTransaction(ctx, reverted.Params)
+	if err != nil {
+		return undone, fmt.Errorf("failed to revert transaction %s: %w", transaction.ID, err)
+	}
+
+	if reverted.SplitsChanged {
+		if err := replaceSplits(ctx, undoer, transaction.ID, reverted.Splits); err != nil {
+			return undone, err
+		}
+	}
+
+	if reverted.Restore != nil {
+		restored, err := undoer.RestoreTransaction(ctx, *reverted.Restore)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return undone, fmt.Errorf("%w: %s", transactions.ErrRuleApplicationConflict, transactions.RuleFieldTransfer)
+		}
+		if err != nil {
+			return undone, fmt.Errorf("failed to restore transaction %s: %w", *reverted.Restore, err)
+		}
+
+		undone.Restored = &restored
+	}
+
+	return undone, nil
+}
+
+// Revert computes the values of a transaction before a rule changed it. Changes are only undone
+// while the transaction still has the values the rule set, otherwise it fails with
+// ErrRuleApplicationConflict rather than overwriting a later edit
+func Revert(transaction repository.Transaction, splits []Split, changes []transactions.FieldChange) (Reverted, error) {
+	reverted := Reverted{
+		Params: repository.RevertTransactionParams{
+			ID:                   transaction.ID,
+			Type:                 transaction.Type,
+			Amount:               types.PgtypeNumericToDecimal(transaction.Amount),
+			AccountID:            transaction.AccountID,
+			DestinationAccountID: transaction.DestinationAccountID,
+			Description:          transaction.Description,
+			Details:              transaction.Details,
+		},
+	}
+	if transaction.CategoryID != nil {
+		reverted.Params.CategoryID = *transaction.CategoryID
+	}
+
+	details := dto.Details{}
+	if transaction.Details != nil {
+		details = *transaction.Details
+	}
+
+	for _, change := range changes {
+		conflict := fmt.Errorf("%w: %s", transactions.ErrRuleApplicationConflict, change.Field)
+
+		switch change.Field {
+		case transactions.RuleFieldCategory:
+			var after uuid.UUID
+			var before *uuid.UUID
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if reverted.Params.CategoryID != after {
+				return reverted, conflict
+			}
+			if before != nil {
+				reverted.Params.CategoryID = *before
+			}
+		case transactions.RuleFieldDescription:
+			var after string
+			var before *string
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if !equalPtr(transaction.Description, after) {
+				return reverted, conflict
+			}
+			reverted.Params.Description = before
+		case transactions.RuleFieldNote, transactions.RuleFieldMerchant:
+			field := &details.Note
+			if change.Field == transactions.RuleFieldMerchant {
+				field = &details.Merchant
+			}
+
+			var after string
+			var before *string
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if !equalPtr(*field, after) {
+				return reverted, conflict
+			}
+			*field = before
+			reverted.Params.Details = &details
+		case transactions.RuleFieldTaxDeductible, transactions.RuleFieldBusinessExpense:
+			field := &details.TaxDeductible
+			if change.Field == transactions.RuleFieldBusinessExpense {
+				field = &details.BusinessExpense
+			}
+
+			var after bool
+			var before *bool
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if !equalPtr(*field, after) {
+				return reverted, conflict
+			}
+			*field = before
+			reverted.Params.Details = &details
+		case transactions.RuleFieldTags:
+			var after, before []string
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if !slices.Equal(details.Tags, after) {
+				return reverted, conflict
+			}
+			details.Tags = before
+			reverted.Params.Details = &details
+		case transactions.RuleFieldSplits:
+			var after, before []Split
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if !sameSplits(splits, after) {
+				return reverted, conflict
+			}
+			reverted.Splits = before
+			reverted.SplitsChanged = true
+		case transactions.RuleFieldTransfer:
+			var after uuid.UUID
+			var before TransferBefore
+			if err := decodeChange(change, &before, &after); err != nil {
+				return reverted, err
+			}
+			if transaction.Type != "transfer" {
+				return reverted, conflict
+			}
+			reverted.Params.Type = before.Type
+			reverted.Params.Amount = before.Amount
+			reverted.Params.AccountID = before.AccountID
+			reverted.Params.DestinationAccountID = nil
+			reverted.Restore = &after
+		default:
+			return reverted, fmt.Errorf("can't undo a change of %s", change.Field)
+		}
+	}
+
+	return reverted, nil
+}
+
+// decodeChange reads the values of a change, which are generic once saved as JSON
+func decodeChange(change transactions.FieldChange, before, after any) error {
+	for _, value := range []struct {
+		from any
+		to   any
+	}{{change.Before, before}, {change.After, after}} {
+		data, err := json.Marshal(value.from)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, value.to); err != nil {
+			return fmt.Errorf("invalid %s change: %w", change.Field, err)
+		}
+	}
+
+	return nil
+}
+
+func equalPtr[T comparable](value *T, want T) bool {
+	return value != nil && *value == want
+}
@@ -0,0 +1,192 @@
+package rules_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// saved returns changes as they are read back from the database
+func saved(t *testing.T, changes []transactions.FieldChange) []transactions.FieldChange {
+	t.Helper()
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded []transactions.FieldChange
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return decoded
+}
+
+func TestRevert(t *testing.T) {
+	groceries := uuid.New()
+	dining := uuid.New()
+	note := "Weekly shop"
+	description := "CARD 1234 TESCO"
+	renamed := "Tesco"
+
+	transaction := repository.Transaction{
+		ID:          uuid.New(),
+		Type:        "expense",
+		Amount:      types.DecimalToPgtypeNumeric(decimal.NewFromInt(-42)),
+		AccountID:   uuid.New(),
+		CategoryID:  &groceries,
+		Description: &renamed,
+		Details:     &dto.Details{Note: &note, Tags: []string{"food"}},
+	}
+
+	changes := saved(t, []transactions.FieldChange{
+		{Field: transactions.RuleFieldCategory, Before: dining, After: groceries},
+		{Field: transactions.RuleFieldDescription, Before: description, After: renamed},
+		{Field: transactions.RuleFieldNote, Before: nil, After: note},
+		{Field: transactions.RuleFieldTags, Before: []string{}, After: []string{"food"}},
+	})
+
+	reverted, err := rules.Revert(transaction, nil, changes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reverted.Params.CategoryID != dining {
+		t.Errorf("Expected category %s, got %s", dining, reverted.Params.CategoryID)
+	}
+
+	if reverted.Params.Description == nil || *reverted.Params.Description != description {
+		t.Errorf("Expected description %q, got %v", description, reverted.Params.Description)
+	}
+
+	if reverted.Params.Details.Note != nil || len(reverted.Params.Details.Tags) != 0 {
+		t.Errorf("Expected the note and tags to be removed, got %+v", reverted.Params.Details)
+	}
+
+	if reverted.SplitsChanged || reverted.Restore != nil {
+		t.Errorf("Expected only the transaction to be reverted")
+	}
+
+	if *transaction.Details.Note != note {
+		t.Errorf("Expected the details of the transaction to be left as they are")
+	}
+}
+
+func TestRevert_Transfer(t *testing.T) {
+	source := uuid.New()
+	destination := uuid.New()
+	counterpart := uuid.New()
+
+	transaction := repository.Transaction{
+		ID:                   uuid.New(),
+		Type:                 "transfer",
+		Amount:               types.DecimalToPgtypeNumeric(decimal.NewFromInt(-250)),
+		AccountID:            source,
+		DestinationAccountID: &destination,
+	}
+
+	// The income side was linked, so the transfer starts from the other account
+	changes := saved(t, []transactions.FieldChange{{
+		Field:  transactions.RuleFieldTransfer,
+		Before: rules.TransferBefore{Type: "income", Amount: decimal.NewFromInt(250), AccountID: destination},
+		After:  counterpart,
+	}})
+
+	reverted, err := rules.Revert(transaction, nil, changes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reverted.Params.Type != "income" || !reverted.Params.Amount.Equal(decimal.NewFromInt(250)) || reverted.Params.AccountID != destination {
+		t.Errorf("Expected an income of 250 on %s, got %s of %s on %s", destination, reverted.Params.Type, reverted.Params.Amount, reverted.Params.AccountID)
+	}
+
+	if reverted.Params.DestinationAccountID != nil {
+		t.Errorf("Expected no destination account, got %s", reverted.Params.DestinationAccountID)
+	}
+
+	if reverted.Restore == nil || *reverted.Restore != counterpart {
+		t.Errorf("Expected %s to be restored, got %v", counterpart, reverted.Restore)
+	}
+}
+
+func TestRevert_Splits(t *testing.T) {
+	food := uuid.New()
+	household := uuid.New()
+
+	splits := []rules.Split{
+		{CategoryID: food, Amount: decimal.NewFromInt(60), Percentage: decimal.NewFromInt(75)},
+		{CategoryID: household, Amount: decimal.NewFromInt(20), Percentage: decimal.NewFromInt(25)},
+	}
+
+	changes := saved(t, []transactions.FieldChange{{Field: transactions.RuleFieldSplits, Before: nil, After: splits}})
+
+	reverted, err := rules.Revert(repository.Transaction{ID: uuid.New(), Type: "expense"}, []rules.Split{splits[1], splits[0]}, changes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reverted.SplitsChanged || len(reverted.Splits) != 0 {
+		t.Errorf("Expected the splits to be removed, got %v", reverted.Splits)
+	}
+}
+
+func TestRevert_Conflict(t *testing.T) {
+	groceries := uuid.New()
+	edited := uuid.New()
+	merchant := "Uber"
+
+	tests := []struct {
+		name        string
+		transaction repository.Transaction
+		splits      []rules.Split
+		change      transactions.FieldChange
+	}{
+		{
+			name:        "category edited",
+			transaction: repository.Transaction{CategoryID: &edited},
+			change:      transactions.FieldChange{Field: transactions.RuleFieldCategory, Before: nil, After: groceries},
+		},
+		{
+			name:        "description removed",
+			transaction: repository.Transaction{},
+			change:      transactions.FieldChange{Field: transactions.RuleFieldDescription, Before: nil, After: "Tesco"},
+		},
+		{
+			name:        "merchant edited",
+			transaction: repository.Transaction{Details: &dto.Details{Merchant: &merchant}},
+			change:      transactions.FieldChange{Field: transactions.RuleFieldMerchant, Before: nil, After: "Uber Eats"},
+		},
+		{
+			name:        "split removed",
+			transaction: repository.Transaction{},
+			change: transactions.FieldChange{Field: transactions.RuleFieldSplits, Before: nil, After: []rules.Split{
+				{CategoryID: groceries, Amount: decimal.NewFromInt(10), Percentage: decimal.NewFromInt(50)},
+			}},
+		},
+		{
+			name:        "transfer unlinked",
+			transaction: repository.Transaction{Type: "expense"},
+			change:      transactions.FieldChange{Field: transactions.RuleFieldTransfer, Before: rules.TransferBefore{Type: "expense"}, After: uuid.New()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rules.Revert(tt.transaction, tt.splits, saved(t, []transactions.FieldChange{tt.change}))
+
+			if !errors.Is(err, transactions.ErrRuleApplicationConflict) {
+				t.Errorf("Expected ErrRuleApplicationConflict, got %v", err)
+			}
+		})
+	}
+}
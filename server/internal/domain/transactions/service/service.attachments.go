@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	attachmentStatusPending  = "pending"
+	attachmentStatusUploaded = "uploaded"
+)
+
+// CreateAttachmentUpload records a pending attachment and returns the presigned link the
+// client puts the file to. The link is empty when the storage can't presign links, the file
+// is then sent to the content endpoint of the attachment
+func (t *TransactionService) CreateAttachmentUpload(ctx context.Context, userID uuid.UUID, req transactions.UploadAttachmentRequest) (*transactions.AttachmentUploadResponse, error) {
+	transactionID, err := uuid.Parse(req.TransactionID)
+	if err != nil {
+		return nil, transactions.ErrNoTransactions
+	}
+
+	if err := attachments.Validate(req.MimeType, req.FileSize); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkTransactionOwnership(ctx, transactionID, userID); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	filename := attachments.SanitizeFilename(req.Filename)
+
+	att, err := t.trscRepo.CreateAttachment(ctx, repository.CreateAttachmentParams{
+		ID:               id,
+		TransactionID:    transactionID,
+		Filename:         filename,
+		OriginalFilename: filename,
+		FileSize:         req.FileSize,
+		MimeType:         attachments.NormalizeType(req.MimeType),
+		StorageKey:       attachments.Key(userID, transactionID, id),
+		BucketName:       t.attachments.Bucket(),
+		UploadedBy:       userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	res := &transactions.AttachmentUploadResponse{AttachmentID: att.ID}
+
+	url, expiresAt, err := t.attachments.PresignUpload(ctx, att.StorageKey)
+	if err != nil && !errors.Is(err, storage.ErrOperationNotSupported) {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	if err == nil {
+		res.UploadURL = url
+		res.ExpiresAt = expiresAt
+	}
+
+	return res, nil
+}
+
+// CompleteAttachmentUpload checks the file the client put to the presigned link. Unlike files
+// sent through the API it isn't encrypted with the server key, files that don't match what was
+// announced are discarded
+func (t *TransactionService) CompleteAttachmentUpload(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.Attachment, error) {
+	att, err := t.getPendingAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := t.attachments.ReadUpload(ctx, att.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", transactions.ErrAttachmentMissing, err)
+	}
+
+	if err := attachments.Check(content, att.MimeType); err != nil {
+		t.discardAttachment(ctx, att)
+		return nil, err
+	}
+
+	completed, err := t.trscRepo.CompleteAttachment(ctx, repository.CompleteAttachmentParams{
+		ID:          att.ID,
+		FileSize:    int64(len(content)),
+		MimeType:    att.MimeType,
+		IsEncrypted: false,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrAttachmentNotPending
+		}
+		return nil, fmt.Errorf("failed to complete attachment: %w", err)
+	}
+
+	return t.toAttachment(ctx, completed), nil
+}
+
+// UploadAttachmentContent stores the file of a pending attachment sent through the API,
+// encrypted with the server key
+func (t *TransactionService) UploadAttachmentContent(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, content []byte) (*transactions.Attachment, error) {
+	att, err := t.getPendingAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachments.Check(content, att.MimeType); err != nil {
+		return nil, err
+	}
+
+	keyID, err := t.attachments.Write(ctx, att.StorageKey, content)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := t.trscRepo.CompleteAttachment(ctx, repository.CompleteAttachmentParams{
+		ID:              att.ID,
+		FileSize:        int64(len(content)),
+		MimeType:        att.MimeType,
+		IsEncrypted:     true,
+		EncryptionKeyID: &keyID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrAttachmentNotPending
+		}
+		return nil, fmt.Errorf("failed to complete attachment: %w", err)
+	}
+
+	return t.toAttachment(ctx, completed), nil
+}
+
+// ListAttachments returns the uploaded attachments of a transaction, with a download link when
+// the storage can presign one
+func (t *TransactionService) ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]transactions.Attachment, error) {
+	if err := t.checkTransactionOwnership(ctx, transactionID, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := t.trscRepo.ListAttachments(ctx, transactionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	list := make([]transactions.Attachment, len(rows))
+	for i, row := range rows {
+		list[i] = *t.toAttachment(ctx, row)
+	}
+
+	return list, nil
+}
+
+// GetAttachmentDownload returns a presigned link to download an attachment,
+// attachments.ErrNotPresignable when the file has to be read through the API
+func (t *TransactionService) GetAttachmentDownload(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.AttachmentDownloadResponse, error) {
+	att, err := t.getUploadedAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	url, expiresAt, err := t.attachments.PresignDownload(ctx, att.StorageKey, att.EncryptionKeyID)
+	if err != nil {
+		if errors.Is(err, storage.ErrOperationNotSupported) {
+			return nil, attachments.ErrNotPresignable
+		}
+		return nil, err
+	}
+
+	return &transactions.AttachmentDownloadResponse{
+		DownloadURL: url,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// ReadAttachment returns an attachment with its decrypted content
+func (t *TransactionService) ReadAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.Attachment, []byte, error) {
+	att, err := t.getUploadedAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := t.attachments.Read(ctx, att.StorageKey, att.EncryptionKeyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return toAttachment(att), content, nil
+}
+
+// DeleteAttachment removes an attachment, its file is removed from storage by a job once the
+// row is gone
+func (t *TransactionService) DeleteAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.rollback(ctx, tx)
+
+	att, err := t.trscRepo.WithTx(tx).DeleteAttachment(ctx, attachmentID, transactionID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transactions.ErrAttachmentNotFound
+		}
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	if err := t.jobs.EnqueueAttachmentCleanupTx(ctx, tx, att.BucketName, []string{att.StorageKey}); err != nil {
+		return fmt.Errorf("failed to enqueue attachment cleanup: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// deleteTransactionAttachments removes the attachments of deleted transactions as part of tx
+func (t *TransactionService) deleteTransactionAttachments(ctx context.Context, tx pgx.Tx, transactionIDs []uuid.UUID) error {
+	deleted, err := t.trscRepo.WithTx(tx).DeleteTransactionAttachments(ctx, transactionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+
+	keys := make(map[string][]string)
+	for _, att := range deleted {
+		keys[att.BucketName] = append(keys[att.BucketName], att.StorageKey)
+	}
+
+	for bucket, bucketKeys := range keys {
+		if err := t.jobs.EnqueueAttachmentCleanupTx(ctx, tx, bucket, bucketKeys); err != nil {
+			return fmt.Errorf("failed to enqueue attachment cleanup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (t *TransactionService) checkTransactionOwnership(ctx context.Context, transactionID, userID uuid.UUID) error {
+	transaction, err := t.trscRepo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transactions.ErrNoTransactions
+		}
+		return err
+	}
+
+	if transaction.CreatedBy == nil || *transaction.CreatedBy != userID {
+		return transactions.ErrNoTransactions
+	}
+
+	return nil
+}
+
+func (t *TransactionService) getAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (repository.TransactionAttachment, error) {
+	att, err := t.trscRepo.GetAttachment(ctx, attachmentID, transactionID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return att, transactions.ErrAttachmentNotFound
+		}
+		return att, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return att, nil
+}
+
+func (t *TransactionService) getPendingAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (repository.TransactionAttachment, error) {
+	att, err := t.getAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return att, err
+	}
+
+	if att.Status != attachmentStatusPending {
+		return att, transactions.ErrAttachmentNotPending
+	}
+
+	return att, nil
+}
+
+func (t *TransactionService) getUploadedAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (repository.TransactionAttachment, error) {
+	att, err := t.getAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return att, err
+	}
+
+	// Pending attachments have no file yet
+	if att.Status != attachmentStatusUploaded {
+		return att, transactions.ErrAttachmentNotFound
+	}
+
+	return att, nil
+}
+
+// discardAttachment removes a rejected upload, the client starts over with a new attachment
+func (t *TransactionService) discardAttachment(ctx context.Context, att repository.TransactionAttachment) {
+	if err := t.attachments.Delete(ctx, att.StorageKey); err != nil {
+		t.logger.Error().Err(err).Str("attachment_id", att.ID.String()).Msg("Failed to remove rejected attachment file")
+	}
+
+	if _, err := t.trscRepo.DeleteAttachment(ctx, att.ID, att.TransactionID, att.UploadedBy); err != nil {
+		t.logger.Error().Err(err).Str("attachment_id", att.ID.String()).Msg("Failed to remove rejected attachment")
+	}
+}
+
+// toAttachment adds a download link to the attachments the storage can presign
+func (t *TransactionService) toAttachment(ctx context.Context, att repository.TransactionAttachment) *transactions.Attachment {
+	res := toAttachment(att)
+
+	url, _, err := t.attachments.PresignDownload(ctx, att.StorageKey, att.EncryptionKeyID)
+	if err == nil {
+		res.PresignedURL = &url
+	}
+
+	return res
+}
+
+func toAttachment(att repository.TransactionAttachment) *transactions.Attachment {
+	return &transactions.Attachment{
+		ID:               att.ID,
+		TransactionID:    att.TransactionID,
+		Filename:         att.Filename,
+		OriginalFilename: att.OriginalFilename,
+		FileSize:         att.FileSize,
+		MimeType:         att.MimeType,
+		StorageKey:       att.StorageKey,
+		BucketName:       att.BucketName,
+		IsEncrypted:      att.IsEncrypted,
+		EncryptionKeyID:  att.EncryptionKeyID,
+		Status:           att.Status,
+		UploadedBy:       att.UploadedBy,
+		CreatedAt:        att.CreatedAt,
+		UploadedAt:       att.UploadedAt,
+	}
+}
@@ -8,10 +8,13 @@ import (
 
 	accRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/accounts/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
 	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
 	"github.com/Fantasy-Programming/nuts/server/pkg/llm"
 	"github.com/google/uuid"
@@ -44,42 +47,70 @@ type Transactions interface {
 	DeleteRule(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	ToggleRuleActive(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.TransactionRule, error)
 	ApplyRulesToTransaction(ctx context.Context, transactionID uuid.UUID, userID uuid.UUID) ([]transactions.RuleMatch, error)
+	PreviewRuleApplication(ctx context.Context, userID uuid.UUID, req transactions.ApplyRulesRequest) (*transactions.RuleApplicationPreview, error)
+	QueueRuleApplication(ctx context.Context, userID uuid.UUID, req transactions.ApplyRulesRequest) error
+	ListRuleHistory(ctx context.Context, ruleID uuid.UUID, userID uuid.UUID, page, limit int) ([]transactions.RuleApplication, error)
+	UndoRuleApplication(ctx context.Context, ruleID uuid.UUID, applicationID uuid.UUID, userID uuid.UUID) (*transactions.RuleApplication, error)
 
 	// Recurring
 	CreateRecurringTransaction(ctx context.Context, req transactions.CreateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error)
-	// GetRecurringTransactionByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.RecurringTransaction, error)
-	// ListRecurringTransactions(ctx context.Context, userID uuid.UUID, filters transactions.RecurringTransactionFilters) ([]transactions.RecurringTransaction, error)
-	// UpdateRecurringTransaction(ctx context.Context, id uuid.UUID, req transactions.UpdateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error)
-	// DeleteRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
-	// PauseRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID, isPaused bool) (*transactions.RecurringTransaction, error)
-	// GetDueRecurringTransactions(ctx context.Context, dueDate time.Time) ([]transactions.RecurringTransaction, error)
-	// GetRecurringTransactionStats(ctx context.Context, userID uuid.UUID) (*transactions.RecurringTransactionStats, error)
-	// GetUpcomingRecurringTransactions(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]transactions.RecurringTransaction, error)
-	// GetRecurringTransactionInstances(ctx context.Context, userID uuid.UUID, recurringID uuid.UUID) ([]repository.Transaction, error)
+	GetRecurringTransactionByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.RecurringTransaction, error)
+	ListRecurringTransactions(ctx context.Context, userID uuid.UUID, filters transactions.RecurringTransactionFilters) ([]transactions.RecurringTransaction, error)
+	UpdateRecurringTransaction(ctx context.Context, id uuid.UUID, req transactions.UpdateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error)
+	DeleteRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	PauseRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID, isPaused bool) (*transactions.RecurringTransaction, error)
+	GetRecurringTransactionStats(ctx context.Context, userID uuid.UUID) (*transactions.RecurringTransactionStats, error)
+	GetRecurringTransactionInstances(ctx context.Context, userID uuid.UUID, recurringID uuid.UUID) ([]repository.Transaction, error)
+	GetRecurringInstances(ctx context.Context, userID uuid.UUID, req transactions.GetRecurringInstancesRequest) (*transactions.RecurringInstancesResponse, error)
+	ProcessRecurringInstance(ctx context.Context, id uuid.UUID, userID uuid.UUID, req transactions.ProcessRecurringTransactionRequest) (*transactions.ProcessRecurringTransactionResponse, error)
+
+	// Imports
+	StageImport(ctx context.Context, params transactions.StageImportParams) (*transactions.ImportPreview, error)
+	GetImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.ImportPreview, error)
+	ListImports(ctx context.Context, userID uuid.UUID, page, limit int) ([]repository.TransactionImport, error)
+	CommitImport(ctx context.Context, id uuid.UUID, userID uuid.UUID, params transactions.CommitImportParams) (*transactions.ImportPreview, error)
+	DiscardImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	SaveImportMapping(ctx context.Context, userID uuid.UUID, name string, mapping dto.CSVMapping) (repository.ImportMapping, error)
+	ListImportMappings(ctx context.Context, userID uuid.UUID) ([]repository.ImportMapping, error)
+	DeleteImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
+	// Attachments
+	CreateAttachmentUpload(ctx context.Context, userID uuid.UUID, req transactions.UploadAttachmentRequest) (*transactions.AttachmentUploadResponse, error)
+	CompleteAttachmentUpload(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.Attachment, error)
+	UploadAttachmentContent(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, content []byte) (*transactions.Attachment, error)
+	ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]transactions.Attachment, error)
+	GetAttachmentDownload(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.AttachmentDownloadResponse, error)
+	ReadAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*transactions.Attachment, []byte, error)
+	DeleteAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) error
+	ParseAttachmentReceipt(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, userTimezone *string) (*transactions.ReceiptDraft, error)
 
 	// AI
 	ParseTransactions(ctx context.Context, req llm.NeuralInputRequest) (*llm.NeuralInputResponse, error)
 }
 
 type TransactionService struct {
-	trscRepo   trscRepo.Transactions
-	accRepo    accRepo.Account
-	llmService llm.Service
-	jobs       *jobs.Service
-	db         *pgxpool.Pool
-	evaluator  *rules.RuleEvaluator
-	logger     *zerolog.Logger
+	trscRepo    trscRepo.Transactions
+	accRepo     accRepo.Account
+	llmService  llm.Service
+	jobs        *jobs.Service
+	db          *pgxpool.Pool
+	attachments *attachments.Store
+	evaluator   *rules.RuleEvaluator
+	events      *events.Bus
+	logger      *zerolog.Logger
 }
 
-func New(db *pgxpool.Pool, trscRepo trscRepo.Transactions, accRepo accRepo.Account, llm llm.Service, jobs *jobs.Service, logger *zerolog.Logger) *TransactionService {
+func New(db *pgxpool.Pool, trscRepo trscRepo.Transactions, accRepo accRepo.Account, llm llm.Service, jobs *jobs.Service, attachments *attachments.Store, bus *events.Bus, logger *zerolog.Logger) *TransactionService {
 	return &TransactionService{
-		trscRepo:   trscRepo,
-		accRepo:    accRepo,
-		llmService: llm,
-		jobs:       jobs,
-		db:         db,
-		evaluator:  rules.NewRuleEvaluator(),
-		logger:     logger,
+		trscRepo:    trscRepo,
+		accRepo:     accRepo,
+		llmService:  llm,
+		jobs:        jobs,
+		db:          db,
+		attachments: attachments,
+		evaluator:   rules.NewRuleEvaluator(),
+		events:      bus,
+		logger:      logger,
 	}
 }
 
@@ -217,6 +248,11 @@ func (t *TransactionService) CreateTransaction(ctx context.Context, params repos
 		return repository.Transaction{}, err
 	}
 
+	t.publishTransaction(ctx, events.TransactionCreated, transaction)
+	t.publishBalanceChanges(ctx, transaction.CreatedBy, map[uuid.UUID]decimal.Decimal{
+		params.AccountID: params.Amount,
+	})
+
 	// // Apply rules to the newly created transaction
 	// if h.rulesService != nil {
 	// 	err = h.rulesService.AutoApplyRulesToNewTransaction(ctx, transaction.ID, userID)
@@ -444,6 +480,20 @@ func (t *TransactionService) UpdateTransaction(ctx context.Context, params repos
 		return repository.Transaction{}, err
 	}
 
+	// Net effect of the reversal and the new amounts, per account
+	deltas := map[uuid.UUID]decimal.Decimal{}
+	deltas[originalTx.AccountID] = deltas[originalTx.AccountID].Sub(reversalAmount)
+	if originalTx.DestinationAccountID != nil {
+		deltas[*originalTx.DestinationAccountID] = deltas[*originalTx.DestinationAccountID].Sub(reversalAmount)
+	}
+	deltas[updatedTx.AccountID] = deltas[updatedTx.AccountID].Add(newAmount)
+	if updatedTx.DestinationAccountID != nil {
+		deltas[*updatedTx.DestinationAccountID] = deltas[*updatedTx.DestinationAccountID].Sub(newAmount)
+	}
+
+	t.publishTransaction(ctx, events.TransactionUpdated, updatedTx)
+	t.publishBalanceChanges(ctx, updatedTx.CreatedBy, deltas)
+
 	return updatedTx, nil
 }
 
@@ -522,6 +572,12 @@ func (t *TransactionService) CreateTransfertTransaction(ctx context.Context, par
 		return repository.Transaction{}, err
 	}
 
+	t.publishTransaction(ctx, events.TransactionCreated, transaction)
+	t.publishBalanceChanges(ctx, &params.UserID, map[uuid.UUID]decimal.Decimal{
+		params.AccountID:            amountOutDecimal,
+		params.DestinationAccountID: amountInDecimal,
+	})
+
 	// // Apply rules to the newly created transaction
 	// if h.rulesService != nil {
 	// 	err = h.rulesService.AutoApplyRulesToNewTransaction(ctx, transaction.ID, userID)
@@ -535,15 +591,77 @@ func (t *TransactionService) CreateTransfertTransaction(ctx context.Context, par
 }
 
 func (r *TransactionService) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
-	return r.trscRepo.DeleteTransaction(ctx, id)
+	transaction, err := r.trscRepo.GetTransaction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.rollback(ctx, tx)
+
+	if err := r.trscRepo.WithTx(tx).DeleteTransaction(ctx, id); err != nil {
+		return err
+	}
+
+	if err := r.deleteTransactionAttachments(ctx, tx, []uuid.UUID{id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	r.publishTransaction(ctx, events.TransactionDeleted, transaction)
+
+	return nil
 }
 
 func (r *TransactionService) BulkDeleteTransactions(ctx context.Context, params repository.BulkDeleteTransactionsParams) error {
-	return r.trscRepo.BulkDeleteTransactions(ctx, params)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.rollback(ctx, tx)
+
+	deleted, err := r.trscRepo.WithTx(tx).BulkDeleteTransactions(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, len(deleted))
+	for i, transaction := range deleted {
+		ids[i] = transaction.ID
+	}
+
+	if err := r.deleteTransactionAttachments(ctx, tx, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, transaction := range deleted {
+		r.publishTransaction(ctx, events.TransactionDeleted, transaction)
+	}
+
+	return nil
 }
 
 func (r *TransactionService) BulkUpdateTransactionCategories(ctx context.Context, params repository.BulkUpdateTransactionCategoriesParams) error {
-	return r.trscRepo.BulkUpdateTransactionCategories(ctx, params)
+	updated, err := r.trscRepo.BulkUpdateTransactionCategories(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range updated {
+		r.publishTransaction(ctx, events.TransactionUpdated, transaction)
+	}
+
+	return nil
 }
 
 func (r *TransactionService) BulkUpdateManualTransactions(ctx context.Context, params transactions.BulkUpdateManualTransactionsParams) error {
@@ -552,13 +670,53 @@ func (r *TransactionService) BulkUpdateManualTransactions(ctx context.Context, p
 		transactionDatetime = pgtype.Timestamptz{Time: *params.TransactionDatetime, Valid: true}
 	}
 
-	return r.trscRepo.BulkUpdateManualTransactions(ctx, repository.BulkUpdateManualTransactionsParams{
+	updated, err := r.trscRepo.BulkUpdateManualTransactions(ctx, repository.BulkUpdateManualTransactionsParams{
 		CategoryID:          params.CategoryID,
 		AccountID:           params.AccountID,
 		TransactionDatetime: transactionDatetime,
 		UpdatedBy:           &params.UserID,
 		Ids:                 params.Ids,
+		UserID:              &params.UserID,
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range updated {
+		r.publishTransaction(ctx, events.TransactionUpdated, transaction)
+	}
+
+	return nil
+}
+
+// publishTransaction notifies the owner's subscribers about a committed change
+func (t *TransactionService) publishTransaction(ctx context.Context, eventType string, transaction repository.Transaction) {
+	if transaction.CreatedBy == nil {
+		return
+	}
+
+	t.events.Publish(ctx, *transaction.CreatedBy, eventType, events.NewTransactionData(transaction))
+}
+
+// publishBalanceChanges emits account.balance_changed for every account that moved
+func (t *TransactionService) publishBalanceChanges(ctx context.Context, userID *uuid.UUID, deltas map[uuid.UUID]decimal.Decimal) {
+	if t.events == nil || userID == nil {
+		return
+	}
+
+	for accountID, delta := range deltas {
+		if delta.IsZero() {
+			continue
+		}
+
+		account, err := t.accRepo.GetAccountByID(ctx, accountID)
+		if err != nil {
+			t.logger.Error().Err(err).Str("account_id", accountID.String()).Msg("Failed to load account for balance event")
+			continue
+		}
+
+		t.events.Publish(ctx, *userID, events.AccountBalanceChanged, events.NewAccountBalanceChangedData(account, delta))
+	}
 }
 
 func (r *TransactionService) ParseTransactions(ctx context.Context, req llm.NeuralInputRequest) (*llm.NeuralInputResponse, error) {
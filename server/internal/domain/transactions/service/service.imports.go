@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/statements"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// StageImport reads a statement file into staged transactions and flags the lines already
+// recorded on the account. Nothing reaches the account until the import is committed
+func (t *TransactionService) StageImport(ctx context.Context, params transactions.StageImportParams) (*transactions.ImportPreview, error) {
+	if err := t.checkAccountOwnership(ctx, params.AccountID.String(), params.UserID, transactions.ErrSrcAccNotFound); err != nil {
+		return nil, err
+	}
+
+	format := statements.Format(params.Format)
+	if format == "" {
+		detected, err := statements.DetectFormat(params.Filename, params.Data)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	mapping := params.Mapping
+	if format == statements.FormatCSV && params.MappingID != nil {
+		saved, err := t.trscRepo.GetImportMapping(ctx, *params.MappingID, params.UserID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, transactions.ErrImportMappingNotFound
+			}
+			return nil, fmt.Errorf("failed to get import mapping: %w", err)
+		}
+		mapping = &saved.Mapping
+	}
+
+	lines, err := statements.Parse(format, params.Data, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates, err := t.findImportDuplicates(ctx, params.AccountID, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicateCount := 0
+	for _, d := range duplicates {
+		if d != nil {
+			duplicateCount++
+		}
+	}
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.rollback(ctx, tx)
+
+	trxRepo := t.trscRepo.WithTx(tx)
+
+	if params.SaveMappingAs != nil && format == statements.FormatCSV && mapping != nil {
+		if _, err := trxRepo.SaveImportMapping(ctx, repository.SaveImportMappingParams{
+			UserID:  params.UserID,
+			Name:    *params.SaveMappingAs,
+			Mapping: *mapping,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to save import mapping: %w", err)
+		}
+	}
+
+	imp, err := trxRepo.CreateImport(ctx, repository.CreateTransactionImportParams{
+		UserID:         params.UserID,
+		AccountID:      params.AccountID,
+		Format:         string(format),
+		Filename:       params.Filename,
+		RowCount:       int32(len(lines)),
+		DuplicateCount: int32(duplicateCount),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import: %w", err)
+	}
+
+	staged := make([]repository.CreateStagedTransactionsParams, len(lines))
+	for i, line := range lines {
+		staged[i] = repository.CreateStagedTransactionsParams{
+			ImportID:            imp.ID,
+			Line:                int32(line.Line),
+			TransactionDatetime: line.Date,
+			Amount:              line.Amount,
+			Currency:            optionalString(line.Currency),
+			Description:         optionalString(line.Description),
+			Payee:               optionalString(line.Payee),
+			Reference:           optionalString(line.Reference),
+			DuplicateOf:         duplicates[i],
+		}
+	}
+
+	if err := trxRepo.StageTransactions(ctx, staged); err != nil {
+		return nil, fmt.Errorf("failed to stage transactions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return t.importPreview(ctx, imp)
+}
+
+// findImportDuplicates matches the statement lines against the account transactions of the
+// same period
+func (t *TransactionService) findImportDuplicates(ctx context.Context, accountID uuid.UUID, lines []statements.Transaction) ([]*uuid.UUID, error) {
+	start, end := lines[0].Date, lines[0].Date
+	for _, line := range lines[1:] {
+		if line.Date.Before(start) {
+			start = line.Date
+		}
+		if line.Date.After(end) {
+			end = line.Date
+		}
+	}
+
+	rows, err := t.trscRepo.ListDuplicateCandidates(ctx, accountID, start.Add(-statements.DuplicateWindow), end.Add(statements.DuplicateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account transactions: %w", err)
+	}
+
+	recorded := make([]statements.Recorded, len(rows))
+	for i, row := range rows {
+		recorded[i] = statements.Recorded{
+			ID:     row.ID,
+			Date:   row.TransactionDatetime,
+			Amount: types.PgtypeNumericToDecimal(row.Amount),
+		}
+		if row.Description != nil {
+			recorded[i].Description = *row.Description
+		}
+	}
+
+	return statements.FindDuplicates(lines, recorded), nil
+}
+
+func (t *TransactionService) GetImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.ImportPreview, error) {
+	imp, err := t.trscRepo.GetImport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrImportNotFound
+		}
+		return nil, err
+	}
+
+	return t.importPreview(ctx, imp)
+}
+
+func (t *TransactionService) importPreview(ctx context.Context, imp repository.TransactionImport) (*transactions.ImportPreview, error) {
+	rows, err := t.trscRepo.ListStagedTransactions(ctx, imp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged transactions: %w", err)
+	}
+
+	return &transactions.ImportPreview{
+		TransactionImport: imp,
+		Transactions:      rows,
+	}, nil
+}
+
+func (t *TransactionService) ListImports(ctx context.Context, userID uuid.UUID, page, limit int) ([]repository.TransactionImport, error) {
+	return t.trscRepo.ListImports(ctx, repository.ListTransactionImportsParams{
+		UserID: userID,
+		Limit:  int64(limit),
+		Offset: int64((page - 1) * limit),
+	})
+}
+
+// CommitImport turns the staged lines into transactions, except the skipped ones and, unless
+// asked for, the duplicates. Rules then run on each new transaction like on manual ones
+func (t *TransactionService) CommitImport(ctx context.Context, id uuid.UUID, userID uuid.UUID, params transactions.CommitImportParams) (*transactions.ImportPreview, error) {
+	imp, err := t.trscRepo.GetImport(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrImportNotFound
+		}
+		return nil, err
+	}
+
+	if imp.Status != transactions.ImportStatusStaged {
+		return nil, transactions.ErrImportCommitted
+	}
+
+	account, err := t.accRepo.GetAccountByID(ctx, imp.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import account: %w", err)
+	}
+
+	rows, err := t.trscRepo.ListStagedTransactions(ctx, imp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged transactions: %w", err)
+	}
+
+	skipped := make(map[uuid.UUID]bool, len(params.SkipIDs))
+	for _, id := range params.SkipIDs {
+		skipped[id] = true
+	}
+
+	included := make([]repository.StagedTransaction, 0, len(rows))
+	for _, row := range rows {
+		if skipped[row.ID] || (row.DuplicateOf != nil && !params.IncludeDuplicates) {
+			continue
+		}
+		included = append(included, row)
+	}
+
+	// Amounts are added to the balance as they are, lines in another currency have to be skipped
+	if lines := foreignCurrencyLines(included, account.Currency); len(lines) > 0 {
+		return nil, fmt.Errorf("%w: lines %s", transactions.ErrImportCurrency, joinLines(lines))
+	}
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.rollback(ctx, tx)
+
+	trxRepo := t.trscRepo.WithTx(tx)
+	accRepo := t.accRepo.WithTx(tx)
+
+	isExternal := false
+	total := decimal.Zero
+	created := make([]repository.Transaction, 0, len(rows))
+
+	for _, row := range included {
+		amount := types.PgtypeNumericToDecimal(row.Amount)
+
+		transactionType := "income"
+		if amount.IsNegative() {
+			transactionType = "expense"
+		}
+
+		description := row.Description
+		if description == nil {
+			description = row.Payee
+		}
+
+		transaction, err := trxRepo.CreateTransaction(ctx, repository.CreateTransactionParams{
+			Amount:              amount,
+			Type:                transactionType,
+			AccountID:           imp.AccountID,
+			CategoryID:          &params.CategoryID,
+			Description:         description,
+			TransactionDatetime: pgtype.Timestamptz{Time: row.TransactionDatetime, Valid: true},
+			TransactionCurrency: account.Currency,
+			OriginalAmount:      amount,
+			Details:             &dto.Details{Merchant: row.Payee},
+			IsExternal:          &isExternal,
+			CreatedBy:           &userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction for line %d: %w", row.Line, err)
+		}
+
+		if err := trxRepo.LinkStagedTransaction(ctx, row.ID, transaction.ID); err != nil {
+			return nil, fmt.Errorf("failed to link staged transaction: %w", err)
+		}
+
+		total = total.Add(amount)
+		created = append(created, transaction)
+	}
+
+	if len(created) > 0 {
+		if err := accRepo.UpdateAccountBalance(ctx, repository.UpdateAccountBalanceParams{
+			ID:      imp.AccountID,
+			Balance: decimal.NewNullDecimal(total),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update account balance: %w", err)
+		}
+	}
+
+	committed, err := trxRepo.MarkImportCommitted(ctx, imp.ID, int32(len(created)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark import committed: %w", err)
+	}
+	if !committed {
+		return nil, transactions.ErrImportCommitted
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range created {
+		t.publishTransaction(ctx, events.TransactionCreated, transaction)
+	}
+	if len(created) > 0 {
+		t.publishBalanceChanges(ctx, &userID, map[uuid.UUID]decimal.Decimal{imp.AccountID: total})
+	}
+
+	for _, transaction := range created {
+		if err := t.AutoApplyRulesToNewTransaction(ctx, transaction.ID, userID); err != nil {
+			// The import is committed, a failing rule only leaves the transaction as imported
+			t.logger.Error().Err(err).Str("transaction_id", transaction.ID.String()).Msg("Failed to apply rules to imported transaction")
+		}
+	}
+
+	return t.GetImport(ctx, id, userID)
+}
+
+// DiscardImport drops an import that wasn't committed, with its staged lines
+func (t *TransactionService) DiscardImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	deleted, err := t.trscRepo.DeleteStagedImport(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		if _, err := t.trscRepo.GetImport(ctx, id, userID); err == nil {
+			return transactions.ErrImportCommitted
+		}
+		return transactions.ErrImportNotFound
+	}
+
+	return nil
+}
+
+func (t *TransactionService) SaveImportMapping(ctx context.Context, userID uuid.UUID, name string, mapping dto.CSVMapping) (repository.ImportMapping, error) {
+	return t.trscRepo.SaveImportMapping(ctx, repository.SaveImportMappingParams{
+		UserID:  userID,
+		Name:    name,
+		Mapping: mapping,
+	})
+}
+
+func (t *TransactionService) ListImportMappings(ctx context.Context, userID uuid.UUID) ([]repository.ImportMapping, error) {
+	return t.trscRepo.ListImportMappings(ctx, userID)
+}
+
+func (t *TransactionService) DeleteImportMapping(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	deleted, err := t.trscRepo.DeleteImportMapping(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return transactions.ErrImportMappingNotFound
+	}
+
+	return nil
+}
+
+// rollback undoes tx unless it was committed
+func (t *TransactionService) rollback(ctx context.Context, tx pgx.Tx) {
+	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		t.logger.Error().Err(err).Msg("Failed to rollback transaction")
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// foreignCurrencyLines returns the lines of the rows in another currency than the account. Lines
+// without a currency are in the one of the account
+func foreignCurrencyLines(rows []repository.StagedTransaction, currency string) []int32 {
+	var lines []int32
+	for _, row := range rows {
+		if row.Currency != nil && *row.Currency != "" && !strings.EqualFold(*row.Currency, currency) {
+			lines = append(lines, row.Line)
+		}
+	}
+
+	return lines
+}
+
+func joinLines(lines []int32) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts = append(parts, strconv.Itoa(int(line)))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+)
+
+// fakeImportRepo holds a single staged import
+type fakeImportRepo struct {
+	trscRepo.Transactions
+
+	imp  repository.TransactionImport
+	rows []repository.StagedTransaction
+}
+
+func (f *fakeImportRepo) GetImport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (repository.TransactionImport, error) {
+	if id != f.imp.ID || userID != f.imp.UserID {
+		return repository.TransactionImport{}, pgx.ErrNoRows
+	}
+
+	return f.imp, nil
+}
+
+func (f *fakeImportRepo) ListStagedTransactions(ctx context.Context, importID uuid.UUID) ([]repository.StagedTransaction, error) {
+	return f.rows, nil
+}
+
+func TestForeignCurrencyLines(t *testing.T) {
+	usd := "USD"
+	eur := "eur"
+	empty := ""
+
+	rows := []repository.StagedTransaction{
+		{Line: 2},
+		{Line: 3, Currency: &eur},
+		{Line: 4, Currency: &usd},
+		{Line: 5, Currency: &empty},
+		{Line: 6, Currency: &usd},
+	}
+
+	lines := foreignCurrencyLines(rows, "EUR")
+	if len(lines) != 2 || lines[0] != 4 || lines[1] != 6 {
+		t.Errorf("Expected lines 4 and 6, got %v", lines)
+	}
+
+	if lines := foreignCurrencyLines(rows[:2], "EUR"); len(lines) != 0 {
+		t.Errorf("Expected no lines, got %v", lines)
+	}
+}
+
+func TestCommitImport_ForeignCurrency(t *testing.T) {
+	logger := zerolog.Nop()
+	userID := uuid.New()
+	accountID := uuid.New()
+	usd := "USD"
+
+	usdLine := repository.StagedTransaction{ID: uuid.New(), Line: 3, Currency: &usd}
+	duplicate := uuid.New()
+
+	imports := &fakeImportRepo{
+		imp: repository.TransactionImport{ID: uuid.New(), UserID: userID, AccountID: accountID, Status: transactions.ImportStatusStaged},
+		rows: []repository.StagedTransaction{
+			{ID: uuid.New(), Line: 2},
+			usdLine,
+			{ID: uuid.New(), Line: 4, Currency: &usd, DuplicateOf: &duplicate},
+		},
+	}
+
+	accounts := &fakeAccountRepo{
+		accounts: map[uuid.UUID]repository.GetAccountByIdRow{
+			accountID: {ID: accountID, Currency: "EUR", CreatedBy: &userID},
+		},
+		changes: map[uuid.UUID]decimal.Decimal{},
+	}
+
+	service := &TransactionService{trscRepo: imports, accRepo: accounts, logger: &logger}
+
+	// The duplicate is left out, only the line that would be committed is reported
+	_, err := service.CommitImport(context.Background(), imports.imp.ID, userID, transactions.CommitImportParams{CategoryID: uuid.New()})
+	if !errors.Is(err, transactions.ErrImportCurrency) {
+		t.Fatalf("Expected ErrImportCurrency, got %v", err)
+	}
+
+	if !strings.HasSuffix(err.Error(), "lines 3") {
+		t.Errorf("Expected the error to name line 3, got %q", err)
+	}
+
+	if len(accounts.changes) != 0 {
+		t.Errorf("Expected the balance to be left as it is, got %v", accounts.changes)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	"github.com/Fantasy-Programming/nuts/server/pkg/llm"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ParseAttachmentReceipt reads the receipt attached to a transaction into a draft. The draft
+// isn't saved, the client reviews it and updates the transaction with it
+func (t *TransactionService) ParseAttachmentReceipt(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, userTimezone *string) (*transactions.ReceiptDraft, error) {
+	att, content, err := t.ReadAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := t.trscRepo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrNoTransactions
+		}
+		return nil, err
+	}
+
+	res, err := t.llmService.ParseReceipt(ctx, llm.ReceiptRequest{
+		Document:     llm.Document{MimeType: att.MimeType, Data: content},
+		UserTimezone: userTimezone,
+		BaseCurrency: &transaction.TransactionCurrency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse receipt: %w", err)
+	}
+
+	return toReceiptDraft(att, res), nil
+}
+
+func toReceiptDraft(att *transactions.Attachment, res *llm.ReceiptResponse) *transactions.ReceiptDraft {
+	data := res.Transaction
+
+	details := transactions.Details{
+		TaxAmount: data.TaxAmount,
+		TipAmount: data.TipAmount,
+	}
+
+	if data.PaymentMedium != nil {
+		details.PaymentMedium = *data.PaymentMedium
+	}
+	if data.Location != nil {
+		details.Location = *data.Location
+	}
+	if data.Note != nil {
+		details.Note = *data.Note
+	}
+
+	for _, item := range data.LineItems {
+		details.SubTransactions = append(details.SubTransactions, transactions.SubTransaction{
+			Description: item.Description,
+			Amount:      item.Amount,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+
+	return &transactions.ReceiptDraft{
+		AttachmentID:        att.ID,
+		TransactionID:       att.TransactionID,
+		Amount:              data.Amount,
+		Type:                data.Type,
+		Description:         data.Description,
+		CategoryHint:        data.CategoryHint,
+		MerchantName:        data.MerchantName,
+		TransactionDatetime: data.TransactionDatetime,
+		CurrencyCode:        data.CurrencyCode,
+		Details:             details,
+		Confidence:          data.Confidence,
+		Model:               res.Model,
+		Provider:            res.Provider,
+		Source:              res.Source,
+		ParsedAt:            res.ParsedAt,
+	}
+}
@@ -2,12 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	accRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/accounts/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shopspring/decimal"
 )
@@ -18,10 +25,396 @@ func (t *TransactionService) CreateRecurringTransaction(ctx context.Context, req
 		return nil, err
 	}
 
+	if err := t.checkAccountOwnership(ctx, req.AccountID, userID, transactions.ErrSrcAccNotFound); err != nil {
+		return nil, err
+	}
+
+	if req.DestinationAccountID != nil {
+		if err := t.checkAccountOwnership(ctx, *req.DestinationAccountID, userID, transactions.ErrDestAccNotFound); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the recurring transaction
 	return t.trscRepo.CreateRecurringTransaction(ctx, req, userID)
 }
 
+func (t *TransactionService) GetRecurringTransactionByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.RecurringTransaction, error) {
+	rt, err := t.trscRepo.GetRecurringTransactionByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRecurringNotFound
+		}
+		return nil, err
+	}
+
+	rt.Status = recurringStatus(rt, time.Now())
+
+	return rt, nil
+}
+
+func (t *TransactionService) ListRecurringTransactions(ctx context.Context, userID uuid.UUID, filters transactions.RecurringTransactionFilters) ([]transactions.RecurringTransaction, error) {
+	recurrings, err := t.trscRepo.ListRecurringTransactions(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range recurrings {
+		recurrings[i].Status = recurringStatus(&recurrings[i], now)
+	}
+
+	return recurrings, nil
+}
+
+// UpdateRecurringTransaction updates the template. Changes apply to the
+// pending and future instances, already posted transactions are left untouched.
+func (t *TransactionService) UpdateRecurringTransaction(ctx context.Context, id uuid.UUID, req transactions.UpdateRecurringTransactionRequest, userID uuid.UUID) (*transactions.RecurringTransaction, error) {
+	if req.UpdateMode == "next_only" {
+		return nil, transactions.ErrUnsupportedUpdateMode
+	}
+
+	existing, err := t.GetRecurringTransactionByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate := existing.StartDate
+	if req.StartDate != nil {
+		startDate = *req.StartDate
+	}
+
+	if req.EndDate != nil && req.EndDate.Before(startDate) {
+		return nil, fmt.Errorf("%w: end date must be after start date", transactions.ErrInvalidRecurring)
+	}
+
+	if req.AccountID != nil {
+		if err := t.checkAccountOwnership(ctx, *req.AccountID, userID, transactions.ErrSrcAccNotFound); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DestinationAccountID != nil {
+		if err := t.checkAccountOwnership(ctx, *req.DestinationAccountID, userID, transactions.ErrDestAccNotFound); err != nil {
+			return nil, err
+		}
+	}
+
+	rt, err := t.trscRepo.UpdateRecurringTransaction(ctx, id, req, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRecurringNotFound
+		}
+		return nil, err
+	}
+
+	// Nothing was generated yet, the series restarts from its (new) start date
+	if req.StartDate != nil && rt.OccurrencesCount == 0 && !rt.NextDueDate.Equal(*req.StartDate) {
+		rt, err = t.trscRepo.SetRecurringNextDueDate(ctx, id, *req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rt.Status = recurringStatus(rt, time.Now())
+
+	return rt, nil
+}
+
+func (t *TransactionService) DeleteRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := t.GetRecurringTransactionByID(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return t.trscRepo.DeleteRecurringTransaction(ctx, id, userID)
+}
+
+func (t *TransactionService) PauseRecurringTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID, isPaused bool) (*transactions.RecurringTransaction, error) {
+	rt, err := t.trscRepo.PauseRecurringTransaction(ctx, id, userID, isPaused)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRecurringNotFound
+		}
+		return nil, err
+	}
+
+	rt.Status = recurringStatus(rt, time.Now())
+
+	return rt, nil
+}
+
+func (t *TransactionService) GetRecurringTransactionStats(ctx context.Context, userID uuid.UUID) (*transactions.RecurringTransactionStats, error) {
+	return t.trscRepo.GetRecurringTransactionStats(ctx, userID)
+}
+
+func (t *TransactionService) GetRecurringTransactionInstances(ctx context.Context, userID uuid.UUID, recurringID uuid.UUID) ([]repository.Transaction, error) {
+	if _, err := t.GetRecurringTransactionByID(ctx, recurringID, userID); err != nil {
+		return nil, err
+	}
+
+	return t.trscRepo.GetRecurringTransactionInstances(ctx, userID, recurringID)
+}
+
+// ProcessRecurringInstance acts on the pending instance of a recurring transaction:
+// "post" creates it from the template, "modify" creates it with the given overrides
+// and "skip" drops it. In every case the template moves to its next due date.
+func (t *TransactionService) ProcessRecurringInstance(ctx context.Context, id uuid.UUID, userID uuid.UUID, req transactions.ProcessRecurringTransactionRequest) (*transactions.ProcessRecurringTransactionResponse, error) {
+	rt, err := t.GetRecurringTransactionByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dueDate, err := pendingInstance(rt, req.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	nextDueDate := t.nextOccurrence(rt, dueDate)
+
+	res := &transactions.ProcessRecurringTransactionResponse{
+		Action:  req.Action,
+		DueDate: dueDate,
+	}
+
+	if req.Action == "skip" {
+		rt, err = t.trscRepo.SkipRecurringInstance(ctx, id, dueDate, nextDueDate)
+		if err != nil {
+			// Posted or skipped by a concurrent request or the daily job
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, transactions.ErrInstanceNotPending
+			}
+			return nil, err
+		}
+
+		rt.Status = recurringStatus(rt, time.Now())
+		res.Recurring = rt
+
+		return res, nil
+	}
+
+	params, destinationAccountID, err := t.buildRecurringInstanceParams(ctx, rt, dueDate, userID, req.TransactionRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			t.logger.Error().Err(rbErr).Msg("Failed to rollback recurring instance")
+		}
+	}()
+
+	transaction, err := postRecurringInstance(ctx, t.trscRepo.WithTx(tx), t.accRepo.WithTx(tx), id, dueDate, nextDueDate, params, destinationAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	rt, err = t.GetRecurringTransactionByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Transaction = &transaction
+	res.Recurring = rt
+
+	return res, nil
+}
+
+// pendingInstance returns the due date of the instance a template can act on, dueDate when
+// given must be the pending one
+func pendingInstance(rt *transactions.RecurringTransaction, dueDate *time.Time) (time.Time, error) {
+	if rt.IsPaused {
+		return time.Time{}, transactions.ErrRecurringPaused
+	}
+
+	if rt.Status == "completed" {
+		return time.Time{}, transactions.ErrRecurringCompleted
+	}
+
+	if dueDate != nil && !sameDay(*dueDate, rt.NextDueDate) {
+		return time.Time{}, transactions.ErrInstanceNotPending
+	}
+
+	return rt.NextDueDate, nil
+}
+
+// postRecurringInstance creates the transaction of the instance due at dueDate and moves the
+// template to nextDueDate, it should run in a database transaction. The template is moved first
+// so a concurrent post of the same instance waits on its row and then fails with
+// ErrInstanceNotPending instead of creating the transaction twice
+func postRecurringInstance(ctx context.Context, trxRepo trscRepo.Transactions, accRepo accRepo.Account, id uuid.UUID, dueDate, nextDueDate time.Time, params repository.CreateTransactionParams, destinationAccountID *uuid.UUID) (repository.Transaction, error) {
+	if err := trxRepo.MarkRecurringInstanceGenerated(ctx, id, dueDate, nextDueDate); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Transaction{}, transactions.ErrInstanceNotPending
+		}
+		return repository.Transaction{}, err
+	}
+
+	transaction, err := trxRepo.CreateTransaction(ctx, params)
+	if err != nil {
+		return transaction, err
+	}
+
+	if err = accRepo.UpdateAccountBalance(ctx, repository.UpdateAccountBalanceParams{
+		ID:      params.AccountID,
+		Balance: decimal.NewNullDecimal(params.Amount),
+	}); err != nil {
+		return transaction, err
+	}
+
+	if destinationAccountID != nil {
+		if err = accRepo.UpdateAccountBalance(ctx, repository.UpdateAccountBalanceParams{
+			ID:      *destinationAccountID,
+			Balance: decimal.NewNullDecimal(params.Amount.Abs()),
+		}); err != nil {
+			return transaction, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// buildRecurringInstanceParams turns a template into the params of the transaction to post,
+// applying the overrides of a "modify" action when given
+func (t *TransactionService) buildRecurringInstanceParams(ctx context.Context, rt *transactions.RecurringTransaction, dueDate time.Time, userID uuid.UUID, override *transactions.CreateTransactionRequest) (repository.CreateTransactionParams, *uuid.UUID, error) {
+	accountID := rt.AccountID
+	categoryID := rt.CategoryID
+	amount := rt.Amount
+	txType := rt.Type
+	description := rt.Description
+	datetime := dueDate
+	var details *dto.Details
+
+	if override != nil {
+		if override.AccountID != "" {
+			parsed, err := uuid.Parse(override.AccountID)
+			if err != nil {
+				return repository.CreateTransactionParams{}, nil, fmt.Errorf("%w: invalid account id", transactions.ErrInvalidRecurring)
+			}
+			accountID = parsed
+		}
+
+		if override.CategoryID != "" {
+			parsed, err := uuid.Parse(override.CategoryID)
+			if err != nil {
+				return repository.CreateTransactionParams{}, nil, fmt.Errorf("%w: invalid category id", transactions.ErrInvalidRecurring)
+			}
+			categoryID = &parsed
+		}
+
+		if override.Amount != 0 {
+			amount = decimal.NewFromFloat(override.Amount).Abs()
+		}
+
+		if override.Type != "" {
+			txType = override.Type
+		}
+
+		if override.Description != nil {
+			description = override.Description
+		}
+
+		if !override.TransactionDatetime.IsZero() {
+			datetime = override.TransactionDatetime
+		}
+
+		details = &override.Details
+	}
+
+	if categoryID == nil {
+		return repository.CreateTransactionParams{}, nil, transactions.ErrMissingCategory
+	}
+
+	account, err := t.accRepo.GetAccountByID(ctx, accountID)
+	if err != nil || account.CreatedBy == nil || *account.CreatedBy != userID {
+		return repository.CreateTransactionParams{}, nil, transactions.ErrSrcAccNotFound
+	}
+
+	// Amounts are stored signed: money leaving the account is negative
+	var destinationAccountID *uuid.UUID
+	switch txType {
+	case "expense":
+		amount = amount.Neg()
+	case "transfer":
+		if rt.DestinationAccountID == nil {
+			return repository.CreateTransactionParams{}, nil, transactions.ErrDestAccNotFound
+		}
+		if *rt.DestinationAccountID == accountID {
+			return repository.CreateTransactionParams{}, nil, transactions.ErrSameAccount
+		}
+		destinationAccountID = rt.DestinationAccountID
+		amount = amount.Neg()
+	}
+
+	isExternal := false
+	recurringID := rt.ID
+
+	return repository.CreateTransactionParams{
+		Amount:                 amount,
+		Type:                   txType,
+		AccountID:              accountID,
+		DestinationAccountID:   destinationAccountID,
+		CategoryID:             categoryID,
+		Description:            description,
+		TransactionDatetime:    pgtype.Timestamptz{Valid: true, Time: datetime},
+		TransactionCurrency:    account.Currency,
+		OriginalAmount:         amount,
+		Details:                details,
+		IsExternal:             &isExternal,
+		CreatedBy:              &userID,
+		RecurringTransactionID: &recurringID,
+		RecurringInstanceDate:  pgtype.Timestamptz{Valid: true, Time: dueDate},
+	}, destinationAccountID, nil
+}
+
+func (t *TransactionService) checkAccountOwnership(ctx context.Context, rawID string, userID uuid.UUID, notFound error) error {
+	accountID, err := uuid.Parse(rawID)
+	if err != nil {
+		return notFound
+	}
+
+	account, err := t.accRepo.GetAccountByID(ctx, accountID)
+	if err != nil || account.CreatedBy == nil || *account.CreatedBy != userID {
+		return notFound
+	}
+
+	return nil
+}
+
+// recurringStatus computes the display status of a template
+func recurringStatus(rt *transactions.RecurringTransaction, now time.Time) string {
+	if rt.MaxOccurrences != nil && rt.OccurrencesCount >= *rt.MaxOccurrences {
+		return "completed"
+	}
+
+	if rt.EndDate != nil && rt.NextDueDate.After(*rt.EndDate) {
+		if rt.EndDate.Before(now) {
+			return "expired"
+		}
+		return "completed"
+	}
+
+	if rt.IsPaused {
+		return "paused"
+	}
+
+	return "active"
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
 // GenerateNextDueDate calculates the next due date based on frequency and pattern
 func (s *TransactionService) GenerateNextDueDate(rt *transactions.RecurringTransaction) time.Time {
 	baseDate := rt.NextDueDate
@@ -29,6 +422,11 @@ func (s *TransactionService) GenerateNextDueDate(rt *transactions.RecurringTrans
 		baseDate = *rt.LastGeneratedDate
 	}
 
+	return s.nextOccurrence(rt, baseDate)
+}
+
+// nextOccurrence returns the occurrence following baseDate
+func (s *TransactionService) nextOccurrence(rt *transactions.RecurringTransaction, baseDate time.Time) time.Time {
 	switch rt.Frequency {
 	case "daily":
 		return baseDate.AddDate(0, 0, rt.FrequencyInterval)
@@ -165,78 +563,132 @@ func (s *TransactionService) ProcessDueRecurringTransactions(ctx context.Context
 	return nil
 }
 
+// GetRecurringInstances builds the calendar of the user's recurring transactions between two dates:
+// instances already posted are read from the transactions, upcoming ones are projected from the templates.
 func (s *TransactionService) GetRecurringInstances(ctx context.Context, userID uuid.UUID, req transactions.GetRecurringInstancesRequest) (*transactions.RecurringInstancesResponse, error) {
+	if req.EndDate.Before(req.StartDate) {
+		return nil, fmt.Errorf("%w: end date must be after start date", transactions.ErrInvalidRecurring)
+	}
+
 	recurringTransactions, err := s.trscRepo.ListRecurringTransactions(ctx, userID, transactions.RecurringTransactionFilters{})
 	if err != nil {
 		return nil, err
 	}
 
-	var instances []transactions.RecurringInstance
+	instances := []transactions.RecurringInstance{}
 	totalAmount := decimal.Zero
 	pendingCount := 0
 	postedCount := 0
 
 	for _, rt := range recurringTransactions {
-		if rt.IsPaused {
+		posted, err := s.trscRepo.GetRecurringTransactionInstances(ctx, userID, rt.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, trx := range posted {
+			dueDate := trx.TransactionDatetime
+			if trx.RecurringInstanceDate != nil {
+				dueDate = *trx.RecurringInstanceDate
+			}
+
+			if dueDate.Before(req.StartDate) || dueDate.After(req.EndDate) {
+				continue
+			}
+
+			transactionID := trx.ID
+			amount := types.PgtypeNumericToDecimal(trx.Amount).Abs()
+
+			instances = append(instances, transactions.RecurringInstance{
+				RecurringTransactionID: rt.ID,
+				TemplateName:           rt.TemplateName,
+				DueDate:                dueDate,
+				Amount:                 amount,
+				Description:            trx.Description,
+				TransactionID:          &transactionID,
+				Status:                 "posted",
+				IsProjected:            false,
+				CanModify:              false,
+			})
+			totalAmount = totalAmount.Add(amount)
+			postedCount++
+		}
+
+		if rt.IsPaused || !req.IncludeProjected {
 			continue
 		}
 
-		// Generate instances for this recurring transaction within the date range
+		// Project the pending and future instances of this template within the date range
+		occurrences := rt.OccurrencesCount
 		currentDate := rt.NextDueDate
-		for currentDate.Before(req.EndDate) || currentDate.Equal(req.EndDate) {
-			if currentDate.After(req.StartDate) || currentDate.Equal(req.StartDate) {
-				instance := transactions.RecurringInstance{
-					DueDate:     currentDate,
-					Amount:      rt.Amount,
-					Description: rt.Description,
-					Status:      "pending",
-					IsProjected: true,
-					CanModify:   true,
-				}
-				instances = append(instances, instance)
+		for !currentDate.After(req.EndDate) {
+			if rt.EndDate != nil && currentDate.After(*rt.EndDate) {
+				break
+			}
+
+			if rt.MaxOccurrences != nil && occurrences >= *rt.MaxOccurrences {
+				break
+			}
+
+			if !currentDate.Before(req.StartDate) {
+				instances = append(instances, transactions.RecurringInstance{
+					RecurringTransactionID: rt.ID,
+					TemplateName:           rt.TemplateName,
+					DueDate:                currentDate,
+					Amount:                 rt.Amount,
+					Description:            rt.Description,
+					Status:                 "pending",
+					IsProjected:            true,
+					// Only the next due instance can be posted, skipped or modified
+					CanModify: currentDate.Equal(rt.NextDueDate),
+				})
 				totalAmount = totalAmount.Add(rt.Amount)
 				pendingCount++
 			}
 
-			// Calculate next date
-			rt.NextDueDate = currentDate
-			currentDate = s.GenerateNextDueDate(&rt)
+			occurrences++
+			currentDate = s.nextOccurrence(&rt, currentDate)
 		}
 	}
 
-	return &transactions.RecurringInstancesResponse{
-		Instances: instances,
-		Summary: struct {
-			TotalCount   int             `json:"total_count"`
-			PendingCount int             `json:"pending_count"`
-			PostedCount  int             `json:"posted_count"`
-			SkippedCount int             `json:"skipped_count"`
-			TotalAmount  decimal.Decimal `json:"total_amount"`
-		}{
-			TotalCount:   len(instances),
-			PendingCount: pendingCount,
-			PostedCount:  postedCount,
-			SkippedCount: 0,
-			TotalAmount:  totalAmount,
-		},
-	}, nil
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].DueDate.Before(instances[j].DueDate)
+	})
+
+	res := &transactions.RecurringInstancesResponse{Instances: instances}
+	res.Summary.TotalCount = len(instances)
+	res.Summary.PendingCount = pendingCount
+	res.Summary.PostedCount = postedCount
+	res.Summary.TotalAmount = totalAmount
+
+	return res, nil
 }
 
 func (s *TransactionService) ValidateRecurringTransaction(req transactions.CreateRecurringTransactionRequest) error {
 	if req.Amount.IsZero() || req.Amount.IsNegative() {
-		return fmt.Errorf("amount must be positive")
+		return fmt.Errorf("%w: amount must be positive", transactions.ErrInvalidRecurring)
 	}
 
 	if req.FrequencyInterval < 1 {
-		return fmt.Errorf("frequency interval must be at least 1")
+		return fmt.Errorf("%w: frequency interval must be at least 1", transactions.ErrInvalidRecurring)
 	}
 
 	if req.EndDate != nil && req.EndDate.Before(req.StartDate) {
-		return fmt.Errorf("end date must be after start date")
+		return fmt.Errorf("%w: end date must be after start date", transactions.ErrInvalidRecurring)
 	}
 
 	if req.MaxOccurrences != nil && *req.MaxOccurrences < 1 {
-		return fmt.Errorf("max occurrences must be at least 1")
+		return fmt.Errorf("%w: max occurrences must be at least 1", transactions.ErrInvalidRecurring)
+	}
+
+	if req.Type == "transfer" {
+		if req.DestinationAccountID == nil {
+			return fmt.Errorf("%w: a transfer needs a destination account", transactions.ErrInvalidRecurring)
+		}
+
+		if *req.DestinationAccountID == req.AccountID {
+			return transactions.ErrSameAccount
+		}
 	}
 
 	return nil
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	accRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/accounts/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+)
+
+// fakeRecurringRepo keeps a single template, moving it only from the due date it's at like the
+// conditional updates of the database
+type fakeRecurringRepo struct {
+	trscRepo.Transactions
+
+	rt      transactions.RecurringTransaction
+	created []repository.CreateTransactionParams
+}
+
+func (f *fakeRecurringRepo) WithTx(tx pgx.Tx) trscRepo.Transactions {
+	return f
+}
+
+func (f *fakeRecurringRepo) GetRecurringTransactionByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.RecurringTransaction, error) {
+	if id != f.rt.ID || userID != f.rt.UserID {
+		return nil, pgx.ErrNoRows
+	}
+
+	rt := f.rt
+	return &rt, nil
+}
+
+func (f *fakeRecurringRepo) SkipRecurringInstance(ctx context.Context, id uuid.UUID, dueDate, nextDueDate time.Time) (*transactions.RecurringTransaction, error) {
+	if id != f.rt.ID || !f.rt.NextDueDate.Equal(dueDate) {
+		return nil, pgx.ErrNoRows
+	}
+
+	f.rt.NextDueDate = nextDueDate
+	rt := f.rt
+	return &rt, nil
+}
+
+func (f *fakeRecurringRepo) MarkRecurringInstanceGenerated(ctx context.Context, id uuid.UUID, generatedDate, nextDueDate time.Time) error {
+	if id != f.rt.ID || !f.rt.NextDueDate.Equal(generatedDate) {
+		return pgx.ErrNoRows
+	}
+
+	f.rt.LastGeneratedDate = &generatedDate
+	f.rt.NextDueDate = nextDueDate
+	f.rt.OccurrencesCount++
+	return nil
+}
+
+func (f *fakeRecurringRepo) CreateTransaction(ctx context.Context, params repository.CreateTransactionParams) (repository.Transaction, error) {
+	f.created = append(f.created, params)
+	return repository.Transaction{ID: uuid.New(), AccountID: params.AccountID, Type: params.Type}, nil
+}
+
+// fakeAccountRepo holds the accounts of the tests and the balance changes made to them
+type fakeAccountRepo struct {
+	accRepo.Account
+
+	accounts map[uuid.UUID]repository.GetAccountByIdRow
+	changes  map[uuid.UUID]decimal.Decimal
+}
+
+func (f *fakeAccountRepo) WithTx(tx pgx.Tx) accRepo.Account {
+	return f
+}
+
+func (f *fakeAccountRepo) GetAccountByID(ctx context.Context, id uuid.UUID) (repository.GetAccountByIdRow, error) {
+	account, ok := f.accounts[id]
+	if !ok {
+		return account, pgx.ErrNoRows
+	}
+
+	return account, nil
+}
+
+func (f *fakeAccountRepo) UpdateAccountBalance(ctx context.Context, params repository.UpdateAccountBalanceParams) error {
+	f.changes[params.ID] = f.changes[params.ID].Add(params.Balance.Decimal)
+	return nil
+}
+
+type recurringFixture struct {
+	service     *TransactionService
+	recurring   *fakeRecurringRepo
+	accounts    *fakeAccountRepo
+	userID      uuid.UUID
+	checking    uuid.UUID
+	savings     uuid.UUID
+	someoneElse uuid.UUID
+}
+
+func newRecurringFixture() *recurringFixture {
+	logger := zerolog.Nop()
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	category := uuid.New()
+	description := "Rent"
+
+	f := &recurringFixture{
+		userID:      userID,
+		checking:    uuid.New(),
+		savings:     uuid.New(),
+		someoneElse: uuid.New(),
+	}
+
+	f.recurring = &fakeRecurringRepo{rt: transactions.RecurringTransaction{
+		ID:                uuid.New(),
+		UserID:            userID,
+		AccountID:         f.checking,
+		CategoryID:        &category,
+		Amount:            decimal.NewFromInt(1200),
+		Type:              "expense",
+		Description:       &description,
+		Frequency:         "monthly",
+		FrequencyInterval: 1,
+		StartDate:         time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NextDueDate:       time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	f.accounts = &fakeAccountRepo{
+		accounts: map[uuid.UUID]repository.GetAccountByIdRow{
+			f.checking:    {ID: f.checking, Currency: "EUR", CreatedBy: &userID},
+			f.savings:     {ID: f.savings, Currency: "EUR", CreatedBy: &userID},
+			f.someoneElse: {ID: f.someoneElse, Currency: "EUR", CreatedBy: &otherUserID},
+		},
+		changes: map[uuid.UUID]decimal.Decimal{},
+	}
+
+	f.service = &TransactionService{trscRepo: f.recurring, accRepo: f.accounts, logger: &logger}
+
+	return f
+}
+
+func TestRecurringStatus(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+	two := 2
+	past := now.AddDate(0, -1, 0)
+	future := now.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name string
+		rt   transactions.RecurringTransaction
+		want string
+	}{
+		{"active", transactions.RecurringTransaction{NextDueDate: now}, "active"},
+		{"paused", transactions.RecurringTransaction{NextDueDate: now, IsPaused: true}, "paused"},
+		{"all occurrences posted", transactions.RecurringTransaction{NextDueDate: now, MaxOccurrences: &two, OccurrencesCount: 2, IsPaused: true}, "completed"},
+		{"occurrences left", transactions.RecurringTransaction{NextDueDate: now, MaxOccurrences: &two, OccurrencesCount: 1}, "active"},
+		{"ended in the past", transactions.RecurringTransaction{NextDueDate: now, EndDate: &past}, "expired"},
+		{"last instance posted before the end", transactions.RecurringTransaction{NextDueDate: future.AddDate(0, 1, 0), EndDate: &future}, "completed"},
+		{"ends later", transactions.RecurringTransaction{NextDueDate: now, EndDate: &future}, "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recurringStatus(&tt.rt, now); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPendingInstance(t *testing.T) {
+	due := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	sameDay := due.Add(15 * time.Hour)
+	nextMonth := due.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		rt      transactions.RecurringTransaction
+		dueDate *time.Time
+		err     error
+	}{
+		{"pending one", transactions.RecurringTransaction{NextDueDate: due}, nil, nil},
+		{"pending one given", transactions.RecurringTransaction{NextDueDate: due}, &sameDay, nil},
+		{"another instance", transactions.RecurringTransaction{NextDueDate: due}, &nextMonth, transactions.ErrInstanceNotPending},
+		{"paused", transactions.RecurringTransaction{NextDueDate: due, IsPaused: true}, nil, transactions.ErrRecurringPaused},
+		{"completed", transactions.RecurringTransaction{NextDueDate: due, Status: "completed"}, nil, transactions.ErrRecurringCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pendingInstance(&tt.rt, tt.dueDate)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+
+			if err == nil && !got.Equal(due) {
+				t.Errorf("Expected the instance due at %v, got %v", due, got)
+			}
+		})
+	}
+}
+
+func TestBuildRecurringInstanceParams(t *testing.T) {
+	ctx := context.Background()
+	due := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("post", func(t *testing.T) {
+		f := newRecurringFixture()
+
+		params, destination, err := f.service.buildRecurringInstanceParams(ctx, &f.recurring.rt, due, f.userID, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !params.Amount.Equal(decimal.NewFromInt(-1200)) || params.Type != "expense" || params.AccountID != f.checking {
+			t.Errorf("Expected an expense of -1200 on the checking account, got %s of %s on %s", params.Type, params.Amount, params.AccountID)
+		}
+
+		if params.TransactionCurrency != "EUR" || !params.TransactionDatetime.Time.Equal(due) || !params.RecurringInstanceDate.Time.Equal(due) {
+			t.Errorf("Expected a EUR transaction on the due date, got %s on %v", params.TransactionCurrency, params.TransactionDatetime.Time)
+		}
+
+		if params.RecurringTransactionID == nil || *params.RecurringTransactionID != f.recurring.rt.ID || destination != nil {
+			t.Errorf("Expected the transaction to be linked to its template only")
+		}
+	})
+
+	t.Run("modify", func(t *testing.T) {
+		f := newRecurringFixture()
+		category := uuid.New()
+		description := "Rent and parking"
+		paidOn := due.AddDate(0, 0, 2)
+
+		params, _, err := f.service.buildRecurringInstanceParams(ctx, &f.recurring.rt, due, f.userID, &transactions.CreateTransactionRequest{
+			AccountID:           f.savings.String(),
+			CategoryID:          category.String(),
+			Amount:              -1250,
+			Description:         &description,
+			TransactionDatetime: paidOn,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if params.AccountID != f.savings || params.CategoryID == nil || *params.CategoryID != category {
+			t.Errorf("Expected the account and category to be overridden")
+		}
+
+		if !params.Amount.Equal(decimal.NewFromInt(-1250)) || *params.Description != description {
+			t.Errorf("Expected an expense of -1250 described %q, got %s %v", description, params.Amount, params.Description)
+		}
+
+		if !params.TransactionDatetime.Time.Equal(paidOn) || !params.RecurringInstanceDate.Time.Equal(due) {
+			t.Errorf("Expected the transaction on %v for the instance due at %v", paidOn, due)
+		}
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		f := newRecurringFixture()
+		f.recurring.rt.Type = "transfer"
+		f.recurring.rt.DestinationAccountID = &f.savings
+
+		params, destination, err := f.service.buildRecurringInstanceParams(ctx, &f.recurring.rt, due, f.userID, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if destination == nil || *destination != f.savings || !params.Amount.IsNegative() {
+			t.Errorf("Expected a negative transfer to the savings account, got %s to %v", params.Amount, destination)
+		}
+	})
+
+	errorTests := []struct {
+		name     string
+		change   func(f *recurringFixture)
+		override *transactions.CreateTransactionRequest
+		err      error
+	}{
+		{"no category", func(f *recurringFixture) { f.recurring.rt.CategoryID = nil }, nil, transactions.ErrMissingCategory},
+		{"account of another user", func(f *recurringFixture) { f.recurring.rt.AccountID = f.someoneElse }, nil, transactions.ErrSrcAccNotFound},
+		{"invalid account override", func(f *recurringFixture) {}, &transactions.CreateTransactionRequest{AccountID: "checking"}, transactions.ErrInvalidRecurring},
+		{"transfer without destination", func(f *recurringFixture) { f.recurring.rt.Type = "transfer" }, nil, transactions.ErrDestAccNotFound},
+		{"transfer to the same account", func(f *recurringFixture) {
+			f.recurring.rt.Type = "transfer"
+			f.recurring.rt.DestinationAccountID = &f.checking
+		}, nil, transactions.ErrSameAccount},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRecurringFixture()
+			tt.change(f)
+
+			_, _, err := f.service.buildRecurringInstanceParams(ctx, &f.recurring.rt, due, f.userID, tt.override)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestProcessRecurringInstance_Skip(t *testing.T) {
+	ctx := context.Background()
+	f := newRecurringFixture()
+	due := f.recurring.rt.NextDueDate
+
+	res, err := f.service.ProcessRecurringInstance(ctx, f.recurring.rt.ID, f.userID, transactions.ProcessRecurringTransactionRequest{Action: "skip"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !res.DueDate.Equal(due) || !res.Recurring.NextDueDate.Equal(due.AddDate(0, 1, 0)) {
+		t.Errorf("Expected the instance of %v to be skipped for the next month, got %v", due, res.Recurring.NextDueDate)
+	}
+
+	if len(f.recurring.created) != 0 || f.recurring.rt.OccurrencesCount != 0 {
+		t.Errorf("Expected a skipped instance not to be posted")
+	}
+
+	// Skipping the same instance again, as a concurrent request would
+	_, err = f.service.ProcessRecurringInstance(ctx, f.recurring.rt.ID, f.userID, transactions.ProcessRecurringTransactionRequest{Action: "skip", DueDate: &due})
+	if !errors.Is(err, transactions.ErrInstanceNotPending) {
+		t.Errorf("Expected ErrInstanceNotPending, got %v", err)
+	}
+}
+
+func TestPostRecurringInstance(t *testing.T) {
+	ctx := context.Background()
+	f := newRecurringFixture()
+	f.recurring.rt.Type = "transfer"
+	f.recurring.rt.DestinationAccountID = &f.savings
+
+	due := f.recurring.rt.NextDueDate
+	next := f.service.nextOccurrence(&f.recurring.rt, due)
+
+	params, destination, err := f.service.buildRecurringInstanceParams(ctx, &f.recurring.rt, due, f.userID, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := postRecurringInstance(ctx, f.recurring, f.accounts, f.recurring.rt.ID, due, next, params, destination); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(f.recurring.created) != 1 || f.recurring.rt.OccurrencesCount != 1 || !f.recurring.rt.NextDueDate.Equal(next) {
+		t.Errorf("Expected one instance posted and the template due at %v, got %d posted due at %v", next, len(f.recurring.created), f.recurring.rt.NextDueDate)
+	}
+
+	if !f.accounts.changes[f.checking].Equal(decimal.NewFromInt(-1200)) || !f.accounts.changes[f.savings].Equal(decimal.NewFromInt(1200)) {
+		t.Errorf("Expected 1200 to move from checking to savings, got %v", f.accounts.changes)
+	}
+
+	// A concurrent post of the same instance, read before the first one moved the template
+	_, err = postRecurringInstance(ctx, f.recurring, f.accounts, f.recurring.rt.ID, due, next, params, destination)
+	if !errors.Is(err, transactions.ErrInstanceNotPending) {
+		t.Fatalf("Expected ErrInstanceNotPending, got %v", err)
+	}
+
+	if len(f.recurring.created) != 1 || !f.accounts.changes[f.checking].Equal(decimal.NewFromInt(-1200)) {
+		t.Errorf("Expected the instance to be posted once, got %d", len(f.recurring.created))
+	}
+}
@@ -2,16 +2,24 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
 	internalRepo "github.com/Fantasy-Programming/nuts/server/internal/repository"
-	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 )
 
+// Transactions listed in the preview of a rule application, the counts cover all of them
+const rulePreviewLimit = 500
+
 func (s *TransactionService) CreateRule(ctx context.Context, req transactions.CreateTransactionRuleRequest, userID uuid.UUID) (*transactions.TransactionRule, error) {
 	params := repository.CreateRuleParams{
 		Name:       req.Name,
@@ -28,6 +36,8 @@ func (s *TransactionService) CreateRule(ctx context.Context, req transactions.Cr
 		return nil, fmt.Errorf("failed to create rule: %w", err)
 	}
 
+	s.publishRule(ctx, userID, events.RuleCreated, rule)
+
 	return rule, nil
 }
 
@@ -68,6 +78,8 @@ func (s *TransactionService) UpdateRule(ctx context.Context, id uuid.UUID, req t
 		return nil, fmt.Errorf("failed to update rule: %w", err)
 	}
 
+	s.publishRule(ctx, userID, events.RuleUpdated, rule)
+
 	return rule, nil
 }
 
@@ -78,6 +90,10 @@ func (s *TransactionService) DeleteRule(ctx context.Context, id uuid.UUID, userI
 		return fmt.Errorf("failed to delete rule: %w", err)
 	}
 
+	s.events.Publish(ctx, userID, events.RuleDeleted, events.RuleData{
+		Rule: events.Rule{ID: id},
+	})
+
 	return nil
 }
 
@@ -88,6 +104,8 @@ func (s *TransactionService) ToggleRuleActive(ctx context.Context, id uuid.UUID,
 		return nil, fmt.Errorf("failed to toggle rule: %w", err)
 	}
 
+	s.publishRule(ctx, userID, events.RuleUpdated, rule)
+
 	return rule, nil
 }
 
@@ -99,41 +117,64 @@ func (s *TransactionService) ApplyRulesToTransaction(ctx context.Context, transa
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 
+	location, err := rules.UserLocation(ctx, s.trscRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert transaction to TransactionData
-	transactionData := s.convertToTransactionData(transaction)
+	transactionData := s.convertToTransactionData(transaction, location)
 
 	// Get active rules for the user
-	rules, err := s.trscRepo.ListActiveRules(ctx, userID)
+	activeRules, err := s.trscRepo.ListActiveRules(ctx, userID)
 	if err != nil {
 		s.logger.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to get active rules")
 		return nil, fmt.Errorf("failed to get active rules: %w", err)
 	}
 
 	// Evaluate rules
-	matches, err := s.evaluator.EvaluateRules(rules, transactionData)
+	matches, err := s.evaluator.EvaluateRules(activeRules, transactionData)
 	if err != nil {
 		s.logger.Error().Err(err).Str("transaction_id", transactionID.String()).Msg("Failed to evaluate rules")
 		return nil, fmt.Errorf("failed to evaluate rules: %w", err)
 	}
 
-	// Apply the actions from the first matching rule (highest priority)
-	if len(matches) > 0 {
-		firstMatch := matches[0]
-		err = s.applyActionsToTransaction(ctx, transactionID, userID, firstMatch.Actions)
-		if err != nil {
-			s.logger.Error().Err(err).Str("transaction_id", transactionID.String()).Str("rule_id", firstMatch.RuleID.String()).Msg("Failed to apply rule actions")
-			return matches, fmt.Errorf("failed to apply rule actions: %w", err)
-		}
+	// Rules apply in priority order, the first one to set a field wins it
+	changes := rules.PlanMatches(transactionData, transaction.Details, matches)
+	if err := rules.Resolve(ctx, s.trscRepo, userID, transactionData, &changes, nil); err != nil {
+		s.logger.Error().Err(err).Str("transaction_id", transactionID.String()).Msg("Failed to resolve rule actions")
+		return matches, fmt.Errorf("failed to apply rule actions: %w", err)
+	}
+
+	if changes.Empty() {
+		return matches, nil
+	}
+
+	if err := s.applyChanges(ctx, transactionID, userID, matches, changes); err != nil {
+		s.logger.Error().Err(err).Str("transaction_id", transactionID.String()).Msg("Failed to apply rule actions")
+		return matches, fmt.Errorf("failed to apply rule actions: %w", err)
+	}
+
+	for _, match := range rules.ChangedBy(matches, changes) {
+		s.events.Publish(ctx, userID, events.RuleApplied, events.RuleAppliedData{
+			Rule: events.Rule{
+				ID:       match.RuleID,
+				Name:     match.RuleName,
+				IsActive: true,
+				Priority: match.RulePriority,
+			},
+			TransactionID: transactionID,
+		})
 	}
 
 	return matches, nil
 }
 
-func (s *TransactionService) convertToTransactionData(transaction internalRepo.Transaction) *transactions.TransactionData {
+func (s *TransactionService) convertToTransactionData(transaction internalRepo.Transaction, location *time.Location) *transactions.TransactionData {
 	// Convert amount to decimal
 	amount, _ := transaction.Amount.Float64Value()
 
-	return &transactions.TransactionData{
+	data := &transactions.TransactionData{
 		ID:                   transaction.ID,
 		Amount:               decimal.NewFromFloat(amount.Float64),
 		Type:                 transaction.Type,
@@ -146,58 +187,33 @@ func (s *TransactionService) convertToTransactionData(transaction internalRepo.T
 		TransactionDatetime:  transaction.TransactionDatetime,
 		TransactionCurrency:  transaction.TransactionCurrency,
 		IsExternal:           transaction.IsExternal != nil && *transaction.IsExternal,
-		Tags:                 []string{}, // This would need to be populated based on details
-	}
-}
-
-// applyActionsToTransaction applies rule actions to a transaction
-func (s *TransactionService) applyActionsToTransaction(ctx context.Context, transactionID uuid.UUID, userID uuid.UUID, actions []transactions.RuleAction) error {
-	updateParams := internalRepo.UpdateTransactionParams{
-		ID:        transactionID,
-		UpdatedBy: &userID,
-	}
-
-	var needsUpdate bool
-	var details *dto.Details
-
-	for _, action := range actions {
-		switch action.Type {
-		case transactions.ActionTypeSetCategory:
-			if categoryIDStr, ok := action.Value.(string); ok {
-				if categoryID, err := uuid.Parse(categoryIDStr); err == nil {
-					updateParams.CategoryID = &categoryID
-					needsUpdate = true
-				}
-			}
-		case transactions.ActionTypeSetDescription:
-			if description, ok := action.Value.(string); ok {
-				updateParams.Description = &description
-				needsUpdate = true
-			}
-		case transactions.ActionTypeSetNote:
-			if note, ok := action.Value.(string); ok {
-				if details == nil {
-					details = &dto.Details{}
-				}
-				details.Note = &note
-				needsUpdate = true
-			}
-		case transactions.ActionTypeSetTags:
-			// Tags would be stored in the details field
-			// This would require additional implementation based on how tags are stored
-			s.logger.Debug().Str("action_type", string(action.Type)).Msg("Tag setting not implemented yet")
-		}
+		Location:             location,
+	}
+	data.SetDetails(transaction.Details)
+
+	return data
+}
+
+// applyChanges saves the changes of rules on a transaction
+func (s *TransactionService) applyChanges(ctx context.Context, transactionID uuid.UUID, userID uuid.UUID, matches []transactions.RuleMatch, changes rules.Changes) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer s.rollback(ctx, tx)
 
-	if details != nil {
-		updateParams.Details = details
+	applied, err := rules.Apply(ctx, s.trscRepo.WithTx(tx), transactionID, userID, matches, changes)
+	if err != nil {
+		return err
 	}
 
-	if needsUpdate {
-		_, err := s.trscRepo.UpdateTransaction(ctx, updateParams)
-		if err != nil {
-			return fmt.Errorf("failed to update transaction: %w", err)
-		}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	s.publishTransaction(ctx, events.TransactionUpdated, applied.Transaction)
+	if applied.Deleted != nil {
+		s.publishTransaction(ctx, events.TransactionDeleted, *applied.Deleted)
 	}
 
 	return nil
@@ -220,3 +236,204 @@ func (s *TransactionService) AutoApplyRulesToNewTransaction(ctx context.Context,
 
 	return nil
 }
+
+// PreviewRuleApplication runs rules over existing transactions without saving anything, and
+// returns what each matching transaction would change
+func (s *TransactionService) PreviewRuleApplication(ctx context.Context, userID uuid.UUID, req transactions.ApplyRulesRequest) (*transactions.RuleApplicationPreview, error) {
+	selected, err := s.selectRules(ctx, userID, req.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := rules.UserLocation(ctx, s.trscRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned, planned, err := s.evaluator.Plan(ctx, s.trscRepo, userID, location, selected, req.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan rule application: %w", err)
+	}
+
+	preview := &transactions.RuleApplicationPreview{
+		Scanned:      scanned,
+		Matched:      len(planned),
+		Truncated:    len(planned) > rulePreviewLimit,
+		Transactions: []transactions.RuleChange{},
+	}
+
+	for _, p := range planned[:min(len(planned), rulePreviewLimit)] {
+		preview.Transactions = append(preview.Transactions, transactions.RuleChange{
+			TransactionID:       p.Transaction.ID,
+			Description:         p.Transaction.Description,
+			TransactionDatetime: p.Transaction.TransactionDatetime,
+			RuleID:              p.Matches[0].RuleID,
+			RuleName:            p.Matches[0].RuleName,
+			Changes:             p.Changes.Fields,
+		})
+	}
+
+	return preview, nil
+}
+
+// QueueRuleApplication checks the rules exist and applies them to existing transactions in the
+// background
+func (s *TransactionService) QueueRuleApplication(ctx context.Context, userID uuid.UUID, req transactions.ApplyRulesRequest) error {
+	if _, err := s.selectRules(ctx, userID, req.RuleIDs); err != nil {
+		return err
+	}
+
+	if err := s.jobs.EnqueueRuleApplication(ctx, userID, req.RuleIDs, req.Filters); err != nil {
+		return fmt.Errorf("failed to enqueue rule application: %w", err)
+	}
+
+	return nil
+}
+
+// ListRuleHistory returns the changes a rule made to transactions, the latest first
+func (s *TransactionService) ListRuleHistory(ctx context.Context, ruleID uuid.UUID, userID uuid.UUID, page, limit int) ([]transactions.RuleApplication, error) {
+	rows, err := s.trscRepo.ListRuleApplications(ctx, internalRepo.ListRuleApplicationsParams{
+		RuleID: ruleID,
+		UserID: userID,
+		Limit:  int64(limit),
+		Offset: int64((page - 1) * limit),
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("rule_id", ruleID.String()).Msg("Failed to list rule history")
+		return nil, fmt.Errorf("failed to list rule history: %w", err)
+	}
+
+	history := make([]transactions.RuleApplication, 0, len(rows))
+	for _, row := range rows {
+		application, err := toRuleApplication(internalRepo.TransactionRuleApplication{
+			ID:            row.ID,
+			RuleID:        row.RuleID,
+			TransactionID: row.TransactionID,
+			Actions:       row.Actions,
+			Changes:       row.Changes,
+			CreatedBy:     row.CreatedBy,
+			CreatedAt:     row.CreatedAt,
+			UndoneAt:      row.UndoneAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		application.Description = row.TransactionDescription
+		application.TransactionDatetime = &row.TransactionDatetime
+		history = append(history, *application)
+	}
+
+	return history, nil
+}
+
+// UndoRuleApplication puts back the values a rule changed on a transaction. It fails with
+// ErrRuleApplicationConflict when the transaction was edited since, rather than losing the edit
+func (s *TransactionService) UndoRuleApplication(ctx context.Context, ruleID uuid.UUID, applicationID uuid.UUID, userID uuid.UUID) (*transactions.RuleApplication, error) {
+	row, err := s.trscRepo.GetRuleApplication(ctx, applicationID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRuleApplicationNotFound
+		}
+		return nil, fmt.Errorf("failed to get rule application: %w", err)
+	}
+
+	if row.RuleID != ruleID {
+		return nil, transactions.ErrRuleApplicationNotFound
+	}
+
+	if row.UndoneAt != nil {
+		return nil, transactions.ErrRuleApplicationUndone
+	}
+
+	application, err := toRuleApplication(row)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.trscRepo.GetTransaction(ctx, row.TransactionID)
+	if err != nil {
+		// The transaction was deleted since
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRuleApplicationConflict
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollback(ctx, tx)
+
+	repo := s.trscRepo.WithTx(tx)
+
+	undone, err := rules.Undo(ctx, repo, transaction, userID, application.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	marked, err := repo.MarkRuleApplicationUndone(ctx, row.ID)
+	if err != nil {
+		// Undone by another request meanwhile
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactions.ErrRuleApplicationUndone
+		}
+		return nil, fmt.Errorf("failed to mark rule application undone: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit rule undo: %w", err)
+	}
+
+	s.publishTransaction(ctx, events.TransactionUpdated, undone.Transaction)
+	if undone.Restored != nil {
+		s.publishTransaction(ctx, events.TransactionCreated, *undone.Restored)
+	}
+
+	application.UndoneAt = marked.UndoneAt
+
+	return application, nil
+}
+
+// toRuleApplication decodes the actions and changes saved with a rule application
+func toRuleApplication(row internalRepo.TransactionRuleApplication) (*transactions.RuleApplication, error) {
+	application := &transactions.RuleApplication{
+		ID:            row.ID,
+		RuleID:        row.RuleID,
+		TransactionID: row.TransactionID,
+		CreatedAt:     row.CreatedAt,
+		UndoneAt:      row.UndoneAt,
+	}
+
+	if err := json.Unmarshal(row.Actions, &application.Actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+	if err := json.Unmarshal(row.Changes, &application.Changes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal changes: %w", err)
+	}
+
+	return application, nil
+}
+
+// selectRules returns the rules of the user with the given IDs, or the active ones
+func (s *TransactionService) selectRules(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]transactions.TransactionRule, error) {
+	all, err := s.trscRepo.ListRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	return rules.Select(all, ids)
+}
+
+// publishRule notifies the owner's subscribers about a rule change
+func (s *TransactionService) publishRule(ctx context.Context, userID uuid.UUID, eventType string, rule *transactions.TransactionRule) {
+	s.events.Publish(ctx, userID, eventType, events.RuleData{
+		Rule: events.Rule{
+			ID:       rule.ID,
+			Name:     rule.Name,
+			IsActive: rule.IsActive,
+			Priority: rule.Priority,
+		},
+	})
+}
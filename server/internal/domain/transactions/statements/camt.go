@@ -0,0 +1,178 @@
+package statements
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The elements read from an ISO 20022 bank to customer statement. Names are matched without
+// their namespace so every camt.053.001.xx version is accepted
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Currency string      `xml:"Acct>Ccy"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount    `xml:"Amt"`
+	Indicator      string        `xml:"CdtDbtInd"`
+	Status         camtStatus    `xml:"Sts"`
+	BookingDate    camtDate      `xml:"BookgDt"`
+	ValueDate      camtDate      `xml:"ValDt"`
+	Reference      string        `xml:"AcctSvcrRef"`
+	AdditionalInfo string        `xml:"AddtlNtryInf"`
+	Details        []camtDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is a plain code up to version 7, then a Cd element
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtDetails struct {
+	EndToEndID     string   `xml:"Refs>EndToEndId"`
+	Creditor       string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorParty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Debtor         string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorParty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Remittance     []string `xml:"RmtInf>Ustrd"`
+	AdditionalInfo string   `xml:"AddtlTxInf"`
+}
+
+func parseCAMT053(data []byte) ([]Transaction, error) {
+	var doc camtDocument
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// Banks declare latin-1 now and then while only using its ASCII subset
+		return input, nil
+	}
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid CAMT.053 file: %w", err)
+	}
+
+	var (
+		txs  []Transaction
+		line int
+	)
+
+	for _, statement := range doc.Statements {
+		for _, entry := range statement.Entries {
+			line++
+
+			// Pending and informational entries aren't booked on the account yet
+			status := strings.ToUpper(strings.TrimSpace(entry.Status.Code + entry.Status.Value))
+			if status == "PDNG" || status == "INFO" {
+				continue
+			}
+
+			tx, err := camtTransaction(entry, statement.Currency)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			tx.Line = line
+
+			txs = append(txs, tx)
+		}
+	}
+
+	return txs, nil
+}
+
+func camtTransaction(entry camtEntry, currency string) (Transaction, error) {
+	amount, err := parseAmount(entry.Amount.Value, ".")
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	debit := strings.EqualFold(strings.TrimSpace(entry.Indicator), "DBIT")
+	if debit {
+		amount = amount.Abs().Neg()
+	} else {
+		amount = amount.Abs()
+	}
+
+	date, err := camtDateValue(entry.BookingDate)
+	if err != nil || date.IsZero() {
+		date, err = camtDateValue(entry.ValueDate)
+		if err != nil {
+			return Transaction{}, err
+		}
+		if date.IsZero() {
+			return Transaction{}, fmt.Errorf("entry without booking or value date")
+		}
+	}
+
+	tx := Transaction{
+		Date:      date,
+		Amount:    amount,
+		Currency:  strings.ToUpper(strings.TrimSpace(entry.Amount.Currency)),
+		Reference: strings.TrimSpace(entry.Reference),
+	}
+
+	if tx.Currency == "" {
+		tx.Currency = strings.ToUpper(strings.TrimSpace(currency))
+	}
+
+	var remittance string
+	if len(entry.Details) > 0 {
+		details := entry.Details[0]
+
+		// The other party is the creditor of a debit and the debtor of a credit
+		if debit {
+			tx.Payee = firstNonEmpty(details.Creditor, details.CreditorParty)
+		} else {
+			tx.Payee = firstNonEmpty(details.Debtor, details.DebtorParty)
+		}
+
+		remittance = joinNonEmpty(" ", details.Remittance...)
+		if remittance == "" {
+			remittance = details.AdditionalInfo
+		}
+
+		if tx.Reference == "" && details.EndToEndID != "NOTPROVIDED" {
+			tx.Reference = strings.TrimSpace(details.EndToEndID)
+		}
+	}
+
+	if remittance == "" {
+		remittance = entry.AdditionalInfo
+	}
+	tx.Description = joinNonEmpty(" - ", tx.Payee, remittance)
+
+	return tx, nil
+}
+
+func camtDateValue(d camtDate) (time.Time, error) {
+	switch {
+	case d.Date != "":
+		return time.Parse("2006-01-02", strings.TrimSpace(d.Date))
+	case d.DateTime != "":
+		value := strings.TrimSpace(d.DateTime)
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02T15:04:05", value)
+	}
+
+	return time.Time{}, nil
+}
@@ -0,0 +1,292 @@
+package statements
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/shopspring/decimal"
+)
+
+// Layouts tried when a mapping has no date format
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+}
+
+func parseCSV(data []byte, mapping dto.CSVMapping) ([]Transaction, error) {
+	if mapping.DateColumn == "" || (mapping.AmountColumn == "" && mapping.DebitColumn == "" && mapping.CreditColumn == "") {
+		return nil, ErrInvalidMapping
+	}
+
+	delimiter, err := csvDelimiter(data, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	layouts := defaultDateLayouts
+	if mapping.DateFormat != "" {
+		layouts = []string{DateLayout(mapping.DateFormat)}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	for i := 0; i < mapping.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, csvError(err)
+		}
+	}
+
+	var header []string
+	if mapping.HasHeader {
+		header, err = reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, csvError(err)
+		}
+	}
+
+	columns := csvColumns{header: header}
+	date := columns.resolve(mapping.DateColumn)
+	amount := columns.resolve(mapping.AmountColumn)
+	debit := columns.resolve(mapping.DebitColumn)
+	credit := columns.resolve(mapping.CreditColumn)
+	description := columns.resolve(mapping.DescriptionColumn)
+	payee := columns.resolve(mapping.PayeeColumn)
+	currency := columns.resolve(mapping.CurrencyColumn)
+	reference := columns.resolve(mapping.ReferenceColumn)
+
+	if columns.err != nil {
+		return nil, columns.err
+	}
+
+	var txs []Transaction
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if blankRecord(record) {
+			continue
+		}
+
+		tx := Transaction{
+			Line:        line,
+			Description: field(record, description),
+			Payee:       field(record, payee),
+			Currency:    strings.ToUpper(field(record, currency)),
+			Reference:   field(record, reference),
+		}
+
+		tx.Date, err = parseDate(field(record, date), layouts)
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+
+		tx.Amount, err = csvAmount(record, amount, debit, credit, mapping.DecimalSeparator)
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+
+		if mapping.InvertAmounts {
+			tx.Amount = tx.Amount.Neg()
+		}
+
+		if tx.Description == "" {
+			tx.Description = tx.Payee
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs, nil
+}
+
+// csvError keeps the line of the CSV reader errors
+func csvError(err error) error {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return &ParseError{Line: csvErr.Line, Err: csvErr.Err}
+	}
+
+	return err
+}
+
+// csvAmount reads the signed amount column, or the credit minus the debit
+func csvAmount(record []string, amount, debit, credit int, decimalSeparator string) (decimal.Decimal, error) {
+	if amount >= 0 {
+		return parseAmount(field(record, amount), decimalSeparator)
+	}
+
+	total := decimal.Zero
+
+	if raw := field(record, credit); raw != "" {
+		value, err := parseAmount(raw, decimalSeparator)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		total = total.Add(value.Abs())
+	}
+
+	if raw := field(record, debit); raw != "" {
+		value, err := parseAmount(raw, decimalSeparator)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		total = total.Sub(value.Abs())
+	}
+
+	return total, nil
+}
+
+// csvDelimiter returns the mapping delimiter, or the most frequent candidate of the first line read
+func csvDelimiter(data []byte, mapping dto.CSVMapping) (rune, error) {
+	if mapping.Delimiter != "" {
+		if mapping.Delimiter == `\t` {
+			return '\t', nil
+		}
+
+		runes := []rune(mapping.Delimiter)
+		if len(runes) != 1 {
+			return 0, fmt.Errorf("%w: delimiter must be a single character", ErrInvalidMapping)
+		}
+		return runes[0], nil
+	}
+
+	lines := strings.SplitN(string(data), "\n", mapping.SkipRows+2)
+	line := lines[len(lines)-1]
+	if len(lines) > mapping.SkipRows {
+		line = lines[mapping.SkipRows]
+	}
+
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if count := strings.Count(line, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+
+	return best, nil
+}
+
+type csvColumns struct {
+	header []string
+	err    error
+}
+
+// resolve finds a column by header name or by 1-based position, -1 when the mapping leaves it unset
+func (c *csvColumns) resolve(ref string) int {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return -1
+	}
+
+	for i, name := range c.header {
+		if strings.EqualFold(strings.TrimSpace(name), ref) {
+			return i
+		}
+	}
+
+	if position, err := strconv.Atoi(ref); err == nil && position > 0 {
+		return position - 1
+	}
+
+	if c.err == nil {
+		c.err = fmt.Errorf("%w: column %q not found", ErrInvalidMapping, ref)
+	}
+
+	return -1
+}
+
+func field(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[index])
+}
+
+func blankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseDate(raw string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// dateTokens are the placeholders of user date formats, longest first
+var dateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+	{"M", "1"},
+	{"D", "2"},
+}
+
+// DateLayout turns a format like DD/MM/YYYY into the matching Go time layout
+func DateLayout(format string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range dateTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package statements
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DuplicateWindow is how far apart a statement line and a recorded transaction of the same
+// amount may be dated and still be the same operation, banks often book a few days late
+const DuplicateWindow = 3 * 24 * time.Hour
+
+// Recorded is a transaction already on the account the statement is imported into
+type Recorded struct {
+	ID          uuid.UUID
+	Date        time.Time
+	Amount      decimal.Decimal
+	Description string
+}
+
+// FindDuplicates returns, for each statement line, the recorded transaction it duplicates or nil.
+// Lines need the same amount and either the same day, or a similar description within
+// DuplicateWindow. A recorded transaction is matched at most once, so two identical purchases
+// on a day where only one was recorded leave the second one to import.
+func FindDuplicates(lines []Transaction, recorded []Recorded) []*uuid.UUID {
+	duplicates := make([]*uuid.UUID, len(lines))
+	used := make([]bool, len(recorded))
+
+	for i, line := range lines {
+		best, bestScore := -1, 0
+
+		for j, r := range recorded {
+			if used[j] || !r.Amount.Equal(line.Amount) {
+				continue
+			}
+
+			if score := duplicateScore(line, r); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+
+		if best >= 0 {
+			used[best] = true
+			id := recorded[best].ID
+			duplicates[i] = &id
+		}
+	}
+
+	return duplicates
+}
+
+// duplicateScore ranks how likely two transactions of the same amount are the same, 0 when they
+// aren't
+func duplicateScore(line Transaction, r Recorded) int {
+	gap := line.Date.Sub(r.Date)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > DuplicateWindow {
+		return 0
+	}
+
+	sameDay := sameDate(line.Date, r.Date)
+	similar := similarDescriptions(line.Description, r.Description) || similarDescriptions(line.Payee, r.Description)
+
+	switch {
+	case sameDay && similar:
+		return 30
+	case sameDay:
+		return 20
+	case similar:
+		// Closer dates win among several candidates
+		return 10 - int(gap.Hours()/24)
+	}
+
+	return 0
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// similarDescriptions compares descriptions ignoring case and punctuation, one containing the
+// other is enough since banks and users rarely shorten them the same way
+func similarDescriptions(a, b string) bool {
+	a, b = normalizeDescription(a), normalizeDescription(b)
+	if len(a) < 4 || len(b) < 4 {
+		return a != "" && a == b
+	}
+
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+func normalizeDescription(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,144 @@
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseOFX reads OFX 1.x, which is SGML where leaf elements aren't closed, and OFX 2.x which is
+// XML. Both are walked as a stream of tags, each followed by the text up to the next tag
+func parseOFX(data []byte) ([]Transaction, error) {
+	s := string(data)
+
+	start := strings.Index(strings.ToUpper(s), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("not an OFX file: missing <OFX> element")
+	}
+
+	var (
+		txs      []Transaction
+		current  *Transaction
+		currency string
+		memo     string
+		line     = strings.Count(s[:start], "\n") + 1
+		pos      = start
+	)
+
+	for {
+		open := strings.IndexByte(s[pos:], '<')
+		if open < 0 {
+			break
+		}
+		line += strings.Count(s[pos:pos+open], "\n")
+		open += pos
+
+		end := strings.IndexByte(s[open:], '>')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		tag := strings.ToUpper(strings.TrimSpace(s[open+1 : end]))
+		pos = end + 1
+
+		valueEnd := strings.IndexByte(s[pos:], '<')
+		if valueEnd < 0 {
+			valueEnd = len(s) - pos
+		}
+		value := html.UnescapeString(strings.TrimSpace(s[pos : pos+valueEnd]))
+
+		switch tag {
+		case "CURDEF":
+			currency = strings.ToUpper(value)
+		case "STMTTRN":
+			current = &Transaction{Line: line}
+			memo = ""
+		case "/STMTTRN":
+			if current == nil {
+				continue
+			}
+
+			if current.Date.IsZero() {
+				return nil, &ParseError{Line: current.Line, Err: errors.New("transaction without DTPOSTED")}
+			}
+			if current.Currency == "" {
+				current.Currency = currency
+			}
+			current.Description = joinNonEmpty(" - ", current.Payee, memo)
+
+			txs = append(txs, *current)
+			current = nil
+		}
+
+		if current == nil {
+			continue
+		}
+
+		var err error
+
+		switch tag {
+		case "DTPOSTED":
+			current.Date, err = parseOFXDate(value)
+		case "TRNAMT":
+			current.Amount, err = parseAmount(value, "")
+		case "FITID":
+			current.Reference = value
+		case "NAME":
+			current.Payee = value
+		case "MEMO":
+			memo = value
+		case "CURSYM":
+			// Inside CURRENCY or ORIGCURRENCY, the transaction was made in another currency
+			current.Currency = strings.ToUpper(value)
+		}
+
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+	}
+
+	return txs, nil
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][gmt offset[:tz name]], the time is in GMT when the
+// offset is missing
+func parseOFXDate(raw string) (time.Time, error) {
+	value, zone, _ := strings.Cut(raw, "[")
+
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		value = value[:dot]
+	}
+
+	var layout string
+	switch len(value) {
+	case 8:
+		layout = "20060102"
+	case 12:
+		layout = "200601021504"
+	case 14:
+		layout = "20060102150405"
+	default:
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+
+	location := time.UTC
+	if zone != "" {
+		offset, _, _ := strings.Cut(strings.TrimSuffix(zone, "]"), ":")
+		hours, err := strconv.ParseFloat(offset, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", raw)
+		}
+		location = time.FixedZone("", int(hours*3600))
+	}
+
+	t, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,185 @@
+package statements
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QIF sections holding cash account transactions, investment and list sections are skipped
+var qifAccountTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+type qifRecord struct {
+	line   int
+	date   string
+	amount string
+	payee  string
+	memo   string
+	number string
+}
+
+func parseQIF(data []byte) ([]Transaction, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var (
+		records []qifRecord
+		current qifRecord
+		inBlock bool
+		skip    = true
+	)
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(line)
+			if typ, ok := strings.CutPrefix(header, "!type:"); ok {
+				skip = !qifAccountTypes[strings.TrimSpace(typ)]
+			} else if header == "!account" {
+				// An account description block, ended by ^, precedes the transactions
+				skip = true
+			}
+			continue
+		}
+
+		if skip {
+			continue
+		}
+
+		if !inBlock {
+			current = qifRecord{line: i + 1}
+			inBlock = true
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case 'D':
+			current.date = value
+		case 'T', 'U':
+			if current.amount == "" {
+				current.amount = value
+			}
+		case 'P':
+			current.payee = value
+		case 'M':
+			current.memo = value
+		case 'N':
+			current.number = value
+		case '^':
+			records = append(records, current)
+			inBlock = false
+		}
+	}
+
+	if inBlock && current.date != "" {
+		records = append(records, current)
+	}
+
+	dayFirst := qifDayFirst(records)
+
+	txs := make([]Transaction, 0, len(records))
+	for _, record := range records {
+		date, err := parseQIFDate(record.date, dayFirst)
+		if err != nil {
+			return nil, &ParseError{Line: record.line, Err: err}
+		}
+
+		amount, err := parseAmount(record.amount, "")
+		if err != nil {
+			return nil, &ParseError{Line: record.line, Err: err}
+		}
+
+		txs = append(txs, Transaction{
+			Line:        record.line,
+			Date:        date,
+			Amount:      amount,
+			Payee:       record.payee,
+			Description: joinNonEmpty(" - ", record.payee, record.memo),
+			Reference:   record.number,
+		})
+	}
+
+	return txs, nil
+}
+
+// qifDayFirst tells whether the file writes dates as D/M/Y. QIF dates are M/D/Y, but some
+// software outside the US writes them day first, which shows as soon as a day is over 12
+func qifDayFirst(records []qifRecord) bool {
+	for _, record := range records {
+		parts := qifDateParts(record.date)
+		if len(parts) != 3 || len(parts[0]) == 4 {
+			continue
+		}
+
+		first, _ := strconv.Atoi(parts[0])
+		second, _ := strconv.Atoi(parts[1])
+		if first > 12 && second <= 12 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func qifDateParts(raw string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\'' || r == ' '
+	})
+}
+
+// parseQIFDate reads M/D/Y dates, where the year may have 2 digits and follow an apostrophe
+// like 1/31'25, as well as ISO dates
+func parseQIFDate(raw string, dayFirst bool) (time.Time, error) {
+	parts := qifDateParts(raw)
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+
+	values := make([]int, 3)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", raw)
+		}
+		values[i] = value
+	}
+
+	var year, month, day int
+	switch {
+	case len(parts[0]) == 4:
+		year, month, day = values[0], values[1], values[2]
+	case dayFirst:
+		day, month, year = values[0], values[1], values[2]
+	default:
+		month, day, year = values[0], values[1], values[2]
+	}
+
+	if year < 100 {
+		if year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", raw)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,229 @@
+// Package statements reads the bank statement files users can import: CSV exports described by
+// a column mapping, OFX/QFX, QIF and ISO 20022 CAMT.053.
+package statements
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/shopspring/decimal"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatOFX     Format = "ofx" // QFX is OFX with a few Quicken specific tags
+	FormatQIF     Format = "qif"
+	FormatCAMT053 Format = "camt053"
+)
+
+var Formats = []Format{FormatCSV, FormatOFX, FormatQIF, FormatCAMT053}
+
+var (
+	ErrUnknownFormat  = errors.New("unrecognized statement format")
+	ErrMissingMapping = errors.New("a column mapping is required to read a CSV statement")
+	ErrInvalidMapping = errors.New("invalid column mapping")
+	ErrEmpty          = errors.New("the statement has no transaction")
+)
+
+// Transaction is a statement line, before it is staged for import
+type Transaction struct {
+	Line        int             `json:"line"` // 1-based position in the file, the entry number for CAMT.053
+	Date        time.Time       `json:"date"`
+	Amount      decimal.Decimal `json:"amount"`   // Negative when money left the account
+	Currency    string          `json:"currency"` // Empty when the file doesn't say
+	Description string          `json:"description"`
+	Payee       string          `json:"payee"`
+	Reference   string          `json:"reference"` // Bank reference of the line, like the OFX FITID
+}
+
+// ParseError locates the line of the statement that couldn't be read
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DetectFormat guesses the format of a statement from its content, then from its file name
+func DetectFormat(filename string, data []byte) (Format, error) {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.ToUpper(bytes.TrimPrefix(head, utf8BOM))
+
+	switch {
+	case bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")):
+		return FormatOFX, nil
+	case bytes.Contains(head, []byte("CAMT.053")) || bytes.Contains(head, []byte("<BKTOCSTMRSTMT")):
+		return FormatCAMT053, nil
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("!TYPE:")) || bytes.HasPrefix(bytes.TrimSpace(head), []byte("!ACCOUNT")):
+		return FormatQIF, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV, nil
+	case ".ofx", ".qfx":
+		return FormatOFX, nil
+	case ".qif":
+		return FormatQIF, nil
+	case ".xml":
+		return FormatCAMT053, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// Parse reads the transactions of a statement, the mapping is only used for CSV files
+func Parse(format Format, data []byte, mapping *dto.CSVMapping) ([]Transaction, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	var (
+		txs []Transaction
+		err error
+	)
+
+	switch format {
+	case FormatCSV:
+		if mapping == nil {
+			return nil, ErrMissingMapping
+		}
+		txs, err = parseCSV(data, *mapping)
+	case FormatOFX:
+		txs, err = parseOFX(data)
+	case FormatQIF:
+		txs, err = parseQIF(data)
+	case FormatCAMT053:
+		txs, err = parseCAMT053(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txs) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return txs, nil
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// parseAmount reads amounts as banks write them: with thousands separators, currency symbols,
+// a trailing minus or between parentheses. A decimal separator of "" guesses it from the value
+func parseAmount(raw, decimalSeparator string) (decimal.Decimal, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return decimal.Zero, errors.New("empty amount")
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasSuffix(s, "-") {
+		negative = !negative
+		s = strings.TrimSuffix(s, "-")
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			b.WriteRune(r)
+		case r == '-':
+			negative = !negative
+		}
+	}
+	s = b.String()
+
+	if decimalSeparator == "" {
+		decimalSeparator = guessDecimalSeparator(s)
+	}
+
+	if decimalSeparator == "," {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	if negative {
+		amount = amount.Neg()
+	}
+
+	return amount, nil
+}
+
+// guessDecimalSeparator picks the separator that comes last, unless a lone comma is followed by
+// three digits which makes it a thousands separator
+func guessDecimalSeparator(s string) string {
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+
+	switch {
+	case comma < 0:
+		return "."
+	case dot > comma:
+		return "."
+	case dot < 0 && strings.Count(s, ",") == 1 && len(s)-comma-1 == 3:
+		return "."
+	}
+
+	return ","
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		duplicate := false
+		for _, k := range kept {
+			if strings.EqualFold(k, p) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, p)
+		}
+	}
+
+	return strings.Join(kept, sep)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,361 @@
+package statements_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/statements"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func assertTransaction(t *testing.T, got statements.Transaction, date string, amount string, description string) {
+	t.Helper()
+
+	if d := got.Date.Format("2006-01-02"); d != date {
+		t.Errorf("line %d: date = %s, want %s", got.Line, d, date)
+	}
+	if !got.Amount.Equal(decimal.RequireFromString(amount)) {
+		t.Errorf("line %d: amount = %s, want %s", got.Line, got.Amount, amount)
+	}
+	if got.Description != description {
+		t.Errorf("line %d: description = %q, want %q", got.Line, got.Description, description)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	data := []byte("Export of account 12345\n" +
+		"Date;Label;Debit;Credit;Ref\n" +
+		"31/01/2025;CARD GROCERY STORE;1.234,50;;A1\n" +
+		"\n" +
+		"01/02/2025;\"SALARY; JANUARY\";;2.500,00;A2\n")
+
+	txs, err := statements.Parse(statements.FormatCSV, data, &dto.CSVMapping{
+		HasHeader:         true,
+		SkipRows:          1,
+		DateColumn:        "date",
+		DateFormat:        "DD/MM/YYYY",
+		DebitColumn:       "Debit",
+		CreditColumn:      "Credit",
+		DescriptionColumn: "Label",
+		ReferenceColumn:   "5",
+		DecimalSeparator:  ",",
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txs))
+	}
+
+	assertTransaction(t, txs[0], "2025-01-31", "-1234.50", "CARD GROCERY STORE")
+	assertTransaction(t, txs[1], "2025-02-01", "2500", "SALARY; JANUARY")
+
+	if txs[0].Line != 3 || txs[1].Line != 5 {
+		t.Errorf("lines = %d, %d, want 3, 5", txs[0].Line, txs[1].Line)
+	}
+	if txs[1].Reference != "A2" {
+		t.Errorf("reference = %q, want A2", txs[1].Reference)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	data := []byte("date,amount\n2025-01-31,12.00\n2025-02-30,5.00\n")
+
+	_, err := statements.Parse(statements.FormatCSV, data, &dto.CSVMapping{HasHeader: true, DateColumn: "date", AmountColumn: "amount"})
+
+	var parseErr *statements.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+		t.Fatalf("error = %v, want a parse error on line 3", err)
+	}
+
+	_, err = statements.Parse(statements.FormatCSV, data, &dto.CSVMapping{HasHeader: true, DateColumn: "date", AmountColumn: "total"})
+	if !errors.Is(err, statements.ErrInvalidMapping) {
+		t.Errorf("error = %v, want ErrInvalidMapping", err)
+	}
+
+	_, err = statements.Parse(statements.FormatCSV, data, nil)
+	if !errors.Is(err, statements.ErrMissingMapping) {
+		t.Errorf("error = %v, want ErrMissingMapping", err)
+	}
+}
+
+func TestParseCSVInvertedAmounts(t *testing.T) {
+	data := []byte("2025-03-01,Coffee,\"$4.50\"\n2025-03-02,Refund,(10.00)\n")
+
+	txs, err := statements.Parse(statements.FormatCSV, data, &dto.CSVMapping{
+		DateColumn:        "1",
+		DescriptionColumn: "2",
+		AmountColumn:      "3",
+		InvertAmounts:     true,
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	assertTransaction(t, txs[0], "2025-03-01", "-4.50", "Coffee")
+	assertTransaction(t, txs[1], "2025-03-02", "10", "Refund")
+}
+
+func TestParseOFX(t *testing.T) {
+	sgml := []byte(`OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>EUR
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250131120000.000[-5:EST]
+<TRNAMT>-42.10
+<FITID>2025013101
+<NAME>GROCERY &amp; CO
+<MEMO>Card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250201
+<TRNAMT>1500,00
+<FITID>2025020101
+<NAME>EMPLOYER
+<CURRENCY><CURRATE>1.1<CURSYM>USD</CURRENCY>
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`)
+
+	xml := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="211"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD</CURDEF><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250131</DTPOSTED><TRNAMT>-42.10</TRNAMT><FITID>X1</FITID><NAME>GROCERY</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`)
+
+	txs, err := statements.Parse(statements.FormatOFX, sgml, nil)
+	if err != nil {
+		t.Fatalf("Parse(sgml) error = %v", err)
+	}
+
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txs))
+	}
+
+	assertTransaction(t, txs[0], "2025-01-31", "-42.10", "GROCERY & CO - Card 1234")
+	assertTransaction(t, txs[1], "2025-02-01", "1500", "EMPLOYER")
+
+	if !txs[0].Date.Equal(time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %s, want the EST time converted", txs[0].Date)
+	}
+	if txs[0].Currency != "EUR" || txs[1].Currency != "USD" {
+		t.Errorf("currencies = %s, %s, want EUR, USD", txs[0].Currency, txs[1].Currency)
+	}
+	if txs[0].Reference != "2025013101" || txs[0].Payee != "GROCERY & CO" {
+		t.Errorf("reference, payee = %q, %q", txs[0].Reference, txs[0].Payee)
+	}
+	if txs[0].Line != 9 {
+		t.Errorf("line = %d, want 9", txs[0].Line)
+	}
+
+	txs, err = statements.Parse(statements.FormatOFX, xml, nil)
+	if err != nil {
+		t.Fatalf("Parse(xml) error = %v", err)
+	}
+
+	if len(txs) != 1 {
+		t.Fatalf("got %d transactions, want 1", len(txs))
+	}
+	assertTransaction(t, txs[0], "2025-01-31", "-42.10", "GROCERY")
+}
+
+func TestParseQIF(t *testing.T) {
+	data := []byte(`!Account
+NChecking
+TBank
+^
+!Type:Bank
+D1/31'25
+T-1,234.50
+PLandlord
+MRent
+N1001
+LHousing
+^
+D02/01/2025
+U2,500.00
+T2,500.00
+PEmployer
+^
+!Type:Cat
+NGroceries
+^
+`)
+
+	txs, err := statements.Parse(statements.FormatQIF, data, nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txs))
+	}
+
+	assertTransaction(t, txs[0], "2025-01-31", "-1234.50", "Landlord - Rent")
+	assertTransaction(t, txs[1], "2025-02-01", "2500", "Employer")
+
+	if txs[0].Reference != "1001" || txs[0].Line != 6 {
+		t.Errorf("reference, line = %q, %d, want 1001, 6", txs[0].Reference, txs[0].Line)
+	}
+}
+
+func TestParseQIFDayFirst(t *testing.T) {
+	data := []byte("!Type:CCard\nD05/02/2025\nT-10.00\nPA\n^\nD25/02/2025\nT-20.00\nPB\n^\n")
+
+	txs, err := statements.Parse(statements.FormatQIF, data, nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	assertTransaction(t, txs[0], "2025-02-05", "-10", "A")
+	assertTransaction(t, txs[1], "2025-02-25", "-20", "B")
+}
+
+func TestParseCAMT053(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Acct><Ccy>EUR</Ccy></Acct>
+      <Ntry>
+        <Amt Ccy="EUR">42.10</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2025-01-31</Dt></BookgDt>
+        <AcctSvcrRef>REF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties><Cdtr><Pty><Nm>Grocery Store</Nm></Pty></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Invoice 12</Ustrd><Ustrd>January</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <BookgDt><Dt>2025-02-01</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt>2500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <ValDt><DtTm>2025-02-01T08:00:00+01:00</DtTm></ValDt>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>E2E-9</EndToEndId></Refs>
+          <RltdPties><Dbtr><Nm>Employer</Nm></Dbtr></RltdPties>
+        </TxDtls></NtryDtls>
+        <AddtlNtryInf>SALARY</AddtlNtryInf>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`)
+
+	format, err := statements.DetectFormat("statement.xml", data)
+	if err != nil || format != statements.FormatCAMT053 {
+		t.Fatalf("DetectFormat() = %s, %v", format, err)
+	}
+
+	txs, err := statements.Parse(format, data, nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2 without the pending entry", len(txs))
+	}
+
+	assertTransaction(t, txs[0], "2025-01-31", "-42.10", "Grocery Store - Invoice 12 January")
+	assertTransaction(t, txs[1], "2025-02-01", "2500", "Employer - SALARY")
+
+	if txs[0].Reference != "REF-1" || txs[1].Reference != "E2E-9" {
+		t.Errorf("references = %q, %q", txs[0].Reference, txs[1].Reference)
+	}
+	if txs[1].Currency != "EUR" || txs[1].Line != 3 {
+		t.Errorf("currency, line = %s, %d, want EUR, 3", txs[1].Currency, txs[1].Line)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+		want     statements.Format
+	}{
+		{"export.dat", "OFXHEADER:100\n<OFX>", statements.FormatOFX},
+		{"bank.qfx", "", statements.FormatOFX},
+		{"file", "\xef\xbb\xbf!Type:Bank\nD1/1/25", statements.FormatQIF},
+		{"export.csv", "date,amount", statements.FormatCSV},
+	}
+
+	for _, tt := range tests {
+		got, err := statements.DetectFormat(tt.filename, []byte(tt.data))
+		if err != nil || got != tt.want {
+			t.Errorf("DetectFormat(%q) = %s, %v, want %s", tt.filename, got, err, tt.want)
+		}
+	}
+
+	if _, err := statements.DetectFormat("photo.png", []byte{0x89, 'P', 'N', 'G'}); !errors.Is(err, statements.ErrUnknownFormat) {
+		t.Errorf("error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := map[string]string{
+		"DD/MM/YYYY":          "02/01/2006",
+		"M/D/YY":              "1/2/06",
+		"YYYY-MM-DD HH:mm:ss": "2006-01-02 15:04:05",
+		"DD MMM YYYY":         "02 Jan 2006",
+	}
+
+	for format, want := range tests {
+		if got := statements.DateLayout(format); got != want {
+			t.Errorf("DateLayout(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	amount := decimal.RequireFromString
+
+	coffee1, coffee2, rent, late := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	recorded := []statements.Recorded{
+		{ID: coffee1, Date: day(10).Add(9 * time.Hour), Amount: amount("-4.50"), Description: "Coffee"},
+		{ID: rent, Date: day(1), Amount: amount("-1200"), Description: "Rent January"},
+		{ID: late, Date: day(12), Amount: amount("-60"), Description: "Electricity"},
+		{ID: coffee2, Date: day(20), Amount: amount("-4.50"), Description: "Coffee"},
+	}
+
+	lines := []statements.Transaction{
+		{Date: day(10), Amount: amount("-4.50"), Description: "COFFEE SHOP"},
+		{Date: day(10), Amount: amount("-4.50"), Description: "COFFEE SHOP"}, // Second purchase, not recorded
+		{Date: day(3), Amount: amount("-1200.00"), Description: "RENT"},      // Booked two days later
+		{Date: day(15), Amount: amount("-60"), Description: "Gas"},           // Same amount, different payee
+		{Date: day(1), Amount: amount("-99"), Description: "Rent January"},
+	}
+
+	got := statements.FindDuplicates(lines, recorded)
+
+	want := []*uuid.UUID{&coffee1, nil, &rent, nil, nil}
+	for i := range want {
+		switch {
+		case want[i] == nil && got[i] != nil:
+			t.Errorf("line %d: duplicate of %s, want none", i, got[i])
+		case want[i] != nil && (got[i] == nil || *got[i] != *want[i]):
+			t.Errorf("line %d: duplicate of %v, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package transactions
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+func RegisterValidations(v *validator.Validate) error {
+	if v == nil {
+		return nil
+	}
+
+	if err := v.RegisterValidation("rule_conditions", validateRuleConditions); err != nil {
+		return err
+	}
+
+	return v.RegisterValidation("rule_actions", validateRuleActions)
+}
+
+// validateRuleConditions rejects condition trees the rule evaluator can't run
+func validateRuleConditions(fl validator.FieldLevel) bool {
+	node, ok := fl.Field().Interface().(ConditionNode)
+	return ok && node.Validate() == nil
+}
+
+// validateRuleActions rejects actions rules can't apply
+func validateRuleActions(fl validator.FieldLevel) bool {
+	actions, ok := fl.Field().Interface().([]RuleAction)
+	return ok && ValidateActions(actions) == nil
+}
@@ -0,0 +1,188 @@
+// Package archive reads and writes the portable account archive, a zip of JSON documents holding
+// everything a user owns so it can be restored on another instance.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// Format identifies nuts archives in the manifest
+	Format = "nuts-archive"
+	// Version is bumped when documents are added or change, older versions stay readable.
+	// Version 2 added the transaction splits
+	Version = 2
+)
+
+// Documents of the archive
+const (
+	ManifestDocument              = "manifest.json"
+	ProfileDocument               = "profile.json"
+	PreferencesDocument           = "preferences.json"
+	AccountsDocument              = "accounts.json"
+	CategoriesDocument            = "categories.json"
+	TagsDocument                  = "tags.json"
+	TransactionsDocument          = "transactions.json"
+	SplitsDocument                = "transaction_splits.json"
+	RulesDocument                 = "rules.json"
+	RecurringTransactionsDocument = "recurring_transactions.json"
+	BudgetsDocument               = "budgets.json"
+	AttachmentsDocument           = "attachments.json"
+)
+
+// filesDir holds the binary files (avatar, attachments) referenced by the documents
+const filesDir = "files/"
+
+var (
+	ErrInvalidArchive     = errors.New("invalid archive")
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	ErrDocumentTooLarge   = errors.New("archive document too large")
+)
+
+type Manifest struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Counts    map[string]int `json:"counts"`
+}
+
+type Profile struct {
+	Email     string  `json:"email"`
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	// Avatar is the name of the avatar in the files of the archive
+	Avatar *string `json:"avatar,omitempty"`
+}
+
+type Preferences struct {
+	Locale            string `json:"locale"`
+	Theme             string `json:"theme"`
+	Currency          string `json:"currency"`
+	Timezone          string `json:"timezone"`
+	TimeFormat        string `json:"time_format"`
+	DateFormat        string `json:"date_format"`
+	StartWeekOnMonday bool   `json:"start_week_on_monday"`
+	DarkSidebar       bool   `json:"dark_sidebar"`
+}
+
+type Account struct {
+	ID              uuid.UUID       `json:"id"`
+	Name            string          `json:"name"`
+	Type            string          `json:"type"`
+	Subtype         *string         `json:"subtype,omitempty"`
+	Balance         decimal.Decimal `json:"balance"`
+	Currency        string          `json:"currency"`
+	InstitutionName string          `json:"institution_name,omitempty"`
+	// ProviderName is informative, provider connections aren't portable
+	ProviderName *string   `json:"provider_name,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Category struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Type      string     `json:"type"`
+	Color     *string    `json:"color,omitempty"`
+	Icon      string     `json:"icon"`
+	IsDefault bool       `json:"is_default"`
+}
+
+type Tag struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Color string    `json:"color"`
+}
+
+type Transaction struct {
+	ID                     uuid.UUID           `json:"id"`
+	Type                   string              `json:"type"`
+	Amount                 decimal.Decimal     `json:"amount"`
+	Currency               string              `json:"currency"`
+	OriginalAmount         decimal.Decimal     `json:"original_amount"`
+	ExchangeRate           decimal.NullDecimal `json:"exchange_rate"`
+	ExchangeRateDate       *time.Time          `json:"exchange_rate_date,omitempty"`
+	AccountID              uuid.UUID           `json:"account_id"`
+	DestinationAccountID   *uuid.UUID          `json:"destination_account_id,omitempty"`
+	CategoryID             *uuid.UUID          `json:"category_id,omitempty"`
+	Date                   time.Time           `json:"date"`
+	Description            *string             `json:"description,omitempty"`
+	Details                *dto.Details        `json:"details,omitempty"`
+	IsExternal             bool                `json:"is_external"`
+	IsCategorized          bool                `json:"is_categorized"`
+	RecurringTransactionID *uuid.UUID          `json:"recurring_transaction_id,omitempty"`
+	RecurringInstanceDate  *time.Time          `json:"recurring_instance_date,omitempty"`
+	CreatedAt              time.Time           `json:"created_at"`
+}
+
+type Split struct {
+	ID            uuid.UUID       `json:"id"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	CategoryID    *uuid.UUID      `json:"category_id,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	Percentage    decimal.Decimal `json:"percentage"`
+	Description   *string         `json:"description,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type Rule struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	IsActive   bool            `json:"is_active"`
+	Priority   int32           `json:"priority"`
+	Conditions json.RawMessage `json:"conditions"`
+	Actions    json.RawMessage `json:"actions"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type RecurringTransaction struct {
+	ID                   uuid.UUID       `json:"id"`
+	AccountID            uuid.UUID       `json:"account_id"`
+	CategoryID           *uuid.UUID      `json:"category_id,omitempty"`
+	DestinationAccountID *uuid.UUID      `json:"destination_account_id,omitempty"`
+	Amount               decimal.Decimal `json:"amount"`
+	Type                 string          `json:"type"`
+	Description          *string         `json:"description,omitempty"`
+	Details              *dto.Details    `json:"details,omitempty"`
+	Frequency            string          `json:"frequency"`
+	FrequencyInterval    int32           `json:"frequency_interval"`
+	FrequencyData        json.RawMessage `json:"frequency_data,omitempty"`
+	StartDate            time.Time       `json:"start_date"`
+	EndDate              *time.Time      `json:"end_date,omitempty"`
+	LastGeneratedDate    *time.Time      `json:"last_generated_date,omitempty"`
+	NextDueDate          time.Time       `json:"next_due_date"`
+	AutoPost             bool            `json:"auto_post"`
+	IsPaused             bool            `json:"is_paused"`
+	MaxOccurrences       *int32          `json:"max_occurrences,omitempty"`
+	OccurrencesCount     int32           `json:"occurrences_count"`
+	TemplateName         *string         `json:"template_name,omitempty"`
+	Tags                 json.RawMessage `json:"tags,omitempty"`
+}
+
+type Budget struct {
+	ID              uuid.UUID       `json:"id"`
+	CategoryID      uuid.UUID       `json:"category_id"`
+	Name            *string         `json:"name,omitempty"`
+	Amount          decimal.Decimal `json:"amount"`
+	StartDate       time.Time       `json:"start_date"`
+	EndDate         time.Time       `json:"end_date"`
+	Frequency       string          `json:"frequency"`
+	RolloverEnabled bool            `json:"rollover_enabled"`
+}
+
+type Attachment struct {
+	ID            uuid.UUID `json:"id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	MimeType      string    `json:"mime_type"`
+	Size          int64     `json:"size"`
+	// File is the name of the attachment in the files of the archive
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+}
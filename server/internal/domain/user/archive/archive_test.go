@@ -0,0 +1,148 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/user/archive"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T, data []byte) *archive.Reader {
+	t.Helper()
+
+	r, err := archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestRoundTrip(t *testing.T) {
+	account := archive.Account{ID: uuid.New(), Name: "Checking", Type: "checking", Balance: decimal.RequireFromString("12.34"), Currency: "EUR"}
+
+	var buf bytes.Buffer
+	w := archive.NewWriter(&buf)
+
+	require.NoError(t, w.WriteDocument(archive.ProfileDocument, archive.Profile{Email: "jane@example.com"}))
+	require.NoError(t, w.WriteDocument(archive.AccountsDocument, []archive.Account{account}))
+
+	stream, err := w.Stream(archive.TransactionsDocument)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, stream.Write(archive.Transaction{ID: uuid.New(), AccountID: account.ID, Amount: decimal.NewFromInt(int64(i))}))
+	}
+
+	require.NoError(t, w.AddFile("avatar.png", strings.NewReader("png")))
+	require.NoError(t, w.Close())
+
+	r := open(t, buf.Bytes())
+	assert.Equal(t, archive.Version, r.Manifest.Version)
+	assert.Equal(t, 1, r.Manifest.Counts[archive.AccountsDocument])
+	assert.Equal(t, 3, r.Manifest.Counts[archive.TransactionsDocument])
+
+	var profile archive.Profile
+	require.NoError(t, r.Decode(archive.ProfileDocument, &profile))
+	assert.Equal(t, "jane@example.com", profile.Email)
+
+	accounts, err := archive.All[archive.Account](r, archive.AccountsDocument)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.True(t, accounts[0].Balance.Equal(account.Balance))
+
+	var amounts []string
+	require.NoError(t, archive.Each(r, archive.TransactionsDocument, func(tx archive.Transaction) error {
+		assert.Equal(t, account.ID, tx.AccountID)
+		amounts = append(amounts, tx.Amount.String())
+		return nil
+	}))
+	assert.Equal(t, []string{"0", "1", "2"}, amounts)
+
+	f, size, err := r.OpenFile("avatar.png")
+	require.NoError(t, err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+	assert.Equal(t, "png", string(content))
+}
+
+func TestMissingDocumentsAreEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, archive.NewWriter(&buf).Close())
+
+	budgets, err := archive.All[archive.Budget](open(t, buf.Bytes()), archive.BudgetsDocument)
+	require.NoError(t, err)
+	assert.Empty(t, budgets)
+}
+
+func TestVersionOneArchives(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(archive.ManifestDocument)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"format":"nuts-archive","version":1}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	// Archives from before splits were exported restore without them
+	splits, err := archive.All[archive.Split](open(t, buf.Bytes()), archive.SplitsDocument)
+	require.NoError(t, err)
+	assert.Empty(t, splits)
+}
+
+func TestInvalidArchives(t *testing.T) {
+	_, err := archive.NewReader(strings.NewReader("not a zip"), 9)
+	assert.ErrorIs(t, err, archive.ErrInvalidArchive)
+
+	zipped := func(manifest string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		if manifest != "" {
+			f, err := zw.Create(archive.ManifestDocument)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(manifest))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	data := zipped("")
+	_, err = archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, archive.ErrInvalidArchive)
+
+	data = zipped(`{"format":"other","version":1}`)
+	_, err = archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, archive.ErrInvalidArchive)
+
+	data = zipped(`{"format":"nuts-archive","version":99}`)
+	_, err = archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, archive.ErrUnsupportedVersion)
+}
+
+func TestRemapJSON(t *testing.T) {
+	oldCategory, newCategory := uuid.New(), uuid.New()
+	unknown := uuid.New()
+
+	raw := json.RawMessage(`{"conditions":[{"field":"category","value":"` + oldCategory.String() + `"},{"field":"amount","value":12345678901234567890.12}],"other":"` + unknown.String() + `"}`)
+
+	remapped, err := archive.RemapJSON(raw, map[uuid.UUID]uuid.UUID{oldCategory: newCategory})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(remapped), newCategory.String())
+	assert.NotContains(t, string(remapped), oldCategory.String())
+	assert.Contains(t, string(remapped), unknown.String())
+	assert.Contains(t, string(remapped), "12345678901234567890.12")
+
+	null, err := archive.RemapJSON(json.RawMessage("null"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(null))
+}
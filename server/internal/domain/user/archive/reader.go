@@ -0,0 +1,176 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/google/uuid"
+)
+
+// maxDocumentSize bounds the documents decoded in memory, arrays are streamed with Each
+const maxDocumentSize = 32 << 20
+
+type Reader struct {
+	zr       *zip.Reader
+	Manifest Manifest
+}
+
+// NewReader opens an archive and checks its manifest
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	reader := &Reader{zr: zr}
+
+	if err := reader.Decode(ManifestDocument, &reader.Manifest); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: missing manifest", ErrInvalidArchive)
+		}
+		return nil, err
+	}
+
+	if reader.Manifest.Format != Format {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidArchive, reader.Manifest.Format)
+	}
+
+	if reader.Manifest.Version < 1 || reader.Manifest.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, reader.Manifest.Version)
+	}
+
+	return reader, nil
+}
+
+// Decode reads a whole document, missing documents return fs.ErrNotExist
+func (r *Reader) Decode(name string, v any) error {
+	f, err := r.zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > maxDocumentSize {
+		return fmt.Errorf("%w: %s", ErrDocumentTooLarge, name)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(f, maxDocumentSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+	}
+
+	return nil
+}
+
+// OpenFile opens a binary file referenced by the documents
+func (r *Reader) OpenFile(name string) (io.ReadCloser, int64, error) {
+	f, err := r.zr.Open(filesDir + name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	return f, info.Size(), nil
+}
+
+// Each decodes the elements of an array document one at a time. Missing documents are treated as
+// empty, so archives of older versions stay readable when documents are added.
+func Each[T any](r *Reader, name string, fn func(T) error) error {
+	f, err := r.zr.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w: %s is not an array", ErrInvalidArchive, name)
+	}
+
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// All decodes a whole array document
+func All[T any](r *Reader, name string) ([]T, error) {
+	items := []T{}
+
+	err := Each(r, name, func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+
+	return items, err
+}
+
+// RemapJSON replaces the IDs found anywhere in a JSON document, for the documents like rule
+// conditions that reference other records by ID in free form
+func RemapJSON(raw json.RawMessage, ids map[uuid.UUID]uuid.UUID) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, nil
+	}
+
+	// Numbers are kept as written, amounts would lose precision as floats
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(remap(v, ids))
+}
+
+func remap(v any, ids map[uuid.UUID]uuid.UUID) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = remap(item, ids)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = remap(item, ids)
+		}
+	case string:
+		if id, err := uuid.Parse(val); err == nil {
+			if mapped, ok := ids[id]; ok {
+				return mapped.String()
+			}
+		}
+	}
+
+	return v
+}
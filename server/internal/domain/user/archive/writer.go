@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Writer builds an archive. The zip format allows a single open entry, so documents and files are
+// written one after the other and the manifest is added on Close.
+type Writer struct {
+	zw       *zip.Writer
+	manifest Manifest
+	open     *ArrayWriter
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		zw: zip.NewWriter(w),
+		manifest: Manifest{
+			Format:    Format,
+			Version:   Version,
+			CreatedAt: time.Now().UTC(),
+			Counts:    map[string]int{},
+		},
+	}
+}
+
+// WriteDocument writes a whole document, slices are counted in the manifest
+func (w *Writer) WriteDocument(name string, v any) error {
+	if err := w.closeOpen(); err != nil {
+		return err
+	}
+
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		return err
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		w.manifest.Counts[name] = rv.Len()
+	}
+
+	return nil
+}
+
+// Stream starts a document holding a JSON array written one element at a time, for documents too
+// large to build in memory. The array ends with the next document or on Close.
+func (w *Writer) Stream(name string) (*ArrayWriter, error) {
+	if err := w.closeOpen(); err != nil {
+		return nil, err
+	}
+
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w.open = &ArrayWriter{name: name, w: bufio.NewWriter(f)}
+	return w.open, nil
+}
+
+// AddFile copies a binary file into the archive, documents reference it by name
+func (w *Writer) AddFile(name string, r io.Reader) error {
+	if err := w.closeOpen(); err != nil {
+		return err
+	}
+
+	f, err := w.zw.Create(filesDir + name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (w *Writer) Close() error {
+	if err := w.closeOpen(); err != nil {
+		return err
+	}
+
+	f, err := w.zw.Create(ManifestDocument)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(f).Encode(w.manifest); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+func (w *Writer) closeOpen() error {
+	if w.open == nil {
+		return nil
+	}
+
+	open := w.open
+	w.open = nil
+
+	if err := open.close(); err != nil {
+		return err
+	}
+
+	w.manifest.Counts[open.name] = open.count
+	return nil
+}
+
+type ArrayWriter struct {
+	name   string
+	w      *bufio.Writer
+	count  int
+	closed bool
+}
+
+func (a *ArrayWriter) Write(v any) error {
+	if a.closed {
+		return errors.New("archive: write to a finished document")
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if a.count == 0 {
+		sep = "[\n"
+	}
+	a.count++
+
+	if _, err := a.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	_, err = a.w.Write(data)
+	return err
+}
+
+func (a *ArrayWriter) close() error {
+	a.closed = true
+
+	end := "\n]\n"
+	if a.count == 0 {
+		end = "[]\n"
+	}
+
+	if _, err := a.w.WriteString(end); err != nil {
+		return err
+	}
+
+	return a.w.Flush()
+}
@@ -0,0 +1,5 @@
+package user
+
+import "errors"
+
+var ErrArchiveTargetNotEmpty = errors.New("archives can only be imported into an account without accounts or transactions")
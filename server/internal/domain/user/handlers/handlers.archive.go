@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/user"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/user/archive"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+)
+
+// maxArchiveSize bounds the archives accepted by ImportArchive
+const maxArchiveSize = 512 << 20
+
+// archiveResponse remembers whether the archive started to be sent, errors can only be reported
+// to the client before that
+type archiveResponse struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (a *archiveResponse) Write(p []byte) (int, error) {
+	if !a.started {
+		a.started = true
+		a.Header().Set("Content-Type", "application/zip")
+		a.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "nuts-"+time.Now().Format("2006-01-02")+".zip"))
+		a.WriteHeader(http.StatusOK)
+	}
+
+	return a.ResponseWriter.Write(p)
+}
+
+// ExportArchive streams a zip holding all the data of the user, to move to another instance
+func (h *Handler) ExportArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	res := &archiveResponse{ResponseWriter: w}
+
+	if err := h.service.ExportArchive(ctx, userID, res); err != nil {
+		if res.started {
+			h.logger.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to send archive")
+			return
+		}
+
+		h.archiveError(w, r, err, userID)
+	}
+}
+
+// ImportArchive restores an archive sent as the "file" field of a multipart form
+func (h *Handler) ImportArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusUnauthorized,
+			ClientErr:  message.ErrUnauthorized,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxArchiveSize)
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    "Failed to parse form",
+		})
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          w,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    "No archive file found in request",
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportArchive(ctx, userID, file, header.Size)
+	if err != nil {
+		h.archiveError(w, r, err, header.Filename)
+		return
+	}
+
+	respond.Json(w, http.StatusCreated, result, h.logger)
+}
+
+func (h *Handler) archiveError(w http.ResponseWriter, r *http.Request, err error, details any) {
+	statusCode := http.StatusInternalServerError
+	clientErr := message.ErrInternalError
+
+	switch {
+	case errors.Is(err, archive.ErrInvalidArchive),
+		errors.Is(err, archive.ErrUnsupportedVersion),
+		errors.Is(err, archive.ErrDocumentTooLarge):
+		statusCode = http.StatusBadRequest
+		clientErr = err
+	case errors.Is(err, user.ErrArchiveTargetNotEmpty):
+		statusCode = http.StatusConflict
+		clientErr = err
+	}
+
+	respond.Error(respond.ErrorOptions{
+		W:          w,
+		R:          r,
+		StatusCode: statusCode,
+		ClientErr:  clientErr,
+		ActualErr:  err,
+		Logger:     h.logger,
+		Details:    details,
+	})
+}
@@ -22,6 +22,8 @@ func RegisterHTTPHandlers(service service.Users, tkn *jwt.Service, validator *va
 	router.Put("/me", h.UpdateInfo)
 	router.Delete("/me", h.DeleteInfo)
 	router.Put("/me/avatar", h.UploadAvatar)
+	router.Get("/me/archive", h.ExportArchive)
+	router.Post("/me/archive", h.ImportArchive)
 
 	// preferences
 	router.Get("/preferences", h.GetPreferences)
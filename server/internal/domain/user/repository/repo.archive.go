@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/google/uuid"
+)
+
+func (r *repo) CountArchiveRecords(ctx context.Context, userID uuid.UUID) (repository.CountArchiveRecordsRow, error) {
+	return r.queries.CountArchiveRecords(ctx, &userID)
+}
+
+func (r *repo) ListArchiveAccounts(ctx context.Context, userID uuid.UUID) ([]repository.Account, error) {
+	return r.queries.ListArchiveAccounts(ctx, &userID)
+}
+
+func (r *repo) ListArchiveCategories(ctx context.Context, userID uuid.UUID) ([]repository.Category, error) {
+	return r.queries.ListArchiveCategories(ctx, userID)
+}
+
+func (r *repo) ListArchiveTags(ctx context.Context, userID uuid.UUID) ([]repository.Tag, error) {
+	return r.queries.ListArchiveTags(ctx, userID)
+}
+
+func (r *repo) ListArchiveTransactions(ctx context.Context, userID uuid.UUID, limit, offset int64) ([]repository.Transaction, error) {
+	return r.queries.ListArchiveTransactions(ctx, repository.ListArchiveTransactionsParams{
+		UserID: &userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (r *repo) ListArchiveTransactionSplits(ctx context.Context, userID uuid.UUID) ([]repository.TransactionSplit, error) {
+	return r.queries.ListArchiveTransactionSplits(ctx, &userID)
+}
+
+func (r *repo) ListArchiveRules(ctx context.Context, userID uuid.UUID) ([]repository.TransactionRule, error) {
+	return r.queries.ListArchiveRules(ctx, userID)
+}
+
+func (r *repo) ListArchiveRecurringTransactions(ctx context.Context, userID uuid.UUID) ([]repository.RecurringTransaction, error) {
+	return r.queries.ListArchiveRecurringTransactions(ctx, userID)
+}
+
+func (r *repo) ListArchiveBudgets(ctx context.Context, userID uuid.UUID) ([]repository.Budget, error) {
+	return r.queries.ListArchiveBudgets(ctx, userID)
+}
+
+func (r *repo) ListArchiveAttachments(ctx context.Context, userID uuid.UUID) ([]repository.TransactionAttachment, error) {
+	return r.queries.ListArchiveAttachments(ctx, userID)
+}
+
+func (r *repo) RestoreAccount(ctx context.Context, params repository.RestoreAccountParams) error {
+	return r.queries.RestoreAccount(ctx, params)
+}
+
+func (r *repo) RestoreCategory(ctx context.Context, params repository.RestoreCategoryParams) error {
+	return r.queries.RestoreCategory(ctx, params)
+}
+
+// RestoreTag returns the ID of the tag, an existing tag of the same name is reused
+func (r *repo) RestoreTag(ctx context.Context, params repository.RestoreTagParams) (uuid.UUID, error) {
+	return r.queries.RestoreTag(ctx, params)
+}
+
+func (r *repo) RestoreTransactions(ctx context.Context, params []repository.RestoreTransactionsParams) (int64, error) {
+	return r.queries.RestoreTransactions(ctx, params)
+}
+
+func (r *repo) RestoreTransactionSplit(ctx context.Context, params repository.RestoreTransactionSplitParams) error {
+	return r.queries.RestoreTransactionSplit(ctx, params)
+}
+
+func (r *repo) RestoreRule(ctx context.Context, params repository.RestoreRuleParams) error {
+	return r.queries.RestoreRule(ctx, params)
+}
+
+func (r *repo) RestoreRecurringTransaction(ctx context.Context, params repository.RestoreRecurringTransactionParams) error {
+	return r.queries.RestoreRecurringTransaction(ctx, params)
+}
+
+func (r *repo) RestoreBudget(ctx context.Context, params repository.RestoreBudgetParams) error {
+	return r.queries.RestoreBudget(ctx, params)
+}
+
+func (r *repo) RestoreAttachment(ctx context.Context, params repository.RestoreAttachmentParams) error {
+	return r.queries.RestoreAttachment(ctx, params)
+}
@@ -24,6 +24,27 @@ type Users interface {
 	// Preferences
 	GetUserPreferences(ctx context.Context, userID uuid.UUID) (repository.GetPreferencesByUserIdRow, error)
 	UpdatePreferences(ctx context.Context, params repository.UpdatePreferencesParams) (repository.Preference, error)
+
+	// Archive
+	CountArchiveRecords(ctx context.Context, userID uuid.UUID) (repository.CountArchiveRecordsRow, error)
+	ListArchiveAccounts(ctx context.Context, userID uuid.UUID) ([]repository.Account, error)
+	ListArchiveCategories(ctx context.Context, userID uuid.UUID) ([]repository.Category, error)
+	ListArchiveTags(ctx context.Context, userID uuid.UUID) ([]repository.Tag, error)
+	ListArchiveTransactions(ctx context.Context, userID uuid.UUID, limit, offset int64) ([]repository.Transaction, error)
+	ListArchiveTransactionSplits(ctx context.Context, userID uuid.UUID) ([]repository.TransactionSplit, error)
+	ListArchiveRules(ctx context.Context, userID uuid.UUID) ([]repository.TransactionRule, error)
+	ListArchiveRecurringTransactions(ctx context.Context, userID uuid.UUID) ([]repository.RecurringTransaction, error)
+	ListArchiveBudgets(ctx context.Context, userID uuid.UUID) ([]repository.Budget, error)
+	ListArchiveAttachments(ctx context.Context, userID uuid.UUID) ([]repository.TransactionAttachment, error)
+	RestoreAccount(ctx context.Context, params repository.RestoreAccountParams) error
+	RestoreCategory(ctx context.Context, params repository.RestoreCategoryParams) error
+	RestoreTag(ctx context.Context, params repository.RestoreTagParams) (uuid.UUID, error)
+	RestoreTransactions(ctx context.Context, params []repository.RestoreTransactionsParams) (int64, error)
+	RestoreTransactionSplit(ctx context.Context, params repository.RestoreTransactionSplitParams) error
+	RestoreRule(ctx context.Context, params repository.RestoreRuleParams) error
+	RestoreRecurringTransaction(ctx context.Context, params repository.RestoreRecurringTransactionParams) error
+	RestoreBudget(ctx context.Context, params repository.RestoreBudgetParams) error
+	RestoreAttachment(ctx context.Context, params repository.RestoreAttachmentParams) error
 }
 
 type repo struct {
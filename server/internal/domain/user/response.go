@@ -35,3 +35,9 @@ type UpdateUserPreferencesReq struct {
 	StartWeekOnMonday *bool   `json:"start_week_on_monday"`
 	DarkSidebar       *bool   `json:"dark_sidebar"`
 }
+
+// ImportArchiveResponse reports what an archive import restored, keyed by document
+type ImportArchiveResponse struct {
+	Counts   map[string]int `json:"counts"`
+	Warnings []string       `json:"warnings"`
+}
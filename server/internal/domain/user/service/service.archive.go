@@ -0,0 +1,1055 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/user"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/user/archive"
+	userRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/user/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// archivePageSize is the number of transactions read or restored at once
+const archivePageSize = 500
+
+// maxArchiveAvatarSize matches the limit of avatar uploads
+const maxArchiveAvatarSize = 5 << 20
+
+// Image types an avatar is restored from, with the extension it is stored under
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ExportArchive writes everything the user owns as a portable archive
+func (s *UserService) ExportArchive(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	userData, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	zw := archive.NewWriter(w)
+
+	profile := archive.Profile{
+		Email:     userData.Email,
+		FirstName: userData.FirstName,
+		LastName:  userData.LastName,
+	}
+
+	if userData.AvatarKey != nil {
+		avatar, err := s.storage.Download(ctx, s.config.PublicBucketName, *userData.AvatarKey)
+		if err != nil {
+			return fmt.Errorf("download avatar: %w", err)
+		}
+
+		name := "avatar" + filepath.Ext(*userData.AvatarKey)
+		err = zw.AddFile(name, avatar)
+		avatar.Close()
+		if err != nil {
+			return err
+		}
+
+		profile.Avatar = &name
+	}
+
+	if err := zw.WriteDocument(archive.ProfileDocument, profile); err != nil {
+		return err
+	}
+
+	prefs, err := s.userRepo.GetUserPreferences(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		if err := zw.WriteDocument(archive.PreferencesDocument, archive.Preferences{
+			Locale:            prefs.Locale,
+			Theme:             prefs.Theme,
+			Currency:          prefs.Currency,
+			Timezone:          prefs.Timezone,
+			TimeFormat:        prefs.TimeFormat,
+			DateFormat:        prefs.DateFormat,
+			StartWeekOnMonday: prefs.StartWeekOnMonday,
+			DarkSidebar:       prefs.DarkSidebar,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.exportArchiveRecords(ctx, zw, userID); err != nil {
+		return err
+	}
+
+	if err := s.exportArchiveTransactions(ctx, zw, userID); err != nil {
+		return err
+	}
+
+	if err := s.exportArchiveSplits(ctx, zw, userID); err != nil {
+		return err
+	}
+
+	if err := s.exportArchiveAttachments(ctx, zw, userID); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (s *UserService) exportArchiveRecords(ctx context.Context, zw *archive.Writer, userID uuid.UUID) error {
+	accounts, err := s.userRepo.ListArchiveAccounts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	accountDocs := make([]archive.Account, 0, len(accounts))
+	for _, a := range accounts {
+		accountDocs = append(accountDocs, archive.Account{
+			ID:              a.ID,
+			Name:            a.Name,
+			Type:            string(a.Type),
+			Subtype:         a.Subtype,
+			Balance:         types.PgtypeNumericToDecimal(a.Balance),
+			Currency:        a.Currency,
+			InstitutionName: a.Meta.InstitutionName,
+			ProviderName:    a.ProviderName,
+			CreatedAt:       a.CreatedAt,
+		})
+	}
+
+	if err := zw.WriteDocument(archive.AccountsDocument, accountDocs); err != nil {
+		return err
+	}
+
+	categories, err := s.userRepo.ListArchiveCategories(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	categoryDocs := make([]archive.Category, 0, len(categories))
+	for _, c := range categories {
+		categoryDocs = append(categoryDocs, archive.Category{
+			ID:        c.ID,
+			Name:      c.Name,
+			ParentID:  c.ParentID,
+			Type:      c.Type,
+			Color:     c.Color,
+			Icon:      c.Icon,
+			IsDefault: c.IsDefault != nil && *c.IsDefault,
+		})
+	}
+
+	if err := zw.WriteDocument(archive.CategoriesDocument, categoryDocs); err != nil {
+		return err
+	}
+
+	tags, err := s.userRepo.ListArchiveTags(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tagDocs := make([]archive.Tag, 0, len(tags))
+	for _, t := range tags {
+		tagDocs = append(tagDocs, archive.Tag{ID: t.ID, Name: t.Name, Color: t.Color})
+	}
+
+	if err := zw.WriteDocument(archive.TagsDocument, tagDocs); err != nil {
+		return err
+	}
+
+	rules, err := s.userRepo.ListArchiveRules(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	ruleDocs := make([]archive.Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := archive.Rule{
+			ID:         r.ID,
+			Name:       r.Name,
+			IsActive:   r.IsActive == nil || *r.IsActive,
+			Conditions: r.Conditions,
+			Actions:    r.Actions,
+			CreatedAt:  r.CreatedAt,
+		}
+		if r.Priority != nil {
+			rule.Priority = *r.Priority
+		}
+		ruleDocs = append(ruleDocs, rule)
+	}
+
+	if err := zw.WriteDocument(archive.RulesDocument, ruleDocs); err != nil {
+		return err
+	}
+
+	recurring, err := s.userRepo.ListArchiveRecurringTransactions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	recurringDocs := make([]archive.RecurringTransaction, 0, len(recurring))
+	for _, r := range recurring {
+		recurringDocs = append(recurringDocs, archive.RecurringTransaction{
+			ID:                   r.ID,
+			AccountID:            r.AccountID,
+			CategoryID:           r.CategoryID,
+			DestinationAccountID: r.DestinationAccountID,
+			Amount:               types.PgtypeNumericToDecimal(r.Amount),
+			Type:                 r.Type,
+			Description:          r.Description,
+			Details:              r.Details,
+			Frequency:            r.Frequency,
+			FrequencyInterval:    r.FrequencyInterval,
+			FrequencyData:        r.FrequencyData,
+			StartDate:            r.StartDate,
+			EndDate:              r.EndDate,
+			LastGeneratedDate:    r.LastGeneratedDate,
+			NextDueDate:          r.NextDueDate,
+			AutoPost:             r.AutoPost,
+			IsPaused:             r.IsPaused,
+			MaxOccurrences:       r.MaxOccurrences,
+			OccurrencesCount:     r.OccurrencesCount,
+			TemplateName:         r.TemplateName,
+			Tags:                 r.Tags,
+		})
+	}
+
+	if err := zw.WriteDocument(archive.RecurringTransactionsDocument, recurringDocs); err != nil {
+		return err
+	}
+
+	budgets, err := s.userRepo.ListArchiveBudgets(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	budgetDocs := make([]archive.Budget, 0, len(budgets))
+	for _, b := range budgets {
+		budgetDocs = append(budgetDocs, archive.Budget{
+			ID:              b.ID,
+			CategoryID:      b.CategoryID,
+			Name:            b.Name,
+			Amount:          types.PgtypeNumericToDecimal(b.Amount),
+			StartDate:       b.StartDate.Time,
+			EndDate:         b.EndDate.Time,
+			Frequency:       b.Frequency,
+			RolloverEnabled: b.RolloverEnabled,
+		})
+	}
+
+	return zw.WriteDocument(archive.BudgetsDocument, budgetDocs)
+}
+
+func (s *UserService) exportArchiveTransactions(ctx context.Context, zw *archive.Writer, userID uuid.UUID) error {
+	stream, err := zw.Stream(archive.TransactionsDocument)
+	if err != nil {
+		return err
+	}
+
+	for offset := int64(0); ; offset += archivePageSize {
+		page, err := s.userRepo.ListArchiveTransactions(ctx, userID, archivePageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range page {
+			doc := archive.Transaction{
+				ID:                     t.ID,
+				Type:                   t.Type,
+				Amount:                 types.PgtypeNumericToDecimal(t.Amount),
+				Currency:               t.TransactionCurrency,
+				OriginalAmount:         types.PgtypeNumericToDecimal(t.OriginalAmount),
+				AccountID:              t.AccountID,
+				DestinationAccountID:   t.DestinationAccountID,
+				CategoryID:             t.CategoryID,
+				Date:                   t.TransactionDatetime,
+				Description:            t.Description,
+				Details:                t.Details,
+				IsExternal:             t.IsExternal != nil && *t.IsExternal,
+				IsCategorized:          t.IsCategorized != nil && *t.IsCategorized,
+				RecurringTransactionID: t.RecurringTransactionID,
+				RecurringInstanceDate:  t.RecurringInstanceDate,
+				CreatedAt:              t.CreatedAt,
+			}
+
+			if t.ExchangeRate.Valid {
+				doc.ExchangeRate = decimal.NewNullDecimal(types.PgtypeNumericToDecimal(t.ExchangeRate))
+			}
+
+			if t.ExchangeRateDate.Valid {
+				doc.ExchangeRateDate = &t.ExchangeRateDate.Time
+			}
+
+			if err := stream.Write(doc); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < archivePageSize {
+			return nil
+		}
+	}
+}
+
+func (s *UserService) exportArchiveSplits(ctx context.Context, zw *archive.Writer, userID uuid.UUID) error {
+	splits, err := s.userRepo.ListArchiveTransactionSplits(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]archive.Split, 0, len(splits))
+	for _, split := range splits {
+		docs = append(docs, archive.Split{
+			ID:            split.ID,
+			TransactionID: split.TransactionID,
+			CategoryID:    split.CategoryID,
+			Amount:        types.PgtypeNumericToDecimal(split.Amount),
+			Percentage:    types.PgtypeNumericToDecimal(split.Percentage),
+			Description:   split.Description,
+			CreatedAt:     split.CreatedAt,
+		})
+	}
+
+	return zw.WriteDocument(archive.SplitsDocument, docs)
+}
+
+// exportArchiveAttachments adds the decrypted attachment files, the archive is portable and
+// can't depend on the keys of this instance
+func (s *UserService) exportArchiveAttachments(ctx context.Context, zw *archive.Writer, userID uuid.UUID) error {
+	list, err := s.userRepo.ListArchiveAttachments(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]archive.Attachment, 0, len(list))
+	if len(list) > 0 {
+		store, err := s.attachmentStore()
+		if err != nil {
+			return err
+		}
+
+		for _, att := range list {
+			content, err := store.Read(ctx, att.StorageKey, att.EncryptionKeyID)
+			if err != nil {
+				return fmt.Errorf("read attachment %s: %w", att.ID, err)
+			}
+
+			name := "attachments/" + att.ID.String() + filepath.Ext(att.Filename)
+			if err := zw.AddFile(name, bytes.NewReader(content)); err != nil {
+				return err
+			}
+
+			docs = append(docs, archive.Attachment{
+				ID:            att.ID,
+				TransactionID: att.TransactionID,
+				Filename:      att.Filename,
+				MimeType:      att.MimeType,
+				Size:          int64(len(content)),
+				File:          name,
+				CreatedAt:     att.CreatedAt,
+			})
+		}
+	}
+
+	return zw.WriteDocument(archive.AttachmentsDocument, docs)
+}
+
+// ImportArchive restores an archive into the account of the user. Every record gets a new ID so
+// archives can be imported on the instance they come from, categories matching the defaults of
+// the account are merged into them. Provider connections aren't portable, accounts come back as
+// manual accounts.
+func (s *UserService) ImportArchive(ctx context.Context, userID uuid.UUID, r io.ReaderAt, size int64) (user.ImportArchiveResponse, error) {
+	result := user.ImportArchiveResponse{Counts: map[string]int{}, Warnings: []string{}}
+
+	ar, err := archive.NewReader(r, size)
+	if err != nil {
+		return result, err
+	}
+
+	existing, err := s.userRepo.CountArchiveRecords(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	if existing.Accounts > 0 || existing.Transactions > 0 {
+		return result, user.ErrArchiveTargetNotEmpty
+	}
+
+	var profile archive.Profile
+	if err := ar.Decode(archive.ProfileDocument, &profile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return result, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			fmt.Println("Failed to roll the transaction")
+		}
+	}()
+
+	restore := archiveRestore{
+		repo:   s.userRepo.WithTx(tx),
+		reader: ar,
+		userID: userID,
+		ids:    map[uuid.UUID]uuid.UUID{},
+		result: &result,
+	}
+
+	if err := restore.run(ctx, profile); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+
+	// The avatar and attachments live in the storage, a failure there shouldn't undo the import
+	if profile.Avatar != nil {
+		if err := s.restoreAvatar(ctx, userID, ar, *profile.Avatar); err != nil {
+			result.Warnings = append(result.Warnings, "the avatar couldn't be restored")
+		}
+	}
+
+	if err := s.restoreAttachments(ctx, &restore); err != nil {
+		result.Warnings = append(result.Warnings, "the attachments couldn't be restored")
+	}
+
+	return result, nil
+}
+
+// restoreAvatar uploads the avatar of the archive to the public bucket. Whatever the archive names
+// it, only images are restored, under an extension matching their content
+func (s *UserService) restoreAvatar(ctx context.Context, userID uuid.UUID, ar *archive.Reader, name string) error {
+	f, size, err := ar.OpenFile(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if size > maxArchiveAvatarSize {
+		return fmt.Errorf("avatar too large: %d bytes", size)
+	}
+
+	// The size recorded in the archive may not be the real one
+	content, err := io.ReadAll(io.LimitReader(f, maxArchiveAvatarSize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(content) > maxArchiveAvatarSize {
+		return fmt.Errorf("avatar too large: more than %d bytes", maxArchiveAvatarSize)
+	}
+
+	contentType := http.DetectContentType(content)
+
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		return fmt.Errorf("avatar isn't an image: %s", contentType)
+	}
+
+	_, err = s.UpdateUserAvatar(ctx, userID, "avatar"+ext, int64(len(content)), bytes.NewReader(content))
+	return err
+}
+
+// restoreAttachments stores the attachment files of the archive, encrypted with the key of this
+// instance, once the transactions they belong to are committed
+func (s *UserService) restoreAttachments(ctx context.Context, a *archiveRestore) error {
+	docs, err := archive.All[archive.Attachment](a.reader, archive.AttachmentsDocument)
+	if err != nil || len(docs) == 0 {
+		return err
+	}
+
+	store, err := s.attachmentStore()
+	if err != nil {
+		return err
+	}
+
+	skipped, failed := 0, 0
+	for _, doc := range docs {
+		transactionID := a.mapped(&doc.TransactionID)
+		if transactionID == nil {
+			skipped++
+			continue
+		}
+
+		if err := s.restoreAttachment(ctx, store, a, doc, *transactionID); err != nil {
+			failed++
+			continue
+		}
+
+		a.result.Counts[archive.AttachmentsDocument]++
+	}
+
+	if skipped > 0 {
+		a.warn("%d attachments were skipped because their transaction isn't in the archive", skipped)
+	}
+
+	if failed > 0 {
+		a.warn("%d attachments couldn't be restored", failed)
+	}
+
+	return nil
+}
+
+func (s *UserService) restoreAttachment(ctx context.Context, store *attachments.Store, a *archiveRestore, doc archive.Attachment, transactionID uuid.UUID) error {
+	f, _, err := a.reader.OpenFile(doc.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, attachments.MaxSize+1))
+	if err != nil {
+		return err
+	}
+
+	// Archives can be edited by hand, their files go through the checks of regular uploads
+	if err := attachments.Check(content, doc.MimeType); err != nil {
+		return err
+	}
+
+	id := uuid.New()
+	key := attachments.Key(a.userID, transactionID, id)
+
+	keyID, err := store.Write(ctx, key, content)
+	if err != nil {
+		return err
+	}
+
+	filename := attachments.SanitizeFilename(doc.Filename)
+
+	if err := s.userRepo.RestoreAttachment(ctx, repository.RestoreAttachmentParams{
+		ID:               id,
+		TransactionID:    transactionID,
+		Filename:         filename,
+		OriginalFilename: filename,
+		FileSize:         int64(len(content)),
+		MimeType:         attachments.NormalizeType(doc.MimeType),
+		StorageKey:       key,
+		BucketName:       store.Bucket(),
+		IsEncrypted:      true,
+		EncryptionKeyID:  &keyID,
+		UploadedBy:       a.userID,
+		CreatedAt:        orNow(doc.CreatedAt),
+	}); err != nil {
+		if delErr := store.Delete(ctx, key); delErr != nil {
+			return errors.Join(err, delErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserService) attachmentStore() (*attachments.Store, error) {
+	encrypter, err := encrypt.NewEncrypter(s.config.EncryptionSecretKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments.NewStore(s.storage, s.config.PrivateBucketName, encrypter), nil
+}
+
+// archiveRestore holds the state of an import, ids maps the IDs of the archive to the new ones
+type archiveRestore struct {
+	repo   userRepo.Users
+	reader *archive.Reader
+	userID uuid.UUID
+	ids    map[uuid.UUID]uuid.UUID
+	result *user.ImportArchiveResponse
+}
+
+func (a *archiveRestore) run(ctx context.Context, profile archive.Profile) error {
+	if profile.FirstName != nil || profile.LastName != nil {
+		if _, err := a.repo.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:        a.userID,
+			FirstName: profile.FirstName,
+			LastName:  profile.LastName,
+		}); err != nil {
+			return err
+		}
+	}
+
+	var prefs archive.Preferences
+	err := a.reader.Decode(archive.PreferencesDocument, &prefs)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err == nil {
+		if _, err := a.repo.UpdatePreferences(ctx, repository.UpdatePreferencesParams{
+			UserID:            a.userID,
+			Locale:            &prefs.Locale,
+			Theme:             &prefs.Theme,
+			Currency:          &prefs.Currency,
+			Timezone:          &prefs.Timezone,
+			TimeFormat:        &prefs.TimeFormat,
+			DateFormat:        &prefs.DateFormat,
+			StartWeekOnMonday: &prefs.StartWeekOnMonday,
+			DarkSidebar:       &prefs.DarkSidebar,
+		}); err != nil {
+			return err
+		}
+	}
+
+	steps := []func(context.Context) error{
+		a.categories,
+		a.accounts,
+		a.tags,
+		a.recurringTransactions,
+		a.transactions,
+		a.splits,
+		a.rules,
+		a.budgets,
+	}
+
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mapped returns the new ID of a record of the archive, nil when it wasn't restored
+func (a *archiveRestore) mapped(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+
+	newID, ok := a.ids[*id]
+	if !ok {
+		return nil
+	}
+
+	return &newID
+}
+
+// orNow replaces the creation dates missing from hand-made archives
+func orNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+
+	return t
+}
+
+func (a *archiveRestore) warn(format string, args ...any) {
+	a.result.Warnings = append(a.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// categories restores parents before their children and reuses the categories the account
+// already has with the same name, type and parent
+func (a *archiveRestore) categories(ctx context.Context) error {
+	categories, err := archive.All[archive.Category](a.reader, archive.CategoriesDocument)
+	if err != nil {
+		return err
+	}
+
+	current, err := a.repo.ListArchiveCategories(ctx, a.userID)
+	if err != nil {
+		return err
+	}
+
+	categoryKey := func(name, kind string, parentID *uuid.UUID) string {
+		parent := ""
+		if parentID != nil {
+			parent = parentID.String()
+		}
+		return strings.ToLower(strings.TrimSpace(name)) + "|" + kind + "|" + parent
+	}
+
+	existing := make(map[string]uuid.UUID, len(current))
+	for _, c := range current {
+		existing[categoryKey(c.Name, c.Type, c.ParentID)] = c.ID
+	}
+
+	inArchive := make(map[uuid.UUID]bool, len(categories))
+	for _, c := range categories {
+		inArchive[c.ID] = true
+	}
+
+	pending := categories
+	for len(pending) > 0 {
+		var deferred []archive.Category
+
+		for _, c := range pending {
+			// Children wait for their parent when it is part of the archive
+			if c.ParentID != nil && inArchive[*c.ParentID] && a.mapped(c.ParentID) == nil {
+				deferred = append(deferred, c)
+				continue
+			}
+
+			parentID := a.mapped(c.ParentID)
+
+			if id, ok := existing[categoryKey(c.Name, c.Type, parentID)]; ok {
+				a.ids[c.ID] = id
+				continue
+			}
+
+			id := uuid.New()
+			if err := a.repo.RestoreCategory(ctx, repository.RestoreCategoryParams{
+				ID:        id,
+				Name:      c.Name,
+				ParentID:  parentID,
+				IsDefault: &c.IsDefault,
+				CreatedBy: a.userID,
+				Type:      c.Type,
+				Color:     c.Color,
+				Icon:      c.Icon,
+			}); err != nil {
+				return fmt.Errorf("restore category %q: %w", c.Name, err)
+			}
+
+			a.ids[c.ID] = id
+			a.result.Counts[archive.CategoriesDocument]++
+		}
+
+		// Nothing was restored in this pass, the parents form a cycle and the remaining
+		// categories are restored at the top level
+		if len(deferred) == len(pending) {
+			for i := range deferred {
+				deferred[i].ParentID = nil
+			}
+		}
+
+		pending = deferred
+	}
+
+	return nil
+}
+
+func (a *archiveRestore) accounts(ctx context.Context) error {
+	accounts, err := archive.All[archive.Account](a.reader, archive.AccountsDocument)
+	if err != nil {
+		return err
+	}
+
+	linked := 0
+
+	for _, acc := range accounts {
+		accountType := repository.ACCOUNTTYPE(acc.Type)
+		if !accountType.Valid() {
+			return fmt.Errorf("%w: account %q has an unknown type %q", archive.ErrInvalidArchive, acc.Name, acc.Type)
+		}
+
+		id := uuid.New()
+		if err := a.repo.RestoreAccount(ctx, repository.RestoreAccountParams{
+			ID:        id,
+			Name:      acc.Name,
+			Type:      accountType,
+			Subtype:   acc.Subtype,
+			Balance:   decimal.NewNullDecimal(acc.Balance),
+			Currency:  acc.Currency,
+			Meta:      dto.AccountMeta{InstitutionName: acc.InstitutionName},
+			CreatedBy: &a.userID,
+			CreatedAt: orNow(acc.CreatedAt),
+		}); err != nil {
+			return fmt.Errorf("restore account %q: %w", acc.Name, err)
+		}
+
+		if acc.ProviderName != nil {
+			linked++
+		}
+
+		a.ids[acc.ID] = id
+		a.result.Counts[archive.AccountsDocument]++
+	}
+
+	if linked > 0 {
+		a.warn("%d linked accounts were restored as manual accounts, connect them again to resume syncing", linked)
+	}
+
+	return nil
+}
+
+func (a *archiveRestore) tags(ctx context.Context) error {
+	return archive.Each(a.reader, archive.TagsDocument, func(t archive.Tag) error {
+		id, err := a.repo.RestoreTag(ctx, repository.RestoreTagParams{
+			ID:     uuid.New(),
+			UserID: a.userID,
+			Name:   t.Name,
+			Color:  t.Color,
+		})
+		if err != nil {
+			return fmt.Errorf("restore tag %q: %w", t.Name, err)
+		}
+
+		a.ids[t.ID] = id
+		a.result.Counts[archive.TagsDocument]++
+		return nil
+	})
+}
+
+func (a *archiveRestore) recurringTransactions(ctx context.Context) error {
+	skipped := 0
+
+	err := archive.Each(a.reader, archive.RecurringTransactionsDocument, func(r archive.RecurringTransaction) error {
+		accountID := a.mapped(&r.AccountID)
+		if accountID == nil {
+			skipped++
+			return nil
+		}
+
+		frequencyData, err := archive.RemapJSON(r.FrequencyData, a.ids)
+		if err != nil {
+			return fmt.Errorf("%w: recurring transaction %s: %v", archive.ErrInvalidArchive, r.ID, err)
+		}
+
+		tags, err := archive.RemapJSON(r.Tags, a.ids)
+		if err != nil {
+			return fmt.Errorf("%w: recurring transaction %s: %v", archive.ErrInvalidArchive, r.ID, err)
+		}
+
+		id := uuid.New()
+		if err := a.repo.RestoreRecurringTransaction(ctx, repository.RestoreRecurringTransactionParams{
+			ID:                   id,
+			UserID:               a.userID,
+			AccountID:            *accountID,
+			CategoryID:           a.mapped(r.CategoryID),
+			DestinationAccountID: a.mapped(r.DestinationAccountID),
+			Amount:               r.Amount,
+			Type:                 r.Type,
+			Description:          r.Description,
+			Details:              r.Details,
+			Frequency:            r.Frequency,
+			FrequencyInterval:    r.FrequencyInterval,
+			FrequencyData:        frequencyData,
+			StartDate:            r.StartDate,
+			EndDate:              r.EndDate,
+			LastGeneratedDate:    r.LastGeneratedDate,
+			NextDueDate:          r.NextDueDate,
+			AutoPost:             r.AutoPost,
+			IsPaused:             r.IsPaused,
+			MaxOccurrences:       r.MaxOccurrences,
+			OccurrencesCount:     r.OccurrencesCount,
+			TemplateName:         r.TemplateName,
+			Tags:                 tags,
+		}); err != nil {
+			return fmt.Errorf("restore recurring transaction %s: %w", r.ID, err)
+		}
+
+		a.ids[r.ID] = id
+		a.result.Counts[archive.RecurringTransactionsDocument]++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		a.warn("%d recurring transactions were skipped because their account isn't in the archive", skipped)
+	}
+
+	return nil
+}
+
+func (a *archiveRestore) transactions(ctx context.Context) error {
+	skipped := 0
+	batch := make([]repository.RestoreTransactionsParams, 0, archivePageSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		n, err := a.repo.RestoreTransactions(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("restore transactions: %w", err)
+		}
+
+		a.result.Counts[archive.TransactionsDocument] += int(n)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := archive.Each(a.reader, archive.TransactionsDocument, func(t archive.Transaction) error {
+		accountID := a.mapped(&t.AccountID)
+		if accountID == nil {
+			skipped++
+			return nil
+		}
+
+		id := uuid.New()
+		a.ids[t.ID] = id
+
+		params := repository.RestoreTransactionsParams{
+			ID:                     id,
+			Amount:                 t.Amount,
+			Type:                   t.Type,
+			AccountID:              *accountID,
+			CategoryID:             a.mapped(t.CategoryID),
+			DestinationAccountID:   a.mapped(t.DestinationAccountID),
+			TransactionDatetime:    t.Date,
+			Description:            t.Description,
+			Details:                t.Details,
+			CreatedBy:              &a.userID,
+			IsExternal:             &t.IsExternal,
+			TransactionCurrency:    t.Currency,
+			OriginalAmount:         t.OriginalAmount,
+			ExchangeRate:           t.ExchangeRate,
+			IsCategorized:          &t.IsCategorized,
+			RecurringTransactionID: a.mapped(t.RecurringTransactionID),
+			RecurringInstanceDate:  t.RecurringInstanceDate,
+			CreatedAt:              orNow(t.CreatedAt),
+		}
+
+		if t.ExchangeRateDate != nil {
+			params.ExchangeRateDate = pgtype.Date{Time: *t.ExchangeRateDate, Valid: true}
+		}
+
+		batch = append(batch, params)
+		if len(batch) == archivePageSize {
+			return flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		a.warn("%d transactions were skipped because their account isn't in the archive", skipped)
+	}
+
+	return nil
+}
+
+// splits restores the splits of the restored transactions, categories that aren't in the archive
+// are left empty like the ones deleted
+func (a *archiveRestore) splits(ctx context.Context) error {
+	skipped := 0
+
+	err := archive.Each(a.reader, archive.SplitsDocument, func(split archive.Split) error {
+		transactionID := a.mapped(&split.TransactionID)
+		if transactionID == nil {
+			skipped++
+			return nil
+		}
+
+		id := uuid.New()
+		if err := a.repo.RestoreTransactionSplit(ctx, repository.RestoreTransactionSplitParams{
+			ID:            id,
+			TransactionID: *transactionID,
+			CategoryID:    a.mapped(split.CategoryID),
+			Amount:        split.Amount,
+			Percentage:    split.Percentage,
+			Description:   split.Description,
+			CreatedAt:     orNow(split.CreatedAt),
+		}); err != nil {
+			return fmt.Errorf("restore split %s: %w", split.ID, err)
+		}
+
+		a.ids[split.ID] = id
+		a.result.Counts[archive.SplitsDocument]++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		a.warn("%d splits were skipped because their transaction isn't in the archive", skipped)
+	}
+
+	return nil
+}
+
+func (a *archiveRestore) rules(ctx context.Context) error {
+	return archive.Each(a.reader, archive.RulesDocument, func(r archive.Rule) error {
+		conditions, err := archive.RemapJSON(r.Conditions, a.ids)
+		if err != nil {
+			return fmt.Errorf("%w: rule %q: %v", archive.ErrInvalidArchive, r.Name, err)
+		}
+
+		actions, err := archive.RemapJSON(r.Actions, a.ids)
+		if err != nil {
+			return fmt.Errorf("%w: rule %q: %v", archive.ErrInvalidArchive, r.Name, err)
+		}
+
+		id := uuid.New()
+		if err := a.repo.RestoreRule(ctx, repository.RestoreRuleParams{
+			ID:         id,
+			Name:       r.Name,
+			IsActive:   &r.IsActive,
+			Priority:   &r.Priority,
+			Conditions: conditions,
+			Actions:    actions,
+			CreatedBy:  a.userID,
+			CreatedAt:  orNow(r.CreatedAt),
+		}); err != nil {
+			return fmt.Errorf("restore rule %q: %w", r.Name, err)
+		}
+
+		a.ids[r.ID] = id
+		a.result.Counts[archive.RulesDocument]++
+		return nil
+	})
+}
+
+func (a *archiveRestore) budgets(ctx context.Context) error {
+	skipped := 0
+
+	err := archive.Each(a.reader, archive.BudgetsDocument, func(b archive.Budget) error {
+		categoryID := a.mapped(&b.CategoryID)
+		if categoryID == nil {
+			skipped++
+			return nil
+		}
+
+		id := uuid.New()
+		if err := a.repo.RestoreBudget(ctx, repository.RestoreBudgetParams{
+			ID:              id,
+			UserID:          a.userID,
+			CategoryID:      *categoryID,
+			Name:            b.Name,
+			Amount:          b.Amount,
+			StartDate:       pgtype.Date{Time: b.StartDate, Valid: true},
+			EndDate:         pgtype.Date{Time: b.EndDate, Valid: true},
+			Frequency:       b.Frequency,
+			RolloverEnabled: b.RolloverEnabled,
+		}); err != nil {
+			return fmt.Errorf("restore budget: %w", err)
+		}
+
+		a.ids[b.ID] = id
+		a.result.Counts[archive.BudgetsDocument]++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		a.warn("%d budgets were skipped because their category isn't in the archive", skipped)
+	}
+
+	return nil
+}
@@ -30,6 +30,10 @@ type Users interface {
 
 	GetUserPreferences(ctx context.Context, userID uuid.UUID) (repository.GetPreferencesByUserIdRow, error)
 	UpdatePreferences(ctx context.Context, params repository.UpdatePreferencesParams) (repository.Preference, error)
+
+	// Archive
+	ExportArchive(ctx context.Context, userID uuid.UUID, w io.Writer) error
+	ImportArchive(ctx context.Context, userID uuid.UUID, r io.ReaderAt, size int64) (user.ImportArchiveResponse, error)
 }
 
 type UserService struct {
@@ -0,0 +1,9 @@
+package webhooks
+
+import "errors"
+
+var (
+	ErrDeliveryNotFound   = errors.New("webhooks.delivery_not_found")
+	ErrDeliveryInProgress = errors.New("webhooks.delivery_in_progress")
+	ErrWebhookDisabled    = errors.New("webhooks.disabled")
+)
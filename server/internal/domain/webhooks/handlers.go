@@ -1,13 +1,8 @@
 package webhooks
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -15,7 +10,9 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/respond"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog"
 )
@@ -35,13 +32,14 @@ import (
 // }
 
 type Handler struct {
-	v      *validation.Validator
-	repo   Repository
-	logger *zerolog.Logger
+	v       *validation.Validator
+	repo    Repository
+	service *Service
+	logger  *zerolog.Logger
 }
 
-func NewHandler(validator *validation.Validator, repo Repository, logger *zerolog.Logger) *Handler {
-	return &Handler{validator, repo, logger}
+func NewHandler(validator *validation.Validator, repo Repository, service *Service, logger *zerolog.Logger) *Handler {
+	return &Handler{validator, repo, service, logger}
 }
 
 func (h *Handler) GetWebhooks(res http.ResponseWriter, r *http.Request) {
@@ -434,87 +432,334 @@ func (h *Handler) TestWebhook(res http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create test payload
-	testPayload := map[string]any{
-		"event": "test",
-		"data": map[string]any{
-			"message":    "This is a test webhook event",
-			"webhook_id": webhook.ID.String(),
-			"timestamp":  time.Now().Format(time.RFC3339),
-		},
+	envelope, err := events.NewEnvelope(userID, TestEventType, map[string]any{
+		"message":    "This is a test webhook event",
+		"webhook_id": webhook.ID.String(),
+	})
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhookID,
+		})
+		return
 	}
 
-	// Implement webhook delivery
-	// (You may want to extract this into a reusable service)
-	success, deliveryErr := h.deliverWebhook(webhook, testPayload)
-
-	if !success {
+	event, err := h.service.Enqueue(ctx, webhook, envelope)
+	if err != nil {
 		respond.Error(respond.ErrorOptions{
 			W:          res,
 			R:          r,
 			StatusCode: http.StatusInternalServerError,
 			ClientErr:  message.ErrInternalError,
-			ActualErr:  deliveryErr,
+			ActualErr:  err,
 			Logger:     h.logger,
 			Details:    webhookID,
 		})
 		return
 	}
 
-	h.logger.Info().
-		Str("webhook_id", webhook.ID.String()).
-		Str("webhook_url", webhook.EndpointUrl).
-		Msg("Test webhook event delivered successfully")
+	respond.Json(res, http.StatusAccepted, event, h.logger)
+}
+
+// GetWebhookEvents lists the events sent to a webhook with their delivery status
+func (h *Handler) GetWebhookEvents(res http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhook, ok := h.ownedWebhook(res, r)
+	if !ok {
+		return
+	}
+
+	page, limit := parsePagination(r)
 
-	respond.Json(res, http.StatusOK, map[string]string{
-		"status":  "success",
-		"message": "Test webhook event delivered successfully",
-	}, h.logger)
+	events, err := h.repo.ListEvents(ctx, repository.ListWebhookEventsBySubscriptionParams{
+		SubscriptionID: webhook.ID,
+		Limit:          int64(limit),
+		Offset:         int64((page - 1) * limit),
+	})
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhook.ID,
+		})
+		return
+	}
+
+	respond.Json(res, http.StatusOK, events, h.logger)
 }
 
-// deliverWebhook sends a payload to the webhook endpoint
-func (h *Handler) deliverWebhook(webhook repository.WebhookSubscription, payload any) (bool, error) {
-	// Convert payload to JSON
-	payloadBytes, err := json.Marshal(payload)
+// GetWebhookDeliveries lists the delivery attempts of a webhook, with the request headers, the
+// response and the latency of each. It can be narrowed to one delivery with ?delivery_id=
+func (h *Handler) GetWebhookDeliveries(res http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhook, ok := h.ownedWebhook(res, r)
+	if !ok {
+		return
+	}
+
+	var deliveryID *uuid.UUID
+	if raw := r.URL.Query().Get("delivery_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respond.Error(respond.ErrorOptions{
+				W:          res,
+				R:          r,
+				StatusCode: http.StatusBadRequest,
+				ClientErr:  message.ErrBadRequest,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    raw,
+			})
+			return
+		}
+		deliveryID = &id
+	}
+
+	page, limit := parsePagination(r)
+
+	attempts, err := h.repo.ListDeliveryAttempts(ctx, repository.ListWebhookDeliveryAttemptsParams{
+		SubscriptionID: webhook.ID,
+		EventID:        deliveryID,
+		Limit:          int64(limit),
+		Offset:         int64((page - 1) * limit),
+	})
 	if err != nil {
-		return false, err
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhook.ID,
+		})
+		return
+	}
+
+	respond.Json(res, http.StatusOK, attempts, h.logger)
+}
+
+// RedeliverWebhook queues a past delivery again, with the same delivery ID and payload
+func (h *Handler) RedeliverWebhook(res http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhook, ok := h.ownedWebhook(res, r)
+	if !ok {
+		return
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequest(http.MethodPost, webhook.EndpointUrl, bytes.NewBuffer(payloadBytes))
+	deliveryID, err := parseUUID(r, "deliveryId")
 	if err != nil {
-		return false, err
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.URL.Path,
+		})
+		return
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "NUTS-Webhook-Service/1.0")
+	event, err := h.service.Redeliver(ctx, webhook, deliveryID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		clientErr := message.ErrInternalError
+
+		switch {
+		case errors.Is(err, ErrDeliveryNotFound):
+			status, clientErr = http.StatusNotFound, ErrDeliveryNotFound
+		case errors.Is(err, ErrDeliveryInProgress):
+			status, clientErr = http.StatusConflict, ErrDeliveryInProgress
+		case errors.Is(err, ErrWebhookDisabled):
+			status, clientErr = http.StatusConflict, ErrWebhookDisabled
+		}
+
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: status,
+			ClientErr:  clientErr,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    deliveryID,
+		})
+		return
+	}
 
-	// Generate signature using the webhook's secret
-	signature := h.generateSignature(payloadBytes, webhook.Secret)
-	req.Header.Set("X-NUTS-Signature", signature)
+	respond.Json(res, http.StatusAccepted, event, h.logger)
+}
 
-	// Send the request
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// ownedWebhook loads the webhook of the {id} path parameter, responding with an error when it
+// doesn't exist or belongs to another user
+func (h *Handler) ownedWebhook(res http.ResponseWriter, r *http.Request) (repository.WebhookSubscription, bool) {
+	webhookID, err := parseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhookID,
+		})
+		return repository.WebhookSubscription{}, false
 	}
-	resp, err := client.Do(req)
+
+	userID, err := jwt.GetUserID(r)
 	if err != nil {
-		return false, err
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return repository.WebhookSubscription{}, false
 	}
-	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return false, fmt.Errorf("webhook delivery failed with status: %d", resp.StatusCode)
+	webhook, err := h.repo.GetWebhook(r.Context(), webhookID)
+	if err != nil || webhook.UserID != userID {
+		if err == nil || err == pgx.ErrNoRows {
+			respond.Error(respond.ErrorOptions{
+				W:          res,
+				R:          r,
+				StatusCode: http.StatusNotFound,
+				ClientErr:  message.ErrNoRecord,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    webhookID,
+			})
+			return repository.WebhookSubscription{}, false
+		}
+
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhookID,
+		})
+		return repository.WebhookSubscription{}, false
 	}
 
-	return true, nil
+	return webhook, true
 }
 
-// generateSignature creates an HMAC signature for the payload using the webhook secret
-func (h *Handler) generateSignature(payload []byte, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+// RotateWebhookSecret replaces the secret of a webhook. Deliveries are signed with both the new
+// and the previous secret during the grace period, so receivers can switch without missing any.
+func (h *Handler) RotateWebhookSecret(res http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhookID, err := parseUUID(r, "id")
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhookID,
+		})
+		return
+	}
+
+	var req RotateSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrBadRequest,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    r.Body,
+		})
+		return
+	}
+
+	if err := h.v.Validator.Struct(req); err != nil {
+		respond.Errors(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusBadRequest,
+			ClientErr:  message.ErrValidation,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	userID, err := jwt.GetUserID(r)
+	if err != nil {
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    nil,
+		})
+		return
+	}
+
+	gracePeriod := defaultSecretGracePeriod
+	if req.GracePeriodHours != nil {
+		gracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	expiresAt := time.Now().Add(gracePeriod)
+
+	webhook, err := h.repo.RotateSecret(ctx, repository.RotateWebhookSubscriptionSecretParams{
+		ID:                      webhookID,
+		UserID:                  userID,
+		Secret:                  req.Secret,
+		PreviousSecretExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			respond.Error(respond.ErrorOptions{
+				W:          res,
+				R:          r,
+				StatusCode: http.StatusNotFound,
+				ClientErr:  message.ErrNoRecord,
+				ActualErr:  err,
+				Logger:     h.logger,
+				Details:    webhookID,
+			})
+			return
+		}
+
+		respond.Error(respond.ErrorOptions{
+			W:          res,
+			R:          r,
+			StatusCode: http.StatusInternalServerError,
+			ClientErr:  message.ErrInternalError,
+			ActualErr:  err,
+			Logger:     h.logger,
+			Details:    webhookID,
+		})
+		return
+	}
+
+	respond.Json(res, http.StatusOK, webhook, h.logger)
 }
@@ -5,16 +5,26 @@ import (
 
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
 	"github.com/Fantasy-Programming/nuts/server/pkg/router"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
 
-func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn *jwt.Service, logger *zerolog.Logger) http.Handler {
+func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn *jwt.Service, scheduler *jobs.Service, bus *events.Bus, logger *zerolog.Logger) http.Handler {
 	queries := repository.New(db)
 	repo := NewRepository(queries)
-	h := NewHandler(validate, repo, logger)
+	service := NewService(db, repo, scheduler, logger)
+	h := NewHandler(validate, repo, service, logger)
+
+	// Every domain event is a candidate for delivery
+	bus.Subscribe(events.All, service.Dispatch)
+
+	if err := RegisterValidations(validate.Validator); err != nil {
+		logger.Panic().Err(err).Msg("Failed to setup validator")
+	}
 
 	// Create the auth verify middleware
 	middleware := jwt.NewMiddleware(tkn)
@@ -27,6 +37,10 @@ func RegisterHTTPHandlers(db *pgxpool.Pool, validate *validation.Validator, tkn
 	router.Put("/{id}", h.UpdateWebhook)
 	router.Delete("/{id}", h.DeleteWebhook)
 	router.Post("/{id}/test", h.TestWebhook)
+	router.Post("/{id}/secret/rotate", h.RotateWebhookSecret)
+	router.Get("/{id}/events", h.GetWebhookEvents)
+	router.Get("/{id}/deliveries", h.GetWebhookDeliveries)
+	router.Post("/{id}/deliveries/{deliveryId}/redeliver", h.RedeliverWebhook)
 
 	return router
 }
@@ -16,6 +16,14 @@ type Repository interface {
 	CreateWebhook(ctx context.Context, params repository.CreateWebhookSubscriptionParams) (repository.WebhookSubscription, error)
 	UpdateWebhook(ctx context.Context, params repository.UpdateWebhookSubscriptionParams) (repository.WebhookSubscription, error)
 	DeleteWebhook(ctx context.Context, params repository.DeleteWebhookSubscriptionParams) error
+	RotateSecret(ctx context.Context, params repository.RotateWebhookSubscriptionSecretParams) (repository.WebhookSubscription, error)
+	ListSubscriptionsForEvent(ctx context.Context, params repository.ListActiveWebhookSubscriptionsForEventParams) ([]repository.WebhookSubscription, error)
+	CreateEvent(ctx context.Context, params repository.CreateWebhookEventParams) (repository.WebhookEvent, error)
+	ListEvents(ctx context.Context, params repository.ListWebhookEventsBySubscriptionParams) ([]repository.WebhookEvent, error)
+	GetEvent(ctx context.Context, id uuid.UUID) (repository.WebhookEvent, error)
+	ResetEventForRedelivery(ctx context.Context, id uuid.UUID) (repository.WebhookEvent, error)
+	ListDeliveryAttempts(ctx context.Context, params repository.ListWebhookDeliveryAttemptsParams) ([]repository.ListWebhookDeliveryAttemptsRow, error)
+	WithTx(tx pgx.Tx) Repository
 }
 
 type repo struct {
@@ -61,3 +69,43 @@ func (r *repo) UpdateWebhook(ctx context.Context, params repository.UpdateWebhoo
 func (r *repo) DeleteWebhook(ctx context.Context, params repository.DeleteWebhookSubscriptionParams) error {
 	return r.queries.DeleteWebhookSubscription(ctx, params)
 }
+
+// RotateSecret replaces the secret of a webhook, keeping the current one valid for a while
+func (r *repo) RotateSecret(ctx context.Context, params repository.RotateWebhookSubscriptionSecretParams) (repository.WebhookSubscription, error) {
+	return r.queries.RotateWebhookSubscriptionSecret(ctx, params)
+}
+
+// ListSubscriptionsForEvent retrieves the active subscriptions of a user that listen to an event type
+func (r *repo) ListSubscriptionsForEvent(ctx context.Context, params repository.ListActiveWebhookSubscriptionsForEventParams) ([]repository.WebhookSubscription, error) {
+	return r.queries.ListActiveWebhookSubscriptionsForEvent(ctx, params)
+}
+
+// CreateEvent stores an event waiting to be delivered to a subscription
+func (r *repo) CreateEvent(ctx context.Context, params repository.CreateWebhookEventParams) (repository.WebhookEvent, error) {
+	return r.queries.CreateWebhookEvent(ctx, params)
+}
+
+// ListEvents retrieves the events of a subscription with their delivery status, most recent first
+func (r *repo) ListEvents(ctx context.Context, params repository.ListWebhookEventsBySubscriptionParams) ([]repository.WebhookEvent, error) {
+	return r.queries.ListWebhookEventsBySubscription(ctx, params)
+}
+
+// GetEvent retrieves a webhook event by ID
+func (r *repo) GetEvent(ctx context.Context, id uuid.UUID) (repository.WebhookEvent, error) {
+	return r.queries.GetWebhookEventById(ctx, id)
+}
+
+// ResetEventForRedelivery puts a sent or failed event back to pending so that it can be delivered
+// again, it returns pgx.ErrNoRows for deliveries still in progress
+func (r *repo) ResetEventForRedelivery(ctx context.Context, id uuid.UUID) (repository.WebhookEvent, error) {
+	return r.queries.ResetWebhookEventForRedelivery(ctx, id)
+}
+
+// ListDeliveryAttempts retrieves the delivery log of a subscription, most recent first
+func (r *repo) ListDeliveryAttempts(ctx context.Context, params repository.ListWebhookDeliveryAttemptsParams) ([]repository.ListWebhookDeliveryAttemptsRow, error) {
+	return r.queries.ListWebhookDeliveryAttempts(ctx, params)
+}
+
+func (r *repo) WithTx(tx pgx.Tx) Repository {
+	return &repo{queries: r.queries.WithTx(tx)}
+}
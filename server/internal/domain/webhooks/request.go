@@ -4,7 +4,7 @@ type WebhookRequest struct {
 	Name        string   `json:"name" validate:"required,min=1,max=100"`
 	URL         string   `json:"url" validate:"required,url"`
 	Description string   `json:"description" validate:"max=500"`
-	Events      []string `json:"events" validate:"required,min=1,dive,oneof=create update delete"`
+	Events      []string `json:"events" validate:"required,min=1,dive,webhook_event"`
 	Secret      string   `json:"secret" validate:"required,min=8"`
 }
 
@@ -12,6 +12,12 @@ type WebhookUpdateRequest struct {
 	Name        *string  `json:"name" validate:"required,min=1,max=100"`
 	URL         *string  `json:"url" validate:"required,url"`
 	Description *string  `json:"description" validate:"max=500"`
-	Events      []string `json:"events" validate:"required,min=1,dive,oneof=create update delete"`
+	Events      []string `json:"events" validate:"required,min=1,dive,webhook_event"`
 	Active      *bool    `json:"active"`
 }
+
+type RotateSecretRequest struct {
+	Secret string `json:"secret" validate:"required,min=8"`
+	// How long deliveries keep being signed with the previous secret, defaults to 24 hours
+	GracePeriodHours *int `json:"grace_period_hours" validate:"omitempty,min=0,max=168"`
+}
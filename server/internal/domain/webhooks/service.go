@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Service persists webhook events and queues their delivery
+type Service struct {
+	db        *pgxpool.Pool
+	repo      Repository
+	scheduler *jobs.Service
+	logger    *zerolog.Logger
+}
+
+func NewService(db *pgxpool.Pool, repo Repository, scheduler *jobs.Service, logger *zerolog.Logger) *Service {
+	return &Service{db, repo, scheduler, logger}
+}
+
+// Dispatch fans a domain event out to the active subscriptions of its user that listen to it
+func (s *Service) Dispatch(ctx context.Context, envelope events.Envelope) error {
+	subscriptions, err := s.repo.ListSubscriptionsForEvent(ctx, repository.ListActiveWebhookSubscriptionsForEventParams{
+		UserID:    envelope.UserID,
+		EventType: envelope.Type,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	_, err = s.enqueue(ctx, subscriptions, envelope)
+	return err
+}
+
+// Enqueue stores an event for a single subscription and queues its delivery
+func (s *Service) Enqueue(ctx context.Context, subscription repository.WebhookSubscription, envelope events.Envelope) (repository.WebhookEvent, error) {
+	stored, err := s.enqueue(ctx, []repository.WebhookSubscription{subscription}, envelope)
+	if err != nil {
+		return repository.WebhookEvent{}, err
+	}
+	return stored[0], nil
+}
+
+// Redeliver sends an event of a subscription again. Deliveries still being retried can't be
+// redelivered, they would race with the pending attempt.
+func (s *Service) Redeliver(ctx context.Context, subscription repository.WebhookSubscription, eventID uuid.UUID) (repository.WebhookEvent, error) {
+	event, err := s.repo.GetEvent(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.WebhookEvent{}, ErrDeliveryNotFound
+		}
+		return repository.WebhookEvent{}, err
+	}
+
+	if event.SubscriptionID != subscription.ID {
+		return repository.WebhookEvent{}, ErrDeliveryNotFound
+	}
+
+	if event.Status == nil || (*event.Status != jobs.WebhookEventStatusSent && *event.Status != jobs.WebhookEventStatusFailed) {
+		return repository.WebhookEvent{}, ErrDeliveryInProgress
+	}
+
+	if !subscription.Active {
+		return repository.WebhookEvent{}, ErrWebhookDisabled
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repository.WebhookEvent{}, err
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			s.logger.Error().Err(rbErr).Msg("Failed to rollback webhook redelivery")
+		}
+	}()
+
+	// The status may have changed since it was read, the update only applies to finished deliveries
+	event, err = s.repo.WithTx(tx).ResetEventForRedelivery(ctx, event.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.WebhookEvent{}, ErrDeliveryInProgress
+		}
+		return repository.WebhookEvent{}, err
+	}
+
+	if err := s.scheduler.EnqueueWebhookDeliveryTx(ctx, tx, event.ID); err != nil {
+		return repository.WebhookEvent{}, err
+	}
+
+	return event, tx.Commit(ctx)
+}
+
+// enqueue stores one event per subscription and queues their delivery in the same transaction,
+// the delivery worker takes care of retries
+func (s *Service) enqueue(ctx context.Context, subscriptions []repository.WebhookSubscription, envelope events.Envelope) ([]repository.WebhookEvent, error) {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			s.logger.Error().Err(rbErr).Msg("Failed to rollback webhook event")
+		}
+	}()
+
+	repo := s.repo.WithTx(tx)
+	status := jobs.WebhookEventStatusPending
+	stored := make([]repository.WebhookEvent, 0, len(subscriptions))
+
+	for _, subscription := range subscriptions {
+		event, err := repo.CreateEvent(ctx, repository.CreateWebhookEventParams{
+			SubscriptionID: subscription.ID,
+			EventType:      envelope.Type,
+			Payload:        body,
+			Status:         &status,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.scheduler.EnqueueWebhookDeliveryTx(ctx, tx, event.ID); err != nil {
+			return nil, err
+		}
+
+		stored = append(stored, event)
+	}
+
+	return stored, tx.Commit(ctx)
+}
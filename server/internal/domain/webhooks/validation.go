@@ -2,15 +2,59 @@ package webhooks
 
 import (
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/message"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
+const (
+	// TestEventType is sent by the test endpoint, it can't be subscribed to
+	TestEventType = "webhook.test"
+
+	// How long a rotated secret keeps signing deliveries when no grace period is given
+	defaultSecretGracePeriod = 24 * time.Hour
+)
+
+func RegisterValidations(v *validator.Validate) error {
+	if v == nil {
+		return nil
+	}
+
+	return v.RegisterValidation("webhook_event", validateWebhookEvent)
+}
+
+// validateWebhookEvent accepts the published event types, or events.All for every one of them
+func validateWebhookEvent(fl validator.FieldLevel) bool {
+	event := fl.Field().String()
+	return event == events.All || slices.Contains(events.Types, event)
+}
+
 func parseUUID(r *http.Request, paramName string) (uuid.UUID, error) {
-	idStr := r.URL.Query().Get(paramName)
+	idStr := r.PathValue(paramName)
 	if idStr == "" {
 		return uuid.Nil, message.ErrMissingParams
 	}
 	return uuid.Parse(idStr)
 }
+
+// parsePagination reads the page and limit query parameters, limit defaults to 25 and is capped at 100
+func parsePagination(r *http.Request) (int, int) {
+	q := r.URL.Query()
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	return page, limit
+}
@@ -113,6 +113,27 @@ func (q *Queries) DeleteAccount(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const detachAccountsFromConnection = `-- name: DetachAccountsFromConnection :exec
+UPDATE accounts
+SET
+    connection_id = NULL,
+    sync_status = 'disconnected',
+    updated_at = current_timestamp
+WHERE
+    connection_id = $1
+    AND created_by = $2
+`
+
+type DetachAccountsFromConnectionParams struct {
+	ConnectionID *uuid.UUID `json:"connection_id"`
+	CreatedBy    *uuid.UUID `json:"created_by"`
+}
+
+func (q *Queries) DetachAccountsFromConnection(ctx context.Context, arg DetachAccountsFromConnectionParams) error {
+	_, err := q.db.Exec(ctx, detachAccountsFromConnection, arg.ConnectionID, arg.CreatedBy)
+	return err
+}
+
 const getAccountBalanceTimeline = `-- name: GetAccountBalanceTimeline :many
 WITH
 period AS (
@@ -23,10 +23,10 @@ INSERT INTO budgets (
   start_date,
   end_date,
   frequency,
-  -- rollover_enabled,
+  rollover_enabled,
   user_id
   ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
   ) RETURNING id, created_at, updated_at
 `
 
@@ -38,6 +38,7 @@ type CreateBudgetParams struct {
 	StartDate       pgtype.Date     `json:"start_date"`
 	EndDate         pgtype.Date     `json:"end_date"`
 	Frequency       string          `json:"frequency"`
+	RolloverEnabled bool            `json:"rollover_enabled"`
 	UserID          uuid.UUID       `json:"user_id"`
 }
 
@@ -56,6 +57,7 @@ func (q *Queries) CreateBudget(ctx context.Context, arg CreateBudgetParams) (Cre
 		arg.StartDate,
 		arg.EndDate,
 		arg.Frequency,
+		arg.RolloverEnabled,
 		arg.UserID,
 	)
 	var i CreateBudgetRow
@@ -63,7 +65,410 @@ func (q *Queries) CreateBudget(ctx context.Context, arg CreateBudgetParams) (Cre
 	return i, err
 }
 
-const updateBudget = `-- name: UpdateBudget :exec
+const createBudgetRollover = `-- name: CreateBudgetRollover :exec
+INSERT INTO budget_rollovers (
+    budget_id,
+    user_id,
+    period_start,
+    period_end,
+    budgeted_amount,
+    carried_in,
+    spent_amount,
+    rollover_amount
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (budget_id, period_start) DO NOTHING
+`
+
+type CreateBudgetRolloverParams struct {
+	BudgetID       uuid.UUID       `json:"budget_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	PeriodStart    pgtype.Date     `json:"period_start"`
+	PeriodEnd      pgtype.Date     `json:"period_end"`
+	BudgetedAmount decimal.Decimal `json:"budgeted_amount"`
+	CarriedIn      decimal.Decimal `json:"carried_in"`
+	SpentAmount    decimal.Decimal `json:"spent_amount"`
+	RolloverAmount decimal.Decimal `json:"rollover_amount"`
+}
+
+func (q *Queries) CreateBudgetRollover(ctx context.Context, arg CreateBudgetRolloverParams) error {
+	_, err := q.db.Exec(ctx, createBudgetRollover,
+		arg.BudgetID,
+		arg.UserID,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.BudgetedAmount,
+		arg.CarriedIn,
+		arg.SpentAmount,
+		arg.RolloverAmount,
+	)
+	return err
+}
+
+const deleteBudget = `-- name: DeleteBudget :execrows
+DELETE FROM budgets
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteBudgetParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteBudget(ctx context.Context, arg DeleteBudgetParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBudget, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getBudgetById = `-- name: GetBudgetById :one
+SELECT id, user_id, category_id, amount, start_date, end_date, frequency, created_at, updated_at, shared_finance_id, name, rollover_enabled
+FROM budgets
+WHERE id = $1 AND user_id = $2
+LIMIT 1
+`
+
+type GetBudgetByIdParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetBudgetById(ctx context.Context, arg GetBudgetByIdParams) (Budget, error) {
+	row := q.db.QueryRow(ctx, getBudgetById, arg.ID, arg.UserID)
+	var i Budget
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.Amount,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Frequency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SharedFinanceID,
+		&i.Name,
+		&i.RolloverEnabled,
+	)
+	return i, err
+}
+
+const getBudgetRolloverByPeriodEnd = `-- name: GetBudgetRolloverByPeriodEnd :one
+SELECT id, budget_id, user_id, period_start, period_end, budgeted_amount, carried_in, spent_amount, rollover_amount, created_at
+FROM budget_rollovers
+WHERE budget_id = $1 AND period_end = $2
+LIMIT 1
+`
+
+type GetBudgetRolloverByPeriodEndParams struct {
+	BudgetID  uuid.UUID   `json:"budget_id"`
+	PeriodEnd pgtype.Date `json:"period_end"`
+}
+
+func (q *Queries) GetBudgetRolloverByPeriodEnd(ctx context.Context, arg GetBudgetRolloverByPeriodEndParams) (BudgetRollover, error) {
+	row := q.db.QueryRow(ctx, getBudgetRolloverByPeriodEnd, arg.BudgetID, arg.PeriodEnd)
+	var i BudgetRollover
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.UserID,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.BudgetedAmount,
+		&i.CarriedIn,
+		&i.SpentAmount,
+		&i.RolloverAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBudgetSpending = `-- name: GetBudgetSpending :one
+WITH user_base_currency AS (
+    SELECT COALESCE((SELECT currency FROM preferences WHERE user_id = $1 LIMIT 1), 'USD')::TEXT AS base_currency
+)
+SELECT
+    (SELECT base_currency FROM user_base_currency) AS base_currency,
+    COALESCE(SUM(ABS(t.amount) * COALESCE(er.rate, 1.0)), 0)::DECIMAL AS spent
+FROM transactions t
+LEFT JOIN LATERAL (
+    SELECT rate FROM exchange_rates er
+    WHERE er.from_currency = t.transaction_currency
+      AND er.to_currency = (SELECT base_currency FROM user_base_currency)
+      AND er.effective_date <= t.transaction_datetime::DATE
+    ORDER BY er.effective_date DESC
+    LIMIT 1
+) er ON TRUE
+WHERE
+    t.created_by = $1
+    AND t.category_id = $2
+    AND t.type = 'expense'
+    AND t.deleted_at IS NULL
+    AND t.transaction_datetime >= $3::TIMESTAMPTZ
+    AND t.transaction_datetime < $4::TIMESTAMPTZ
+`
+
+type GetBudgetSpendingParams struct {
+	UserID      uuid.UUID `json:"user_id"`
+	CategoryID  uuid.UUID `json:"category_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+type GetBudgetSpendingRow struct {
+	BaseCurrency string         `json:"base_currency"`
+	Spent        pgtype.Numeric `json:"spent"`
+}
+
+// Sums the expenses of a category over [period_start, period_end) converted
+// to the user's base currency using the closest prior exchange rate.
+func (q *Queries) GetBudgetSpending(ctx context.Context, arg GetBudgetSpendingParams) (GetBudgetSpendingRow, error) {
+	row := q.db.QueryRow(ctx, getBudgetSpending,
+		arg.UserID,
+		arg.CategoryID,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+	)
+	var i GetBudgetSpendingRow
+	err := row.Scan(&i.BaseCurrency, &i.Spent)
+	return i, err
+}
+
+const getLatestBudgetRollover = `-- name: GetLatestBudgetRollover :one
+SELECT id, budget_id, user_id, period_start, period_end, budgeted_amount, carried_in, spent_amount, rollover_amount, created_at
+FROM budget_rollovers
+WHERE budget_id = $1
+ORDER BY period_start DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestBudgetRollover(ctx context.Context, budgetID uuid.UUID) (BudgetRollover, error) {
+	row := q.db.QueryRow(ctx, getLatestBudgetRollover, budgetID)
+	var i BudgetRollover
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.UserID,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.BudgetedAmount,
+		&i.CarriedIn,
+		&i.SpentAmount,
+		&i.RolloverAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listActiveBudgets = `-- name: ListActiveBudgets :many
+SELECT id, user_id, category_id, amount, start_date, end_date, frequency, created_at, updated_at, shared_finance_id, name, rollover_enabled
+FROM budgets
+WHERE
+    user_id = $1
+    AND start_date <= $2::DATE
+    AND end_date >= $2::DATE
+ORDER BY name ASC, created_at ASC
+`
+
+type ListActiveBudgetsParams struct {
+	UserID uuid.UUID   `json:"user_id"`
+	At     pgtype.Date `json:"at"`
+}
+
+func (q *Queries) ListActiveBudgets(ctx context.Context, arg ListActiveBudgetsParams) ([]Budget, error) {
+	rows, err := q.db.Query(ctx, listActiveBudgets, arg.UserID, arg.At)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Budget{}
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Frequency,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SharedFinanceID,
+			&i.Name,
+			&i.RolloverEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetRollovers = `-- name: ListBudgetRollovers :many
+SELECT id, budget_id, user_id, period_start, period_end, budgeted_amount, carried_in, spent_amount, rollover_amount, created_at
+FROM budget_rollovers
+WHERE budget_id = $1 AND user_id = $2
+ORDER BY period_start DESC
+`
+
+type ListBudgetRolloversParams struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) ListBudgetRollovers(ctx context.Context, arg ListBudgetRolloversParams) ([]BudgetRollover, error) {
+	rows, err := q.db.Query(ctx, listBudgetRollovers, arg.BudgetID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []BudgetRollover{}
+	for rows.Next() {
+		var i BudgetRollover
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.UserID,
+			&i.PeriodStart,
+			&i.PeriodEnd,
+			&i.BudgetedAmount,
+			&i.CarriedIn,
+			&i.SpentAmount,
+			&i.RolloverAmount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgets = `-- name: ListBudgets :many
+SELECT id, user_id, category_id, amount, start_date, end_date, frequency, created_at, updated_at, shared_finance_id, name, rollover_enabled
+FROM budgets
+WHERE user_id = $1
+ORDER BY start_date DESC, created_at DESC
+`
+
+func (q *Queries) ListBudgets(ctx context.Context, userID uuid.UUID) ([]Budget, error) {
+	rows, err := q.db.Query(ctx, listBudgets, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Budget{}
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Frequency,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SharedFinanceID,
+			&i.Name,
+			&i.RolloverEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRolloverEnabledBudgets = `-- name: ListRolloverEnabledBudgets :many
+SELECT id, user_id, category_id, amount, start_date, end_date, frequency, created_at, updated_at, shared_finance_id, name, rollover_enabled
+FROM budgets
+WHERE
+    rollover_enabled = TRUE
+    AND start_date < $1::DATE
+ORDER BY id
+`
+
+func (q *Queries) ListRolloverEnabledBudgets(ctx context.Context, before pgtype.Date) ([]Budget, error) {
+	rows, err := q.db.Query(ctx, listRolloverEnabledBudgets, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Budget{}
+	for rows.Next() {
+		var i Budget
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.CategoryID,
+			&i.Amount,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Frequency,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SharedFinanceID,
+			&i.Name,
+			&i.RolloverEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordBudgetAlert = `-- name: RecordBudgetAlert :execrows
+INSERT INTO budget_alerts (
+    budget_id,
+    period_start,
+    budgeted_amount,
+    spent_amount
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (budget_id, period_start) DO NOTHING
+`
+
+type RecordBudgetAlertParams struct {
+	BudgetID       uuid.UUID       `json:"budget_id"`
+	PeriodStart    pgtype.Date     `json:"period_start"`
+	BudgetedAmount decimal.Decimal `json:"budgeted_amount"`
+	SpentAmount    decimal.Decimal `json:"spent_amount"`
+}
+
+func (q *Queries) RecordBudgetAlert(ctx context.Context, arg RecordBudgetAlertParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordBudgetAlert,
+		arg.BudgetID,
+		arg.PeriodStart,
+		arg.BudgetedAmount,
+		arg.SpentAmount,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateBudget = `-- name: UpdateBudget :execrows
 UPDATE budgets
 SET
     category_id = $1,
@@ -72,32 +477,39 @@ SET
     start_date = $4,
     end_date = $5,
     frequency = $6,
-    -- rollover_enabled = $7,
-    updated_at = $7
-WHERE id = $8
+    rollover_enabled = $7,
+    updated_at = $8
+WHERE id = $9 AND user_id = $10
 `
 
 type UpdateBudgetParams struct {
-	CategoryID uuid.UUID          `json:"category_id"`
-	Amount     decimal.Decimal    `json:"amount"`
-	Name       *string            `json:"name"`
-	StartDate  pgtype.Date        `json:"start_date"`
-	EndDate    pgtype.Date        `json:"end_date"`
-	Frequency  string             `json:"frequency"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
-	ID         uuid.UUID          `json:"id"`
-}
-
-func (q *Queries) UpdateBudget(ctx context.Context, arg UpdateBudgetParams) error {
-	_, err := q.db.Exec(ctx, updateBudget,
+	CategoryID      uuid.UUID          `json:"category_id"`
+	Amount          decimal.Decimal    `json:"amount"`
+	Name            *string            `json:"name"`
+	StartDate       pgtype.Date        `json:"start_date"`
+	EndDate         pgtype.Date        `json:"end_date"`
+	Frequency       string             `json:"frequency"`
+	RolloverEnabled bool               `json:"rollover_enabled"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+}
+
+func (q *Queries) UpdateBudget(ctx context.Context, arg UpdateBudgetParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateBudget,
 		arg.CategoryID,
 		arg.Amount,
 		arg.Name,
 		arg.StartDate,
 		arg.EndDate,
 		arg.Frequency,
+		arg.RolloverEnabled,
 		arg.UpdatedAt,
 		arg.ID,
+		arg.UserID,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -33,7 +33,7 @@ INSERT INTO user_financial_connections (
     $7,
     $8,
     $9
-) RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at
+) RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
 `
 
 type CreateConnectionParams struct {
@@ -74,6 +74,8 @@ func (q *Queries) CreateConnection(ctx context.Context, arg CreateConnectionPara
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
@@ -94,7 +96,7 @@ func (q *Queries) DeleteConnection(ctx context.Context, arg DeleteConnectionPara
 }
 
 const getConnectionByID = `-- name: GetConnectionByID :one
-SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at FROM user_financial_connections
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
 WHERE id = $1 LIMIT 1
 `
 
@@ -114,12 +116,84 @@ func (q *Queries) GetConnectionByID(ctx context.Context, id uuid.UUID) (UserFina
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const getConnectionByItemID = `-- name: GetConnectionByItemID :one
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
+WHERE provider_name = $1
+  AND item_id = $2
+LIMIT 1
+`
+
+type GetConnectionByItemIDParams struct {
+	ProviderName string  `json:"provider_name"`
+	ItemID       *string `json:"item_id"`
+}
+
+func (q *Queries) GetConnectionByItemID(ctx context.Context, arg GetConnectionByItemIDParams) (UserFinancialConnection, error) {
+	row := q.db.QueryRow(ctx, getConnectionByItemID, arg.ProviderName, arg.ItemID)
+	var i UserFinancialConnection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProviderName,
+		&i.AccessTokenEncrypted,
+		&i.ItemID,
+		&i.InstitutionID,
+		&i.InstitutionName,
+		&i.Status,
+		&i.LastSyncAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const getConnectionByProviderAccountID = `-- name: GetConnectionByProviderAccountID :one
+SELECT c.id, c.user_id, c.provider_name, c.access_token_encrypted, c.item_id, c.institution_id, c.institution_name, c.status, c.last_sync_at, c.expires_at, c.created_at, c.updated_at, c.sync_failures, c.last_error FROM user_financial_connections c
+JOIN accounts a ON a.connection_id = c.id
+WHERE c.provider_name = $1
+  AND a.provider_account_id = $2
+  AND a.deleted_at IS NULL
+LIMIT 1
+`
+
+type GetConnectionByProviderAccountIDParams struct {
+	ProviderName      string  `json:"provider_name"`
+	ProviderAccountID *string `json:"provider_account_id"`
+}
+
+func (q *Queries) GetConnectionByProviderAccountID(ctx context.Context, arg GetConnectionByProviderAccountIDParams) (UserFinancialConnection, error) {
+	row := q.db.QueryRow(ctx, getConnectionByProviderAccountID, arg.ProviderName, arg.ProviderAccountID)
+	var i UserFinancialConnection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProviderName,
+		&i.AccessTokenEncrypted,
+		&i.ItemID,
+		&i.InstitutionID,
+		&i.InstitutionName,
+		&i.Status,
+		&i.LastSyncAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
 
 const getConnectionByProviderItemID = `-- name: GetConnectionByProviderItemID :one
-SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at FROM user_financial_connections
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
 WHERE user_id = $1
   AND provider_name = $2
   AND item_id = $3
@@ -148,12 +222,14 @@ func (q *Queries) GetConnectionByProviderItemID(ctx context.Context, arg GetConn
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
 
 const getConnectionsByUserID = `-- name: GetConnectionsByUserID :many
-SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at FROM user_financial_connections
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
 WHERE user_id = $1
 ORDER BY created_at DESC
 `
@@ -180,6 +256,8 @@ func (q *Queries) GetConnectionsByUserID(ctx context.Context, userID uuid.UUID)
 			&i.ExpiresAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SyncFailures,
+			&i.LastError,
 		); err != nil {
 			return nil, err
 		}
@@ -191,8 +269,18 @@ func (q *Queries) GetConnectionsByUserID(ctx context.Context, userID uuid.UUID)
 	return items, nil
 }
 
+const lockConnectionSync = `-- name: LockConnectionSync :exec
+SELECT pg_advisory_xact_lock(hashtextextended($1::uuid::text, 0))
+`
+
+// Serialises the syncs of a connection until the end of the transaction
+func (q *Queries) LockConnectionSync(ctx context.Context, connectionID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockConnectionSync, connectionID)
+	return err
+}
+
 const listConnections = `-- name: ListConnections :many
-SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at FROM user_financial_connections
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -224,6 +312,49 @@ func (q *Queries) ListConnections(ctx context.Context, arg ListConnectionsParams
 			&i.ExpiresAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SyncFailures,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSyncableConnections = `-- name: ListSyncableConnections :many
+SELECT id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error FROM user_financial_connections
+WHERE status IN ('active', 'error')
+ORDER BY last_sync_at ASC NULLS FIRST
+`
+
+func (q *Queries) ListSyncableConnections(ctx context.Context) ([]UserFinancialConnection, error) {
+	rows, err := q.db.Query(ctx, listSyncableConnections)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserFinancialConnection{}
+	for rows.Next() {
+		var i UserFinancialConnection
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProviderName,
+			&i.AccessTokenEncrypted,
+			&i.ItemID,
+			&i.InstitutionID,
+			&i.InstitutionName,
+			&i.Status,
+			&i.LastSyncAt,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SyncFailures,
+			&i.LastError,
 		); err != nil {
 			return nil, err
 		}
@@ -241,7 +372,7 @@ SET
     status = $2, -- Should be an error status
     updated_at = NOW()
 WHERE id = $1 AND user_id = $3
-RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at
+RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
 `
 
 type SetConnectionErrorStatusParams struct {
@@ -266,6 +397,83 @@ func (q *Queries) SetConnectionErrorStatus(ctx context.Context, arg SetConnectio
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const setConnectionStatus = `-- name: SetConnectionStatus :one
+UPDATE user_financial_connections
+SET
+    status = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
+`
+
+type SetConnectionStatusParams struct {
+	ID     uuid.UUID `json:"id"`
+	Status *string   `json:"status"`
+}
+
+func (q *Queries) SetConnectionStatus(ctx context.Context, arg SetConnectionStatusParams) (UserFinancialConnection, error) {
+	row := q.db.QueryRow(ctx, setConnectionStatus, arg.ID, arg.Status)
+	var i UserFinancialConnection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProviderName,
+		&i.AccessTokenEncrypted,
+		&i.ItemID,
+		&i.InstitutionID,
+		&i.InstitutionName,
+		&i.Status,
+		&i.LastSyncAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const setConnectionSyncFailed = `-- name: SetConnectionSyncFailed :one
+UPDATE user_financial_connections
+SET
+    status = $2,
+    sync_failures = sync_failures + 1,
+    last_error = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
+`
+
+type SetConnectionSyncFailedParams struct {
+	ID        uuid.UUID `json:"id"`
+	Status    *string   `json:"status"`
+	LastError *string   `json:"last_error"`
+}
+
+func (q *Queries) SetConnectionSyncFailed(ctx context.Context, arg SetConnectionSyncFailedParams) (UserFinancialConnection, error) {
+	row := q.db.QueryRow(ctx, setConnectionSyncFailed, arg.ID, arg.Status, arg.LastError)
+	var i UserFinancialConnection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProviderName,
+		&i.AccessTokenEncrypted,
+		&i.ItemID,
+		&i.InstitutionID,
+		&i.InstitutionName,
+		&i.Status,
+		&i.LastSyncAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
@@ -275,9 +483,11 @@ UPDATE user_financial_connections
 SET
     status = $2,
     last_sync_at = $3,
+    sync_failures = 0,
+    last_error = NULL,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $4
-RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at
+RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
 `
 
 type SetConnectionSyncStatusParams struct {
@@ -308,6 +518,8 @@ func (q *Queries) SetConnectionSyncStatus(ctx context.Context, arg SetConnection
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
@@ -324,7 +536,7 @@ SET
     expires_at = $7,   -- Use sqlc.narg for nullable timestamp
     updated_at = NOW()
 WHERE id = $8 AND user_id = $9
-RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at
+RETURNING id, user_id, provider_name, access_token_encrypted, item_id, institution_id, institution_name, status, last_sync_at, expires_at, created_at, updated_at, sync_failures, last_error
 `
 
 type UpdateConnectionParams struct {
@@ -365,6 +577,8 @@ func (q *Queries) UpdateConnection(ctx context.Context, arg UpdateConnectionPara
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SyncFailures,
+		&i.LastError,
 	)
 	return i, err
 }
@@ -96,3 +96,93 @@ func (r iteratorForBatchCreateTransaction) Err() error {
 func (q *Queries) BatchCreateTransaction(ctx context.Context, arg []BatchCreateTransactionParams) (int64, error) {
 	return q.db.CopyFrom(ctx, []string{"transactions"}, []string{"amount", "type", "account_id", "destination_account_id", "category_id", "description", "transaction_datetime", "transaction_currency", "original_amount", "details", "provider_transaction_id", "is_external", "created_by", "recurring_transaction_id", "recurring_instance_date"}, &iteratorForBatchCreateTransaction{rows: arg})
 }
+
+// iteratorForCreateStagedTransactions implements pgx.CopyFromSource.
+type iteratorForCreateStagedTransactions struct {
+	rows                 []CreateStagedTransactionsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateStagedTransactions) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateStagedTransactions) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ImportID,
+		r.rows[0].Line,
+		r.rows[0].TransactionDatetime,
+		r.rows[0].Amount,
+		r.rows[0].Currency,
+		r.rows[0].Description,
+		r.rows[0].Payee,
+		r.rows[0].Reference,
+		r.rows[0].DuplicateOf,
+	}, nil
+}
+
+func (r iteratorForCreateStagedTransactions) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateStagedTransactions(ctx context.Context, arg []CreateStagedTransactionsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"staged_transactions"}, []string{"import_id", "line", "transaction_datetime", "amount", "currency", "description", "payee", "reference", "duplicate_of"}, &iteratorForCreateStagedTransactions{rows: arg})
+}
+
+// iteratorForRestoreTransactions implements pgx.CopyFromSource.
+type iteratorForRestoreTransactions struct {
+	rows                 []RestoreTransactionsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreTransactions) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreTransactions) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].Amount,
+		r.rows[0].Type,
+		r.rows[0].AccountID,
+		r.rows[0].CategoryID,
+		r.rows[0].DestinationAccountID,
+		r.rows[0].TransactionDatetime,
+		r.rows[0].Description,
+		r.rows[0].Details,
+		r.rows[0].CreatedBy,
+		r.rows[0].IsExternal,
+		r.rows[0].TransactionCurrency,
+		r.rows[0].OriginalAmount,
+		r.rows[0].ExchangeRate,
+		r.rows[0].ExchangeRateDate,
+		r.rows[0].IsCategorized,
+		r.rows[0].RecurringTransactionID,
+		r.rows[0].RecurringInstanceDate,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForRestoreTransactions) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreTransactions(ctx context.Context, arg []RestoreTransactionsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"transactions"}, []string{"id", "amount", "type", "account_id", "category_id", "destination_account_id", "transaction_datetime", "description", "details", "created_by", "is_external", "transaction_currency", "original_amount", "exchange_rate", "exchange_rate_date", "is_categorized", "recurring_transaction_id", "recurring_instance_date", "created_at"}, &iteratorForRestoreTransactions{rows: arg})
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportFilters selects the transactions of an export, with the filters of the transaction list
+type ExportFilters struct {
+	Search      *string    `json:"search,omitempty"`
+	Type        *string    `json:"type,omitempty" validate:"omitempty,oneof=expense income transfer"`
+	AccountID   *uuid.UUID `json:"account_id,omitempty"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
+	Currency    *string    `json:"currency,omitempty" validate:"omitempty,len=3"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	MinAmount   *float64   `json:"min_amount,omitempty"`
+	MaxAmount   *float64   `json:"max_amount,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	IsExternal  *bool      `json:"is_external,omitempty"`
+	IsRecurring *bool      `json:"is_recurring,omitempty"`
+	IsPending   *bool      `json:"is_pending,omitempty"`
+}
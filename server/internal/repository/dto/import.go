@@ -0,0 +1,25 @@
+package dto
+
+// CSVMapping tells how the columns of a bank CSV export map to transaction fields. Columns are
+// referenced by header name, or by their 1-based position when the file has no header
+type CSVMapping struct {
+	Delimiter string `json:"delimiter,omitempty"` // Detected from the first line when empty
+	HasHeader bool   `json:"has_header"`
+	SkipRows  int    `json:"skip_rows,omitempty" validate:"min=0,max=50"` // Lines before the header, some banks add a preamble
+
+	DateColumn string `json:"date_column" validate:"required"`
+	DateFormat string `json:"date_format,omitempty"` // Like DD/MM/YYYY, ISO 8601 when empty
+
+	// Either a signed amount column, or separate debit and credit columns
+	AmountColumn string `json:"amount_column,omitempty" validate:"required_without_all=DebitColumn CreditColumn"`
+	DebitColumn  string `json:"debit_column,omitempty"`
+	CreditColumn string `json:"credit_column,omitempty"`
+
+	DescriptionColumn string `json:"description_column,omitempty"`
+	PayeeColumn       string `json:"payee_column,omitempty"`
+	CurrencyColumn    string `json:"currency_column,omitempty"`
+	ReferenceColumn   string `json:"reference_column,omitempty"`
+
+	DecimalSeparator string `json:"decimal_separator,omitempty" validate:"omitempty,oneof=. ,"`
+	InvertAmounts    bool   `json:"invert_amounts,omitempty"` // For statements listing spending as positive amounts
+}
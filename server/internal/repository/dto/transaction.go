@@ -1,8 +1,13 @@
 package dto
 
 type Details struct {
-	PaymentMedium *string `json:"payment_medium"`
-	Location      *string `json:"location"`
-	Note          *string `json:"note"`
-	PaymentStatus *string `json:"payment_status"`
+	PaymentMedium        *string  `json:"payment_medium"`
+	Location             *string  `json:"location"`
+	Note                 *string  `json:"note"`
+	PaymentStatus        *string  `json:"payment_status"`
+	Tags                 []string `json:"tags,omitempty"`
+	Merchant             *string  `json:"merchant,omitempty"`               // Merchant or payee
+	MerchantCategoryCode *string  `json:"merchant_category_code,omitempty"` // ISO 18245 MCC
+	TaxDeductible        *bool    `json:"tax_deductible,omitempty"`
+	BusinessExpense      *bool    `json:"business_expense,omitempty"`
 }
@@ -0,0 +1,4 @@
+package dto
+
+// WebhookHeaders are the request headers of a webhook delivery attempt
+type WebhookHeaders map[string]string
@@ -162,6 +162,15 @@ type Account struct {
 	SharedFinanceID   *uuid.UUID      `json:"shared_finance_id"`
 }
 
+type AccountSyncState struct {
+	AccountID    uuid.UUID  `json:"account_id"`
+	LastSeenAt   *time.Time `json:"last_seen_at"`
+	LastSyncedAt time.Time  `json:"last_synced_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Cursor       *string    `json:"cursor"`
+}
+
 type Budget struct {
 	ID              uuid.UUID      `json:"id"`
 	UserID          uuid.UUID      `json:"user_id"`
@@ -174,6 +183,29 @@ type Budget struct {
 	UpdatedAt       *time.Time     `json:"updated_at"`
 	SharedFinanceID *uuid.UUID     `json:"shared_finance_id"`
 	Name            *string        `json:"name"`
+	RolloverEnabled bool           `json:"rollover_enabled"`
+}
+
+type BudgetAlert struct {
+	ID             uuid.UUID      `json:"id"`
+	BudgetID       uuid.UUID      `json:"budget_id"`
+	PeriodStart    pgtype.Date    `json:"period_start"`
+	BudgetedAmount pgtype.Numeric `json:"budgeted_amount"`
+	SpentAmount    pgtype.Numeric `json:"spent_amount"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type BudgetRollover struct {
+	ID             uuid.UUID      `json:"id"`
+	BudgetID       uuid.UUID      `json:"budget_id"`
+	UserID         uuid.UUID      `json:"user_id"`
+	PeriodStart    pgtype.Date    `json:"period_start"`
+	PeriodEnd      pgtype.Date    `json:"period_end"`
+	BudgetedAmount pgtype.Numeric `json:"budgeted_amount"`
+	CarriedIn      pgtype.Numeric `json:"carried_in"`
+	SpentAmount    pgtype.Numeric `json:"spent_amount"`
+	RolloverAmount pgtype.Numeric `json:"rollover_amount"`
+	CreatedAt      time.Time      `json:"created_at"`
 }
 
 type Category struct {
@@ -206,6 +238,20 @@ type ExchangeRate struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+type Export struct {
+	ID          uuid.UUID         `json:"id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	Format      string            `json:"format"`
+	Filters     dto.ExportFilters `json:"filters"`
+	Status      string            `json:"status"`
+	StorageKey  *string           `json:"storage_key"`
+	RowCount    *int32            `json:"row_count"`
+	FileSize    *int64            `json:"file_size"`
+	Error       *string           `json:"error"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at"`
+}
+
 type FinancialGoal struct {
 	ID            uuid.UUID      `json:"id"`
 	UserID        uuid.UUID      `json:"user_id"`
@@ -219,6 +265,15 @@ type FinancialGoal struct {
 	UpdatedAt     *time.Time     `json:"updated_at"`
 }
 
+type ImportMapping struct {
+	ID        uuid.UUID      `json:"id"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Name      string         `json:"name"`
+	Mapping   dto.CSVMapping `json:"mapping"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
 type LinkedAccount struct {
 	ID             uuid.UUID `json:"id"`
 	UserID         uuid.UUID `json:"user_id"`
@@ -244,6 +299,14 @@ type Preference struct {
 	DarkSidebar       bool       `json:"dark_sidebar"`
 }
 
+type ProviderWebhookDelivery struct {
+	ID           uuid.UUID `json:"id"`
+	ProviderName string    `json:"provider_name"`
+	DeliveryID   string    `json:"delivery_id"`
+	EventType    string    `json:"event_type"`
+	ReceivedAt   time.Time `json:"received_at"`
+}
+
 type RecurringTransaction struct {
 	ID                   uuid.UUID      `json:"id"`
 	UserID               uuid.UUID      `json:"user_id"`
@@ -341,6 +404,20 @@ type SharedFinanceMember struct {
 	Role            string    `json:"role"`
 }
 
+type StagedTransaction struct {
+	ID                  uuid.UUID      `json:"id"`
+	ImportID            uuid.UUID      `json:"import_id"`
+	Line                int32          `json:"line"`
+	TransactionDatetime time.Time      `json:"transaction_datetime"`
+	Amount              pgtype.Numeric `json:"amount"`
+	Currency            *string        `json:"currency"`
+	Description         *string        `json:"description"`
+	Payee               *string        `json:"payee"`
+	Reference           *string        `json:"reference"`
+	DuplicateOf         *uuid.UUID     `json:"duplicate_of"`
+	TransactionID       *uuid.UUID     `json:"transaction_id"`
+}
+
 type Tag struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -376,6 +453,37 @@ type Transaction struct {
 	RecurringInstanceDate  *time.Time     `json:"recurring_instance_date"`
 }
 
+type TransactionAttachment struct {
+	ID               uuid.UUID  `json:"id"`
+	TransactionID    uuid.UUID  `json:"transaction_id"`
+	Filename         string     `json:"filename"`
+	OriginalFilename string     `json:"original_filename"`
+	FileSize         int64      `json:"file_size"`
+	MimeType         string     `json:"mime_type"`
+	StorageKey       string     `json:"storage_key"`
+	BucketName       string     `json:"bucket_name"`
+	IsEncrypted      bool       `json:"is_encrypted"`
+	EncryptionKeyID  *string    `json:"encryption_key_id"`
+	Status           string     `json:"status"`
+	UploadedBy       uuid.UUID  `json:"uploaded_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UploadedAt       *time.Time `json:"uploaded_at"`
+}
+
+type TransactionImport struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	AccountID      uuid.UUID  `json:"account_id"`
+	Format         string     `json:"format"`
+	Filename       string     `json:"filename"`
+	Status         string     `json:"status"`
+	RowCount       int32      `json:"row_count"`
+	DuplicateCount int32      `json:"duplicate_count"`
+	CommittedCount int32      `json:"committed_count"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CommittedAt    *time.Time `json:"committed_at"`
+}
+
 type TransactionRule struct {
 	ID         uuid.UUID  `json:"id"`
 	Name       string     `json:"name"`
@@ -390,6 +498,27 @@ type TransactionRule struct {
 	DeletedAt  *time.Time `json:"deleted_at"`
 }
 
+type TransactionRuleApplication struct {
+	ID            uuid.UUID  `json:"id"`
+	RuleID        uuid.UUID  `json:"rule_id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Actions       []byte     `json:"actions"`
+	Changes       []byte     `json:"changes"`
+	CreatedBy     uuid.UUID  `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UndoneAt      *time.Time `json:"undone_at"`
+}
+
+type TransactionSplit struct {
+	ID            uuid.UUID      `json:"id"`
+	TransactionID uuid.UUID      `json:"transaction_id"`
+	CategoryID    *uuid.UUID     `json:"category_id"`
+	Amount        pgtype.Numeric `json:"amount"`
+	Percentage    pgtype.Numeric `json:"percentage"`
+	Description   *string        `json:"description"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
 type User struct {
 	ID            uuid.UUID  `json:"id"`
 	Email         string     `json:"email"`
@@ -419,6 +548,8 @@ type UserFinancialConnection struct {
 	ExpiresAt            *time.Time `json:"expires_at"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
+	SyncFailures         int32      `json:"sync_failures"`
+	LastError            *string    `json:"last_error"`
 }
 
 type UserToken struct {
@@ -438,6 +569,20 @@ type UserToken struct {
 	Revoked      *bool      `json:"revoked"`
 }
 
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID          `json:"id"`
+	EventID        uuid.UUID          `json:"event_id"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	Attempt        int32              `json:"attempt"`
+	RequestUrl     string             `json:"request_url"`
+	RequestHeaders dto.WebhookHeaders `json:"request_headers"`
+	ResponseStatus *int32             `json:"response_status"`
+	ResponseBody   *string            `json:"response_body"`
+	ErrorMessage   *string            `json:"error_message"`
+	DurationMs     int32              `json:"duration_ms"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
 type WebhookEvent struct {
 	ID             uuid.UUID        `json:"id"`
 	SubscriptionID uuid.UUID        `json:"subscription_id"`
@@ -447,14 +592,22 @@ type WebhookEvent struct {
 	Attempts       *int32           `json:"attempts"`
 	LastAttempt    pgtype.Timestamp `json:"last_attempt"`
 	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ResponseStatus *int32           `json:"response_status"`
+	LastError      *string          `json:"last_error"`
+	DeliveredAt    *time.Time       `json:"delivered_at"`
 }
 
 type WebhookSubscription struct {
-	ID          uuid.UUID        `json:"id"`
-	UserID      uuid.UUID        `json:"user_id"`
-	Event       []string         `json:"event"`
-	Active      bool             `json:"active"`
-	EndpointUrl string           `json:"endpoint_url"`
-	Secret      string           `json:"secret"`
-	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	ID                      uuid.UUID        `json:"id"`
+	UserID                  uuid.UUID        `json:"user_id"`
+	Event                   []string         `json:"event"`
+	Active                  bool             `json:"active"`
+	EndpointUrl             string           `json:"endpoint_url"`
+	Secret                  string           `json:"secret"`
+	CreatedAt               pgtype.Timestamp `json:"created_at"`
+	ConsecutiveFailures     int32            `json:"consecutive_failures"`
+	DisabledAt              *time.Time       `json:"disabled_at"`
+	DisabledReason          *string          `json:"disabled_reason"`
+	PreviousSecret          *string          `json:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time       `json:"previous_secret_expires_at"`
 }
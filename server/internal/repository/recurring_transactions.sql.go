@@ -789,6 +789,55 @@ func (q *Queries) PauseRecurringTransaction(ctx context.Context, arg PauseRecurr
 	return i, err
 }
 
+const skipRecurringInstance = `-- name: SkipRecurringInstance :one
+UPDATE recurring_transactions
+SET
+    next_due_date = $1,
+    updated_at = current_timestamp
+WHERE id = $2 AND next_due_date = $3 AND deleted_at IS NULL
+RETURNING id, user_id, account_id, category_id, destination_account_id, amount, type, description, details, frequency, frequency_interval, frequency_data, start_date, end_date, last_generated_date, next_due_date, auto_post, is_paused, max_occurrences, occurrences_count, template_name, tags, created_at, updated_at, deleted_at
+`
+
+type SkipRecurringInstanceParams struct {
+	NextDueDate pgtype.Timestamptz `json:"next_due_date"`
+	ID          uuid.UUID          `json:"id"`
+	DueDate     pgtype.Timestamptz `json:"due_date"`
+}
+
+// Moves the template past the pending instance at due_date, unless it already moved
+func (q *Queries) SkipRecurringInstance(ctx context.Context, arg SkipRecurringInstanceParams) (RecurringTransaction, error) {
+	row := q.db.QueryRow(ctx, skipRecurringInstance, arg.NextDueDate, arg.ID, arg.DueDate)
+	var i RecurringTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.DestinationAccountID,
+		&i.Amount,
+		&i.Type,
+		&i.Description,
+		&i.Details,
+		&i.Frequency,
+		&i.FrequencyInterval,
+		&i.FrequencyData,
+		&i.StartDate,
+		&i.EndDate,
+		&i.LastGeneratedDate,
+		&i.NextDueDate,
+		&i.AutoPost,
+		&i.IsPaused,
+		&i.MaxOccurrences,
+		&i.OccurrencesCount,
+		&i.TemplateName,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateRecurringTransaction = `-- name: UpdateRecurringTransaction :one
 UPDATE recurring_transactions
 SET
@@ -892,25 +941,36 @@ func (q *Queries) UpdateRecurringTransaction(ctx context.Context, arg UpdateRecu
 	return i, err
 }
 
-const updateRecurringTransactionAfterGeneration = `-- name: UpdateRecurringTransactionAfterGeneration :exec
+const updateRecurringTransactionAfterGeneration = `-- name: UpdateRecurringTransactionAfterGeneration :execrows
 UPDATE recurring_transactions
 SET
-    last_generated_date = $2,
-    next_due_date = $3,
+    last_generated_date = $1,
+    next_due_date = $2,
     occurrences_count = occurrences_count + 1,
     updated_at = current_timestamp
-WHERE id = $1
+WHERE id = $3 AND next_due_date = $4 AND deleted_at IS NULL
 `
 
 type UpdateRecurringTransactionAfterGenerationParams struct {
-	ID                uuid.UUID          `json:"id"`
 	LastGeneratedDate pgtype.Timestamptz `json:"last_generated_date"`
 	NextDueDate       pgtype.Timestamptz `json:"next_due_date"`
+	ID                uuid.UUID          `json:"id"`
+	DueDate           pgtype.Timestamptz `json:"due_date"`
 }
 
-func (q *Queries) UpdateRecurringTransactionAfterGeneration(ctx context.Context, arg UpdateRecurringTransactionAfterGenerationParams) error {
-	_, err := q.db.Exec(ctx, updateRecurringTransactionAfterGeneration, arg.ID, arg.LastGeneratedDate, arg.NextDueDate)
-	return err
+// Only the pending instance is generated: no row is updated when a concurrent post or the
+// daily job already moved the template past due_date
+func (q *Queries) UpdateRecurringTransactionAfterGeneration(ctx context.Context, arg UpdateRecurringTransactionAfterGenerationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRecurringTransactionAfterGeneration,
+		arg.LastGeneratedDate,
+		arg.NextDueDate,
+		arg.ID,
+		arg.DueDate,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateRecurringTransactionNextDueDate = `-- name: UpdateRecurringTransactionNextDueDate :one
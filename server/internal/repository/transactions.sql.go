@@ -33,11 +33,12 @@ type BatchCreateTransactionParams struct {
 	RecurringInstanceDate  pgtype.Timestamptz `json:"recurring_instance_date"`
 }
 
-const bulkDeleteTransactions = `-- name: BulkDeleteTransactions :exec
+const bulkDeleteTransactions = `-- name: BulkDeleteTransactions :many
 UPDATE transactions
 SET deleted_at = current_timestamp
 WHERE id = ANY($1::uuid[])
     AND created_by = $2
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
 `
 
 type BulkDeleteTransactionsParams struct {
@@ -45,12 +46,52 @@ type BulkDeleteTransactionsParams struct {
 	UserID *uuid.UUID  `json:"user_id"`
 }
 
-func (q *Queries) BulkDeleteTransactions(ctx context.Context, arg BulkDeleteTransactionsParams) error {
-	_, err := q.db.Exec(ctx, bulkDeleteTransactions, arg.Ids, arg.UserID)
-	return err
+func (q *Queries) BulkDeleteTransactions(ctx context.Context, arg BulkDeleteTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, bulkDeleteTransactions, arg.Ids, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.Type,
+			&i.AccountID,
+			&i.CategoryID,
+			&i.DestinationAccountID,
+			&i.TransactionDatetime,
+			&i.Description,
+			&i.Details,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.IsExternal,
+			&i.ProviderTransactionID,
+			&i.TransactionCurrency,
+			&i.OriginalAmount,
+			&i.ExchangeRate,
+			&i.ExchangeRateDate,
+			&i.IsCategorized,
+			&i.SharedFinanceID,
+			&i.RecurringTransactionID,
+			&i.RecurringInstanceDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const bulkUpdateManualTransactions = `-- name: BulkUpdateManualTransactions :exec
+const bulkUpdateManualTransactions = `-- name: BulkUpdateManualTransactions :many
 UPDATE transactions
 SET 
     category_id = coalesce($1, category_id),
@@ -61,6 +102,7 @@ WHERE id = ANY($5::uuid[])
     AND created_by = $6
     AND is_external = false
     AND deleted_at IS NULL
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
 `
 
 type BulkUpdateManualTransactionsParams struct {
@@ -72,8 +114,8 @@ type BulkUpdateManualTransactionsParams struct {
 	UserID              *uuid.UUID         `json:"user_id"`
 }
 
-func (q *Queries) BulkUpdateManualTransactions(ctx context.Context, arg BulkUpdateManualTransactionsParams) error {
-	_, err := q.db.Exec(ctx, bulkUpdateManualTransactions,
+func (q *Queries) BulkUpdateManualTransactions(ctx context.Context, arg BulkUpdateManualTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, bulkUpdateManualTransactions,
 		arg.CategoryID,
 		arg.AccountID,
 		arg.TransactionDatetime,
@@ -81,10 +123,50 @@ func (q *Queries) BulkUpdateManualTransactions(ctx context.Context, arg BulkUpda
 		arg.Ids,
 		arg.UserID,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.Type,
+			&i.AccountID,
+			&i.CategoryID,
+			&i.DestinationAccountID,
+			&i.TransactionDatetime,
+			&i.Description,
+			&i.Details,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.IsExternal,
+			&i.ProviderTransactionID,
+			&i.TransactionCurrency,
+			&i.OriginalAmount,
+			&i.ExchangeRate,
+			&i.ExchangeRateDate,
+			&i.IsCategorized,
+			&i.SharedFinanceID,
+			&i.RecurringTransactionID,
+			&i.RecurringInstanceDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const bulkUpdateTransactionCategories = `-- name: BulkUpdateTransactionCategories :exec
+const bulkUpdateTransactionCategories = `-- name: BulkUpdateTransactionCategories :many
 UPDATE transactions
 SET 
     category_id = $1,
@@ -92,6 +174,7 @@ SET
 WHERE id = ANY($3::uuid[])
     AND created_by = $4
     AND deleted_at IS NULL
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
 `
 
 type BulkUpdateTransactionCategoriesParams struct {
@@ -101,14 +184,54 @@ type BulkUpdateTransactionCategoriesParams struct {
 	UserID     *uuid.UUID  `json:"user_id"`
 }
 
-func (q *Queries) BulkUpdateTransactionCategories(ctx context.Context, arg BulkUpdateTransactionCategoriesParams) error {
-	_, err := q.db.Exec(ctx, bulkUpdateTransactionCategories,
+func (q *Queries) BulkUpdateTransactionCategories(ctx context.Context, arg BulkUpdateTransactionCategoriesParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, bulkUpdateTransactionCategories,
 		arg.CategoryID,
 		arg.UpdatedBy,
 		arg.Ids,
 		arg.UserID,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.Type,
+			&i.AccountID,
+			&i.CategoryID,
+			&i.DestinationAccountID,
+			&i.TransactionDatetime,
+			&i.Description,
+			&i.Details,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.IsExternal,
+			&i.ProviderTransactionID,
+			&i.TransactionCurrency,
+			&i.OriginalAmount,
+			&i.ExchangeRate,
+			&i.ExchangeRateDate,
+			&i.IsCategorized,
+			&i.SharedFinanceID,
+			&i.RecurringTransactionID,
+			&i.RecurringInstanceDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const countTransactions = `-- name: CountTransactions :one
@@ -285,6 +408,43 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 	return i, err
 }
 
+const deleteDroppedProviderTransactions = `-- name: DeleteDroppedProviderTransactions :exec
+UPDATE transactions
+SET deleted_at = current_timestamp
+WHERE
+    account_id = $1
+    AND id = ANY($2::uuid[])
+`
+
+type DeleteDroppedProviderTransactionsParams struct {
+	AccountID uuid.UUID   `json:"account_id"`
+	Ids       []uuid.UUID `json:"ids"`
+}
+
+func (q *Queries) DeleteDroppedProviderTransactions(ctx context.Context, arg DeleteDroppedProviderTransactionsParams) error {
+	_, err := q.db.Exec(ctx, deleteDroppedProviderTransactions, arg.AccountID, arg.Ids)
+	return err
+}
+
+const deleteRemovedProviderTransactions = `-- name: DeleteRemovedProviderTransactions :exec
+UPDATE transactions
+SET deleted_at = current_timestamp
+WHERE
+    account_id = $1
+    AND provider_transaction_id = ANY($2::text[])
+    AND deleted_at IS NULL
+`
+
+type DeleteRemovedProviderTransactionsParams struct {
+	AccountID              uuid.UUID `json:"account_id"`
+	ProviderTransactionIds []string  `json:"provider_transaction_ids"`
+}
+
+func (q *Queries) DeleteRemovedProviderTransactions(ctx context.Context, arg DeleteRemovedProviderTransactionsParams) error {
+	_, err := q.db.Exec(ctx, deleteRemovedProviderTransactions, arg.AccountID, arg.ProviderTransactionIds)
+	return err
+}
+
 const deleteTransaction = `-- name: DeleteTransaction :exec
 UPDATE transactions
 SET deleted_at = current_timestamp
@@ -297,6 +457,78 @@ func (q *Queries) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const findTransferCounterpart = `-- name: FindTransferCounterpart :one
+SELECT id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
+FROM transactions
+WHERE
+    created_by = $1
+    AND deleted_at IS NULL
+    AND id <> $2
+    AND account_id <> $3
+    AND type = $4
+    AND amount = $5
+    AND transaction_currency = $6
+    AND transaction_datetime BETWEEN $7 AND $8
+ORDER BY abs(extract(epoch FROM transaction_datetime - $9::timestamptz))
+LIMIT 1
+`
+
+type FindTransferCounterpartParams struct {
+	UserID              *uuid.UUID         `json:"user_id"`
+	ID                  uuid.UUID          `json:"id"`
+	AccountID           uuid.UUID          `json:"account_id"`
+	Type                string             `json:"type"`
+	Amount              decimal.Decimal    `json:"amount"`
+	Currency            string             `json:"currency"`
+	StartDate           time.Time          `json:"start_date"`
+	EndDate             time.Time          `json:"end_date"`
+	TransactionDatetime pgtype.Timestamptz `json:"transaction_datetime"`
+}
+
+// The other side of a transfer recorded as an expense and an income: same amount with the
+// opposite sign on another account of the user, closest in time
+func (q *Queries) FindTransferCounterpart(ctx context.Context, arg FindTransferCounterpartParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, findTransferCounterpart,
+		arg.UserID,
+		arg.ID,
+		arg.AccountID,
+		arg.Type,
+		arg.Amount,
+		arg.Currency,
+		arg.StartDate,
+		arg.EndDate,
+		arg.TransactionDatetime,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.Type,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.DestinationAccountID,
+		&i.TransactionDatetime,
+		&i.Description,
+		&i.Details,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsExternal,
+		&i.ProviderTransactionID,
+		&i.TransactionCurrency,
+		&i.OriginalAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateDate,
+		&i.IsCategorized,
+		&i.SharedFinanceID,
+		&i.RecurringTransactionID,
+		&i.RecurringInstanceDate,
+	)
+	return i, err
+}
+
 const getCategorySpending = `-- name: GetCategorySpending :many
 SELECT
     c.name AS category_name,
@@ -425,6 +657,180 @@ func (q *Queries) GetTransactionStats(ctx context.Context, arg GetTransactionSta
 	return i, err
 }
 
+const linkTransfer = `-- name: LinkTransfer :one
+UPDATE transactions
+SET
+    type = 'transfer',
+    amount = $1,
+    account_id = $2,
+    destination_account_id = $3,
+    updated_by = $4
+WHERE
+    id = $5
+    AND deleted_at IS NULL
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
+`
+
+type LinkTransferParams struct {
+	Amount               decimal.Decimal `json:"amount"`
+	AccountID            uuid.UUID       `json:"account_id"`
+	DestinationAccountID *uuid.UUID      `json:"destination_account_id"`
+	UpdatedBy            *uuid.UUID      `json:"updated_by"`
+	ID                   uuid.UUID       `json:"id"`
+}
+
+func (q *Queries) LinkTransfer(ctx context.Context, arg LinkTransferParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, linkTransfer,
+		arg.Amount,
+		arg.AccountID,
+		arg.DestinationAccountID,
+		arg.UpdatedBy,
+		arg.ID,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.Type,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.DestinationAccountID,
+		&i.TransactionDatetime,
+		&i.Description,
+		&i.Details,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsExternal,
+		&i.ProviderTransactionID,
+		&i.TransactionCurrency,
+		&i.OriginalAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateDate,
+		&i.IsCategorized,
+		&i.SharedFinanceID,
+		&i.RecurringTransactionID,
+		&i.RecurringInstanceDate,
+	)
+	return i, err
+}
+
+const listPendingProviderTransactions = `-- name: ListPendingProviderTransactions :many
+SELECT
+    id,
+    provider_transaction_id::text AS provider_transaction_id,
+    amount,
+    transaction_datetime,
+    description,
+    COALESCE(details->>'merchant', '')::text AS merchant
+FROM transactions
+WHERE
+    account_id = $1
+    AND provider_transaction_id IS NOT NULL
+    AND deleted_at IS NULL
+    AND details->>'payment_status' = 'pending'
+    AND ($2::timestamptz IS NULL OR transaction_datetime >= $2::timestamptz)
+`
+
+type ListPendingProviderTransactionsParams struct {
+	AccountID uuid.UUID          `json:"account_id"`
+	Since     pgtype.Timestamptz `json:"since"`
+}
+
+type ListPendingProviderTransactionsRow struct {
+	ID                    uuid.UUID      `json:"id"`
+	ProviderTransactionID string         `json:"provider_transaction_id"`
+	Amount                pgtype.Numeric `json:"amount"`
+	TransactionDatetime   time.Time      `json:"transaction_datetime"`
+	Description           *string        `json:"description"`
+	Merchant              string         `json:"merchant"`
+}
+
+func (q *Queries) ListPendingProviderTransactions(ctx context.Context, arg ListPendingProviderTransactionsParams) ([]ListPendingProviderTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, listPendingProviderTransactions, arg.AccountID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPendingProviderTransactionsRow{}
+	for rows.Next() {
+		var i ListPendingProviderTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProviderTransactionID,
+			&i.Amount,
+			&i.TransactionDatetime,
+			&i.Description,
+			&i.Merchant,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProviderTransactions = `-- name: ListProviderTransactions :many
+SELECT
+    id,
+    provider_transaction_id::text AS provider_transaction_id,
+    amount,
+    transaction_datetime,
+    COALESCE(details->>'payment_status', '')::text AS payment_status,
+    deleted_at
+FROM transactions
+WHERE
+    account_id = $1
+    AND provider_transaction_id = ANY($2::text[])
+`
+
+type ListProviderTransactionsParams struct {
+	AccountID              uuid.UUID `json:"account_id"`
+	ProviderTransactionIds []string  `json:"provider_transaction_ids"`
+}
+
+type ListProviderTransactionsRow struct {
+	ID                    uuid.UUID      `json:"id"`
+	ProviderTransactionID string         `json:"provider_transaction_id"`
+	Amount                pgtype.Numeric `json:"amount"`
+	TransactionDatetime   time.Time      `json:"transaction_datetime"`
+	PaymentStatus         string         `json:"payment_status"`
+	DeletedAt             *time.Time     `json:"deleted_at"`
+}
+
+// Includes soft deleted rows so transactions removed by the user aren't imported again
+func (q *Queries) ListProviderTransactions(ctx context.Context, arg ListProviderTransactionsParams) ([]ListProviderTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, listProviderTransactions, arg.AccountID, arg.ProviderTransactionIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProviderTransactionsRow{}
+	for rows.Next() {
+		var i ListProviderTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProviderTransactionID,
+			&i.Amount,
+			&i.TransactionDatetime,
+			&i.PaymentStatus,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransactions = `-- name: ListTransactions :many
 SELECT
     t.id,
@@ -494,7 +900,8 @@ WHERE
          )
     )
 ORDER BY
-    t.transaction_datetime DESC
+    t.transaction_datetime DESC,
+    t.id DESC
 LIMIT
     $16
 OFFSET
@@ -810,6 +1217,148 @@ func (q *Queries) ListTransactionsByDateRange(ctx context.Context, arg ListTrans
 	return items, nil
 }
 
+const reconcileProviderTransaction = `-- name: ReconcileProviderTransaction :exec
+UPDATE transactions
+SET
+    provider_transaction_id = $1,
+    amount = $2,
+    original_amount = $2,
+    transaction_datetime = $3,
+    details = jsonb_set(COALESCE(details, '{}'::jsonb), '{payment_status}', to_jsonb($4::text)),
+    updated_at = current_timestamp
+WHERE id = $5
+`
+
+type ReconcileProviderTransactionParams struct {
+	ProviderTransactionID *string            `json:"provider_transaction_id"`
+	Amount                decimal.Decimal    `json:"amount"`
+	TransactionDatetime   pgtype.Timestamptz `json:"transaction_datetime"`
+	PaymentStatus         string             `json:"payment_status"`
+	ID                    uuid.UUID          `json:"id"`
+}
+
+func (q *Queries) ReconcileProviderTransaction(ctx context.Context, arg ReconcileProviderTransactionParams) error {
+	_, err := q.db.Exec(ctx, reconcileProviderTransaction,
+		arg.ProviderTransactionID,
+		arg.Amount,
+		arg.TransactionDatetime,
+		arg.PaymentStatus,
+		arg.ID,
+	)
+	return err
+}
+
+const restoreTransaction = `-- name: RestoreTransaction :one
+UPDATE transactions
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
+`
+
+func (q *Queries) RestoreTransaction(ctx context.Context, id uuid.UUID) (Transaction, error) {
+	row := q.db.QueryRow(ctx, restoreTransaction, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.Type,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.DestinationAccountID,
+		&i.TransactionDatetime,
+		&i.Description,
+		&i.Details,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsExternal,
+		&i.ProviderTransactionID,
+		&i.TransactionCurrency,
+		&i.OriginalAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateDate,
+		&i.IsCategorized,
+		&i.SharedFinanceID,
+		&i.RecurringTransactionID,
+		&i.RecurringInstanceDate,
+	)
+	return i, err
+}
+
+const revertTransaction = `-- name: RevertTransaction :one
+UPDATE transactions
+SET
+    type = $1,
+    amount = $2,
+    account_id = $3,
+    destination_account_id = $4,
+    category_id = $5,
+    description = $6,
+    details = $7,
+    updated_by = $8
+WHERE
+    id = $9
+    AND deleted_at IS NULL
+RETURNING id, amount, type, account_id, category_id, destination_account_id, transaction_datetime, description, details, created_by, updated_by, created_at, updated_at, deleted_at, is_external, provider_transaction_id, transaction_currency, original_amount, exchange_rate, exchange_rate_date, is_categorized, shared_finance_id, recurring_transaction_id, recurring_instance_date
+`
+
+type RevertTransactionParams struct {
+	Type                 string          `json:"type"`
+	Amount               decimal.Decimal `json:"amount"`
+	AccountID            uuid.UUID       `json:"account_id"`
+	DestinationAccountID *uuid.UUID      `json:"destination_account_id"`
+	CategoryID           uuid.UUID       `json:"category_id"`
+	Description          *string         `json:"description"`
+	Details              *dto.Details    `json:"details"`
+	UpdatedBy            *uuid.UUID      `json:"updated_by"`
+	ID                   uuid.UUID       `json:"id"`
+}
+
+// Puts back the fields rules changed as they were, unlike UpdateTransaction null values are kept
+func (q *Queries) RevertTransaction(ctx context.Context, arg RevertTransactionParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, revertTransaction,
+		arg.Type,
+		arg.Amount,
+		arg.AccountID,
+		arg.DestinationAccountID,
+		arg.CategoryID,
+		arg.Description,
+		arg.Details,
+		arg.UpdatedBy,
+		arg.ID,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.Type,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.DestinationAccountID,
+		&i.TransactionDatetime,
+		&i.Description,
+		&i.Details,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.IsExternal,
+		&i.ProviderTransactionID,
+		&i.TransactionCurrency,
+		&i.OriginalAmount,
+		&i.ExchangeRate,
+		&i.ExchangeRateDate,
+		&i.IsCategorized,
+		&i.SharedFinanceID,
+		&i.RecurringTransactionID,
+		&i.RecurringInstanceDate,
+	)
+	return i, err
+}
+
 const updateTransaction = `-- name: UpdateTransaction :one
 UPDATE transactions
 SET
@@ -7,7 +7,9 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -24,6 +26,49 @@ func (q *Queries) CleanupOldWebhookEvents(ctx context.Context) error {
 	return err
 }
 
+const createWebhookDeliveryAttempt = `-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (
+    event_id,
+    subscription_id,
+    attempt,
+    request_url,
+    request_headers,
+    response_status,
+    response_body,
+    error_message,
+    duration_ms
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+`
+
+type CreateWebhookDeliveryAttemptParams struct {
+	EventID        uuid.UUID          `json:"event_id"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	Attempt        int32              `json:"attempt"`
+	RequestUrl     string             `json:"request_url"`
+	RequestHeaders dto.WebhookHeaders `json:"request_headers"`
+	ResponseStatus *int32             `json:"response_status"`
+	ResponseBody   *string            `json:"response_body"`
+	ErrorMessage   *string            `json:"error_message"`
+	DurationMs     int32              `json:"duration_ms"`
+}
+
+func (q *Queries) CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, createWebhookDeliveryAttempt,
+		arg.EventID,
+		arg.SubscriptionID,
+		arg.Attempt,
+		arg.RequestUrl,
+		arg.RequestHeaders,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+		arg.ErrorMessage,
+		arg.DurationMs,
+	)
+	return err
+}
+
 const createWebhookEvent = `-- name: CreateWebhookEvent :one
 INSERT INTO webhook_events (
     subscription_id,
@@ -32,7 +77,7 @@ INSERT INTO webhook_events (
     status
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at
+) RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at, response_status, last_error, delivered_at
 `
 
 type CreateWebhookEventParams struct {
@@ -59,6 +104,9 @@ func (q *Queries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEvent
 		&i.Attempts,
 		&i.LastAttempt,
 		&i.CreatedAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
 	)
 	return i, err
 }
@@ -72,7 +120,7 @@ INSERT INTO webhook_subscriptions (
     secret
 ) VALUES (
     $1, $2, $3, $4, $5
-) RETURNING id, user_id, event, active, endpoint_url, secret, created_at
+) RETURNING id, user_id, event, active, endpoint_url, secret, created_at, consecutive_failures, disabled_at, disabled_reason, previous_secret, previous_secret_expires_at
 `
 
 type CreateWebhookSubscriptionParams struct {
@@ -100,6 +148,11 @@ func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebho
 		&i.EndpointUrl,
 		&i.Secret,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
 	)
 	return i, err
 }
@@ -140,7 +193,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE
     status IN ('pending', 'retrying')
@@ -168,6 +224,9 @@ func (q *Queries) GetPendingWebhookEvents(ctx context.Context, limit int64) ([]W
 			&i.Attempts,
 			&i.LastAttempt,
 			&i.CreatedAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -188,7 +247,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE id = $1 LIMIT 1
 `
@@ -205,6 +267,9 @@ func (q *Queries) GetWebhookEventById(ctx context.Context, id uuid.UUID) (Webhoo
 		&i.Attempts,
 		&i.LastAttempt,
 		&i.CreatedAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
 	)
 	return i, err
 }
@@ -218,7 +283,10 @@ SELECT
     status,
     attempts,
     last_attempt,
-    created_at
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
 FROM webhook_events
 WHERE subscription_id = $1
 ORDER BY created_at DESC
@@ -242,6 +310,9 @@ func (q *Queries) GetWebhookEventsBySubscriptionId(ctx context.Context, subscrip
 			&i.Attempts,
 			&i.LastAttempt,
 			&i.CreatedAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -261,7 +332,12 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE id = $1 LIMIT 1
 `
@@ -277,6 +353,11 @@ func (q *Queries) GetWebhookSubscriptionById(ctx context.Context, id uuid.UUID)
 		&i.EndpointUrl,
 		&i.Secret,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
 	)
 	return i, err
 }
@@ -289,7 +370,12 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE $1 = ANY(event)
 ORDER BY created_at
@@ -312,6 +398,11 @@ func (q *Queries) GetWebhookSubscriptionsByEvent(ctx context.Context, event stri
 			&i.EndpointUrl,
 			&i.Secret,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -331,7 +422,12 @@ SELECT
     active,
     endpoint_url,
     secret,
-    created_at
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
 FROM webhook_subscriptions
 WHERE user_id = $1
 ORDER BY created_at DESC
@@ -354,6 +450,208 @@ func (q *Queries) GetWebhookSubscriptionsByUserId(ctx context.Context, userID uu
 			&i.EndpointUrl,
 			&i.Secret,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveWebhookSubscriptionsForEvent = `-- name: ListActiveWebhookSubscriptionsForEvent :many
+SELECT
+    id,
+    user_id,
+    event,
+    active,
+    endpoint_url,
+    secret,
+    created_at,
+    consecutive_failures,
+    disabled_at,
+    disabled_reason,
+    previous_secret,
+    previous_secret_expires_at
+FROM webhook_subscriptions
+WHERE
+    user_id = $1
+    AND active
+    AND ($2::text = ANY(event) OR '*' = ANY(event))
+ORDER BY created_at
+`
+
+type ListActiveWebhookSubscriptionsForEventParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	EventType string    `json:"event_type"`
+}
+
+func (q *Queries) ListActiveWebhookSubscriptionsForEvent(ctx context.Context, arg ListActiveWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listActiveWebhookSubscriptionsForEvent, arg.UserID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Event,
+			&i.Active,
+			&i.EndpointUrl,
+			&i.Secret,
+			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveryAttempts = `-- name: ListWebhookDeliveryAttempts :many
+SELECT
+    a.id,
+    a.event_id,
+    e.event_type,
+    a.attempt,
+    a.request_url,
+    a.request_headers,
+    a.response_status,
+    a.response_body,
+    a.error_message,
+    a.duration_ms,
+    a.created_at
+FROM webhook_delivery_attempts a
+JOIN webhook_events e ON e.id = a.event_id
+WHERE
+    a.subscription_id = $1
+    AND ($2::uuid IS NULL OR a.event_id = $2::uuid)
+ORDER BY a.created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListWebhookDeliveryAttemptsParams struct {
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	EventID        *uuid.UUID `json:"event_id"`
+	Limit          int64      `json:"limit"`
+	Offset         int64      `json:"offset"`
+}
+
+type ListWebhookDeliveryAttemptsRow struct {
+	ID             uuid.UUID          `json:"id"`
+	EventID        uuid.UUID          `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Attempt        int32              `json:"attempt"`
+	RequestUrl     string             `json:"request_url"`
+	RequestHeaders dto.WebhookHeaders `json:"request_headers"`
+	ResponseStatus *int32             `json:"response_status"`
+	ResponseBody   *string            `json:"response_body"`
+	ErrorMessage   *string            `json:"error_message"`
+	DurationMs     int32              `json:"duration_ms"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+func (q *Queries) ListWebhookDeliveryAttempts(ctx context.Context, arg ListWebhookDeliveryAttemptsParams) ([]ListWebhookDeliveryAttemptsRow, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveryAttempts,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWebhookDeliveryAttemptsRow{}
+	for rows.Next() {
+		var i ListWebhookDeliveryAttemptsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Attempt,
+			&i.RequestUrl,
+			&i.RequestHeaders,
+			&i.ResponseStatus,
+			&i.ResponseBody,
+			&i.ErrorMessage,
+			&i.DurationMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookEventsBySubscription = `-- name: ListWebhookEventsBySubscription :many
+SELECT
+    id,
+    subscription_id,
+    event_type,
+    payload,
+    status,
+    attempts,
+    last_attempt,
+    created_at,
+    response_status,
+    last_error,
+    delivered_at
+FROM webhook_events
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListWebhookEventsBySubscriptionParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Limit          int64     `json:"limit"`
+	Offset         int64     `json:"offset"`
+}
+
+func (q *Queries) ListWebhookEventsBySubscription(ctx context.Context, arg ListWebhookEventsBySubscriptionParams) ([]WebhookEvent, error) {
+	rows, err := q.db.Query(ctx, listWebhookEventsBySubscription, arg.SubscriptionID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookEvent{}
+	for rows.Next() {
+		var i WebhookEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.LastAttempt,
+			&i.CreatedAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -424,6 +722,182 @@ func (q *Queries) ListWebhookSubscriptions(ctx context.Context, arg ListWebhookS
 	return items, nil
 }
 
+const recordWebhookEventAttempt = `-- name: RecordWebhookEventAttempt :one
+UPDATE webhook_events
+SET
+    status = $2,
+    attempts = attempts + 1,
+    last_attempt = now(),
+    response_status = $3,
+    last_error = $4,
+    delivered_at = CASE WHEN $2 = 'sent' THEN now() ELSE delivered_at END
+WHERE id = $1
+RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at, response_status, last_error, delivered_at
+`
+
+type RecordWebhookEventAttemptParams struct {
+	ID             uuid.UUID `json:"id"`
+	Status         *string   `json:"status"`
+	ResponseStatus *int32    `json:"response_status"`
+	LastError      *string   `json:"last_error"`
+}
+
+func (q *Queries) RecordWebhookEventAttempt(ctx context.Context, arg RecordWebhookEventAttemptParams) (WebhookEvent, error) {
+	row := q.db.QueryRow(ctx, recordWebhookEventAttempt,
+		arg.ID,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.LastError,
+	)
+	var i WebhookEvent
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.LastAttempt,
+		&i.CreatedAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const recordWebhookSubscriptionFailure = `-- name: RecordWebhookSubscriptionFailure :one
+UPDATE webhook_subscriptions
+SET
+    consecutive_failures = consecutive_failures + 1,
+    active = CASE WHEN consecutive_failures + 1 >= $1::int THEN false ELSE active END,
+    disabled_at = CASE
+        WHEN active AND consecutive_failures + 1 >= $1::int THEN now()
+        ELSE disabled_at
+    END,
+    disabled_reason = CASE
+        WHEN active AND consecutive_failures + 1 >= $1::int THEN $2
+        ELSE disabled_reason
+    END
+WHERE id = $3
+RETURNING id, user_id, event, active, endpoint_url, secret, created_at, consecutive_failures, disabled_at, disabled_reason, previous_secret, previous_secret_expires_at
+`
+
+type RecordWebhookSubscriptionFailureParams struct {
+	MaxFailures    int32     `json:"max_failures"`
+	DisabledReason *string   `json:"disabled_reason"`
+	ID             uuid.UUID `json:"id"`
+}
+
+// Counts a failed delivery and disables the subscription once max_failures is reached
+func (q *Queries) RecordWebhookSubscriptionFailure(ctx context.Context, arg RecordWebhookSubscriptionFailureParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, recordWebhookSubscriptionFailure, arg.MaxFailures, arg.DisabledReason, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Event,
+		&i.Active,
+		&i.EndpointUrl,
+		&i.Secret,
+		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+	)
+	return i, err
+}
+
+const resetWebhookEventForRedelivery = `-- name: ResetWebhookEventForRedelivery :one
+-- Only finished deliveries, a pending or retrying one would race with its next attempt
+UPDATE webhook_events
+SET
+    status = 'pending',
+    last_error = NULL
+WHERE
+    id = $1
+    AND status IN ('sent', 'failed')
+RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at, response_status, last_error, delivered_at
+`
+
+// Only finished deliveries, a pending or retrying one would race with its next attempt
+func (q *Queries) ResetWebhookEventForRedelivery(ctx context.Context, id uuid.UUID) (WebhookEvent, error) {
+	row := q.db.QueryRow(ctx, resetWebhookEventForRedelivery, id)
+	var i WebhookEvent
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.LastAttempt,
+		&i.CreatedAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const resetWebhookSubscriptionFailures = `-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1
+`
+
+func (q *Queries) ResetWebhookSubscriptionFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, resetWebhookSubscriptionFailures, id)
+	return err
+}
+
+const rotateWebhookSubscriptionSecret = `-- name: RotateWebhookSubscriptionSecret :one
+UPDATE webhook_subscriptions
+SET
+    previous_secret = secret,
+    previous_secret_expires_at = $1,
+    secret = $2
+WHERE
+    id = $3
+    AND user_id = $4
+RETURNING id, user_id, event, active, endpoint_url, secret, created_at, consecutive_failures, disabled_at, disabled_reason, previous_secret, previous_secret_expires_at
+`
+
+type RotateWebhookSubscriptionSecretParams struct {
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at"`
+	Secret                  string     `json:"secret"`
+	ID                      uuid.UUID  `json:"id"`
+	UserID                  uuid.UUID  `json:"user_id"`
+}
+
+// The current secret keeps signing deliveries, next to the new one, until previous_secret_expires_at
+func (q *Queries) RotateWebhookSubscriptionSecret(ctx context.Context, arg RotateWebhookSubscriptionSecretParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, rotateWebhookSubscriptionSecret,
+		arg.PreviousSecretExpiresAt,
+		arg.Secret,
+		arg.ID,
+		arg.UserID,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Event,
+		&i.Active,
+		&i.EndpointUrl,
+		&i.Secret,
+		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+	)
+	return i, err
+}
+
 const updateWebhookEventStatus = `-- name: UpdateWebhookEventStatus :one
 UPDATE webhook_events
 SET
@@ -431,7 +905,7 @@ SET
     attempts = attempts + 1,
     last_attempt = now()
 WHERE id = $1
-RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at
+RETURNING id, subscription_id, event_type, payload, status, attempts, last_attempt, created_at, response_status, last_error, delivered_at
 `
 
 type UpdateWebhookEventStatusParams struct {
@@ -451,6 +925,9 @@ func (q *Queries) UpdateWebhookEventStatus(ctx context.Context, arg UpdateWebhoo
 		&i.Attempts,
 		&i.LastAttempt,
 		&i.CreatedAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
 	)
 	return i, err
 }
@@ -461,11 +938,15 @@ SET
     event = coalesce($1, event),
     endpoint_url = coalesce($2, endpoint_url),
     secret = coalesce($3, secret),
-    active = coalesce($4, active)
+    active = coalesce($4, active),
+    -- Reactivating a subscription gives it a fresh start
+    consecutive_failures = CASE WHEN $4::boolean THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE WHEN $4::boolean THEN NULL ELSE disabled_at END,
+    disabled_reason = CASE WHEN $4::boolean THEN NULL ELSE disabled_reason END
 WHERE
     id = $5
     AND user_id = $6
-RETURNING id, user_id, event, active, endpoint_url, secret, created_at
+RETURNING id, user_id, event, active, endpoint_url, secret, created_at, consecutive_failures, disabled_at, disabled_reason, previous_secret, previous_secret_expires_at
 `
 
 type UpdateWebhookSubscriptionParams struct {
@@ -495,6 +976,11 @@ func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebho
 		&i.EndpointUrl,
 		&i.Secret,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
 	)
 	return i, err
 }
@@ -8,6 +8,9 @@ import (
 	athHandler "github.com/Fantasy-Programming/nuts/server/internal/domain/auth/handlers"
 	athRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/auth/repository"
 	athService "github.com/Fantasy-Programming/nuts/server/internal/domain/auth/service"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/budgets"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/exports"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/integrations"
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/mail"
 
 	"github.com/Fantasy-Programming/nuts/server/internal/domain/meta"
@@ -19,6 +22,7 @@ import (
 	ctgHandler "github.com/Fantasy-Programming/nuts/server/internal/domain/categories/handlers"
 	ctgRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/categories/repository"
 	ctgService "github.com/Fantasy-Programming/nuts/server/internal/domain/categories/service"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/attachments"
 	trcHandler "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/handlers"
 	trcRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
 	trcService "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/service"
@@ -38,8 +42,11 @@ func (s *Server) RegisterDomain() {
 	s.initTransaction()
 	s.initCategory()
 	s.initTags()
+	s.initBudgets()
 	s.initMeta()
 	s.initWebHooks()
+	s.initExports()
+	s.initIntegrations()
 	s.initMail()
 	s.initVersion()
 	s.initHealth()
@@ -81,13 +88,18 @@ func (s *Server) initAccount() {
 	accountsRepo := accRepo.NewRepository(s.db)
 	transactionsRepo := trcRepo.NewRepository(s.db)
 	categoriesRepo := ctgRepo.NewRepository(s.db)
-	accountsService := accService.New(s.db, encrypter, s.openfinance, s.jobsManager, accountsRepo, transactionsRepo, categoriesRepo, s.logger)
+	accountsService := accService.New(s.db, encrypter, s.openfinance, s.jobsManager, s.events, accountsRepo, transactionsRepo, categoriesRepo, s.logger)
 
 	AccountDomain := accHandler.RegisterHTTPHandlers(accountsService, s.validator, s.jwt, s.logger)
 	s.router.Mount("/accounts", AccountDomain)
 }
 
 func (s *Server) initTransaction() {
+	encrypter, err := encrypt.NewEncrypter(s.cfg.EncryptionSecretKeyHex)
+	if err != nil {
+		s.logger.Panic().Err(err).Msg("Failed to setup encrypter")
+	}
+
 	transactionsRepo := trcRepo.NewRepository(s.db)
 	accountsRepo := accRepo.NewRepository(s.db)
 	llmService, err := llm.NewService(s.cfg.LLM, s.logger)
@@ -95,7 +107,9 @@ func (s *Server) initTransaction() {
 		s.logger.Panic().Err(err).Msg("Failed to setup llm service")
 	}
 
-	transactionsService := trcService.New(s.db, transactionsRepo, accountsRepo, llmService, s.jobsManager, s.logger)
+	attachmentStore := attachments.NewStore(s.storage, s.cfg.PrivateBucketName, encrypter)
+
+	transactionsService := trcService.New(s.db, transactionsRepo, accountsRepo, llmService, s.jobsManager, attachmentStore, s.events, s.logger)
 	TransactionDomain := trcHandler.RegisterHTTPHandlers(transactionsService, s.jwt, s.validator, s.logger)
 	s.router.Mount("/transactions", TransactionDomain)
 }
@@ -112,11 +126,26 @@ func (s *Server) initTags() {
 	s.router.Mount("/tags", TagsDomain)
 }
 
+func (s *Server) initBudgets() {
+	budgetsDomain := budgets.RegisterHTTPHandlers(s.db, s.validator, s.jwt, s.events, s.logger)
+	s.router.Mount("/budgets", budgetsDomain)
+}
+
 func (s *Server) initWebHooks() {
-	hooksDomain := webhooks.RegisterHTTPHandlers(s.db, s.validator, s.jwt, s.logger)
+	hooksDomain := webhooks.RegisterHTTPHandlers(s.db, s.validator, s.jwt, s.jobsManager, s.events, s.logger)
 	s.router.Mount("/webhooks", hooksDomain)
 }
 
+func (s *Server) initExports() {
+	exportsDomain := exports.RegisterHTTPHandlers(s.db, s.validator, s.jwt, s.jobsManager, s.storage, s.cfg.PrivateBucketName, s.logger)
+	s.router.Mount("/exports", exportsDomain)
+}
+
+func (s *Server) initIntegrations() {
+	IntegrationsDomain := integrations.RegisterHTTPHandlers(s.db, s.cfg.Integrations, s.jobsManager, s.events, s.logger)
+	s.router.Mount("/integrations", IntegrationsDomain)
+}
+
 func (s *Server) initMail() {
 	MailDomain := mail.RegisterHTTPHandlers(s.db, s.validator, s.jwt, s.mailer, s.logger)
 	s.router.Mount("/mail", MailDomain)
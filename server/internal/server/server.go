@@ -16,6 +16,7 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/i18n"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/validation"
 	"github.com/Fantasy-Programming/nuts/server/pkg/database"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jobs"
 	"github.com/Fantasy-Programming/nuts/server/pkg/jwt"
@@ -47,6 +48,7 @@ type Server struct {
 	cors        *cors.Cors
 	router      router.Router
 	jobsManager *jobs.Service
+	events      *events.Bus
 	validator   *validation.Validator
 	i18n        *i18n.I18n
 
@@ -98,6 +100,7 @@ func (s *Server) Init() {
 	// s.SetupPaymentProcessors()
 
 	s.NewTokenService()
+	s.NewEventBus()
 	s.NewJobService()
 	s.NewValidator()
 	s.NewI18n()
@@ -360,8 +363,12 @@ func (s *Server) setGlobalMiddleware() {
 	})
 }
 
+func (s *Server) NewEventBus() {
+	s.events = events.NewBus(s.logger)
+}
+
 func (s *Server) NewJobService() {
-	jobService, err := jobs.NewService(s.db, s.logger, s.openfinance, s.cfg.EncryptionSecretKeyHex)
+	jobService, err := jobs.NewService(s.db, s.logger, s.openfinance, s.events, s.cfg.EncryptionSecretKeyHex, s.cfg.Webhooks.AllowedDestinations, s.storage, s.cfg.PrivateBucketName)
 	if err != nil {
 		s.logger.Fatal().Err(err).Msg("Failed to setup job service")
 	}
@@ -4,6 +4,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -28,6 +29,12 @@ func NewEncrypter(keyHex string) (*Encrypter, error) {
 	return &Encrypter{key: key}, nil
 }
 
+// KeyID identifies the key without revealing it, to know which key encrypted stored data
+func (e *Encrypter) KeyID() string {
+	sum := sha256.Sum256(e.key)
+	return hex.EncodeToString(sum[:8])
+}
+
 func (e *Encrypter) Encrypt(plaintext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(e.key)
 	if err != nil {
@@ -0,0 +1,80 @@
+package budgetperiod
+
+import "time"
+
+const (
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// Period is a half-open [Start, End) window of a budget
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// At returns the budget period that contains the given date.
+// Periods are anchored on the budget start date and repeat according to the
+// frequency, the last one being cut at the (inclusive) end date.
+// It returns false when the date is outside of the budget range.
+func At(startDate, endDate time.Time, frequency string, at time.Time) (Period, bool) {
+	start := truncateDay(startDate)
+	limit := truncateDay(endDate).AddDate(0, 0, 1)
+	day := truncateDay(at)
+
+	if day.Before(start) || !day.Before(limit) {
+		return Period{}, false
+	}
+
+	var period Period
+
+	switch frequency {
+	case FrequencyWeekly:
+		weeks := int(day.Sub(start).Hours()/24) / 7
+		period.Start = start.AddDate(0, 0, weeks*7)
+		period.End = period.Start.AddDate(0, 0, 7)
+	case FrequencyMonthly, FrequencyYearly:
+		step := 1
+		if frequency == FrequencyYearly {
+			step = 12
+		}
+
+		months := (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+		months -= months % step
+		if addMonths(start, months).After(day) {
+			months -= step
+		}
+
+		period.Start = addMonths(start, months)
+		period.End = addMonths(start, months+step)
+	default:
+		// Unknown frequencies are treated as a single period spanning the budget
+		period.Start = start
+		period.End = limit
+	}
+
+	if period.End.After(limit) {
+		period.End = limit
+	}
+
+	return period, true
+}
+
+// addMonths adds n months to t, clamping the day to the end of the target
+// month so that a budget starting on the 31st keeps a monthly cadence.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package budgetperiod_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/pkg/budgetperiod"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		frequency string
+		at        time.Time
+		want      budgetperiod.Period
+		ok        bool
+	}{
+		{
+			name:      "weekly second week",
+			start:     date(2025, 1, 1),
+			end:       date(2025, 12, 31),
+			frequency: budgetperiod.FrequencyWeekly,
+			at:        date(2025, 1, 10),
+			want:      budgetperiod.Period{Start: date(2025, 1, 8), End: date(2025, 1, 15)},
+			ok:        true,
+		},
+		{
+			name:      "monthly anchored mid month",
+			start:     date(2025, 1, 15),
+			end:       date(2025, 12, 31),
+			frequency: budgetperiod.FrequencyMonthly,
+			at:        date(2025, 3, 2),
+			want:      budgetperiod.Period{Start: date(2025, 2, 15), End: date(2025, 3, 15)},
+			ok:        true,
+		},
+		{
+			name:      "monthly clamps to end of month",
+			start:     date(2025, 1, 31),
+			end:       date(2025, 12, 31),
+			frequency: budgetperiod.FrequencyMonthly,
+			at:        date(2025, 2, 28),
+			want:      budgetperiod.Period{Start: date(2025, 2, 28), End: date(2025, 3, 31)},
+			ok:        true,
+		},
+		{
+			name:      "yearly",
+			start:     date(2024, 6, 1),
+			end:       date(2027, 5, 31),
+			frequency: budgetperiod.FrequencyYearly,
+			at:        date(2025, 5, 31),
+			want:      budgetperiod.Period{Start: date(2024, 6, 1), End: date(2025, 6, 1)},
+			ok:        true,
+		},
+		{
+			name:      "last period is cut at end date",
+			start:     date(2025, 1, 1),
+			end:       date(2025, 1, 20),
+			frequency: budgetperiod.FrequencyWeekly,
+			at:        date(2025, 1, 20),
+			want:      budgetperiod.Period{Start: date(2025, 1, 15), End: date(2025, 1, 21)},
+			ok:        true,
+		},
+		{
+			name:      "outside of the budget range",
+			start:     date(2025, 1, 1),
+			end:       date(2025, 1, 31),
+			frequency: budgetperiod.FrequencyMonthly,
+			at:        date(2025, 2, 1),
+			ok:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := budgetperiod.At(tt.start, tt.end, tt.frequency, tt.at)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
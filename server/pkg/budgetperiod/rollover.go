@@ -0,0 +1,76 @@
+package budgetperiod
+
+import (
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/shopspring/decimal"
+)
+
+// Rollover closes a budget period, Amount is carried into the next one
+type Rollover struct {
+	Period    Period
+	Budgeted  decimal.Decimal
+	CarriedIn decimal.Decimal
+	Spent     decimal.Decimal
+	Amount    decimal.Decimal // Left over, negative when overspent
+}
+
+// SpendingFunc returns what was spent over a period of a budget
+type SpendingFunc func(Period) (decimal.Decimal, error)
+
+// CloseRollovers returns the rollovers of the budget periods that ended on or before processDate
+// since last, the rollover closed most recently (nil when there is none). Each period carries the
+// rollover of the previous one, so the leftover or overspent amount keeps accumulating.
+// Periods resume at the first one starting on or after the end of last: when the start date or
+// the frequency changed since, the period straddling that end is skipped rather than counted twice.
+func CloseRollovers(b repository.Budget, last *Rollover, processDate time.Time, spent SpendingFunc) ([]Rollover, error) {
+	budgeted := types.PgtypeNumericToDecimal(b.Amount)
+	carriedIn := decimal.Zero
+	cursor := truncateDay(b.StartDate.Time)
+
+	if last != nil {
+		carriedIn = last.Amount
+		if end := truncateDay(last.Period.End); end.After(cursor) {
+			cursor = end
+		}
+	}
+
+	rollovers := []Rollover{}
+
+	for {
+		period, ok := At(b.StartDate.Time, b.EndDate.Time, b.Frequency, cursor)
+		if !ok {
+			break
+		}
+
+		if period.Start.Before(cursor) {
+			cursor = period.End
+			continue
+		}
+
+		if period.End.After(processDate) {
+			break
+		}
+
+		amount, err := spent(period)
+		if err != nil {
+			return nil, err
+		}
+
+		rollover := Rollover{
+			Period:    period,
+			Budgeted:  budgeted,
+			CarriedIn: carriedIn,
+			Spent:     amount,
+			Amount:    budgeted.Add(carriedIn).Sub(amount),
+		}
+
+		rollovers = append(rollovers, rollover)
+		carriedIn = rollover.Amount
+		cursor = period.End
+	}
+
+	return rollovers, nil
+}
@@ -0,0 +1,140 @@
+package budgetperiod_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/budgetperiod"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func budget(start time.Time, frequency string, amount int64) repository.Budget {
+	return repository.Budget{
+		Amount:    types.DecimalToPgtypeNumeric(decimal.NewFromInt(amount)),
+		StartDate: pgtype.Date{Valid: true, Time: start},
+		EndDate:   pgtype.Date{Valid: true, Time: date(2025, 12, 31)},
+		Frequency: frequency,
+	}
+}
+
+// spending returns the amount spent in the periods starting on the given dates, nothing otherwise
+func spending(t *testing.T, spent map[time.Time]int64) (budgetperiod.SpendingFunc, *[]budgetperiod.Period) {
+	t.Helper()
+
+	var asked []budgetperiod.Period
+	return func(period budgetperiod.Period) (decimal.Decimal, error) {
+		asked = append(asked, period)
+		return decimal.NewFromInt(spent[period.Start]), nil
+	}, &asked
+}
+
+func TestCloseRollovers(t *testing.T) {
+	b := budget(date(2025, 1, 1), budgetperiod.FrequencyMonthly, 100)
+
+	spent, _ := spending(t, map[time.Time]int64{
+		date(2025, 1, 1): 60,
+		date(2025, 2, 1): 150,
+		date(2025, 3, 1): 20,
+	})
+
+	// Three periods were missed, April hasn't ended yet
+	rollovers, err := budgetperiod.CloseRollovers(b, nil, date(2025, 4, 15), spent)
+	require.NoError(t, err)
+	require.Len(t, rollovers, 3)
+
+	assert.Equal(t, budgetperiod.Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)}, rollovers[0].Period)
+	assert.True(t, rollovers[0].CarriedIn.IsZero())
+	assert.Equal(t, "40", rollovers[0].Amount.String())
+
+	// Overspending uses up the carry in and goes negative
+	assert.Equal(t, "40", rollovers[1].CarriedIn.String())
+	assert.Equal(t, "-10", rollovers[1].Amount.String())
+
+	assert.Equal(t, "-10", rollovers[2].CarriedIn.String())
+	assert.Equal(t, "70", rollovers[2].Amount.String())
+	assert.Equal(t, date(2025, 4, 1), rollovers[2].Period.End)
+}
+
+func TestCloseRollovers_ResumesAfterLast(t *testing.T) {
+	b := budget(date(2025, 1, 1), budgetperiod.FrequencyMonthly, 100)
+	last := &budgetperiod.Rollover{
+		Period: budgetperiod.Period{Start: date(2025, 2, 1), End: date(2025, 3, 1)},
+		Amount: decimal.NewFromInt(25),
+	}
+
+	spent, _ := spending(t, map[time.Time]int64{date(2025, 3, 1): 50})
+
+	rollovers, err := budgetperiod.CloseRollovers(b, last, date(2025, 4, 1), spent)
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+
+	assert.Equal(t, date(2025, 3, 1), rollovers[0].Period.Start)
+	assert.Equal(t, "25", rollovers[0].CarriedIn.String())
+	assert.Equal(t, "75", rollovers[0].Amount.String())
+
+	// Nothing ended since
+	rollovers, err = budgetperiod.CloseRollovers(b, &rollovers[0], date(2025, 4, 20), spent)
+	require.NoError(t, err)
+	assert.Empty(t, rollovers)
+}
+
+func TestCloseRollovers_AnchorChanged(t *testing.T) {
+	// Closed up to March 1st, then the budget moved to start on the 15th
+	b := budget(date(2025, 1, 15), budgetperiod.FrequencyMonthly, 100)
+	last := &budgetperiod.Rollover{
+		Period: budgetperiod.Period{Start: date(2025, 2, 1), End: date(2025, 3, 1)},
+		Amount: decimal.NewFromInt(10),
+	}
+
+	spent, asked := spending(t, nil)
+
+	rollovers, err := budgetperiod.CloseRollovers(b, last, date(2025, 4, 20), spent)
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+
+	// February 15th - March 15th overlaps the closed period and is skipped
+	assert.Equal(t, budgetperiod.Period{Start: date(2025, 3, 15), End: date(2025, 4, 15)}, rollovers[0].Period)
+	assert.Equal(t, "10", rollovers[0].CarriedIn.String())
+	assert.Len(t, *asked, 1)
+}
+
+func TestCloseRollovers_FrequencyChanged(t *testing.T) {
+	// Closed a monthly period, then switched to weekly periods from the same start
+	b := budget(date(2025, 1, 1), budgetperiod.FrequencyWeekly, 25)
+	last := &budgetperiod.Rollover{
+		Period: budgetperiod.Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)},
+		Amount: decimal.NewFromInt(5),
+	}
+
+	spent, _ := spending(t, nil)
+
+	rollovers, err := budgetperiod.CloseRollovers(b, last, date(2025, 2, 13), spent)
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+
+	// The week of January 29th straddles the closed month
+	assert.Equal(t, budgetperiod.Period{Start: date(2025, 2, 5), End: date(2025, 2, 12)}, rollovers[0].Period)
+	assert.Equal(t, "30", rollovers[0].Amount.String())
+}
+
+func TestCloseRollovers_StartMovedLater(t *testing.T) {
+	b := budget(date(2025, 6, 1), budgetperiod.FrequencyMonthly, 100)
+	last := &budgetperiod.Rollover{
+		Period: budgetperiod.Period{Start: date(2025, 1, 1), End: date(2025, 2, 1)},
+		Amount: decimal.NewFromInt(-20),
+	}
+
+	spent, _ := spending(t, nil)
+
+	rollovers, err := budgetperiod.CloseRollovers(b, last, date(2025, 7, 1), spent)
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+
+	assert.Equal(t, budgetperiod.Period{Start: date(2025, 6, 1), End: date(2025, 7, 1)}, rollovers[0].Period)
+	assert.Equal(t, "80", rollovers[0].Amount.String())
+}
@@ -0,0 +1,150 @@
+// Package events is the internal event bus. Domains publish what happened after their changes
+// are committed, and subscribers such as webhook delivery react to it.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Version of the envelope and payload schemas. Fields may be added within a version, anything
+// else requires a new one.
+const Version = "2025-08-01"
+
+// All subscribes to every event type
+const All = "*"
+
+const (
+	TransactionCreated = "transaction.created"
+	TransactionUpdated = "transaction.updated"
+	TransactionDeleted = "transaction.deleted"
+
+	AccountCreated        = "account.created"
+	AccountUpdated        = "account.updated"
+	AccountDeleted        = "account.deleted"
+	AccountBalanceChanged = "account.balance_changed"
+
+	BudgetExceeded = "budget.exceeded"
+
+	RuleCreated = "rule.created"
+	RuleUpdated = "rule.updated"
+	RuleDeleted = "rule.deleted"
+	RuleApplied = "rule.applied"
+
+	ConnectionSynced         = "connection.synced"
+	ConnectionReauthRequired = "connection.reauth_required"
+	ConnectionDisconnected   = "connection.disconnected"
+)
+
+// Types lists the event types that can be subscribed to
+var Types = []string{
+	TransactionCreated,
+	TransactionUpdated,
+	TransactionDeleted,
+	AccountCreated,
+	AccountUpdated,
+	AccountDeleted,
+	AccountBalanceChanged,
+	BudgetExceeded,
+	RuleCreated,
+	RuleUpdated,
+	RuleDeleted,
+	RuleApplied,
+	ConnectionSynced,
+	ConnectionReauthRequired,
+	ConnectionDisconnected,
+}
+
+// Envelope wraps every published event
+type Envelope struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Version   string          `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+
+	// The user the event belongs to, used for routing and never serialized
+	UserID uuid.UUID `json:"-"`
+}
+
+// NewEnvelope wraps data into a new envelope of the given type
+func NewEnvelope(userID uuid.UUID, eventType string, data any) (Envelope, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:        uuid.New(),
+		Type:      eventType,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+		UserID:    userID,
+	}, nil
+}
+
+type Handler func(ctx context.Context, envelope Envelope) error
+
+// Bus dispatches events synchronously to their subscribers. A nil *Bus drops every event, which
+// keeps services usable without one.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	logger   *zerolog.Logger
+}
+
+func NewBus(logger *zerolog.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		logger:   logger,
+	}
+}
+
+// Subscribe registers a handler for an event type, or for every type with All
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish hands an event to its subscribers. Events are published once the change they describe
+// is committed, so subscriber failures are logged rather than returned.
+func (b *Bus) Publish(ctx context.Context, userID uuid.UUID, eventType string, data any) {
+	if b == nil {
+		return
+	}
+
+	envelope, err := NewEnvelope(userID, eventType, data)
+	if err != nil {
+		b.logger.Error().Err(err).Str("event_type", eventType).Msg("Failed to encode event")
+		return
+	}
+
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[eventType]...), b.handlers[All]...)
+	b.mu.RUnlock()
+
+	// Subscribers shouldn't be cut short by the request that triggered the event
+	ctx = context.WithoutCancel(ctx)
+
+	for _, handler := range handlers {
+		if err := handler(ctx, envelope); err != nil {
+			b.logger.Error().
+				Err(err).
+				Str("event_id", envelope.ID.String()).
+				Str("event_type", eventType).
+				Msg("Event subscriber failed")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestBusPublish(t *testing.T) {
+	logger := zerolog.Nop()
+	bus := NewBus(&logger)
+	userID := uuid.New()
+
+	var received []string
+
+	bus.Subscribe(TransactionCreated, func(ctx context.Context, envelope Envelope) error {
+		received = append(received, "typed:"+envelope.Type)
+		return errors.New("subscriber failure")
+	})
+	bus.Subscribe(All, func(ctx context.Context, envelope Envelope) error {
+		if envelope.UserID != userID {
+			t.Errorf("UserID = %s, want %s", envelope.UserID, userID)
+		}
+		if envelope.Version != Version {
+			t.Errorf("Version = %q, want %q", envelope.Version, Version)
+		}
+		received = append(received, "all:"+envelope.Type)
+		return nil
+	})
+
+	bus.Publish(context.Background(), userID, TransactionCreated, map[string]string{"id": "1"})
+	bus.Publish(context.Background(), userID, AccountUpdated, map[string]string{"id": "2"})
+
+	want := []string{"typed:transaction.created", "all:transaction.created", "all:account.updated"}
+	if len(received) != len(want) {
+		t.Fatalf("received %v, want %v", received, want)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("received[%d] = %q, want %q", i, received[i], want[i])
+		}
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	var bus *Bus
+
+	bus.Subscribe(All, func(ctx context.Context, envelope Envelope) error { return nil })
+	bus.Publish(context.Background(), uuid.New(), TransactionCreated, nil)
+}
+
+func TestEnvelopeJSON(t *testing.T) {
+	envelope, err := NewEnvelope(uuid.New(), RuleApplied, RuleAppliedData{TransactionID: uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"id", "type", "version", "created_at", "data"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("envelope is missing %q", key)
+		}
+	}
+	if _, ok := fields["UserID"]; ok {
+		t.Error("envelope leaks the user id")
+	}
+}
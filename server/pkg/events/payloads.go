@@ -0,0 +1,165 @@
+package events
+
+import (
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// The payloads below are part of the public webhook contract, they are decoupled from the
+// database models on purpose
+
+type Transaction struct {
+	ID                   uuid.UUID       `json:"id"`
+	AccountID            uuid.UUID       `json:"account_id"`
+	DestinationAccountID *uuid.UUID      `json:"destination_account_id"`
+	CategoryID           *uuid.UUID      `json:"category_id"`
+	Type                 string          `json:"type"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Description          *string         `json:"description"`
+	TransactionDatetime  time.Time       `json:"transaction_datetime"`
+	IsExternal           bool            `json:"is_external"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+}
+
+type TransactionData struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+func NewTransactionData(t repository.Transaction) TransactionData {
+	return TransactionData{
+		Transaction: Transaction{
+			ID:                   t.ID,
+			AccountID:            t.AccountID,
+			DestinationAccountID: t.DestinationAccountID,
+			CategoryID:           t.CategoryID,
+			Type:                 t.Type,
+			Amount:               types.PgtypeNumericToDecimal(t.Amount),
+			Currency:             t.TransactionCurrency,
+			Description:          t.Description,
+			TransactionDatetime:  t.TransactionDatetime,
+			IsExternal:           t.IsExternal != nil && *t.IsExternal,
+			CreatedAt:            t.CreatedAt,
+			UpdatedAt:            t.UpdatedAt,
+		},
+	}
+}
+
+type Account struct {
+	ID           uuid.UUID       `json:"id"`
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	Balance      decimal.Decimal `json:"balance"`
+	Currency     string          `json:"currency"`
+	IsExternal   bool            `json:"is_external"`
+	ConnectionID *uuid.UUID      `json:"connection_id"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type AccountData struct {
+	Account Account `json:"account"`
+}
+
+func NewAccountData(a repository.Account) AccountData {
+	return AccountData{
+		Account: Account{
+			ID:           a.ID,
+			Name:         a.Name,
+			Type:         string(a.Type),
+			Balance:      types.PgtypeNumericToDecimal(a.Balance),
+			Currency:     a.Currency,
+			IsExternal:   a.IsExternal != nil && *a.IsExternal,
+			ConnectionID: a.ConnectionID,
+			UpdatedAt:    a.UpdatedAt,
+		},
+	}
+}
+
+// NewAccountBalanceChangedData describes a balance that moved by delta
+func NewAccountBalanceChangedData(a repository.GetAccountByIdRow, delta decimal.Decimal) AccountBalanceChangedData {
+	balance := types.PgtypeNumericToDecimal(a.Balance)
+
+	return AccountBalanceChangedData{
+		Account: Account{
+			ID:           a.ID,
+			Name:         a.Name,
+			Type:         string(a.Type),
+			Balance:      balance,
+			Currency:     a.Currency,
+			IsExternal:   a.IsExternal != nil && *a.IsExternal,
+			ConnectionID: a.ConnectionID,
+			UpdatedAt:    a.UpdatedAt,
+		},
+		PreviousBalance: balance.Sub(delta),
+	}
+}
+
+type AccountBalanceChangedData struct {
+	Account         Account         `json:"account"`
+	PreviousBalance decimal.Decimal `json:"previous_balance"`
+}
+
+type BudgetExceededData struct {
+	BudgetID    uuid.UUID       `json:"budget_id"`
+	Name        *string         `json:"name"`
+	CategoryID  uuid.UUID       `json:"category_id"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"` // Exclusive
+	Budgeted    decimal.Decimal `json:"budgeted"`
+	Spent       decimal.Decimal `json:"spent"`
+	Currency    string          `json:"currency"`
+}
+
+type Rule struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	IsActive bool      `json:"is_active"`
+	Priority int       `json:"priority"`
+}
+
+type RuleData struct {
+	Rule Rule `json:"rule"`
+}
+
+type RuleAppliedData struct {
+	Rule          Rule      `json:"rule"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
+type Connection struct {
+	ID              uuid.UUID  `json:"id"`
+	Provider        string     `json:"provider"`
+	InstitutionName *string    `json:"institution_name"`
+	Status          string     `json:"status"`
+	LastSyncAt      *time.Time `json:"last_sync_at"`
+}
+
+type ConnectionData struct {
+	Connection Connection `json:"connection"`
+}
+
+type ConnectionSyncedData struct {
+	Connection          Connection `json:"connection"`
+	AccountsSynced      int        `json:"accounts_synced"`
+	TransactionsCreated int        `json:"transactions_created"`
+}
+
+func NewConnection(c repository.UserFinancialConnection) Connection {
+	connection := Connection{
+		ID:              c.ID,
+		Provider:        c.ProviderName,
+		InstitutionName: c.InstitutionName,
+		LastSyncAt:      c.LastSyncAt,
+	}
+
+	if c.Status != nil {
+		connection.Status = *c.Status
+	}
+
+	return connection
+}
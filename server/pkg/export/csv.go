@@ -0,0 +1,56 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+var csvHeader = []string{"id", "date", "type", "amount", "currency", "description", "category", "account", "destination_account", "note"}
+
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(row Row) error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		row.ID.String(),
+		row.Date.UTC().Format(time.RFC3339),
+		row.Type,
+		row.Amount.String(),
+		row.Currency,
+		row.Description,
+		row.Category,
+		row.Account,
+		row.DestinationAccount,
+		row.Note,
+	})
+}
+
+// header is written with the first row, or on Close so an empty export still has one
+func (c *csvWriter) header() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,78 @@
+// Package export writes transactions to the file formats users can download them in: CSV for
+// spreadsheets, JSON for scripts and OFX 2 for other finance software.
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatOFX  Format = "ofx"
+)
+
+var Formats = []Format{FormatCSV, FormatJSON, FormatOFX}
+
+var ErrUnknownFormat = errors.New("unsupported export format")
+
+// Row is an exported transaction
+type Row struct {
+	ID                 uuid.UUID       `json:"id"`
+	Date               time.Time       `json:"date"`
+	Type               string          `json:"type"`
+	Amount             decimal.Decimal `json:"amount"` // Negative when money left the account
+	Currency           string          `json:"currency"`
+	Description        string          `json:"description"`
+	Category           string          `json:"category"`
+	AccountID          uuid.UUID       `json:"account_id"`
+	Account            string          `json:"account"`
+	AccountType        string          `json:"account_type"`
+	DestinationAccount string          `json:"destination_account,omitempty"`
+	Note               string          `json:"note,omitempty"`
+}
+
+// Writer encodes rows as they are read, Close must be called to complete the file
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewWriter returns the writer of a format. OFX groups transactions in a statement per account,
+// its rows must come grouped by account.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatJSON:
+		return "application/json"
+	case FormatOFX:
+		return "application/x-ofx"
+	}
+
+	return "application/octet-stream"
+}
+
+func (f Format) Extension() string {
+	return "." + string(f)
+}
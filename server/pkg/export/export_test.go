@@ -0,0 +1,133 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/statements"
+	"github.com/Fantasy-Programming/nuts/server/pkg/export"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	checking = uuid.MustParse("7d2b5f0e-5b2a-4c36-8f45-0a4f1d8f6a01")
+	savings  = uuid.MustParse("7d2b5f0e-5b2a-4c36-8f45-0a4f1d8f6a02")
+)
+
+func rows() []export.Row {
+	return []export.Row{
+		{
+			ID:          uuid.New(),
+			Date:        time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC),
+			Type:        "expense",
+			Amount:      decimal.RequireFromString("-4.5"),
+			Currency:    "USD",
+			Description: "Coffee, \"to go\"",
+			Category:    "Food",
+			AccountID:   checking,
+			Account:     "Checking",
+			AccountType: "checking",
+		},
+		{
+			ID:          uuid.New(),
+			Date:        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Type:        "income",
+			Amount:      decimal.RequireFromString("2500"),
+			Currency:    "USD",
+			Description: "Salary from a company with a very long name & co",
+			Category:    "Salary",
+			AccountID:   checking,
+			Account:     "Checking",
+			AccountType: "checking",
+		},
+		{
+			ID:          uuid.New(),
+			Date:        time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+			Type:        "income",
+			Amount:      decimal.RequireFromString("1.25"),
+			Currency:    "EUR",
+			Description: "Interest",
+			Category:    "Income",
+			AccountID:   savings,
+			Account:     "Savings",
+			AccountType: "savings",
+		},
+	}
+}
+
+func write(t *testing.T, format export.Format, rows []export.Row) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	data := write(t, export.FormatCSV, rows())
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, "amount", records[0][3])
+	assert.Equal(t, "2025-03-14T09:30:00Z", records[1][1])
+	assert.Equal(t, "-4.5", records[1][3])
+	assert.Equal(t, "Coffee, \"to go\"", records[1][5])
+}
+
+func TestJSON(t *testing.T) {
+	var decoded []export.Row
+	require.NoError(t, json.Unmarshal(write(t, export.FormatJSON, rows()), &decoded))
+
+	require.Len(t, decoded, 3)
+	assert.True(t, decoded[0].Amount.Equal(decimal.RequireFromString("-4.5")))
+	assert.Equal(t, savings, decoded[2].AccountID)
+}
+
+func TestEmptyExports(t *testing.T) {
+	assert.Equal(t, "[]\n", string(write(t, export.FormatJSON, nil)))
+
+	records, err := csv.NewReader(bytes.NewReader(write(t, export.FormatCSV, nil))).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	assert.Contains(t, string(write(t, export.FormatOFX, nil)), "<BANKMSGSRSV1>\n</BANKMSGSRSV1>")
+}
+
+// An exported OFX file reads back through the statement importer
+func TestOFXRoundTrip(t *testing.T) {
+	data := write(t, export.FormatOFX, rows())
+
+	assert.Equal(t, 2, bytes.Count(data, []byte("<STMTRS>")))
+	assert.Contains(t, string(data), "<ACCTTYPE>SAVINGS</ACCTTYPE>")
+	assert.Contains(t, string(data), "<DTSTART>20250301000000[0:GMT]</DTSTART><DTEND>20250314093000[0:GMT]</DTEND>")
+
+	parsed, err := statements.Parse(statements.FormatOFX, data, nil)
+	require.NoError(t, err)
+	require.Len(t, parsed, 3)
+
+	assert.True(t, parsed[0].Amount.Equal(decimal.RequireFromString("-4.5")))
+	assert.Equal(t, "Coffee, \"to go\"", parsed[0].Description)
+	assert.True(t, rows()[0].Date.Equal(parsed[0].Date))
+	assert.Equal(t, "EUR", parsed[2].Currency)
+	assert.Contains(t, parsed[1].Description, "Salary from a company")
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := export.NewWriter("pdf", &bytes.Buffer{})
+	assert.ErrorIs(t, err, export.ErrUnknownFormat)
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// jsonWriter writes a JSON array one element at a time instead of marshalling the whole export
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (j *jsonWriter) Write(row Row) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+
+	if _, err := j.w.WriteString(sep); err != nil {
+		return err
+	}
+
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+
+	if _, err := j.w.WriteString(end); err != nil {
+		return err
+	}
+
+	return j.w.Flush()
+}
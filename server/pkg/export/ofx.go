@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+const ofxDateLayout = "20060102150405"
+
+// ofxNameLimit is the length of the NAME element in the OFX specification, longer descriptions
+// go to the MEMO
+const ofxNameLimit = 32
+
+// ofxWriter writes an OFX 2.2 file with a bank statement per account. The statement range comes
+// before its transactions, so the transactions of the current account are kept until the next
+// account starts.
+type ofxWriter struct {
+	w       *bufio.Writer
+	now     time.Time
+	started bool
+
+	account    *Row
+	start, end time.Time
+	body       bytes.Buffer
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{w: bufio.NewWriter(w), now: time.Now()}
+}
+
+func (o *ofxWriter) Write(row Row) error {
+	if o.account == nil || o.account.AccountID != row.AccountID {
+		if err := o.flush(); err != nil {
+			return err
+		}
+
+		account := row
+		o.account = &account
+		o.start, o.end = row.Date, row.Date
+	}
+
+	if row.Date.Before(o.start) {
+		o.start = row.Date
+	}
+	if row.Date.After(o.end) {
+		o.end = row.Date
+	}
+
+	trnType := "CREDIT"
+	switch {
+	case row.DestinationAccount != "":
+		trnType = "XFER"
+	case row.Amount.IsNegative():
+		trnType = "DEBIT"
+	}
+
+	name, memo := row.Description, row.Note
+	if len([]rune(name)) > ofxNameLimit {
+		name, memo = string([]rune(name)[:ofxNameLimit]), joinMemo(row.Description, memo)
+	}
+
+	o.body.WriteString("<STMTTRN>")
+	ofxElement(&o.body, "TRNTYPE", trnType)
+	ofxElement(&o.body, "DTPOSTED", ofxDate(row.Date))
+	ofxElement(&o.body, "TRNAMT", row.Amount.StringFixed(2))
+	ofxElement(&o.body, "FITID", row.ID.String())
+	if name != "" {
+		ofxElement(&o.body, "NAME", name)
+	}
+	if memo != "" {
+		ofxElement(&o.body, "MEMO", memo)
+	}
+	o.body.WriteString("</STMTTRN>\n")
+
+	return nil
+}
+
+func (o *ofxWriter) begin() error {
+	if o.started {
+		return nil
+	}
+	o.started = true
+
+	var b bytes.Buffer
+	b.WriteString(ofxHeader)
+	b.WriteString("<OFX>\n<SIGNONMSGSRSV1><SONRS>")
+	ofxStatus(&b)
+	ofxElement(&b, "DTSERVER", ofxDate(o.now))
+	ofxElement(&b, "LANGUAGE", "ENG")
+	b.WriteString("</SONRS></SIGNONMSGSRSV1>\n<BANKMSGSRSV1>\n")
+
+	_, err := o.w.Write(b.Bytes())
+	return err
+}
+
+// flush writes the statement of the current account
+func (o *ofxWriter) flush() error {
+	if err := o.begin(); err != nil {
+		return err
+	}
+
+	if o.account == nil {
+		return nil
+	}
+
+	var b bytes.Buffer
+	b.WriteString("<STMTTRNRS>")
+	ofxElement(&b, "TRNUID", uuid.NewString())
+	ofxStatus(&b)
+	b.WriteString("<STMTRS>")
+	ofxElement(&b, "CURDEF", o.account.Currency)
+	b.WriteString("<BANKACCTFROM>")
+	ofxElement(&b, "BANKID", "NUTS")
+	ofxElement(&b, "ACCTID", o.account.AccountID.String())
+	ofxElement(&b, "ACCTTYPE", ofxAccountType(o.account.AccountType))
+	b.WriteString("</BANKACCTFROM>\n<BANKTRANLIST>")
+	ofxElement(&b, "DTSTART", ofxDate(o.start))
+	ofxElement(&b, "DTEND", ofxDate(o.end))
+	b.WriteString("\n")
+
+	if _, err := o.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	if _, err := o.w.Write(o.body.Bytes()); err != nil {
+		return err
+	}
+	if _, err := o.w.WriteString("</BANKTRANLIST>\n</STMTRS></STMTTRNRS>\n"); err != nil {
+		return err
+	}
+
+	o.account = nil
+	o.body.Reset()
+
+	return nil
+}
+
+func (o *ofxWriter) Close() error {
+	if err := o.flush(); err != nil {
+		return err
+	}
+
+	if _, err := o.w.WriteString("</BANKMSGSRSV1>\n</OFX>\n"); err != nil {
+		return err
+	}
+
+	return o.w.Flush()
+}
+
+func ofxElement(b *bytes.Buffer, name, value string) {
+	b.WriteString("<" + name + ">")
+	_ = xml.EscapeText(b, []byte(value))
+	b.WriteString("</" + name + ">")
+}
+
+func ofxStatus(b *bytes.Buffer) {
+	b.WriteString("<STATUS>")
+	ofxElement(b, "CODE", "0")
+	ofxElement(b, "SEVERITY", "INFO")
+	b.WriteString("</STATUS>")
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout) + "[0:GMT]"
+}
+
+// ofxAccountType maps account types to the few bank account types OFX knows
+func ofxAccountType(accountType string) string {
+	switch accountType {
+	case "savings":
+		return "SAVINGS"
+	case "credit", "loan":
+		return "CREDITLINE"
+	}
+
+	return "CHECKING"
+}
+
+func joinMemo(description, note string) string {
+	if note == "" {
+		return description
+	}
+
+	return description + " - " + note
+}
@@ -100,11 +100,14 @@ type LinkTokenRequest struct {
 	Products     []string `json:"products,omitempty"`
 	CountryCodes []string `json:"country_codes,omitempty"`
 	RedirectURI  string   `json:"redirect_uri,omitempty"`
+	// Providers without a hosted institution picker (GoCardless) need it upfront
+	InstitutionID string `json:"institution_id,omitempty"`
 }
 
 // LinkTokenResponse represents the response from creating a link token
 type LinkTokenResponse struct {
 	LinkToken string    `json:"link_token"`
+	LinkURL   string    `json:"link_url,omitempty"` // Consent page for redirect based providers
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
@@ -120,11 +123,27 @@ type ExchangeTokenResponse struct {
 	ItemID      string `json:"item_id,omitempty"`
 }
 
+// TransactionsSync holds the changes reported by a cursor based sync, for every account of the connection
+type TransactionsSync struct {
+	Added      []Transaction        `json:"added"`
+	Modified   []Transaction        `json:"modified"`
+	Removed    []RemovedTransaction `json:"removed"`
+	NextCursor string               `json:"next_cursor"`
+}
+
+// RemovedTransaction is a transaction the provider deleted since the cursor
+type RemovedTransaction struct {
+	ProviderTransactionID string `json:"provider_transaction_id"`
+	AccountID             string `json:"account_id"`
+}
+
 type GetTransactionsArgs struct {
-	Count     *int
-	FromID    *string
-	startDate *time.Time
-	endDate   *time.Time
+	Count  *int
+	FromID *string
+
+	// Optional window on the transaction date, both bounds are inclusive
+	StartDate *time.Time
+	EndDate   *time.Time
 }
 
 // Provider defines the interface for financial data providers
@@ -158,6 +177,12 @@ type Provider interface {
 	GetSupportedAccountTypes() []AccountType
 }
 
+// TransactionSyncer is implemented by providers that report the changes of a whole connection since
+// a cursor, including the transactions they removed. The bank sync prefers it over date windows.
+type TransactionSyncer interface {
+	SyncTransactions(ctx context.Context, accessToken, cursor string) (*TransactionsSync, error)
+}
+
 // ProviderManager manages multiple financial providers
 type ProviderManager struct {
 	providers map[string]Provider
@@ -239,6 +264,8 @@ func createProvider(name string, cfg config.Integrations, logger *zerolog.Logger
 			Environment: cfg.PlaidEnvironment,
 			ClientID:    cfg.PlaidClientId,
 			Secret:      cfg.PlaidSecret,
+			BaseURL:     cfg.PlaidBaseUri,
+			WebhookURL:  cfg.PlaidWebhookUri,
 		}, logger)
 	case "teller":
 		return NewTellerProvider(TellerConfig{
@@ -248,7 +275,11 @@ func createProvider(name string, cfg config.Integrations, logger *zerolog.Logger
 			CertPrivateKeyPath: cfg.TellerCertPrivateKeyPath,
 		}, logger)
 	case "gocardless":
-		// return NewGoCardlessProvider(cfg.GoCardless, logger)
+		return NewGoCardlessProvider(GoCardlessConfig{
+			SecretID:  cfg.GoCardlessSecretId,
+			SecretKey: cfg.GoCardlessSecretKey,
+			BaseURL:   cfg.GoCardlessBaseUri,
+		}, logger)
 	case "mono":
 		return NewMonoProvider(cfg.MonoSecretKey, logger)
 	case "brankas":
@@ -0,0 +1,282 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGoCardless is a minimal in-memory stand-in for the Bank Account Data API
+type fakeGoCardless struct {
+	t            *testing.T
+	tokens       int
+	expireTokens bool
+	requisition  string // status of the requisition
+	deleted      bool
+}
+
+func (f *fakeGoCardless) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	write := func(w http.ResponseWriter, status int, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+
+	auth := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer access-1" && r.Header.Get("Authorization") != "Bearer access-2" {
+				write(w, http.StatusUnauthorized, `{"summary":"Invalid token","detail":"Token is invalid or expired","status_code":401}`)
+				return
+			}
+			if f.expireTokens && r.Header.Get("Authorization") == "Bearer access-1" {
+				write(w, http.StatusUnauthorized, `{"summary":"Invalid token","detail":"Token is invalid or expired","status_code":401}`)
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	mux.HandleFunc("POST /api/v2/token/new/", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		if body["secret_id"] != "id" || body["secret_key"] != "key" {
+			write(w, http.StatusUnauthorized, `{"summary":"Authentication failed","detail":"No active account found with the given credentials","status_code":401}`)
+			return
+		}
+
+		f.tokens++
+		token := "access-1"
+		if f.tokens > 1 {
+			token = "access-2"
+		}
+		write(w, http.StatusOK, `{"access":"`+token+`","access_expires":86400,"refresh":"refresh-1","refresh_expires":2592000}`)
+	})
+
+	mux.HandleFunc("GET /api/v2/institutions/{id}/", auth(func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, `{"id":"SANDBOXFINANCE_SFIN0000","name":"Sandbox Finance","bic":"SFIN0000","transaction_total_days":"730","max_access_valid_for_days":"180","countries":["XX"],"logo":"https://cdn.example/sandbox.png"}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/institutions/", auth(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("country") == "fr" {
+			write(w, http.StatusOK, `[{"id":"BNP_BNPAFRPP","name":"BNP Paribas","bic":"BNPAFRPP","countries":["FR"]}]`)
+			return
+		}
+		write(w, http.StatusOK, `[{"id":"BNP_BNPAFRPP","name":"BNP Paribas","bic":"BNPAFRPP","countries":["FR"]},{"id":"REVOLUT_REVOGB21","name":"Revolut","bic":"REVOGB21","countries":["GB"]}]`)
+	}))
+
+	mux.HandleFunc("POST /api/v2/agreements/enduser/", auth(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(f.t, float64(730), body["max_historical_days"])
+		assert.Equal(f.t, float64(180), body["access_valid_for_days"])
+		write(w, http.StatusCreated, `{"id":"agr-1","created":"2024-01-01T00:00:00Z","institution_id":"SANDBOXFINANCE_SFIN0000","max_historical_days":730,"access_valid_for_days":180,"access_scope":["balances","details","transactions"],"accepted":null}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/agreements/enduser/{id}/", auth(func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, `{"id":"agr-1","created":"2024-01-01T00:00:00Z","institution_id":"SANDBOXFINANCE_SFIN0000","max_historical_days":730,"access_valid_for_days":180,"accepted":"2024-01-02T00:00:00Z"}`)
+	}))
+
+	mux.HandleFunc("POST /api/v2/requisitions/", auth(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(f.t, "agr-1", body["agreement"])
+		write(w, http.StatusCreated, `{"id":"req-1","status":"CR","agreement":"agr-1","institution_id":"SANDBOXFINANCE_SFIN0000","accounts":[],"link":"https://ob.example/psd2/start/req-1"}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/requisitions/{id}/", auth(func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "req-1" {
+			write(w, http.StatusNotFound, `{"summary":"Not found.","detail":"Not found.","status_code":404}`)
+			return
+		}
+		write(w, http.StatusOK, `{"id":"req-1","status":"`+f.requisition+`","agreement":"agr-1","institution_id":"SANDBOXFINANCE_SFIN0000","accounts":["acc-1"]}`)
+	}))
+
+	mux.HandleFunc("DELETE /api/v2/requisitions/{id}/", auth(func(w http.ResponseWriter, r *http.Request) {
+		f.deleted = true
+		write(w, http.StatusOK, `{"summary":"Requisition deleted","detail":"Requisition req-1 deleted with all its End User Agreements"}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/accounts/acc-1/", auth(func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, `{"id":"acc-1","iban":"GL3510230000010234","institution_id":"SANDBOXFINANCE_SFIN0000","status":"READY","owner_name":"Jane Doe"}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/accounts/acc-1/details/", auth(func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, `{"account":{"resourceId":"01F3NS4YV94RA29YCH8R0F6BMF","iban":"GL3510230000010234","currency":"EUR","ownerName":"Jane Doe","name":"Main Account","product":"Checking","cashAccountType":"CACC"}}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/accounts/acc-1/balances/", auth(func(w http.ResponseWriter, r *http.Request) {
+		write(w, http.StatusOK, `{"balances":[{"balanceAmount":{"amount":"1913.12","currency":"EUR"},"balanceType":"interimAvailable","referenceDate":"2024-03-01"},{"balanceAmount":{"amount":"1900.00","currency":"EUR"},"balanceType":"closingBooked","referenceDate":"2024-03-01"}]}`)
+	}))
+
+	mux.HandleFunc("GET /api/v2/accounts/acc-1/transactions/", auth(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(f.t, "2024-02-01", r.URL.Query().Get("date_from"))
+		write(w, http.StatusOK, `{"transactions":{
+			"booked":[
+				{"transactionId":"2024030101","bookingDate":"2024-03-01","valueDate":"2024-03-01","transactionAmount":{"amount":"-15.00","currency":"EUR"},"creditorName":"Cafe Central","remittanceInformationUnstructured":"Coffee","merchantCategoryCode":"5814"},
+				{"internalTransactionId":"int-2","bookingDate":"2024-02-25","transactionAmount":{"amount":"2500.00","currency":"EUR"},"debtorName":"ACME Corp","remittanceInformationUnstructuredArray":["Salary","February"]}
+			],
+			"pending":[
+				{"valueDate":"2024-03-02","transactionAmount":{"amount":"-4.50","currency":"EUR"},"remittanceInformationUnstructured":"Bakery"}
+			]
+		}}`)
+	}))
+
+	return mux
+}
+
+func newTestGoCardless(t *testing.T) (*GoCardlessProvider, *fakeGoCardless) {
+	t.Helper()
+
+	fake := &fakeGoCardless{t: t, requisition: "LN"}
+	server := httptest.NewServer(fake.handler())
+	t.Cleanup(server.Close)
+
+	logger := zerolog.Nop()
+	provider, err := NewGoCardlessProvider(GoCardlessConfig{
+		SecretID:  "id",
+		SecretKey: "key",
+		BaseURL:   server.URL,
+	}, &logger)
+	require.NoError(t, err)
+
+	return provider, fake
+}
+
+func TestGoCardlessProvider_Link(t *testing.T) {
+	provider, fake := newTestGoCardless(t)
+	ctx := context.Background()
+
+	_, err := provider.CreateLinkToken(ctx, LinkTokenRequest{UserID: "user-1", RedirectURI: "https://nuts.example/callback"})
+	assert.ErrorIs(t, err, ErrInsufficientData)
+
+	link, err := provider.CreateLinkToken(ctx, LinkTokenRequest{
+		UserID:        "user-1",
+		RedirectURI:   "https://nuts.example/callback",
+		InstitutionID: "SANDBOXFINANCE_SFIN0000",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", link.LinkToken)
+	assert.Equal(t, "https://ob.example/psd2/start/req-1", link.LinkURL)
+	assert.Equal(t, time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC), link.ExpiresAt.UTC())
+
+	// The API token is reused between calls
+	assert.Equal(t, 1, fake.tokens)
+
+	fake.requisition = "UA"
+	_, err = provider.ExchangePublicToken(ctx, ExchangeTokenRequest{PublicToken: "req-1"})
+	assert.ErrorIs(t, err, ErrAuthenticationFailed)
+
+	fake.requisition = "LN"
+	exchange, err := provider.ExchangePublicToken(ctx, ExchangeTokenRequest{PublicToken: "req-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", exchange.AccessToken)
+	assert.Equal(t, "agr-1", exchange.ItemID)
+}
+
+func TestGoCardlessProvider_Accounts(t *testing.T) {
+	provider, _ := newTestGoCardless(t)
+
+	accounts, err := provider.GetAccounts(context.Background(), "req-1")
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	account := accounts[0]
+	assert.Equal(t, "acc-1", account.ProviderAccountID)
+	assert.Equal(t, "Main Account", account.Name)
+	assert.Equal(t, AccountTypeCash, account.Type)
+	assert.Equal(t, AccountTypeChecking, *account.Subtype)
+	assert.Equal(t, 1900.0, account.Balance)
+	assert.Equal(t, 1913.12, *account.AvailableBalance)
+	assert.Equal(t, "EUR", account.Currency)
+	assert.Equal(t, "****0234", *account.AccountNumber)
+	assert.Equal(t, "Sandbox Finance", account.InstitutionName)
+	assert.True(t, account.IsActive)
+
+	// Agreement accepted on 2024-01-02 for 180 days
+	require.NotNil(t, account.ExpiresAt)
+	assert.Equal(t, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), account.ExpiresAt.UTC())
+
+	_, err = provider.GetAccount(context.Background(), "req-1", "acc-2")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestGoCardlessProvider_Transactions(t *testing.T) {
+	provider, _ := newTestGoCardless(t)
+
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	transactions, err := provider.GetTransactions(context.Background(), "req-1", "acc-1", GetTransactionsArgs{StartDate: &start})
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+
+	pending := transactions[0]
+	assert.Equal(t, "pending", pending.Status)
+	assert.Equal(t, -4.5, pending.Amount)
+	assert.NotEmpty(t, pending.ProviderTransactionID)
+
+	coffee := transactions[1]
+	assert.Equal(t, "2024030101", coffee.ProviderTransactionID)
+	assert.Equal(t, "posted", coffee.Status)
+	assert.Equal(t, "expense", coffee.Type)
+	assert.Equal(t, "Cafe Central", *coffee.MerchantName)
+	assert.Equal(t, "5814", coffee.Metadata["merchant_category_code"])
+
+	salary := transactions[2]
+	assert.Equal(t, "int-2", salary.ProviderTransactionID)
+	assert.Equal(t, "income", salary.Type)
+	assert.Equal(t, "Salary February", salary.Description)
+	assert.Equal(t, "ACME Corp", *salary.MerchantName)
+
+	// Identifiers derived from the content are stable between syncs
+	again, err := provider.GetTransactions(context.Background(), "req-1", "acc-1", GetTransactionsArgs{StartDate: &start})
+	require.NoError(t, err)
+	assert.Equal(t, pending.ProviderTransactionID, again[0].ProviderTransactionID)
+}
+
+func TestGoCardlessProvider_Institutions(t *testing.T) {
+	provider, _ := newTestGoCardless(t)
+	ctx := context.Background()
+
+	institutions, err := provider.GetInstitutionsByCountry(ctx, "FR")
+	require.NoError(t, err)
+	require.Len(t, institutions, 1)
+	assert.Equal(t, "BNP Paribas", institutions[0].Name)
+	assert.Equal(t, "gocardless", institutions[0].Provider)
+
+	institutions, err = provider.SearchInstitutions(ctx, "revo")
+	require.NoError(t, err)
+	require.Len(t, institutions, 1)
+	assert.Equal(t, "REVOLUT_REVOGB21", institutions[0].ID)
+}
+
+func TestGoCardlessProvider_ConnectionLifecycle(t *testing.T) {
+	provider, fake := newTestGoCardless(t)
+	ctx := context.Background()
+
+	// The requisition is linked but its agreement (180 days from 2024-01-02) is long expired
+	ok, err := provider.GetConnectionStatus(ctx, "req-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.ErrorIs(t, provider.RefreshConnection(ctx, "req-1"), ErrAuthenticationFailed)
+
+	ok, err = provider.GetConnectionStatus(ctx, "unknown")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// An expired API token is replaced transparently
+	fake.expireTokens = true
+	_, err = provider.GetAccounts(ctx, "req-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.tokens)
+
+	require.NoError(t, provider.RemoveConnection(ctx, "req-1"))
+	assert.True(t, fake.deleted)
+}
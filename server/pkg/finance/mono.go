@@ -261,11 +261,11 @@ func (m *MonoProvider) GetTransactions(ctx context.Context, accessToken, account
 		params.Set("from_id", *args.FromID)
 	}
 
-	if args.startDate != nil {
-		params.Add("start", args.startDate.Format("2006-01-02"))
+	if args.StartDate != nil {
+		params.Add("start", args.StartDate.Format("2006-01-02"))
 	}
-	if args.endDate != nil {
-		params.Add("end", args.endDate.Format("2006-01-02"))
+	if args.EndDate != nil {
+		params.Add("end", args.EndDate.Format("2006-01-02"))
 	}
 
 	params.Add("paginate", "false")
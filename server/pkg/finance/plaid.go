@@ -1,24 +1,141 @@
 package finance
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+// Plaid authenticates every call with the client id & secret headers,
+// the item access token travels in the JSON body.
+
+const (
+	PlaidSandboxURL    = "https://sandbox.plaid.com"
+	PlaidProductionURL = "https://production.plaid.com"
+	plaidAPIVersion    = "2020-09-14"
+	plaidSyncPageSize  = 500
+
+	// How many times a sync starts over when the item changes while its pages are read
+	plaidSyncMaxRestarts = 3
+)
+
+// errPlaidSyncMutation means the item changed between two pages of a sync, the pagination has to
+// start over from its first cursor
+var errPlaidSyncMutation = errors.New("transactions changed during pagination")
+
 // Configuration structures for each provider
 type PlaidConfig struct {
 	ClientID    string `json:"client_id"`
 	Secret      string `json:"secret"`
-	Environment string `json:"environment"` // sandbox, development, production
-	BaseURL     string `json:"base_url"`
+	Environment string `json:"environment"` // sandbox, production
+	BaseURL     string `json:"base_url"`    // Defaults to the host of the environment
+	ClientName  string `json:"client_name"` // Name displayed in Plaid Link
+	WebhookURL  string `json:"webhook_url"`
 }
 
 type PlaidProvider struct {
-	config PlaidConfig
-	logger *zerolog.Logger
-	// Add plaid client here
+	config     PlaidConfig
+	httpClient *http.Client
+	baseURL    string
+	logger     *zerolog.Logger
+}
+
+// Plaid API response structures
+type plaidError struct {
+	ErrorType      string `json:"error_type"`
+	ErrorCode      string `json:"error_code"`
+	ErrorMessage   string `json:"error_message"`
+	DisplayMessage string `json:"display_message"`
+	RequestID      string `json:"request_id"`
+}
+
+type plaidBalances struct {
+	Available              *float64 `json:"available"`
+	Current                *float64 `json:"current"`
+	Limit                  *float64 `json:"limit"`
+	IsoCurrencyCode        *string  `json:"iso_currency_code"`
+	UnofficialCurrencyCode *string  `json:"unofficial_currency_code"`
+}
+
+type plaidAccount struct {
+	AccountID    string        `json:"account_id"`
+	Balances     plaidBalances `json:"balances"`
+	Mask         *string       `json:"mask"`
+	Name         string        `json:"name"`
+	OfficialName *string       `json:"official_name"`
+	Type         string        `json:"type"`
+	Subtype      *string       `json:"subtype"`
+}
+
+type plaidItem struct {
+	ItemID                string      `json:"item_id"`
+	InstitutionID         *string     `json:"institution_id"`
+	Error                 *plaidError `json:"error"`
+	ConsentExpirationTime *time.Time  `json:"consent_expiration_time"`
+}
+
+type plaidAccountsResponse struct {
+	Accounts []plaidAccount `json:"accounts"`
+	Item     plaidItem      `json:"item"`
+}
+
+type plaidPersonalFinanceCategory struct {
+	Primary  string `json:"primary"`
+	Detailed string `json:"detailed"`
+}
+
+type plaidTransaction struct {
+	TransactionID           string                        `json:"transaction_id"`
+	AccountID               string                        `json:"account_id"`
+	Amount                  float64                       `json:"amount"`
+	IsoCurrencyCode         *string                       `json:"iso_currency_code"`
+	UnofficialCurrencyCode  *string                       `json:"unofficial_currency_code"`
+	Date                    string                        `json:"date"`
+	Datetime                *time.Time                    `json:"datetime"`
+	Name                    string                        `json:"name"`
+	MerchantName            *string                       `json:"merchant_name"`
+	Pending                 bool                          `json:"pending"`
+	PendingTransactionID    *string                       `json:"pending_transaction_id"`
+	PaymentChannel          string                        `json:"payment_channel"`
+	Category                []string                      `json:"category"`
+	PersonalFinanceCategory *plaidPersonalFinanceCategory `json:"personal_finance_category"`
+}
+
+type plaidRemovedTransaction struct {
+	TransactionID string `json:"transaction_id"`
+	AccountID     string `json:"account_id"`
+}
+
+type plaidSyncResponse struct {
+	Added      []plaidTransaction        `json:"added"`
+	Modified   []plaidTransaction        `json:"modified"`
+	Removed    []plaidRemovedTransaction `json:"removed"`
+	NextCursor string                    `json:"next_cursor"`
+	HasMore    bool                      `json:"has_more"`
+}
+
+type plaidTransactionsGetResponse struct {
+	Accounts          []plaidAccount     `json:"accounts"`
+	Transactions      []plaidTransaction `json:"transactions"`
+	TotalTransactions int                `json:"total_transactions"`
+}
+
+type plaidInstitution struct {
+	InstitutionID string   `json:"institution_id"`
+	Name          string   `json:"name"`
+	Logo          *string  `json:"logo"`
+	PrimaryColor  *string  `json:"primary_color"`
+	URL           *string  `json:"url"`
+	CountryCodes  []string `json:"country_codes"`
 }
 
 func NewPlaidProvider(config PlaidConfig, logger *zerolog.Logger) (*PlaidProvider, error) {
@@ -26,9 +143,27 @@ func NewPlaidProvider(config PlaidConfig, logger *zerolog.Logger) (*PlaidProvide
 		return nil, errors.New("missing required Plaid configuration")
 	}
 
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		switch config.Environment {
+		case "production":
+			baseURL = PlaidProductionURL
+		case "sandbox", "":
+			baseURL = PlaidSandboxURL
+		default:
+			return nil, fmt.Errorf("unknown Plaid environment: %s", config.Environment)
+		}
+	}
+
+	if config.ClientName == "" {
+		config.ClientName = "Nuts"
+	}
+
 	return &PlaidProvider{
-		config: config,
-		logger: logger,
+		config:     config,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		logger:     logger,
 	}, nil
 }
 
@@ -41,71 +176,683 @@ func (p *PlaidProvider) GetSupportedCountries() []string {
 }
 
 func (p *PlaidProvider) GetSupportedAccountTypes() []AccountType {
-	return []AccountType{AccountTypeCredit, AccountTypeInvestment}
+	return []AccountType{AccountTypeCash, AccountTypeCredit, AccountTypeInvestment, AccountTypeLoan}
 }
 
-// Implement other Provider interface methods for PlaidProvider...
+// CreateLinkToken creates the short lived token used to initialize Plaid Link on the client
 func (p *PlaidProvider) CreateLinkToken(ctx context.Context, req LinkTokenRequest) (*LinkTokenResponse, error) {
-	// Implementation for Plaid link token creation
-	return nil, errors.New("not implemented")
+	products := req.Products
+	if len(products) == 0 {
+		products = []string{"transactions"}
+	}
+
+	countryCodes := req.CountryCodes
+	if len(countryCodes) == 0 {
+		countryCodes = []string{"US"}
+	}
+
+	payload := map[string]any{
+		"client_name":   p.config.ClientName,
+		"language":      "en",
+		"country_codes": countryCodes,
+		"products":      products,
+		"user": map[string]string{
+			"client_user_id": req.UserID,
+		},
+	}
+
+	if req.RedirectURI != "" {
+		payload["redirect_uri"] = req.RedirectURI
+	}
+
+	if p.config.WebhookURL != "" {
+		payload["webhook"] = p.config.WebhookURL
+	}
+
+	var resp struct {
+		LinkToken  string    `json:"link_token"`
+		Expiration time.Time `json:"expiration"`
+	}
+
+	if err := p.makeRequest(ctx, "/link/token/create", payload, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create link token: %w", err)
+	}
+
+	return &LinkTokenResponse{
+		LinkToken: resp.LinkToken,
+		ExpiresAt: resp.Expiration,
+	}, nil
 }
 
+// ExchangePublicToken trades the public token returned by Plaid Link for a long lived access token
 func (p *PlaidProvider) ExchangePublicToken(ctx context.Context, req ExchangeTokenRequest) (*ExchangeTokenResponse, error) {
-	// Implementation for Plaid token exchange
-	return nil, errors.New("not implemented")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ItemID      string `json:"item_id"`
+	}
+
+	if err := p.makeRequest(ctx, "/item/public_token/exchange", map[string]any{
+		"public_token": req.PublicToken,
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to exchange public token: %w", err)
+	}
+
+	return &ExchangeTokenResponse{
+		AccessToken: resp.AccessToken,
+		ItemID:      resp.ItemID,
+	}, nil
 }
 
 func (p *PlaidProvider) GetAccounts(ctx context.Context, accessToken string) ([]Account, error) {
-	// Implementation for getting Plaid accounts and mapping to standardized format
-	return nil, errors.New("not implemented")
+	var resp plaidAccountsResponse
+
+	if err := p.makeRequest(ctx, "/accounts/get", map[string]any{
+		"access_token": accessToken,
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	return p.convertPlaidAccounts(ctx, resp), nil
 }
 
 func (p *PlaidProvider) GetAccount(ctx context.Context, accessToken, accountID string) (*Account, error) {
-	// Implementation for getting single Plaid account
-	return nil, errors.New("not implemented")
+	accounts, err := p.GetAccounts(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range accounts {
+		if accounts[i].ProviderAccountID == accountID {
+			return &accounts[i], nil
+		}
+	}
+
+	return nil, ErrAccountNotFound
 }
 
+// GetAccountBalance fetches the real-time balance of an account (/accounts/get only returns cached balances)
 func (p *PlaidProvider) GetAccountBalance(ctx context.Context, accessToken, accountID string) (*Account, error) {
-	// Implementation for getting Plaid account balance
-	return nil, errors.New("not implemented")
+	var resp plaidAccountsResponse
+
+	if err := p.makeRequest(ctx, "/accounts/balance/get", map[string]any{
+		"access_token": accessToken,
+		"options": map[string]any{
+			"account_ids": []string{accountID},
+		},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+
+	accounts := p.convertPlaidAccounts(ctx, resp)
+	for i := range accounts {
+		if accounts[i].ProviderAccountID == accountID {
+			return &accounts[i], nil
+		}
+	}
+
+	return nil, ErrAccountNotFound
 }
 
+// GetTransactions returns the transactions of an account. Without a date range it returns the whole
+// history, the bank sync resumes from its stored cursor through SyncTransactions instead.
 func (p *PlaidProvider) GetTransactions(ctx context.Context, accessToken, accountID string, args GetTransactionsArgs) ([]Transaction, error) {
-	// Implementation for getting Plaid transactions
-	return nil, errors.New("not implemented")
+	if args.StartDate != nil || args.EndDate != nil {
+		return p.getTransactionsInRange(ctx, accessToken, accountID, args)
+	}
+
+	sync, err := p.SyncTransactions(ctx, accessToken, "")
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]Transaction, 0, len(sync.Added)+len(sync.Modified))
+	for _, changes := range [][]Transaction{sync.Added, sync.Modified} {
+		for _, t := range changes {
+			if t.AccountID == accountID {
+				transactions = append(transactions, t)
+			}
+		}
+	}
+
+	sortTransactionsByDateDesc(transactions)
+
+	if args.Count != nil && *args.Count < len(transactions) {
+		transactions = transactions[:*args.Count]
+	}
+
+	return transactions, nil
 }
 
 func (p *PlaidProvider) GetRecentTransactions(ctx context.Context, accessToken, accountID string, count int) ([]Transaction, error) {
-	// Implementation for getting recent Plaid transactions
-	return nil, errors.New("not implemented")
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -30)
+
+	return p.getTransactionsInRange(ctx, accessToken, accountID, GetTransactionsArgs{
+		Count:     &count,
+		StartDate: &start,
+		EndDate:   &end,
+	})
+}
+
+// SyncTransactions pulls every change of the item since the cursor, following the pages until
+// Plaid reports no more updates. The returned cursor is the one to resume from next time.
+func (p *PlaidProvider) SyncTransactions(ctx context.Context, accessToken, cursor string) (*TransactionsSync, error) {
+	for restart := 0; ; restart++ {
+		sync, err := p.syncTransactionPages(ctx, accessToken, cursor)
+		if errors.Is(err, errPlaidSyncMutation) && restart < plaidSyncMaxRestarts {
+			p.logger.Warn().Int("restart", restart+1).Msg("Transactions changed during sync pagination, starting over")
+			continue
+		}
+		return sync, err
+	}
+}
+
+// syncTransactionPages reads the pages of a sync starting at cursor
+func (p *PlaidProvider) syncTransactionPages(ctx context.Context, accessToken, cursor string) (*TransactionsSync, error) {
+	result := &TransactionsSync{
+		Added:    []Transaction{},
+		Modified: []Transaction{},
+		Removed:  []RemovedTransaction{},
+	}
+
+	for {
+		payload := map[string]any{
+			"access_token": accessToken,
+			"count":        plaidSyncPageSize,
+			"options": map[string]any{
+				"include_personal_finance_category": true,
+			},
+		}
+
+		if cursor != "" {
+			payload["cursor"] = cursor
+		}
+
+		var resp plaidSyncResponse
+		if err := p.makeRequest(ctx, "/transactions/sync", payload, &resp); err != nil {
+			return nil, fmt.Errorf("failed to sync transactions: %w", err)
+		}
+
+		for _, pt := range resp.Added {
+			transaction, err := p.convertPlaidTransaction(pt)
+			if err != nil {
+				p.logger.Warn().Err(err).Str("transaction_id", pt.TransactionID).Msg("Failed to convert transaction")
+				continue
+			}
+			result.Added = append(result.Added, transaction)
+		}
+
+		for _, pt := range resp.Modified {
+			transaction, err := p.convertPlaidTransaction(pt)
+			if err != nil {
+				p.logger.Warn().Err(err).Str("transaction_id", pt.TransactionID).Msg("Failed to convert transaction")
+				continue
+			}
+			result.Modified = append(result.Modified, transaction)
+		}
+
+		for _, removed := range resp.Removed {
+			result.Removed = append(result.Removed, RemovedTransaction{
+				ProviderTransactionID: removed.TransactionID,
+				AccountID:             removed.AccountID,
+			})
+		}
+
+		cursor = resp.NextCursor
+
+		if !resp.HasMore {
+			break
+		}
+	}
+
+	result.NextCursor = cursor
+
+	return result, nil
+}
+
+// getTransactionsInRange pages through /transactions/get for an account
+func (p *PlaidProvider) getTransactionsInRange(ctx context.Context, accessToken, accountID string, args GetTransactionsArgs) ([]Transaction, error) {
+	end := time.Now().UTC()
+	if args.EndDate != nil {
+		end = *args.EndDate
+	}
+
+	start := end.AddDate(-2, 0, 0)
+	if args.StartDate != nil {
+		start = *args.StartDate
+	}
+
+	transactions := []Transaction{}
+	offset := 0
+
+	for {
+		pageSize := plaidSyncPageSize
+		if args.Count != nil && *args.Count-len(transactions) < pageSize {
+			pageSize = *args.Count - len(transactions)
+		}
+
+		if pageSize <= 0 {
+			break
+		}
+
+		var resp plaidTransactionsGetResponse
+		if err := p.makeRequest(ctx, "/transactions/get", map[string]any{
+			"access_token": accessToken,
+			"start_date":   start.Format("2006-01-02"),
+			"end_date":     end.Format("2006-01-02"),
+			"options": map[string]any{
+				"account_ids":                       []string{accountID},
+				"count":                             pageSize,
+				"offset":                            offset,
+				"include_personal_finance_category": true,
+			},
+		}, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		for _, pt := range resp.Transactions {
+			transaction, err := p.convertPlaidTransaction(pt)
+			if err != nil {
+				p.logger.Warn().Err(err).Str("transaction_id", pt.TransactionID).Msg("Failed to convert transaction")
+				continue
+			}
+			transactions = append(transactions, transaction)
+		}
+
+		offset += len(resp.Transactions)
+
+		if len(resp.Transactions) == 0 || offset >= resp.TotalTransactions {
+			break
+		}
+	}
+
+	return transactions, nil
 }
 
 func (p *PlaidProvider) GetInstitutions(ctx context.Context) ([]Institution, error) {
-	// Implementation for getting Plaid institutions
-	return nil, errors.New("not implemented")
+	var resp struct {
+		Institutions []plaidInstitution `json:"institutions"`
+	}
+
+	if err := p.makeRequest(ctx, "/institutions/get", map[string]any{
+		"count":         500,
+		"offset":        0,
+		"country_codes": p.GetSupportedCountries(),
+		"options": map[string]any{
+			"include_optional_metadata": true,
+		},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get institutions: %w", err)
+	}
+
+	return p.convertPlaidInstitutions(resp.Institutions), nil
 }
 
 func (p *PlaidProvider) GetInstitution(ctx context.Context, institutionID string) (*Institution, error) {
-	// Implementation for getting single Plaid institution
-	return nil, errors.New("not implemented")
+	var resp struct {
+		Institution plaidInstitution `json:"institution"`
+	}
+
+	if err := p.makeRequest(ctx, "/institutions/get_by_id", map[string]any{
+		"institution_id": institutionID,
+		"country_codes":  p.GetSupportedCountries(),
+		"options": map[string]any{
+			"include_optional_metadata": true,
+		},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get institution: %w", err)
+	}
+
+	institution := p.convertPlaidInstitutions([]plaidInstitution{resp.Institution})[0]
+
+	return &institution, nil
 }
 
 func (p *PlaidProvider) SearchInstitutions(ctx context.Context, query string) ([]Institution, error) {
-	// Implementation for searching Plaid institutions
-	return nil, errors.New("not implemented")
+	var resp struct {
+		Institutions []plaidInstitution `json:"institutions"`
+	}
+
+	if err := p.makeRequest(ctx, "/institutions/search", map[string]any{
+		"query":         query,
+		"products":      []string{"transactions"},
+		"country_codes": p.GetSupportedCountries(),
+		"options": map[string]any{
+			"include_optional_metadata": true,
+		},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to search institutions: %w", err)
+	}
+
+	return p.convertPlaidInstitutions(resp.Institutions), nil
 }
 
+// GetConnectionStatus reports false when the item needs the user to go through Link again
 func (p *PlaidProvider) GetConnectionStatus(ctx context.Context, accessToken string) (bool, error) {
-	// Implementation for checking Plaid connection status
-	return false, errors.New("not implemented")
+	var resp struct {
+		Item plaidItem `json:"item"`
+	}
+
+	if err := p.makeRequest(ctx, "/item/get", map[string]any{
+		"access_token": accessToken,
+	}, &resp); err != nil {
+		if errors.Is(err, ErrAuthenticationFailed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check connection status: %w", err)
+	}
+
+	if resp.Item.Error != nil && resp.Item.Error.ErrorCode != "" {
+		return false, nil
+	}
+
+	if resp.Item.ConsentExpirationTime != nil && resp.Item.ConsentExpirationTime.Before(time.Now()) {
+		return false, nil
+	}
+
+	return true, nil
 }
 
+// RefreshConnection asks Plaid to fetch new transactions from the institution
 func (p *PlaidProvider) RefreshConnection(ctx context.Context, accessToken string) error {
-	// Implementation for refreshing Plaid connection
-	return errors.New("not implemented")
+	if err := p.makeRequest(ctx, "/transactions/refresh", map[string]any{
+		"access_token": accessToken,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to refresh connection: %w", err)
+	}
+
+	return nil
 }
 
+// RemoveConnection invalidates the access token and removes the item on Plaid's side
 func (p *PlaidProvider) RemoveConnection(ctx context.Context, accessToken string) error {
-	// Implementation for removing Plaid connection
-	return errors.New("not implemented")
+	if err := p.makeRequest(ctx, "/item/remove", map[string]any{
+		"access_token": accessToken,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to remove connection: %w", err)
+	}
+
+	return nil
+}
+
+// Helper methods
+
+// makeRequest POSTs the payload to a Plaid endpoint and decodes the response into out (when not nil)
+func (p *PlaidProvider) makeRequest(ctx context.Context, endpoint string, payload any, out any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nuts-finance/1.0")
+	req.Header.Set("Plaid-Version", plaidAPIVersion)
+	req.Header.Set("PLAID-CLIENT-ID", p.config.ClientID)
+	req.Header.Set("PLAID-SECRET", p.config.Secret)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var plaidErr plaidError
+		if jsonErr := json.Unmarshal(respBody, &plaidErr); jsonErr != nil {
+			return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		}
+
+		p.logger.Error().
+			Int("status_code", resp.StatusCode).
+			Str("error_type", plaidErr.ErrorType).
+			Str("error_code", plaidErr.ErrorCode).
+			Str("request_id", plaidErr.RequestID).
+			Str("endpoint", endpoint).
+			Msg("Plaid API error")
+
+		switch {
+		case plaidErr.ErrorType == "RATE_LIMIT_EXCEEDED" || resp.StatusCode == http.StatusTooManyRequests:
+			return fmt.Errorf("%w: %s (%s)", ErrRateLimitExceeded, plaidErr.ErrorMessage, plaidErr.ErrorCode)
+		case plaidErr.ErrorCode == "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION":
+			return fmt.Errorf("%w: %s", errPlaidSyncMutation, plaidErr.ErrorMessage)
+		case plaidErr.ErrorType == "ITEM_ERROR" && isPlaidReauthError(plaidErr.ErrorCode),
+			plaidErr.ErrorCode == "INVALID_ACCESS_TOKEN":
+			return fmt.Errorf("%w: %s (%s)", ErrAuthenticationFailed, plaidErr.ErrorMessage, plaidErr.ErrorCode)
+		default:
+			return fmt.Errorf("API error %d: %s (%s)", resp.StatusCode, plaidErr.ErrorMessage, plaidErr.ErrorCode)
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// isPlaidReauthError tells whether the item error can only be fixed by the user through Link update mode
+func isPlaidReauthError(code string) bool {
+	switch code {
+	case "ITEM_LOGIN_REQUIRED", "PENDING_EXPIRATION", "ACCESS_NOT_GRANTED", "USER_PERMISSION_REVOKED", "ITEM_LOCKED":
+		return true
+	default:
+		return false
+	}
+}
+
+// convertPlaidAccounts converts the accounts of an item, resolving the institution name once
+func (p *PlaidProvider) convertPlaidAccounts(ctx context.Context, resp plaidAccountsResponse) []Account {
+	var institutionID, institutionName string
+
+	if resp.Item.InstitutionID != nil {
+		institutionID = *resp.Item.InstitutionID
+
+		institution, err := p.GetInstitution(ctx, institutionID)
+		if err != nil {
+			p.logger.Warn().Err(err).Str("institution_id", institutionID).Msg("Failed to fetch institution")
+		} else {
+			institutionName = institution.Name
+		}
+	}
+
+	accounts := make([]Account, 0, len(resp.Accounts))
+	for _, pa := range resp.Accounts {
+		accounts = append(accounts, p.convertPlaidAccount(pa, institutionID, institutionName, resp.Item.ConsentExpirationTime))
+	}
+
+	return accounts
+}
+
+// convertPlaidAccount converts a Plaid account to the standard Account struct
+func (p *PlaidProvider) convertPlaidAccount(pa plaidAccount, institutionID, institutionName string, expiresAt *time.Time) Account {
+	subtype := ""
+	if pa.Subtype != nil {
+		subtype = *pa.Subtype
+	}
+
+	accountType, accountSubType := p.mapPlaidAccountType(pa.Type, subtype)
+
+	var balance float64
+	if pa.Balances.Current != nil {
+		balance = *pa.Balances.Current
+	} else if pa.Balances.Available != nil {
+		balance = *pa.Balances.Available
+	}
+
+	var accountNumber *string
+	if pa.Mask != nil && *pa.Mask != "" {
+		masked := "****" + *pa.Mask
+		accountNumber = &masked
+	}
+
+	status := "open"
+
+	return Account{
+		ID:                pa.AccountID,
+		Name:              pa.Name,
+		Type:              accountType,
+		Balance:           balance,
+		AvailableBalance:  pa.Balances.Available,
+		Currency:          plaidCurrency(pa.Balances.IsoCurrencyCode, pa.Balances.UnofficialCurrencyCode),
+		AccountNumber:     accountNumber,
+		InstitutionName:   institutionName,
+		InstitutionID:     institutionID,
+		LastUpdated:       time.Now(),
+		IsActive:          true,
+		ProviderAccountID: pa.AccountID,
+		Status:            &status,
+		Subtype:           &accountSubType,
+		ExpiresAt:         expiresAt,
+	}
+}
+
+// convertPlaidTransaction converts a Plaid transaction to the standard Transaction struct.
+// Plaid reports money leaving the account as a positive amount, we store it negative.
+func (p *PlaidProvider) convertPlaidTransaction(pt plaidTransaction) (Transaction, error) {
+	date, err := time.Parse("2006-01-02", pt.Date)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	if pt.Datetime != nil {
+		date = *pt.Datetime
+	}
+
+	transactionType := "income"
+	if pt.Amount > 0 {
+		transactionType = "expense"
+	}
+
+	category := "Other"
+	if pt.PersonalFinanceCategory != nil && pt.PersonalFinanceCategory.Primary != "" {
+		category = plaidCategoryName(pt.PersonalFinanceCategory.Primary)
+	} else if len(pt.Category) > 0 {
+		category = pt.Category[0]
+	}
+
+	status := "posted"
+	if pt.Pending {
+		status = "pending"
+	}
+
+	metadata := map[string]string{
+		"payment_channel": pt.PaymentChannel,
+	}
+
+	if pt.PendingTransactionID != nil {
+		metadata["pending_transaction_id"] = *pt.PendingTransactionID
+	}
+
+	if pt.PersonalFinanceCategory != nil {
+		metadata["category_detailed"] = pt.PersonalFinanceCategory.Detailed
+	}
+
+	return Transaction{
+		ID:                    pt.TransactionID,
+		AccountID:             pt.AccountID,
+		Amount:                -pt.Amount,
+		Currency:              plaidCurrency(pt.IsoCurrencyCode, pt.UnofficialCurrencyCode),
+		Description:           pt.Name,
+		Category:              &category,
+		Date:                  date,
+		MerchantName:          pt.MerchantName,
+		Type:                  transactionType,
+		Status:                status,
+		ProviderTransactionID: pt.TransactionID,
+		Metadata:              metadata,
+	}, nil
+}
+
+func (p *PlaidProvider) convertPlaidInstitutions(pis []plaidInstitution) []Institution {
+	institutions := make([]Institution, 0, len(pis))
+	for _, pi := range pis {
+		institution := Institution{
+			ID:       pi.InstitutionID,
+			Name:     pi.Name,
+			Provider: "plaid",
+		}
+
+		if pi.Logo != nil {
+			institution.Logo = *pi.Logo
+		}
+
+		if pi.PrimaryColor != nil {
+			institution.Primary = *pi.PrimaryColor
+		}
+
+		if pi.URL != nil {
+			institution.Website = *pi.URL
+		}
+
+		institutions = append(institutions, institution)
+	}
+
+	return institutions
+}
+
+// mapPlaidAccountType maps Plaid account types to standard account types
+func (p *PlaidProvider) mapPlaidAccountType(accountType, subtype string) (AccountType, AccountSubType) {
+	switch strings.ToLower(accountType) {
+	case "depository":
+		switch strings.ToLower(subtype) {
+		case "savings", "money market", "cd", "hsa":
+			return AccountTypeCash, AccountTypeSavings
+		default:
+			return AccountTypeCash, AccountTypeChecking
+		}
+	case "credit":
+		return AccountTypeCredit, AccountTypeCards
+	case "loan":
+		return AccountTypeLoan, AccountSTypeLoan
+	case "investment":
+		switch strings.ToLower(subtype) {
+		case "401k":
+			return AccountTypeInvestment, AccountType401K
+		case "brokerage":
+			return AccountTypeInvestment, AccountTypeBrokerage
+		default:
+			return AccountTypeInvestment, AccountSTypeInvestment
+		}
+	default:
+		p.logger.Debug().Str("account_type", accountType).Msg("could not find type")
+		return AccountTypeOther, AccountSubType(subtype)
+	}
+}
+
+// plaidCategoryName turns a personal finance category (FOOD_AND_DRINK) into a display name (Food and drink)
+func plaidCategoryName(primary string) string {
+	name := strings.ToLower(strings.ReplaceAll(primary, "_", " "))
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func plaidCurrency(iso, unofficial *string) string {
+	if iso != nil && *iso != "" {
+		return strings.ToUpper(*iso)
+	}
+
+	if unofficial != nil && *unofficial != "" {
+		return strings.ToUpper(*unofficial)
+	}
+
+	return "USD"
+}
+
+func sortTransactionsByDateDesc(transactions []Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.After(transactions[j].Date)
+	})
 }
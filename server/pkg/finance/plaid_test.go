@@ -0,0 +1,329 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlaid is a minimal in-memory stand-in for the Plaid REST API
+type fakePlaid struct {
+	t         *testing.T
+	itemError string
+	syncCalls []string
+	// Second pages that fail because the item changed during the pagination
+	mutations  int
+	linkTokens []map[string]any
+}
+
+func (f *fakePlaid) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	write := func(w http.ResponseWriter, status int, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+
+	auth := func(next func(w http.ResponseWriter, body map[string]any)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				write(w, http.StatusMethodNotAllowed, `{}`)
+				return
+			}
+
+			if r.Header.Get("PLAID-CLIENT-ID") != "client" || r.Header.Get("PLAID-SECRET") != "secret" {
+				write(w, http.StatusBadRequest, `{"error_type":"INVALID_INPUT","error_code":"INVALID_API_KEYS","error_message":"invalid client_id or secret provided"}`)
+				return
+			}
+
+			var body map[string]any
+			require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+
+			if token, ok := body["access_token"]; ok && token != "access-sandbox-123" {
+				write(w, http.StatusBadRequest, `{"error_type":"INVALID_INPUT","error_code":"INVALID_ACCESS_TOKEN","error_message":"provided access token is invalid"}`)
+				return
+			}
+
+			if f.itemError != "" && body["access_token"] != nil {
+				write(w, http.StatusBadRequest, `{"error_type":"ITEM_ERROR","error_code":"`+f.itemError+`","error_message":"the login details of this item have changed"}`)
+				return
+			}
+
+			next(w, body)
+		}
+	}
+
+	mux.HandleFunc("/link/token/create", auth(func(w http.ResponseWriter, body map[string]any) {
+		f.linkTokens = append(f.linkTokens, body)
+		write(w, http.StatusOK, `{"link_token":"link-sandbox-abc","expiration":"2025-01-01T12:00:00Z","request_id":"r1"}`)
+	}))
+
+	mux.HandleFunc("/item/public_token/exchange", auth(func(w http.ResponseWriter, body map[string]any) {
+		if body["public_token"] != "public-sandbox-xyz" {
+			write(w, http.StatusBadRequest, `{"error_type":"INVALID_INPUT","error_code":"INVALID_PUBLIC_TOKEN","error_message":"provided public token is invalid"}`)
+			return
+		}
+		write(w, http.StatusOK, `{"access_token":"access-sandbox-123","item_id":"item-1","request_id":"r2"}`)
+	}))
+
+	accounts := `{
+		"accounts": [
+			{"account_id":"acc-checking","balances":{"available":100,"current":110,"iso_currency_code":"USD"},"mask":"0000","name":"Plaid Checking","type":"depository","subtype":"checking"},
+			{"account_id":"acc-credit","balances":{"available":null,"current":410.5,"limit":2000,"iso_currency_code":"USD"},"mask":"3333","name":"Plaid Credit Card","type":"credit","subtype":"credit card"}
+		],
+		"item": {"item_id":"item-1","institution_id":"ins_109508","error":null},
+		"request_id":"r3"
+	}`
+
+	mux.HandleFunc("/accounts/get", auth(func(w http.ResponseWriter, body map[string]any) {
+		write(w, http.StatusOK, accounts)
+	}))
+
+	mux.HandleFunc("/accounts/balance/get", auth(func(w http.ResponseWriter, body map[string]any) {
+		write(w, http.StatusOK, accounts)
+	}))
+
+	mux.HandleFunc("/institutions/get_by_id", auth(func(w http.ResponseWriter, body map[string]any) {
+		write(w, http.StatusOK, `{"institution":{"institution_id":"ins_109508","name":"First Platypus Bank","url":"https://platypus.example"}}`)
+	}))
+
+	mux.HandleFunc("/transactions/sync", auth(func(w http.ResponseWriter, body map[string]any) {
+		cursor, _ := body["cursor"].(string)
+		f.syncCalls = append(f.syncCalls, cursor)
+
+		if cursor == "cursor-1" && f.mutations > 0 {
+			f.mutations--
+			write(w, http.StatusBadRequest, `{"error_type":"TRANSACTIONS_ERROR","error_code":"TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION","error_message":"underlying transaction data changed since last page was fetched"}`)
+			return
+		}
+
+		switch cursor {
+		case "":
+			write(w, http.StatusOK, `{
+				"added": [
+					{"transaction_id":"tx-1","account_id":"acc-checking","amount":12.5,"iso_currency_code":"USD","date":"2024-03-01","name":"Coffee Shop","merchant_name":"Coffee Shop","pending":false,"payment_channel":"in store","personal_finance_category":{"primary":"FOOD_AND_DRINK","detailed":"FOOD_AND_DRINK_COFFEE"}},
+					{"transaction_id":"tx-2","account_id":"acc-credit","amount":40,"iso_currency_code":"USD","date":"2024-03-02","name":"Gas","pending":true,"payment_channel":"in store"}
+				],
+				"modified": [],
+				"removed": [],
+				"next_cursor": "cursor-1",
+				"has_more": true
+			}`)
+		case "cursor-1":
+			write(w, http.StatusOK, `{
+				"added": [
+					{"transaction_id":"tx-3","account_id":"acc-checking","amount":-1500,"iso_currency_code":"USD","date":"2024-03-05","name":"Payroll","pending":false,"payment_channel":"other","category":["Transfer","Payroll"]}
+				],
+				"modified": [],
+				"removed": [{"transaction_id":"tx-old","account_id":"acc-checking"}],
+				"next_cursor": "cursor-2",
+				"has_more": false
+			}`)
+		default:
+			write(w, http.StatusOK, `{"added":[],"modified":[],"removed":[],"next_cursor":"`+cursor+`","has_more":false}`)
+		}
+	}))
+
+	mux.HandleFunc("/item/get", auth(func(w http.ResponseWriter, body map[string]any) {
+		write(w, http.StatusOK, `{"item":{"item_id":"item-1","institution_id":"ins_109508","error":null}}`)
+	}))
+
+	mux.HandleFunc("/item/remove", auth(func(w http.ResponseWriter, body map[string]any) {
+		write(w, http.StatusOK, `{"request_id":"r4"}`)
+	}))
+
+	return mux
+}
+
+func newTestPlaid(t *testing.T) (*PlaidProvider, *fakePlaid) {
+	t.Helper()
+
+	fake := &fakePlaid{t: t}
+	server := httptest.NewServer(fake.handler())
+	t.Cleanup(server.Close)
+
+	logger := zerolog.Nop()
+	provider, err := NewPlaidProvider(PlaidConfig{
+		ClientID:    "client",
+		Secret:      "secret",
+		Environment: "sandbox",
+		BaseURL:     server.URL,
+		WebhookURL:  "https://nuts.example/webhook",
+	}, &logger)
+	require.NoError(t, err)
+
+	return provider, fake
+}
+
+func TestNewPlaidProvider(t *testing.T) {
+	logger := zerolog.Nop()
+
+	_, err := NewPlaidProvider(PlaidConfig{}, &logger)
+	assert.Error(t, err)
+
+	provider, err := NewPlaidProvider(PlaidConfig{ClientID: "c", Secret: "s", Environment: "production"}, &logger)
+	require.NoError(t, err)
+	assert.Equal(t, PlaidProductionURL, provider.baseURL)
+
+	provider, err = NewPlaidProvider(PlaidConfig{ClientID: "c", Secret: "s"}, &logger)
+	require.NoError(t, err)
+	assert.Equal(t, PlaidSandboxURL, provider.baseURL)
+
+	_, err = NewPlaidProvider(PlaidConfig{ClientID: "c", Secret: "s", Environment: "staging"}, &logger)
+	assert.Error(t, err)
+}
+
+func TestPlaidProvider_LinkAndExchange(t *testing.T) {
+	provider, fake := newTestPlaid(t)
+	ctx := context.Background()
+
+	link, err := provider.CreateLinkToken(ctx, LinkTokenRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "link-sandbox-abc", link.LinkToken)
+	assert.Equal(t, 2025, link.ExpiresAt.Year())
+
+	require.Len(t, fake.linkTokens, 1)
+	sent := fake.linkTokens[0]
+	assert.Equal(t, []any{"transactions"}, sent["products"])
+	assert.Equal(t, []any{"US"}, sent["country_codes"])
+	assert.Equal(t, "https://nuts.example/webhook", sent["webhook"])
+	assert.Equal(t, map[string]any{"client_user_id": "user-1"}, sent["user"])
+
+	exchange, err := provider.ExchangePublicToken(ctx, ExchangeTokenRequest{PublicToken: "public-sandbox-xyz"})
+	require.NoError(t, err)
+	assert.Equal(t, "access-sandbox-123", exchange.AccessToken)
+	assert.Equal(t, "item-1", exchange.ItemID)
+
+	_, err = provider.ExchangePublicToken(ctx, ExchangeTokenRequest{PublicToken: "bad"})
+	assert.ErrorContains(t, err, "INVALID_PUBLIC_TOKEN")
+}
+
+func TestPlaidProvider_Accounts(t *testing.T) {
+	provider, _ := newTestPlaid(t)
+	ctx := context.Background()
+
+	accounts, err := provider.GetAccounts(ctx, "access-sandbox-123")
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	checking := accounts[0]
+	assert.Equal(t, "acc-checking", checking.ProviderAccountID)
+	assert.Equal(t, AccountTypeCash, checking.Type)
+	assert.Equal(t, AccountTypeChecking, *checking.Subtype)
+	assert.Equal(t, 110.0, checking.Balance)
+	assert.Equal(t, 100.0, *checking.AvailableBalance)
+	assert.Equal(t, "USD", checking.Currency)
+	assert.Equal(t, "****0000", *checking.AccountNumber)
+	assert.Equal(t, "First Platypus Bank", checking.InstitutionName)
+	assert.Equal(t, "ins_109508", checking.InstitutionID)
+
+	credit := accounts[1]
+	assert.Equal(t, AccountTypeCredit, credit.Type)
+	assert.Nil(t, credit.AvailableBalance)
+
+	balance, err := provider.GetAccountBalance(ctx, "access-sandbox-123", "acc-credit")
+	require.NoError(t, err)
+	assert.Equal(t, 410.5, balance.Balance)
+
+	_, err = provider.GetAccount(ctx, "access-sandbox-123", "missing")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+
+	_, err = provider.GetAccounts(ctx, "revoked")
+	assert.ErrorIs(t, err, ErrAuthenticationFailed)
+}
+
+func TestPlaidProvider_SyncTransactions(t *testing.T) {
+	provider, fake := newTestPlaid(t)
+	ctx := context.Background()
+
+	sync, err := provider.SyncTransactions(ctx, "access-sandbox-123", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "cursor-1"}, fake.syncCalls)
+	assert.Equal(t, "cursor-2", sync.NextCursor)
+	assert.Equal(t, []RemovedTransaction{{ProviderTransactionID: "tx-old", AccountID: "acc-checking"}}, sync.Removed)
+	require.Len(t, sync.Added, 3)
+
+	coffee := sync.Added[0]
+	assert.Equal(t, -12.5, coffee.Amount)
+	assert.Equal(t, "expense", coffee.Type)
+	assert.Equal(t, "posted", coffee.Status)
+	assert.Equal(t, "Food and drink", *coffee.Category)
+	assert.Equal(t, "Coffee Shop", *coffee.MerchantName)
+
+	assert.Equal(t, "pending", sync.Added[1].Status)
+	assert.Equal(t, "Other", *sync.Added[1].Category)
+
+	payroll := sync.Added[2]
+	assert.Equal(t, 1500.0, payroll.Amount)
+	assert.Equal(t, "income", payroll.Type)
+	assert.Equal(t, "Transfer", *payroll.Category)
+
+	// Resuming from the returned cursor only fetches the new changes
+	sync, err = provider.SyncTransactions(ctx, "access-sandbox-123", sync.NextCursor)
+	require.NoError(t, err)
+	assert.Empty(t, sync.Added)
+	assert.Equal(t, "cursor-2", sync.NextCursor)
+}
+
+func TestPlaidProvider_SyncTransactionsRestartsOnMutation(t *testing.T) {
+	provider, fake := newTestPlaid(t)
+	ctx := context.Background()
+
+	fake.mutations = 1
+
+	sync, err := provider.SyncTransactions(ctx, "access-sandbox-123", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "cursor-1", "", "cursor-1"}, fake.syncCalls)
+	assert.Equal(t, "cursor-2", sync.NextCursor)
+	assert.Len(t, sync.Added, 3)
+
+	fake.syncCalls = nil
+	fake.mutations = plaidSyncMaxRestarts + 1
+
+	_, err = provider.SyncTransactions(ctx, "access-sandbox-123", "")
+	assert.ErrorIs(t, err, errPlaidSyncMutation)
+	assert.Len(t, fake.syncCalls, 2*(plaidSyncMaxRestarts+1))
+}
+
+func TestPlaidProvider_GetTransactions(t *testing.T) {
+	provider, _ := newTestPlaid(t)
+
+	transactions, err := provider.GetTransactions(context.Background(), "access-sandbox-123", "acc-checking", GetTransactionsArgs{})
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	// Most recent first, other accounts filtered out
+	assert.Equal(t, "tx-3", transactions[0].ProviderTransactionID)
+	assert.Equal(t, "tx-1", transactions[1].ProviderTransactionID)
+}
+
+func TestPlaidProvider_ConnectionStatus(t *testing.T) {
+	provider, fake := newTestPlaid(t)
+	ctx := context.Background()
+
+	ok, err := provider.GetConnectionStatus(ctx, "access-sandbox-123")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	fake.itemError = "ITEM_LOGIN_REQUIRED"
+
+	ok, err = provider.GetConnectionStatus(ctx, "access-sandbox-123")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = provider.GetAccounts(ctx, "access-sandbox-123")
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+	fake.itemError = ""
+	assert.NoError(t, provider.RemoveConnection(ctx, "access-sandbox-123"))
+}
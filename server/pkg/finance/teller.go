@@ -16,6 +16,9 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// Page size used when walking the transaction history of an account
+const tellerTransactionsPageSize = 250
+
 type TellerConfig struct {
 	Environment        string
 	BaseURL            string
@@ -208,25 +211,81 @@ func (t *TellerProvider) GetAccountBalanceInternal(ctx context.Context, accessTo
 	return &tellerBalance, nil
 }
 
-// GetTransactions retrieves transactions for an account within a date range
+// GetTransactions retrieves transactions for an account. Teller has no date filter, so a date
+// window is served by paging backwards from the newest transaction until the window start
 func (t *TellerProvider) GetTransactions(ctx context.Context, accessToken, accountID string, args GetTransactionsArgs) ([]Transaction, error) {
-	endpoint := fmt.Sprintf("/accounts/%s/transactions", accountID)
-	params := url.Values{}
-
 	account, err := t.GetAccount(ctx, accessToken, accountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account info: %w", err)
 	}
 
-	if args.Count != nil {
-		params.Set("count", strconv.Itoa(*args.Count))
+	if args.StartDate == nil && args.EndDate == nil {
+		tellerTransactions, err := t.getTransactionsPage(ctx, accessToken, accountID, args.Count, args.FromID)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.convertTellerTransactions(tellerTransactions, account.Type), nil
 	}
 
-	if args.FromID != nil {
-		params.Set("from_id", *args.FromID)
+	count := tellerTransactionsPageSize
+	fromID := args.FromID
+	seen := make(map[string]bool)
+
+	var transactions []Transaction
+
+	for {
+		page, err := t.getTransactionsPage(ctx, accessToken, accountID, &count, fromID)
+		if err != nil {
+			return nil, err
+		}
+
+		reachedStart := false
+		for _, transaction := range t.convertTellerTransactions(page, account.Type) {
+			if seen[transaction.ID] {
+				continue
+			}
+			seen[transaction.ID] = true
+
+			if args.StartDate != nil && transaction.Date.Before(args.StartDate.Truncate(24*time.Hour)) {
+				reachedStart = true
+				continue
+			}
+
+			if args.EndDate != nil && transaction.Date.After(*args.EndDate) {
+				continue
+			}
+
+			transactions = append(transactions, transaction)
+		}
+
+		if reachedStart || len(page) < count {
+			break
+		}
+
+		lastID := page[len(page)-1].ID
+		if fromID != nil && *fromID == lastID {
+			break
+		}
+		fromID = &lastID
+	}
+
+	return transactions, nil
+}
+
+// getTransactionsPage fetches one page of transactions, newest first
+func (t *TellerProvider) getTransactionsPage(ctx context.Context, accessToken, accountID string, count *int, fromID *string) ([]tellerTransaction, error) {
+	endpoint := fmt.Sprintf("/accounts/%s/transactions", accountID)
+	params := url.Values{}
+
+	if count != nil {
+		params.Set("count", strconv.Itoa(*count))
+	}
+
+	if fromID != nil {
+		params.Set("from_id", *fromID)
 	}
 
-	// You could add logic here for startDate and endDate if the API supports them
 	if encoded := params.Encode(); encoded != "" {
 		endpoint += "?" + encoded
 	}
@@ -241,9 +300,13 @@ func (t *TellerProvider) GetTransactions(ctx context.Context, accessToken, accou
 		return nil, fmt.Errorf("failed to parse transactions response: %w", err)
 	}
 
+	return tellerTransactions, nil
+}
+
+func (t *TellerProvider) convertTellerTransactions(tellerTransactions []tellerTransaction, accountType AccountType) []Transaction {
 	transactions := make([]Transaction, 0, len(tellerTransactions))
 	for _, tt := range tellerTransactions {
-		transaction, err := t.convertTellerTransaction(tt, account.Type)
+		transaction, err := t.convertTellerTransaction(tt, accountType)
 		if err != nil {
 			t.logger.Warn().Err(err).Str("transaction_id", tt.ID).Msg("Failed to convert transaction")
 			continue
@@ -252,12 +315,11 @@ func (t *TellerProvider) GetTransactions(ctx context.Context, accessToken, accou
 		transactions = append(transactions, transaction)
 	}
 
-	return transactions, nil
+	return transactions
 }
 
-// IGNORE
 func (t *TellerProvider) GetRecentTransactions(ctx context.Context, accessToken, accountID string, count int) ([]Transaction, error) {
-	return nil, nil
+	return t.GetTransactions(ctx, accessToken, accountID, GetTransactionsArgs{Count: &count})
 }
 
 // GetInstitutions retrieves all supported institutions
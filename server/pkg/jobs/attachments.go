@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
+)
+
+const (
+	attachmentCleanupMaxAttempts = 10
+
+	// Upload links expire after 15 minutes, an attachment still pending after this long was abandoned
+	pendingAttachmentMaxAge = time.Hour
+)
+
+// AttachmentCleanupJob removes the files of deleted attachments from storage
+type AttachmentCleanupJob struct {
+	Bucket string   `json:"bucket"`
+	Keys   []string `json:"keys"`
+}
+
+func (AttachmentCleanupJob) Kind() string { return "attachment_cleanup" }
+
+type AttachmentCleanupWorkerDeps struct {
+	Storage storage.Storage
+	Logger  *zerolog.Logger
+}
+
+type AttachmentCleanupWorker struct {
+	river.WorkerDefaults[AttachmentCleanupJob]
+	deps *AttachmentCleanupWorkerDeps
+}
+
+func (w *AttachmentCleanupWorker) Work(ctx context.Context, job *river.Job[AttachmentCleanupJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Str("bucket", job.Args.Bucket).
+		Logger()
+
+	// Deletes are idempotent, a retry goes through the keys already removed again
+	var errs []error
+	for _, key := range job.Args.Keys {
+		if err := w.deps.Storage.Delete(ctx, job.Args.Bucket, key); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", key, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	logger.Info().Int("files", len(job.Args.Keys)).Msg("Attachment files removed")
+
+	return nil
+}
+
+// PendingAttachmentCleanupJob removes the attachments whose upload was never completed, with
+// whatever file the client put before giving up
+type PendingAttachmentCleanupJob struct {
+	ProcessDate time.Time `json:"process_date"`
+}
+
+func (PendingAttachmentCleanupJob) Kind() string { return "pending_attachment_cleanup" }
+
+type PendingAttachmentCleanupWorkerDeps struct {
+	DB      *pgxpool.Pool
+	Queries *repository.Queries
+	Logger  *zerolog.Logger
+}
+
+type PendingAttachmentCleanupWorker struct {
+	river.WorkerDefaults[PendingAttachmentCleanupJob]
+	deps *PendingAttachmentCleanupWorkerDeps
+}
+
+func (w *PendingAttachmentCleanupWorker) Work(ctx context.Context, job *river.Job[PendingAttachmentCleanupJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Logger()
+
+	tx, err := w.deps.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			logger.Error().Err(rbErr).Msg("Failed to roll the transaction")
+		}
+	}()
+
+	abandoned, err := w.deps.Queries.WithTx(tx).DeletePendingAttachments(ctx, time.Now().Add(-pendingAttachmentMaxAge))
+	if err != nil {
+		return fmt.Errorf("failed to delete pending attachments: %w", err)
+	}
+
+	if len(abandoned) == 0 {
+		return nil
+	}
+
+	keys := make(map[string][]string)
+	for _, att := range abandoned {
+		keys[att.BucketName] = append(keys[att.BucketName], att.StorageKey)
+	}
+
+	// The files go away with the rows, through the same job as the ones of deleted attachments
+	cleanups := make([]river.InsertManyParams, 0, len(keys))
+	for bucket, bucketKeys := range keys {
+		cleanups = append(cleanups, river.InsertManyParams{
+			Args:       AttachmentCleanupJob{Bucket: bucket, Keys: bucketKeys},
+			InsertOpts: &river.InsertOpts{MaxAttempts: attachmentCleanupMaxAttempts},
+		})
+	}
+
+	client := river.ClientFromContext[pgx.Tx](ctx)
+	if _, err := client.InsertManyTx(ctx, tx, cleanups); err != nil {
+		return fmt.Errorf("failed to enqueue attachment cleanups: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit pending attachments cleanup: %w", err)
+	}
+
+	logger.Info().Int("attachments", len(abandoned)).Msg("Abandoned attachment uploads removed")
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/export"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+
+	exportMaxAttempts = 3
+	exportTimeout     = 10 * time.Minute
+
+	// Transactions read per query while writing an export
+	exportPageSize = 500
+)
+
+// ExportJob writes the transactions selected by an export to a file in storage
+type ExportJob struct {
+	ExportID uuid.UUID `json:"export_id"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (ExportJob) Kind() string { return "export" }
+
+type ExportWorkerDeps struct {
+	Queries *repository.Queries
+	Storage storage.Storage
+	Bucket  string
+	Logger  *zerolog.Logger
+}
+
+type ExportWorker struct {
+	river.WorkerDefaults[ExportJob]
+	deps *ExportWorkerDeps
+}
+
+func (w *ExportWorker) Timeout(job *river.Job[ExportJob]) time.Duration {
+	return exportTimeout
+}
+
+func (w *ExportWorker) Work(ctx context.Context, job *river.Job[ExportJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Str("export_id", job.Args.ExportID.String()).
+		Logger()
+
+	exp, err := w.deps.Queries.GetExportByID(ctx, job.Args.ExportID)
+	if err != nil {
+		// The user deleted the export before it ran
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get export: %w", err)
+	}
+
+	if exp.Status == ExportStatusCompleted || exp.Status == ExportStatusFailed {
+		return nil
+	}
+
+	if err := w.deps.Queries.StartExport(ctx, exp.ID); err != nil {
+		return fmt.Errorf("failed to start export: %w", err)
+	}
+
+	key, rows, size, err := w.generate(ctx, exp)
+	if err != nil {
+		if job.Attempt >= job.MaxAttempts {
+			message := err.Error()
+			if failErr := w.deps.Queries.FailExport(ctx, repository.FailExportParams{
+				ID:    exp.ID,
+				Error: &message,
+			}); failErr != nil {
+				logger.Error().Err(failErr).Msg("Failed to mark export failed")
+			}
+		}
+		return err
+	}
+
+	if err := w.deps.Queries.CompleteExport(ctx, repository.CompleteExportParams{
+		ID:         exp.ID,
+		StorageKey: &key,
+		RowCount:   &rows,
+		FileSize:   &size,
+	}); err != nil {
+		return fmt.Errorf("failed to complete export: %w", err)
+	}
+
+	logger.Info().Int32("rows", rows).Int64("size", size).Msg("Export completed")
+
+	return nil
+}
+
+// generate writes the export to a temporary file, so that large exports don't sit in memory,
+// then uploads it
+func (w *ExportWorker) generate(ctx context.Context, exp repository.Export) (string, int32, int64, error) {
+	format := export.Format(exp.Format)
+
+	file, err := os.CreateTemp("", "nuts-export-*"+format.Extension())
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer, err := export.NewWriter(format, file)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	rows, err := w.writeTransactions(ctx, exp, format, writer)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, 0, err
+	}
+
+	key := exportStorageKey(exp.UserID, exp.ID, format)
+	if err := w.deps.Storage.Upload(ctx, w.deps.Bucket, key, size, file); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	return key, int32(rows), size, nil
+}
+
+// writeTransactions streams the selected transactions to writer. OFX needs them grouped by
+// account, they are read one account at a time
+func (w *ExportWorker) writeTransactions(ctx context.Context, exp repository.Export, format export.Format, writer export.Writer) (int, error) {
+	if format != export.FormatOFX {
+		return w.writePages(ctx, exp.UserID, exp.Filters, writer)
+	}
+
+	accounts, err := w.deps.Queries.GetAccounts(ctx, &exp.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	total := 0
+	for _, account := range accounts {
+		if exp.Filters.AccountID != nil && *exp.Filters.AccountID != account.ID {
+			continue
+		}
+
+		filters := exp.Filters
+		filters.AccountID = &account.ID
+
+		count, err := w.writePages(ctx, exp.UserID, filters, writer)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+func (w *ExportWorker) writePages(ctx context.Context, userID uuid.UUID, filters dto.ExportFilters, writer export.Writer) (int, error) {
+	params := repository.ListTransactionsParams{
+		UserID:      &userID,
+		Type:        filters.Type,
+		AccountID:   filters.AccountID,
+		CategoryID:  filters.CategoryID,
+		Currency:    filters.Currency,
+		IsExternal:  filters.IsExternal,
+		IsRecurring: filters.IsRecurring,
+		IsPending:   filters.IsPending,
+		StartDate:   filters.StartDate,
+		EndDate:     filters.EndDate,
+		MinAmount:   types.ToPgNumeric(filters.MinAmount),
+		MaxAmount:   types.ToPgNumeric(filters.MaxAmount),
+		Search:      filters.Search,
+		Tags:        filters.Tags,
+		Limit:       exportPageSize,
+	}
+
+	// Pages are ordered by date then id, transactions sharing a timestamp don't move between pages
+	count := 0
+	for {
+		params.Offset = int64(count)
+
+		page, err := w.deps.Queries.ListTransactions(ctx, params)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, transaction := range page {
+			if err := writer.Write(exportRow(transaction)); err != nil {
+				return 0, fmt.Errorf("failed to write export: %w", err)
+			}
+		}
+
+		count += len(page)
+		if len(page) < exportPageSize {
+			return count, nil
+		}
+	}
+}
+
+func exportRow(transaction repository.ListTransactionsRow) export.Row {
+	row := export.Row{
+		ID:          transaction.ID,
+		Date:        transaction.TransactionDatetime,
+		Type:        transaction.Type,
+		Amount:      types.PgtypeNumericToDecimal(transaction.Amount),
+		Currency:    transaction.Account.Currency,
+		Category:    transaction.Category.Name,
+		AccountID:   transaction.Account.ID,
+		Account:     transaction.Account.Name,
+		AccountType: string(transaction.Account.Type),
+	}
+
+	if transaction.Description != nil {
+		row.Description = *transaction.Description
+	}
+
+	if transaction.DestinationAccountName != nil {
+		row.DestinationAccount = *transaction.DestinationAccountName
+	}
+
+	if transaction.Details != nil && transaction.Details.Note != nil {
+		row.Note = *transaction.Details.Note
+	}
+
+	return row
+}
+
+// exportStorageKey is where the file of an export is stored in the private bucket
+func exportStorageKey(userID, exportID uuid.UUID, format export.Format) string {
+	return fmt.Sprintf("exports/%s/%s%s", userID, exportID, format.Extension())
+}
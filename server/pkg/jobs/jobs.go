@@ -13,6 +13,8 @@ import (
 	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/types"
+	"github.com/Fantasy-Programming/nuts/server/pkg/budgetperiod"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -40,16 +42,12 @@ type BankSyncJob struct {
 
 func (BankSyncJob) Kind() string { return "bank_sync" }
 
-type ExportJob struct {
-	UserID     int64  `json:"user_id"`
-	ExportType string `json:"export_type"` // "csv", "pdf", etc.
-	DateRange  struct {
-		From time.Time `json:"from"`
-		To   time.Time `json:"to"`
-	} `json:"date_range"`
+// BankSyncSchedulerJob fans out a BankSyncJob for every connection that is due for a sync
+type BankSyncSchedulerJob struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
 }
 
-func (ExportJob) Kind() string { return "export" }
+func (BankSyncSchedulerJob) Kind() string { return "bank_sync_scheduler" }
 
 type EmailWorker struct {
 	river.WorkerDefaults[EmailJob]
@@ -73,9 +71,17 @@ type BankSyncWorkerDeps struct {
 	Queries        *repository.Queries
 	encrypt        *encrypt.Encrypter
 	FinanceManager *finance.ProviderManager
+	Events         *events.Bus
 	Logger         *zerolog.Logger
 }
 
+// syncReport collects what a sync changed, it's published once the sync is committed
+type syncReport struct {
+	accounts            int
+	transactionsCreated int64
+	balanceChanges      []events.AccountBalanceChangedData
+}
+
 type BankSyncWorker struct {
 	river.WorkerDefaults[BankSyncJob]
 	deps *BankSyncWorkerDeps
@@ -89,20 +95,6 @@ func (w *BankSyncWorker) Work(ctx context.Context, job *river.Job[BankSyncJob])
 		Str("sync_type", job.Args.SyncType).
 		Msg("Starting bank sync job")
 
-		// Get user's connection details
-	connection, err := w.deps.Queries.GetConnectionByID(ctx, job.Args.ConnectionID)
-	if err != nil {
-		w.deps.Logger.Error().Err(err).Msg("Failed to get connection")
-		return fmt.Errorf("failed to get connection: %w", err)
-	}
-
-	// Get the appropriate finance provider
-	provider, err := w.deps.FinanceManager.GetProvider(connection.ProviderName)
-	if err != nil {
-		w.deps.Logger.Error().Err(err).Str("provider", connection.ProviderName).Msg("Failed to get provider")
-		return fmt.Errorf("failed to get provider: %w", err)
-	}
-
 	// Start transaction for atomic sync
 	tx, err := w.deps.DB.Begin(ctx)
 	if err != nil {
@@ -116,23 +108,53 @@ func (w *BankSyncWorker) Work(ctx context.Context, job *river.Job[BankSyncJob])
 
 	qtx := w.deps.Queries.WithTx(tx)
 
+	// Scheduled, manual and webhook syncs of the same connection run one after the other
+	if err := qtx.LockConnectionSync(ctx, job.Args.ConnectionID); err != nil {
+		return fmt.Errorf("failed to lock connection: %w", err)
+	}
+
+	// Get user's connection details, read once the lock is held to see the previous sync's outcome
+	connection, err := qtx.GetConnectionByID(ctx, job.Args.ConnectionID)
+	if err != nil {
+		w.deps.Logger.Error().Err(err).Msg("Failed to get connection")
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	// Broken links are only synced again once the user reconnected them
+	if connection.Status != nil && (*connection.Status == ConnectionStatusReauthRequired || *connection.Status == ConnectionStatusDisconnected) {
+		w.deps.Logger.Info().Any("connection_id", connection.ID).Str("status", *connection.Status).Msg("Skipping sync of inactive connection")
+		return nil
+	}
+
+	// Get the appropriate finance provider
+	provider, err := w.deps.FinanceManager.GetProvider(connection.ProviderName)
+	if err != nil {
+		w.deps.Logger.Error().Err(err).Str("provider", connection.ProviderName).Msg("Failed to get provider")
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	report := &syncReport{}
+
 	// Sync accounts first
-	if err := w.syncAccounts(ctx, qtx, provider, connection, job.Args.UserID); err != nil {
-		return fmt.Errorf("failed to sync accounts: %w", err)
+	if err := w.syncAccounts(ctx, qtx, provider, connection, job.Args.UserID, report); err != nil {
+		return w.recordSyncFailure(ctx, connection, fmt.Errorf("failed to sync accounts: %w", err))
 	}
 
 	// Sync transactions
-	if err := w.syncTransactions(ctx, qtx, provider, connection, job.Args.UserID, job.Args.SyncType); err != nil {
-		return fmt.Errorf("failed to sync transactions: %w", err)
+	if err := w.syncTransactions(ctx, qtx, provider, connection, job.Args.UserID, job.Args.SyncType, report); err != nil {
+		return w.recordSyncFailure(ctx, connection, fmt.Errorf("failed to sync transactions: %w", err))
 	}
 
-	// Update last sync time
+	// Update last sync time, a successful sync clears the failure streak
 	now := time.Now()
-	if _, err := qtx.SetConnectionSyncStatus(ctx, repository.SetConnectionSyncStatusParams{
+	status := ConnectionStatusActive
+	synced, err := qtx.SetConnectionSyncStatus(ctx, repository.SetConnectionSyncStatusParams{
 		ID:         job.Args.ConnectionID,
 		UserID:     job.Args.UserID,
+		Status:     &status,
 		LastSyncAt: pgtype.Timestamptz{Valid: true, Time: now},
-	}); err != nil {
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update last sync time: %w", err)
 	}
 
@@ -141,6 +163,16 @@ func (w *BankSyncWorker) Work(ctx context.Context, job *river.Job[BankSyncJob])
 		return fmt.Errorf("failed to commit sync transaction: %w", err)
 	}
 
+	for _, change := range report.balanceChanges {
+		w.deps.Events.Publish(ctx, job.Args.UserID, events.AccountBalanceChanged, change)
+	}
+
+	w.deps.Events.Publish(ctx, job.Args.UserID, events.ConnectionSynced, events.ConnectionSyncedData{
+		Connection:          events.NewConnection(synced),
+		AccountsSynced:      report.accounts,
+		TransactionsCreated: int(report.transactionsCreated),
+	})
+
 	w.deps.Logger.Info().
 		Any("user_id", job.Args.UserID).
 		Str("sync_type", job.Args.SyncType).
@@ -149,8 +181,38 @@ func (w *BankSyncWorker) Work(ctx context.Context, job *river.Job[BankSyncJob])
 	return nil
 }
 
+// recordSyncFailure flags the connection so the scheduler backs off from it. Authentication
+// failures won't fix themselves, so the job is cancelled instead of retried
+func (w *BankSyncWorker) recordSyncFailure(ctx context.Context, connection repository.UserFinancialConnection, syncErr error) error {
+	status := ConnectionStatusError
+	if errors.Is(syncErr, finance.ErrAuthenticationFailed) {
+		status = ConnectionStatusReauthRequired
+	}
+
+	lastError := syncErr.Error()
+	failed, err := w.deps.Queries.SetConnectionSyncFailed(ctx, repository.SetConnectionSyncFailedParams{
+		ID:        connection.ID,
+		Status:    &status,
+		LastError: &lastError,
+	})
+	if err != nil {
+		w.deps.Logger.Error().Err(err).Any("connection_id", connection.ID).Msg("Failed to record sync failure")
+	}
+
+	if status == ConnectionStatusReauthRequired {
+		if err == nil {
+			w.deps.Events.Publish(ctx, connection.UserID, events.ConnectionReauthRequired, events.ConnectionData{
+				Connection: events.NewConnection(failed),
+			})
+		}
+		return river.JobCancel(syncErr)
+	}
+
+	return syncErr
+}
+
 // syncAccounts syncs account data from provider
-func (w *BankSyncWorker) syncAccounts(ctx context.Context, qtx *repository.Queries, provider finance.Provider, connection repository.UserFinancialConnection, userID uuid.UUID) error {
+func (w *BankSyncWorker) syncAccounts(ctx context.Context, qtx *repository.Queries, provider finance.Provider, connection repository.UserFinancialConnection, userID uuid.UUID, report *syncReport) error {
 	decryptedToken, err := w.deps.encrypt.Decrypt(connection.AccessTokenEncrypted)
 	if err != nil {
 		return fmt.Errorf("failed to decrypt access token: %w", err)
@@ -166,6 +228,8 @@ func (w *BankSyncWorker) syncAccounts(ctx context.Context, qtx *repository.Queri
 		return nil
 	}
 
+	report.accounts = len(accounts)
+
 	existingAccounts, err := qtx.GetAccountsByConnectionID(ctx, repository.GetAccountsByConnectionIDParams{
 		CreatedBy:    &userID,
 		ConnectionID: &connection.ID,
@@ -227,16 +291,32 @@ func (w *BankSyncWorker) syncAccounts(ctx context.Context, qtx *repository.Queri
 	}
 
 	if len(accountsToUpdate) > 0 {
-		if err := w.batchUpdateAccounts(ctx, qtx, accountsToUpdate); err != nil {
+		updated, err := w.batchUpdateAccounts(ctx, qtx, accountsToUpdate)
+		if err != nil {
 			return fmt.Errorf("failed to batch update accounts: %w", err)
 		}
+
+		previousBalances := make(map[uuid.UUID]decimal.Decimal, len(existingAccounts))
+		for _, acc := range existingAccounts {
+			previousBalances[acc.ID] = types.PgtypeNumericToDecimal(acc.Balance)
+		}
+
+		for _, acc := range updated {
+			previous := previousBalances[acc.ID]
+			if !types.PgtypeNumericToDecimal(acc.Balance).Equal(previous) {
+				report.balanceChanges = append(report.balanceChanges, events.AccountBalanceChangedData{
+					Account:         events.NewAccountData(acc).Account,
+					PreviousBalance: previous,
+				})
+			}
+		}
 	}
 
 	return nil
 }
 
 // syncTransactions syncs transaction data from provider for a user
-func (w *BankSyncWorker) syncTransactions(ctx context.Context, qtx *repository.Queries, provider finance.Provider, connection repository.UserFinancialConnection, userID uuid.UUID, syncType string) error {
+func (w *BankSyncWorker) syncTransactions(ctx context.Context, qtx *repository.Queries, provider finance.Provider, connection repository.UserFinancialConnection, userID uuid.UUID, syncType string, report *syncReport) error {
 	// Get user's accounts with that connection
 	accounts, err := qtx.GetAccountsByConnectionID(ctx, repository.GetAccountsByConnectionIDParams{
 		CreatedBy:    &userID,
@@ -246,14 +326,39 @@ func (w *BankSyncWorker) syncTransactions(ctx context.Context, qtx *repository.Q
 		return fmt.Errorf("failed to get user accounts: %w", err)
 	}
 
+	if len(accounts) == 0 {
+		return nil
+	}
+
 	// Pre-load category cache
 	categoryCache, err := w.buildCategoryCache(ctx, qtx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to build category cache: %w", err)
 	}
 
+	decryptedToken, err := w.deps.encrypt.Decrypt(connection.AccessTokenEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	accessToken := string(decryptedToken)
+
+	// Change feeds cover every account of the connection, they're read once and split by account
+	var feed *finance.TransactionsSync
+	if syncer, ok := provider.(finance.TransactionSyncer); ok {
+		cursor, err := w.transactionsCursor(ctx, qtx, accounts, syncType)
+		if err != nil {
+			return err
+		}
+
+		feed, err = syncer.SyncTransactions(ctx, accessToken, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to sync transactions from provider: %w", err)
+		}
+	}
+
 	for _, account := range accounts {
-		if err := w.syncAccountTransactions(ctx, qtx, provider, connection, account, syncType, categoryCache, userID); err != nil {
+		if err := w.syncAccountTransactions(ctx, qtx, provider, accessToken, feed, account, syncType, categoryCache, userID, report); err != nil {
 			w.deps.Logger.Error().Err(err).Str("account_id", account.ID.String()).Msg("Failed to sync account transactions")
 			continue // Continue with other accounts
 		}
@@ -262,68 +367,117 @@ func (w *BankSyncWorker) syncTransactions(ctx context.Context, qtx *repository.Q
 	return nil
 }
 
-// Sync transactions for a single account with optimizations
-func (w *BankSyncWorker) syncAccountTransactions(ctx context.Context, qtx *repository.Queries, provider finance.Provider, connection repository.UserFinancialConnection, account repository.GetAccountsByConnectionIDRow, syncType string, categoryCache map[string]uuid.UUID, userID uuid.UUID) error {
-	decryptedToken, err := w.deps.encrypt.Decrypt(connection.AccessTokenEncrypted)
-	if err != nil {
-		return fmt.Errorf("failed to decrypt access token: %w", err)
-	}
-
-	// Get transactions from provider
-	var transactions []finance.Transaction
-
-	if syncType == "full" {
-		transactions, err = provider.GetTransactions(ctx, string(decryptedToken), *account.ProviderAccountID, finance.GetTransactionsArgs{})
-	} else {
-		transactions, err = provider.GetRecentTransactions(ctx, string(decryptedToken), *account.ProviderAccountID, 100)
-	}
+// Sync transactions for a single account. Providers with a change feed hand over the account's share
+// of the connection feed, the others only request the window since the newest transaction seen by
+// the previous sync, widened by bankSyncOverlap
+func (w *BankSyncWorker) syncAccountTransactions(ctx context.Context, qtx *repository.Queries, provider finance.Provider, accessToken string, feed *finance.TransactionsSync, account repository.GetAccountsByConnectionIDRow, syncType string, categoryCache map[string]uuid.UUID, userID uuid.UUID, report *syncReport) error {
+	now := time.Now()
 
+	changes, err := w.fetchTransactions(ctx, qtx, provider, accessToken, feed, account, syncType, now)
 	if err != nil {
-		return fmt.Errorf("failed to get transactions from provider: %w", err)
+		return err
 	}
 
-	if len(transactions) == 0 {
-		return nil
-	}
+	transactions := changes.transactions
 
 	w.deps.Logger.Info().
 		Int("count", len(transactions)).
 		Str("account_id", *account.ProviderAccountID).
 		Msg("Syncing transactions")
 
-	// Get existing transactions for this account (batch lookup)
-	existingTransactions, err := qtx.ListTransactionsByAccount(ctx, account.ID)
-	if err != nil {
-		return fmt.Errorf("failed to get existing transactions: %w", err)
+	var lastSeenAt *time.Time
+	providerIDs := make([]string, 0, len(transactions))
+
+	for _, transaction := range transactions {
+		providerIDs = append(providerIDs, transaction.ProviderTransactionID)
+
+		if lastSeenAt == nil || transaction.Date.After(*lastSeenAt) {
+			lastSeenAt = &transaction.Date
+		}
 	}
 
-	// Create existence map for O(1) lookups
-	existingTxnMap := make(map[string]bool)
-	for _, txn := range existingTransactions {
-		if txn.ProviderTransactionID != nil {
-			existingTxnMap[*txn.ProviderTransactionID] = true
+	// Only look up the transactions we received, through the (account_id, provider_transaction_id) index
+	existingTxnMap := make(map[string]repository.ListProviderTransactionsRow)
+	incomingIDs := make(map[string]bool, len(providerIDs))
+
+	if len(providerIDs) > 0 {
+		existingTransactions, err := qtx.ListProviderTransactions(ctx, repository.ListProviderTransactionsParams{
+			AccountID:              account.ID,
+			ProviderTransactionIds: providerIDs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get existing transactions: %w", err)
+		}
+
+		for _, txn := range existingTransactions {
+			existingTxnMap[txn.ProviderTransactionID] = txn
+		}
+
+		for _, id := range providerIDs {
+			incomingIDs[id] = true
 		}
 	}
 
+	// Pending transactions of the window are either still reported, replaced by a posted one or dropped by the bank
+	pending, err := w.loadPendingTransactions(ctx, qtx, account.ID, changes.since(), incomingIDs)
+	if err != nil {
+		return err
+	}
+
 	// Prepare batch insert
 	var transactionsToCreate []repository.BatchCreateTransactionParams
+	var reconciledCount int
 
 	for _, transaction := range transactions {
-		// Skip if transaction already exists
-		if existingTxnMap[transaction.ProviderTransactionID] {
+		status := transactionStatus(transaction)
+		amount := decimal.NewFromFloat(transaction.Amount)
+
+		// Known transaction, a pending one is updated in place as it settles
+		if existing, exists := existingTxnMap[transaction.ProviderTransactionID]; exists {
+			if existing.DeletedAt == nil && existing.PaymentStatus == transactionStatusPending &&
+				(status != transactionStatusPending || !types.PgtypeNumericToDecimal(existing.Amount).Equal(amount) || !existing.TransactionDatetime.Equal(transaction.Date)) {
+				if err := w.reconcileTransaction(ctx, qtx, existing.ID, transaction, status); err != nil {
+					return err
+				}
+				reconciledCount++
+			}
 			continue
 		}
 
+		// Posted under a new identifier, take over its pending predecessor
+		if status == transactionStatusPosted {
+			if i := matchPendingTransaction(transaction, pending); i >= 0 {
+				if err := w.reconcileTransaction(ctx, qtx, pending[i].ID, transaction, status); err != nil {
+					return err
+				}
+				pending[i].resolved = true
+				reconciledCount++
+				continue
+			}
+		}
+
+		// Providers may return the same transaction twice when the window overlaps pages
+		existingTxnMap[transaction.ProviderTransactionID] = repository.ListProviderTransactionsRow{PaymentStatus: status}
+
+		categoryName := "Other"
+		if transaction.Category != nil && *transaction.Category != "" {
+			categoryName = *transaction.Category
+		}
+
 		// Get category ID from cache (or create if needed)
-		categoryID, err := w.getCategoryIDFromCache(ctx, qtx, categoryCache, userID, *transaction.Category)
+		categoryID, err := w.getCategoryIDFromCache(ctx, qtx, categoryCache, userID, categoryName)
 		if err != nil {
-			w.deps.Logger.Error().Err(err).Str("category", *transaction.Category).Msg("Failed to get category")
+			w.deps.Logger.Error().Err(err).Str("category", categoryName).Msg("Failed to get category")
 			continue
 		}
 
-		amount := decimal.NewFromFloat(transaction.Amount)
 		isExternal := true
 
+		details := &dto.Details{PaymentStatus: &status, Merchant: transaction.MerchantName}
+		if mcc := transaction.Metadata["merchant_category_code"]; mcc != "" {
+			details.MerchantCategoryCode = &mcc
+		}
+
 		transactionsToCreate = append(transactionsToCreate, repository.BatchCreateTransactionParams{
 			Amount:                amount,
 			OriginalAmount:        amount,
@@ -334,12 +488,51 @@ func (w *BankSyncWorker) syncAccountTransactions(ctx context.Context, qtx *repos
 			TransactionDatetime:   pgtype.Timestamptz{Valid: true, Time: transaction.Date},
 			Description:           &transaction.Description,
 			ProviderTransactionID: &transaction.ProviderTransactionID,
-			Details:               &dto.Details{},
+			Details:               details,
 			CreatedBy:             &userID,
 			IsExternal:            &isExternal,
 		})
 	}
 
+	// Pending transactions missing from a window were dropped by the bank. An empty response more
+	// likely means a provider hiccup than every pending charge being voided
+	var dropped []uuid.UUID
+	if changes.window != nil && len(transactions) > 0 {
+		for _, p := range pending {
+			if !p.resolved {
+				dropped = append(dropped, p.ID)
+			}
+		}
+
+		if len(dropped) > 0 {
+			if err := qtx.DeleteDroppedProviderTransactions(ctx, repository.DeleteDroppedProviderTransactionsParams{
+				AccountID: account.ID,
+				Ids:       dropped,
+			}); err != nil {
+				return fmt.Errorf("failed to remove dropped pending transactions: %w", err)
+			}
+		}
+	}
+
+	// Change feeds name the removed transactions
+	if len(changes.removed) > 0 {
+		if err := qtx.DeleteRemovedProviderTransactions(ctx, repository.DeleteRemovedProviderTransactionsParams{
+			AccountID:              account.ID,
+			ProviderTransactionIds: changes.removed,
+		}); err != nil {
+			return fmt.Errorf("failed to remove deleted transactions: %w", err)
+		}
+	}
+
+	if reconciledCount > 0 || len(dropped) > 0 || len(changes.removed) > 0 {
+		w.deps.Logger.Info().
+			Int("reconciled", reconciledCount).
+			Int("dropped", len(dropped)).
+			Int("removed", len(changes.removed)).
+			Str("account_id", *account.ProviderAccountID).
+			Msg("Reconciled pending transactions")
+	}
+
 	// Batch insert transactions
 	if len(transactionsToCreate) > 0 {
 		val, err := qtx.BatchCreateTransaction(ctx, transactionsToCreate)
@@ -347,11 +540,161 @@ func (w *BankSyncWorker) syncAccountTransactions(ctx context.Context, qtx *repos
 			return fmt.Errorf("failed to batch create transactions: %w", err)
 		}
 		w.deps.Logger.Info().Int64("created", val).Msg("Created new transactions")
+		report.transactionsCreated += val
+	}
+
+	// Remember how far we got, the query keeps the newest last_seen_at
+	lastSeen := pgtype.Timestamptz{Valid: false}
+	if lastSeenAt != nil {
+		lastSeen = pgtype.Timestamptz{Valid: true, Time: *lastSeenAt}
+	}
+
+	if _, err := qtx.UpsertAccountSyncState(ctx, repository.UpsertAccountSyncStateParams{
+		AccountID:    account.ID,
+		LastSeenAt:   lastSeen,
+		LastSyncedAt: pgtype.Timestamptz{Valid: true, Time: now},
+		Cursor:       changes.cursor,
+	}); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
 	}
 
 	return nil
 }
 
+// loadPendingTransactions returns the stored pending transactions of the account dated after since.
+// The ones the provider still reports are already resolved
+func (w *BankSyncWorker) loadPendingTransactions(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID, since *time.Time, incomingIDs map[string]bool) ([]pendingTransaction, error) {
+	params := repository.ListPendingProviderTransactionsParams{AccountID: accountID}
+	if since != nil {
+		params.Since = pgtype.Timestamptz{Valid: true, Time: *since}
+	}
+
+	rows, err := qtx.ListPendingProviderTransactions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transactions: %w", err)
+	}
+
+	pending := make([]pendingTransaction, 0, len(rows))
+	for _, row := range rows {
+		description := ""
+		if row.Description != nil {
+			description = *row.Description
+		}
+
+		pending = append(pending, pendingTransaction{
+			ID:                    row.ID,
+			ProviderTransactionID: row.ProviderTransactionID,
+			Amount:                types.PgtypeNumericToDecimal(row.Amount),
+			Date:                  row.TransactionDatetime,
+			Description:           description,
+			Merchant:              row.Merchant,
+			resolved:              incomingIDs[row.ProviderTransactionID],
+		})
+	}
+
+	return pending, nil
+}
+
+// reconcileTransaction moves a stored transaction to the latest state reported by the provider
+func (w *BankSyncWorker) reconcileTransaction(ctx context.Context, qtx *repository.Queries, id uuid.UUID, transaction finance.Transaction, status string) error {
+	if err := qtx.ReconcileProviderTransaction(ctx, repository.ReconcileProviderTransactionParams{
+		ID:                    id,
+		ProviderTransactionID: &transaction.ProviderTransactionID,
+		Amount:                decimal.NewFromFloat(transaction.Amount),
+		TransactionDatetime:   pgtype.Timestamptz{Valid: true, Time: transaction.Date},
+		PaymentStatus:         status,
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile transaction %s: %w", transaction.ProviderTransactionID, err)
+	}
+
+	return nil
+}
+
+// fetchTransactions returns what changed on the account since the previous sync, from the feed of the
+// connection when the provider has one
+func (w *BankSyncWorker) fetchTransactions(ctx context.Context, qtx *repository.Queries, provider finance.Provider, accessToken string, feed *finance.TransactionsSync, account repository.GetAccountsByConnectionIDRow, syncType string, now time.Time) (providerChanges, error) {
+	if feed != nil {
+		return providerChanges{
+			transactions: accountTransactions(feed, *account.ProviderAccountID),
+			removed:      accountRemovedTransactions(feed, *account.ProviderAccountID),
+			cursor:       &feed.NextCursor,
+		}, nil
+	}
+
+	args, err := w.transactionsWindow(ctx, qtx, account.ID, syncType, now)
+	if err != nil {
+		return providerChanges{}, err
+	}
+
+	transactions, err := provider.GetTransactions(ctx, accessToken, *account.ProviderAccountID, args)
+	if err != nil {
+		return providerChanges{}, fmt.Errorf("failed to get transactions from provider: %w", err)
+	}
+
+	return providerChanges{transactions: transactions, window: &args}, nil
+}
+
+// transactionsCursor returns the cursor to resume the change feed of a connection from. Every account
+// of the connection stores the cursor of the last sync that covered it. Full syncs, and connections
+// whose accounts don't agree on it (a new account, or one whose changes failed to apply), replay the
+// feed from the start, already known transactions are matched again
+func (w *BankSyncWorker) transactionsCursor(ctx context.Context, qtx *repository.Queries, accounts []repository.GetAccountsByConnectionIDRow, syncType string) (string, error) {
+	if syncType == "full" {
+		return "", nil
+	}
+
+	var cursor *string
+	for _, account := range accounts {
+		state, err := qtx.GetAccountSyncState(ctx, account.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to get sync state: %w", err)
+		}
+
+		if state.Cursor == nil || (cursor != nil && *cursor != *state.Cursor) {
+			return "", nil
+		}
+
+		cursor = state.Cursor
+	}
+
+	if cursor == nil {
+		return "", nil
+	}
+
+	return *cursor, nil
+}
+
+// transactionsWindow builds the provider request of an account sync. Full syncs, and accounts that
+// were never synced, fetch the whole history
+func (w *BankSyncWorker) transactionsWindow(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID, syncType string, now time.Time) (finance.GetTransactionsArgs, error) {
+	if syncType == "full" {
+		return finance.GetTransactionsArgs{}, nil
+	}
+
+	state, err := qtx.GetAccountSyncState(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return finance.GetTransactionsArgs{}, nil
+		}
+		return finance.GetTransactionsArgs{}, fmt.Errorf("failed to get sync state: %w", err)
+	}
+
+	since := state.LastSyncedAt
+	if state.LastSeenAt != nil {
+		since = *state.LastSeenAt
+	}
+
+	start := since.Add(-bankSyncOverlap)
+
+	return finance.GetTransactionsArgs{
+		StartDate: &start,
+		EndDate:   &now,
+	}, nil
+}
+
 func (w *BankSyncWorker) buildCategoryCache(ctx context.Context, qtx *repository.Queries, userID uuid.UUID) (map[string]uuid.UUID, error) {
 	categories, err := qtx.ListCategories(ctx, userID)
 	if err != nil {
@@ -392,32 +735,222 @@ func (w *BankSyncWorker) getCategoryIDFromCache(ctx context.Context, qtx *reposi
 }
 
 // Batch update accounts
-func (w *BankSyncWorker) batchUpdateAccounts(ctx context.Context, qtx *repository.Queries, accounts []repository.UpdateAccountParams) error {
+func (w *BankSyncWorker) batchUpdateAccounts(ctx context.Context, qtx *repository.Queries, accounts []repository.UpdateAccountParams) ([]repository.Account, error) {
+	updated := make([]repository.Account, 0, len(accounts))
 	for _, account := range accounts {
-		_, err := qtx.UpdateAccount(ctx, account)
+		acc, err := qtx.UpdateAccount(ctx, account)
 		if err != nil {
-			return fmt.Errorf("failed to update account: %w", err)
+			return nil, fmt.Errorf("failed to update account: %w", err)
 		}
+		updated = append(updated, acc)
 	}
-	return nil
+	return updated, nil
 }
 
-type ExportWorker struct {
-	river.WorkerDefaults[ExportJob]
-	logger *zerolog.Logger
+// Connection statuses stored in user_financial_connections.status
+const (
+	ConnectionStatusActive         = "active"
+	ConnectionStatusPending        = "pending"
+	ConnectionStatusError          = "error"
+	ConnectionStatusReauthRequired = "reauth_required"
+	ConnectionStatusDisconnected   = "disconnected"
+)
+
+// How often the connections of each provider are synced. Providers that aren't listed
+// use defaultBankSyncInterval
+var bankSyncIntervals = map[string]time.Duration{
+	"plaid":      6 * time.Hour,
+	"teller":     6 * time.Hour,
+	"gocardless": 8 * time.Hour, // Institutions may only allow 4 calls a day per account
+	"mono":       24 * time.Hour,
 }
 
-func (w *ExportWorker) Work(ctx context.Context, job *river.Job[ExportJob]) error {
-	w.logger.Info().
-		Int64("user_id", job.Args.UserID).
-		Str("export_type", job.Args.ExportType).
-		Msg("Processing export job")
+const (
+	defaultBankSyncInterval = 24 * time.Hour
+	maxBankSyncBackoff      = 7 * 24 * time.Hour
+
+	// Incremental syncs look this far behind the newest transaction already seen, banks
+	// commonly post transactions a few days after their date
+	bankSyncOverlap = 7 * 24 * time.Hour
+)
+
+// Manual syncs use up the same provider quotas as scheduled ones. A connection synced less than
+// this long ago isn't synced again on request, providers that aren't listed use defaultManualBankSyncInterval
+var manualBankSyncIntervals = map[string]time.Duration{
+	"gocardless": 6 * time.Hour,
+}
+
+const defaultManualBankSyncInterval = 15 * time.Minute
+
+// ManualBankSyncAllowed reports whether the user may ask for a sync of connection at now
+func ManualBankSyncAllowed(connection repository.UserFinancialConnection, now time.Time) bool {
+	if connection.LastSyncAt == nil {
+		return true
+	}
+
+	interval, ok := manualBankSyncIntervals[connection.ProviderName]
+	if !ok {
+		interval = defaultManualBankSyncInterval
+	}
+
+	return !now.Before(connection.LastSyncAt.Add(interval))
+}
+
+func bankSyncInterval(provider string) time.Duration {
+	if interval, ok := bankSyncIntervals[provider]; ok {
+		return interval
+	}
+	return defaultBankSyncInterval
+}
+
+// bankSyncBackoff doubles the sync interval for every consecutive failure, up to maxBankSyncBackoff
+func bankSyncBackoff(interval time.Duration, failures int32) time.Duration {
+	backoff := interval
+	for i := int32(1); i < failures && backoff < maxBankSyncBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBankSyncBackoff)
+}
+
+// bankSyncDue reports whether a connection should be synced at now. Errored connections are
+// retried with an exponential backoff counted from their last failure
+func bankSyncDue(connection repository.UserFinancialConnection, now time.Time) bool {
+	interval := bankSyncInterval(connection.ProviderName)
+
+	if connection.Status != nil && *connection.Status == ConnectionStatusError {
+		return !now.Before(connection.UpdatedAt.Add(bankSyncBackoff(interval, connection.SyncFailures)))
+	}
+
+	if connection.LastSyncAt == nil {
+		return true
+	}
+
+	return !now.Before(connection.LastSyncAt.Add(interval))
+}
+
+type BankSyncSchedulerWorker struct {
+	river.WorkerDefaults[BankSyncSchedulerJob]
+	deps *BankSyncWorkerDeps
+}
+
+func (w *BankSyncSchedulerWorker) Work(ctx context.Context, job *river.Job[BankSyncSchedulerJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Logger()
+
+	now := time.Now()
+
+	connections, err := w.deps.Queries.ListSyncableConnections(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list connections: %w", err)
+	}
+
+	var syncJobs []river.InsertManyParams
+	var brokenCount int
+
+	for _, connection := range connections {
+		if !bankSyncDue(connection, now) {
+			continue
+		}
+
+		healthy, err := w.checkConnection(ctx, connection)
+		if err != nil {
+			logger.Error().Err(err).Any("connection_id", connection.ID).Msg("Failed to check connection status")
+			continue
+		}
+
+		if !healthy {
+			brokenCount++
+			continue
+		}
+
+		syncJobs = append(syncJobs, river.InsertManyParams{
+			Args: BankSyncJob{
+				UserID:       connection.UserID,
+				ConnectionID: connection.ID,
+				SyncType:     "incremental",
+			},
+			InsertOpts: &river.InsertOpts{
+				Queue:       "sync",
+				MaxAttempts: 3,
+				UniqueOpts: river.UniqueOpts{
+					ByArgs:   true,
+					ByPeriod: bankSyncInterval(connection.ProviderName),
+				},
+			},
+		})
+	}
+
+	if len(syncJobs) > 0 {
+		client := river.ClientFromContext[pgx.Tx](ctx)
+		if _, err := client.InsertMany(ctx, syncJobs); err != nil {
+			return fmt.Errorf("failed to enqueue bank syncs: %w", err)
+		}
+	}
 
-	// Your export generation logic here
+	logger.Info().
+		Int("connections", len(connections)).
+		Int("scheduled", len(syncJobs)).
+		Int("reauth_required", brokenCount).
+		Msg("Bank sync scheduling completed")
 
 	return nil
 }
 
+// checkConnection asks the provider whether the link still works. Broken links are flagged
+// as reauth_required, other failures count towards the connection backoff
+func (w *BankSyncSchedulerWorker) checkConnection(ctx context.Context, connection repository.UserFinancialConnection) (bool, error) {
+	provider, err := w.deps.FinanceManager.GetProvider(connection.ProviderName)
+	if err != nil {
+		return false, err
+	}
+
+	accessToken, err := w.deps.encrypt.Decrypt(connection.AccessTokenEncrypted)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	healthy, err := provider.GetConnectionStatus(ctx, string(accessToken))
+	if err != nil && !errors.Is(err, finance.ErrAuthenticationFailed) {
+		status := ConnectionStatusError
+		lastError := err.Error()
+		if _, updateErr := w.deps.Queries.SetConnectionSyncFailed(ctx, repository.SetConnectionSyncFailedParams{
+			ID:        connection.ID,
+			Status:    &status,
+			LastError: &lastError,
+		}); updateErr != nil {
+			return false, updateErr
+		}
+		return false, err
+	}
+
+	if healthy && err == nil {
+		return true, nil
+	}
+
+	status := ConnectionStatusReauthRequired
+	lastError := "the provider reported the connection as broken"
+	if err != nil {
+		lastError = err.Error()
+	}
+
+	broken, err := w.deps.Queries.SetConnectionSyncFailed(ctx, repository.SetConnectionSyncFailedParams{
+		ID:        connection.ID,
+		Status:    &status,
+		LastError: &lastError,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	w.deps.Events.Publish(ctx, connection.UserID, events.ConnectionReauthRequired, events.ConnectionData{
+		Connection: events.NewConnection(broken),
+	})
+
+	return false, nil
+}
+
 type ExchangeRatesWorkerDeps struct {
 	DB      *pgxpool.Pool
 	Queries *repository.Queries
@@ -875,43 +1408,17 @@ func (w *DailyRecurringProcessorWorker) Work(ctx context.Context, job *river.Job
 			continue
 		}
 
-		// Process this recurring transaction
-		if recurringTx.AutoPost {
-			// Create the transaction directly
-			_, err = w.deps.Queries.CreateTransaction(ctx, repository.CreateTransactionParams{
-				Amount:                 types.PgtypeNumericToDecimal(recurringTx.Amount),
-				OriginalAmount:         types.PgtypeNumericToDecimal(recurringTx.Amount),
-				Type:                   recurringTx.Type,
-				AccountID:              recurringTx.AccountID,
-				CategoryID:             recurringTx.CategoryID,
-				TransactionCurrency:    "USD", // TODO: Get from account currency
-				TransactionDatetime:    pgtype.Timestamptz{Valid: true, Time: job.Args.ProcessDate},
-				Description:            recurringTx.Description,
-				Details:                recurringTx.Details,
-				CreatedBy:              &recurringTx.UserID,
-				RecurringTransactionID: &recurringTx.ID,
-				RecurringInstanceDate:  pgtype.Timestamptz{Valid: true, Time: job.Args.ProcessDate},
-			})
-			if err != nil {
-				logger.Error().Err(err).
-					Any("recurring_id", recurringTx.ID).
-					Msg("Failed to create transaction from recurring template")
-				errorCount++
-				continue
-			}
-			processedCount++
-		}
-
-		// Update the next due date for the recurring transaction
-		nextDueDate := w.calculateNextDueDate(recurringTx, job.Args.ProcessDate)
-		_, err = w.deps.Queries.UpdateRecurringTransactionNextDueDate(ctx, repository.UpdateRecurringTransactionNextDueDateParams{
-			ID:          recurringTx.ID,
-			NextDueDate: pgtype.Timestamptz{Valid: true, Time: nextDueDate},
-		})
+		posted, err := w.processRecurring(ctx, recurringTx, job.Args.ProcessDate)
 		if err != nil {
 			logger.Error().Err(err).
 				Any("recurring_id", recurringTx.ID).
-				Msg("Failed to update next due date for recurring transaction")
+				Msg("Failed to process recurring transaction")
+			errorCount++
+			continue
+		}
+
+		if posted {
+			processedCount++
 		}
 	}
 
@@ -923,6 +1430,76 @@ func (w *DailyRecurringProcessorWorker) Work(ctx context.Context, job *river.Job
 	return nil
 }
 
+// processRecurring posts the pending instance of an auto-posted template, or moves other
+// templates past it. The template is moved first, conditionally on its due date, so an instance
+// posted meanwhile by the user is left alone instead of being posted twice
+func (w *DailyRecurringProcessorWorker) processRecurring(ctx context.Context, recurringTx repository.RecurringTransaction, processDate time.Time) (bool, error) {
+	tx, err := w.deps.DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := w.deps.Queries.WithTx(tx)
+	dueDate := pgtype.Timestamptz{Valid: true, Time: recurringTx.NextDueDate}
+	nextDueDate := pgtype.Timestamptz{Valid: true, Time: w.calculateNextDueDate(recurringTx, processDate)}
+
+	if !recurringTx.AutoPost {
+		_, err := qtx.SkipRecurringInstance(ctx, repository.SkipRecurringInstanceParams{
+			ID:          recurringTx.ID,
+			DueDate:     dueDate,
+			NextDueDate: nextDueDate,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to update next due date: %w", err)
+		}
+
+		return false, tx.Commit(ctx)
+	}
+
+	updated, err := qtx.UpdateRecurringTransactionAfterGeneration(ctx, repository.UpdateRecurringTransactionAfterGenerationParams{
+		ID:                recurringTx.ID,
+		LastGeneratedDate: pgtype.Timestamptz{Valid: true, Time: processDate},
+		NextDueDate:       nextDueDate,
+		DueDate:           dueDate,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update next due date: %w", err)
+	}
+
+	// Posted or skipped meanwhile
+	if updated == 0 {
+		return false, nil
+	}
+
+	_, err = qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
+		Amount:                 types.PgtypeNumericToDecimal(recurringTx.Amount),
+		OriginalAmount:         types.PgtypeNumericToDecimal(recurringTx.Amount),
+		Type:                   recurringTx.Type,
+		AccountID:              recurringTx.AccountID,
+		CategoryID:             recurringTx.CategoryID,
+		TransactionCurrency:    "USD", // TODO: Get from account currency
+		TransactionDatetime:    pgtype.Timestamptz{Valid: true, Time: processDate},
+		Description:            recurringTx.Description,
+		Details:                recurringTx.Details,
+		CreatedBy:              &recurringTx.UserID,
+		RecurringTransactionID: &recurringTx.ID,
+		RecurringInstanceDate:  pgtype.Timestamptz{Valid: true, Time: processDate},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit recurring transaction: %w", err)
+	}
+
+	return true, nil
+}
+
 // calculateNextDueDate calculates the next due date based on frequency
 func (w *DailyRecurringProcessorWorker) calculateNextDueDate(rt repository.RecurringTransaction, currentDate time.Time) time.Time {
 	switch rt.Frequency {
@@ -949,3 +1526,135 @@ func (w *DailyRecurringProcessorWorker) Timeout(job *river.Job[DailyRecurringPro
 func (w *DailyRecurringProcessorWorker) NextRetry(job *river.Job[DailyRecurringProcessorJob]) time.Time {
 	return time.Now().Add(1 * time.Hour)
 }
+
+// BudgetRolloverJob closes the finished periods of rollover enabled budgets
+type BudgetRolloverJob struct {
+	ProcessDate time.Time `json:"process_date"`
+}
+
+func (BudgetRolloverJob) Kind() string {
+	return "budget_rollover"
+}
+
+type BudgetRolloverWorkerDeps struct {
+	DB      *pgxpool.Pool
+	Queries *repository.Queries
+	Logger  *zerolog.Logger
+}
+
+type BudgetRolloverWorker struct {
+	river.WorkerDefaults[BudgetRolloverJob]
+	deps *BudgetRolloverWorkerDeps
+}
+
+func (w *BudgetRolloverWorker) Work(ctx context.Context, job *river.Job[BudgetRolloverJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Time("process_date", job.Args.ProcessDate).
+		Logger()
+
+	logger.Info().Msg("Starting budget rollover processor")
+
+	processDate := job.Args.ProcessDate.UTC().Truncate(24 * time.Hour)
+
+	rolloverBudgets, err := w.deps.Queries.ListRolloverEnabledBudgets(ctx, pgtype.Date{Valid: true, Time: processDate})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get rollover enabled budgets")
+		return fmt.Errorf("failed to get rollover enabled budgets: %w", err)
+	}
+
+	var closedCount int
+	var errorCount int
+
+	for _, budget := range rolloverBudgets {
+		closed, err := w.closeBudgetPeriods(ctx, budget, processDate)
+		if err != nil {
+			logger.Error().Err(err).
+				Any("budget_id", budget.ID).
+				Msg("Failed to roll over budget")
+			errorCount++
+			continue
+		}
+		closedCount += closed
+	}
+
+	logger.Info().
+		Int("budgets", len(rolloverBudgets)).
+		Int("closed_periods", closedCount).
+		Int("errors", errorCount).
+		Msg("Completed budget rollover processing")
+
+	return nil
+}
+
+// closeBudgetPeriods records a rollover for every period of the budget that
+// ended on or before processDate and hasn't been closed yet
+func (w *BudgetRolloverWorker) closeBudgetPeriods(ctx context.Context, budget repository.Budget, processDate time.Time) (int, error) {
+	tx, err := w.deps.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := w.deps.Queries.WithTx(tx)
+
+	var last *budgetperiod.Rollover
+
+	latest, err := qtx.GetLatestBudgetRollover(ctx, budget.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get latest rollover: %w", err)
+	}
+	if err == nil {
+		last = &budgetperiod.Rollover{
+			Period: budgetperiod.Period{Start: latest.PeriodStart.Time, End: latest.PeriodEnd.Time},
+			Amount: types.PgtypeNumericToDecimal(latest.RolloverAmount),
+		}
+	}
+
+	rollovers, err := budgetperiod.CloseRollovers(budget, last, processDate, func(period budgetperiod.Period) (decimal.Decimal, error) {
+		spending, err := qtx.GetBudgetSpending(ctx, repository.GetBudgetSpendingParams{
+			UserID:      budget.UserID,
+			CategoryID:  budget.CategoryID,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		})
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to get spending: %w", err)
+		}
+
+		return types.PgtypeNumericToDecimal(spending.Spent), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, rollover := range rollovers {
+		if err := qtx.CreateBudgetRollover(ctx, repository.CreateBudgetRolloverParams{
+			BudgetID:       budget.ID,
+			UserID:         budget.UserID,
+			PeriodStart:    pgtype.Date{Valid: true, Time: rollover.Period.Start},
+			PeriodEnd:      pgtype.Date{Valid: true, Time: rollover.Period.End},
+			BudgetedAmount: rollover.Budgeted,
+			CarriedIn:      rollover.CarriedIn,
+			SpentAmount:    rollover.Spent,
+			RolloverAmount: rollover.Amount,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to create rollover: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rollovers: %w", err)
+	}
+
+	return len(rollovers), nil
+}
+
+func (w *BudgetRolloverWorker) Timeout(job *river.Job[BudgetRolloverJob]) time.Duration {
+	return 15 * time.Minute
+}
+
+func (w *BudgetRolloverWorker) NextRetry(job *river.Job[BudgetRolloverJob]) time.Time {
+	return time.Now().Add(1 * time.Hour)
+}
@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankSyncBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int32
+		want     time.Duration
+	}{
+		{"no failure", 6 * time.Hour, 0, 6 * time.Hour},
+		{"first failure", 6 * time.Hour, 1, 6 * time.Hour},
+		{"second failure doubles", 6 * time.Hour, 2, 12 * time.Hour},
+		{"many failures", 6 * time.Hour, 5, 96 * time.Hour},
+		{"capped", 6 * time.Hour, 6, maxBankSyncBackoff},
+		{"far past the cap", 6 * time.Hour, 1000, maxBankSyncBackoff},
+		{"interval above the cap", 10 * 24 * time.Hour, 1, maxBankSyncBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bankSyncBackoff(tt.interval, tt.failures))
+		})
+	}
+}
+
+func TestBankSyncDue(t *testing.T) {
+	now := time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	active := ConnectionStatusActive
+	errored := ConnectionStatusError
+
+	tests := []struct {
+		name       string
+		connection repository.UserFinancialConnection
+		want       bool
+	}{
+		{
+			name:       "never synced",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &active},
+			want:       true,
+		},
+		{
+			name:       "just before the interval",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &active, LastSyncAt: ago(6*time.Hour - time.Second)},
+			want:       false,
+		},
+		{
+			name:       "at the interval",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &active, LastSyncAt: ago(6 * time.Hour)},
+			want:       true,
+		},
+		{
+			name:       "unknown provider uses the default interval",
+			connection: repository.UserFinancialConnection{ProviderName: "other", Status: &active, LastSyncAt: ago(12 * time.Hour)},
+			want:       false,
+		},
+		{
+			name:       "errored before its backoff",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &errored, SyncFailures: 2, UpdatedAt: *ago(12*time.Hour - time.Second), LastSyncAt: ago(48 * time.Hour)},
+			want:       false,
+		},
+		{
+			name:       "errored at its backoff",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &errored, SyncFailures: 2, UpdatedAt: *ago(12 * time.Hour)},
+			want:       true,
+		},
+		{
+			name:       "errored waits at most the cap",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", Status: &errored, SyncFailures: 50, UpdatedAt: *ago(maxBankSyncBackoff)},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bankSyncDue(tt.connection, now))
+		})
+	}
+}
+
+func TestManualBankSyncAllowed(t *testing.T) {
+	now := time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		connection repository.UserFinancialConnection
+		want       bool
+	}{
+		{
+			name:       "never synced",
+			connection: repository.UserFinancialConnection{ProviderName: "gocardless"},
+			want:       true,
+		},
+		{
+			name:       "synced moments ago",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", LastSyncAt: ago(time.Minute)},
+			want:       false,
+		},
+		{
+			name:       "at the default interval",
+			connection: repository.UserFinancialConnection{ProviderName: "plaid", LastSyncAt: ago(defaultManualBankSyncInterval)},
+			want:       true,
+		},
+		{
+			name:       "gocardless keeps within its daily quota",
+			connection: repository.UserFinancialConnection{ProviderName: "gocardless", LastSyncAt: ago(6*time.Hour - time.Second)},
+			want:       false,
+		},
+		{
+			name:       "gocardless at its interval",
+			connection: repository.UserFinancialConnection{ProviderName: "gocardless", LastSyncAt: ago(6 * time.Hour)},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ManualBankSyncAllowed(tt.connection, now))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
+)
+
+// Providers stop retrying a delivery long before that, older deliveries can't be replayed anymore
+const providerWebhookDeliveryRetention = 30 * 24 * time.Hour
+
+// ProviderWebhookPruneJob removes the provider webhook deliveries kept to detect replays
+type ProviderWebhookPruneJob struct {
+	ProcessDate time.Time `json:"process_date"`
+}
+
+func (ProviderWebhookPruneJob) Kind() string { return "provider_webhook_prune" }
+
+type ProviderWebhookPruneWorkerDeps struct {
+	Queries *repository.Queries
+	Logger  *zerolog.Logger
+}
+
+type ProviderWebhookPruneWorker struct {
+	river.WorkerDefaults[ProviderWebhookPruneJob]
+	deps *ProviderWebhookPruneWorkerDeps
+}
+
+func (w *ProviderWebhookPruneWorker) Work(ctx context.Context, job *river.Job[ProviderWebhookPruneJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Logger()
+
+	before := job.Args.ProcessDate.Add(-providerWebhookDeliveryRetention)
+
+	pruned, err := w.deps.Queries.PruneProviderWebhookDeliveries(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to prune provider webhook deliveries: %w", err)
+	}
+
+	logger.Info().Int64("pruned", pruned).Time("received_before", before).Msg("Provider webhook deliveries pruned")
+
+	return nil
+}
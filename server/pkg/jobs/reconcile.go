@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	transactionStatusPending = "pending"
+	transactionStatusPosted  = "posted"
+
+	// A pending charge usually posts within a few days of its authorization
+	pendingMatchMaxDays = 10
+
+	// Tips and currency conversions can change the posted amount of a card authorization
+	pendingAmountTolerance = 0.25
+
+	// Shorter words are mostly processor prefixes like "SQ" or "TST"
+	descriptionMinWordLength = 3
+)
+
+// Words banks add to card descriptions that say nothing about the merchant
+var descriptionNoiseWords = map[string]bool{
+	"card":     true,
+	"debit":    true,
+	"credit":   true,
+	"payment":  true,
+	"purchase": true,
+	"pos":      true,
+	"the":      true,
+	"www":      true,
+	"com":      true,
+}
+
+// pendingTransaction is a stored pending transaction that may get replaced by a posted one
+type pendingTransaction struct {
+	ID                    uuid.UUID
+	ProviderTransactionID string
+	Amount                decimal.Decimal
+	Date                  time.Time
+	Description           string
+	Merchant              string
+
+	// Set once the provider still reports it or a posted transaction replaced it
+	resolved bool
+}
+
+// providerChanges is what a provider reported for an account since the previous sync
+type providerChanges struct {
+	transactions []finance.Transaction
+
+	// Request of a date based sync, its pending transactions missing from the response were dropped
+	window *finance.GetTransactionsArgs
+
+	// Cursor based syncs name the removed transactions of the account and the cursor to resume from
+	removed []string
+	cursor  *string
+}
+
+// since is the start of the window the changes cover, nil when they may be from any date
+func (c providerChanges) since() *time.Time {
+	if c.window == nil {
+		return nil
+	}
+	return c.window.StartDate
+}
+
+// accountTransactions keeps the added and modified transactions of one account from a sync of the
+// whole connection
+func accountTransactions(sync *finance.TransactionsSync, providerAccountID string) []finance.Transaction {
+	transactions := make([]finance.Transaction, 0, len(sync.Added)+len(sync.Modified))
+	for _, changes := range [][]finance.Transaction{sync.Added, sync.Modified} {
+		for _, transaction := range changes {
+			if transaction.AccountID == providerAccountID {
+				transactions = append(transactions, transaction)
+			}
+		}
+	}
+	return transactions
+}
+
+// accountRemovedTransactions keeps the provider IDs of the removed transactions of one account from
+// a sync of the whole connection
+func accountRemovedTransactions(sync *finance.TransactionsSync, providerAccountID string) []string {
+	var removed []string
+	for _, transaction := range sync.Removed {
+		if transaction.AccountID == providerAccountID {
+			removed = append(removed, transaction.ProviderTransactionID)
+		}
+	}
+	return removed
+}
+
+// transactionStatus normalizes the status reported by providers, anything that isn't pending is posted
+func transactionStatus(transaction finance.Transaction) string {
+	if strings.EqualFold(transaction.Status, transactionStatusPending) {
+		return transactionStatusPending
+	}
+	return transactionStatusPosted
+}
+
+// matchPendingTransaction finds the pending predecessor of a posted transaction and returns its
+// index, or -1. Providers that link the two (Plaid's pending_transaction_id) are trusted, otherwise
+// the candidate must have the same direction, a close date, a similar amount and share a word of
+// its description or merchant, so that unrelated charges of the same amount aren't paired. Amounts
+// that differ need the same description.
+func matchPendingTransaction(posted finance.Transaction, pending []pendingTransaction) int {
+	if pendingID := posted.Metadata["pending_transaction_id"]; pendingID != "" {
+		for i, candidate := range pending {
+			if !candidate.resolved && candidate.ProviderTransactionID == pendingID {
+				return i
+			}
+		}
+	}
+
+	amount := decimal.NewFromFloat(posted.Amount)
+	description := normalizeDescription(posted.Description)
+
+	best := -1
+	bestExact := false
+	var bestDistance time.Duration
+
+	for i, candidate := range pending {
+		if candidate.resolved || candidate.Amount.Sign() != amount.Sign() {
+			continue
+		}
+
+		// Transactions post on or after their authorization date
+		distance := posted.Date.Sub(candidate.Date)
+		if distance < -24*time.Hour || distance > pendingMatchMaxDays*24*time.Hour {
+			continue
+		}
+		distance = distance.Abs()
+
+		exact := candidate.Amount.Equal(amount)
+		if exact {
+			if !similarDescriptions(posted, candidate) {
+				continue
+			}
+		} else {
+			if description == "" || normalizeDescription(candidate.Description) != description {
+				continue
+			}
+
+			diff := candidate.Amount.Sub(amount).Abs()
+			if diff.GreaterThan(candidate.Amount.Abs().Mul(decimal.NewFromFloat(pendingAmountTolerance))) {
+				continue
+			}
+		}
+
+		// Prefer exact amounts, then the closest date
+		if best == -1 || (exact && !bestExact) || (exact == bestExact && distance < bestDistance) {
+			best = i
+			bestExact = exact
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// similarDescriptions reports whether the posted transaction and the pending one share a
+// significant word of their description or merchant
+func similarDescriptions(posted finance.Transaction, candidate pendingTransaction) bool {
+	words := descriptionWords(posted.Description)
+	if posted.MerchantName != nil {
+		for word := range descriptionWords(*posted.MerchantName) {
+			words[word] = true
+		}
+	}
+
+	for _, text := range []string{candidate.Description, candidate.Merchant} {
+		for word := range descriptionWords(text) {
+			if words[word] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// descriptionWords splits a description into its lower case words, without the short and noise ones
+func descriptionWords(description string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(description, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		word = strings.ToLower(word)
+		if len(word) >= descriptionMinWordLength && !descriptionNoiseWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// normalizeDescription keeps the letters of a description so that "SQ *COFFEE #123" and
+// "Sq Coffee 123" compare equal
+func normalizeDescription(description string) string {
+	var b strings.Builder
+	for _, r := range description {
+		if unicode.IsLetter(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
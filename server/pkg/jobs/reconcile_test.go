@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionStatus(t *testing.T) {
+	assert.Equal(t, transactionStatusPending, transactionStatus(finance.Transaction{Status: "pending"}))
+	assert.Equal(t, transactionStatusPending, transactionStatus(finance.Transaction{Status: "PENDING"}))
+	assert.Equal(t, transactionStatusPosted, transactionStatus(finance.Transaction{Status: "posted"}))
+	assert.Equal(t, transactionStatusPosted, transactionStatus(finance.Transaction{}))
+}
+
+func TestAccountTransactions(t *testing.T) {
+	sync := &finance.TransactionsSync{
+		Added: []finance.Transaction{
+			{ProviderTransactionID: "tx-1", AccountID: "acc-checking"},
+			{ProviderTransactionID: "tx-2", AccountID: "acc-credit"},
+		},
+		Modified: []finance.Transaction{
+			{ProviderTransactionID: "tx-3", AccountID: "acc-checking"},
+		},
+		Removed: []finance.RemovedTransaction{
+			{ProviderTransactionID: "tx-4", AccountID: "acc-checking"},
+			{ProviderTransactionID: "tx-5", AccountID: "acc-credit"},
+		},
+	}
+
+	transactions := accountTransactions(sync, "acc-checking")
+
+	ids := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ProviderTransactionID)
+	}
+
+	assert.Equal(t, []string{"tx-1", "tx-3"}, ids)
+	assert.Equal(t, []string{"tx-4"}, accountRemovedTransactions(sync, "acc-checking"))
+	assert.Empty(t, accountRemovedTransactions(sync, "acc-savings"))
+}
+
+func TestProviderChanges_Since(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, providerChanges{}.since())
+	assert.Equal(t, &start, providerChanges{window: &finance.GetTransactionsArgs{StartDate: &start}}.since())
+}
+
+func TestMatchPendingTransaction(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	pending := func() []pendingTransaction {
+		return []pendingTransaction{
+			{ID: uuid.New(), ProviderTransactionID: "p-coffee", Amount: decimal.NewFromFloat(-4.5), Date: day(1), Description: "SQ *BLUE BOTTLE"},
+			{ID: uuid.New(), ProviderTransactionID: "p-dinner", Amount: decimal.NewFromFloat(-40), Date: day(2), Description: "Luigi's Trattoria"},
+			{ID: uuid.New(), ProviderTransactionID: "p-refund", Amount: decimal.NewFromFloat(12), Date: day(2), Description: "Refund"},
+			{ID: uuid.New(), ProviderTransactionID: "p-coffee-2", Amount: decimal.NewFromFloat(-4.5), Date: day(3), Description: "SQ *BLUE BOTTLE"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		posted   finance.Transaction
+		resolved []int
+		want     int
+	}{
+		{
+			name:   "linked by the provider",
+			posted: finance.Transaction{Amount: -41, Date: day(20), Metadata: map[string]string{"pending_transaction_id": "p-dinner"}},
+			want:   1,
+		},
+		{
+			name:   "same amount, closest date wins",
+			posted: finance.Transaction{Amount: -4.5, Date: day(4), Description: "Blue Bottle Coffee"},
+			want:   3,
+		},
+		{
+			name:     "already resolved candidates are skipped",
+			posted:   finance.Transaction{Amount: -4.5, Date: day(4), Description: "Blue Bottle Coffee"},
+			resolved: []int{3},
+			want:     0,
+		},
+		{
+			name:   "tip added to the authorization",
+			posted: finance.Transaction{Amount: -48, Date: day(4), Description: "LUIGI'S TRATTORIA"},
+			want:   1,
+		},
+		{
+			name:   "different amount needs a matching description",
+			posted: finance.Transaction{Amount: -48, Date: day(4), Description: "Other restaurant"},
+			want:   -1,
+		},
+		{
+			name:   "amount too far from the authorization",
+			posted: finance.Transaction{Amount: -80, Date: day(4), Description: "Luigi's Trattoria"},
+			want:   -1,
+		},
+		{
+			name:   "direction must match",
+			posted: finance.Transaction{Amount: -12, Date: day(3), Description: "Refund"},
+			want:   -1,
+		},
+		{
+			name:   "posted too long after the authorization",
+			posted: finance.Transaction{Amount: -40, Date: day(20), Description: "Luigi's Trattoria"},
+			want:   -1,
+		},
+		{
+			name:   "posted before the authorization",
+			posted: finance.Transaction{Amount: 12, Date: time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC), Description: "Refund"},
+			want:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := pending()
+			for _, i := range tt.resolved {
+				candidates[i].resolved = true
+			}
+
+			assert.Equal(t, tt.want, matchPendingTransaction(tt.posted, candidates))
+		})
+	}
+}
+
+func TestMatchPendingTransaction_SameAmount(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	uber := "Uber"
+	amazon := "Amazon"
+
+	pending := []pendingTransaction{
+		{ID: uuid.New(), ProviderTransactionID: "p-amazon", Amount: decimal.NewFromFloat(-25), Date: day, Description: "AMZN MKTP US*2K4", Merchant: "Amazon"},
+		{ID: uuid.New(), ProviderTransactionID: "p-uber", Amount: decimal.NewFromFloat(-25), Date: day.AddDate(0, 0, 1), Description: "UBER *TRIP HELP.UBER.COM"},
+	}
+
+	tests := []struct {
+		name   string
+		posted finance.Transaction
+		want   int
+	}{
+		{
+			name:   "matching description",
+			posted: finance.Transaction{Amount: -25, Date: day.AddDate(0, 0, 2), Description: "Uber Trip"},
+			want:   1,
+		},
+		{
+			name:   "matching merchant",
+			posted: finance.Transaction{Amount: -25, Date: day.AddDate(0, 0, 2), Description: "CARD PURCHASE 4821", MerchantName: &amazon},
+			want:   0,
+		},
+		{
+			name:   "merchant against the pending description",
+			posted: finance.Transaction{Amount: -25, Date: day.AddDate(0, 0, 2), Description: "POS 4821", MerchantName: &uber},
+			want:   1,
+		},
+		{
+			name:   "unrelated merchant of the same amount",
+			posted: finance.Transaction{Amount: -25, Date: day.AddDate(0, 0, 2), Description: "NETFLIX.COM"},
+			want:   -1,
+		},
+		{
+			name:   "no description to compare",
+			posted: finance.Transaction{Amount: -25, Date: day.AddDate(0, 0, 2)},
+			want:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchPendingTransaction(tt.posted, pending))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions"
+	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ruleApplicationMaxAttempts = 3
+	ruleApplicationTimeout     = 10 * time.Minute
+)
+
+// RuleApplicationJob runs transaction rules over the existing transactions selected by filters
+type RuleApplicationJob struct {
+	UserID  uuid.UUID         `json:"user_id"`
+	RuleIDs []uuid.UUID       `json:"rule_ids,omitempty"` // All active rules when empty
+	Filters dto.ExportFilters `json:"filters"`
+}
+
+func (RuleApplicationJob) Kind() string { return "rule_application" }
+
+type RuleApplicationWorkerDeps struct {
+	DB           *pgxpool.Pool
+	Queries      *repository.Queries
+	Transactions trscRepo.Transactions
+	Evaluator    *rules.RuleEvaluator
+	Events       *events.Bus
+	Logger       *zerolog.Logger
+}
+
+type RuleApplicationWorker struct {
+	river.WorkerDefaults[RuleApplicationJob]
+	deps *RuleApplicationWorkerDeps
+}
+
+func (w *RuleApplicationWorker) Timeout(job *river.Job[RuleApplicationJob]) time.Duration {
+	return ruleApplicationTimeout
+}
+
+func (w *RuleApplicationWorker) Work(ctx context.Context, job *river.Job[RuleApplicationJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Str("user_id", job.Args.UserID.String()).
+		Logger()
+
+	all, err := w.deps.Transactions.ListRules(ctx, job.Args.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	selected, err := rules.Select(all, job.Args.RuleIDs)
+	if err != nil {
+		// A rule was deleted since the job was queued
+		if errors.Is(err, transactions.ErrRuleNotFound) {
+			return river.JobCancel(err)
+		}
+		return err
+	}
+
+	location, err := rules.UserLocation(ctx, w.deps.Queries, job.Args.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Changes already applied by an earlier attempt are left out of the plan
+	scanned, planned, err := w.deps.Evaluator.Plan(ctx, w.deps.Queries, job.Args.UserID, location, selected, job.Args.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to plan rule application: %w", err)
+	}
+
+	tx, err := w.deps.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := w.deps.Queries.WithTx(tx)
+	applied := make([]rules.Applied, 0, len(planned))
+
+	for _, p := range planned {
+		result, err := rules.Apply(ctx, qtx, p.Transaction.ID, job.Args.UserID, p.Matches, p.Changes)
+		if err != nil {
+			return err
+		}
+
+		applied = append(applied, result)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit rule application: %w", err)
+	}
+
+	for i, result := range applied {
+		w.deps.Events.Publish(ctx, job.Args.UserID, events.TransactionUpdated, events.NewTransactionData(result.Transaction))
+		if result.Deleted != nil {
+			w.deps.Events.Publish(ctx, job.Args.UserID, events.TransactionDeleted, events.NewTransactionData(*result.Deleted))
+		}
+
+		for _, match := range planned[i].Matches {
+			w.deps.Events.Publish(ctx, job.Args.UserID, events.RuleApplied, events.RuleAppliedData{
+				Rule: events.Rule{
+					ID:       match.RuleID,
+					Name:     match.RuleName,
+					IsActive: true,
+					Priority: match.RulePriority,
+				},
+				TransactionID: result.Transaction.ID,
+			})
+		}
+	}
+
+	logger.Info().
+		Int("scanned", scanned).
+		Int("updated", len(applied)).
+		Msg("Rules applied to existing transactions")
+
+	return nil
+}
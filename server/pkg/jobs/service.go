@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"time"
 
+	trscRepo "github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/domain/transactions/rules"
 	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
 	"github.com/Fantasy-Programming/nuts/server/internal/utils/encrypt"
+	"github.com/Fantasy-Programming/nuts/server/pkg/events"
 	"github.com/Fantasy-Programming/nuts/server/pkg/finance"
+	"github.com/Fantasy-Programming/nuts/server/pkg/storage"
+	"github.com/Fantasy-Programming/nuts/server/pkg/webhook"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog"
 )
@@ -24,7 +31,7 @@ type Service struct {
 	logger      *zerolog.Logger
 }
 
-func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.ProviderManager, encryptionKey string) (*Service, error) {
+func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.ProviderManager, bus *events.Bus, encryptionKey string, webhookAllowlist []string, store storage.Storage, exportBucket string) (*Service, error) {
 	workers := river.NewWorkers()
 
 	queries := repository.New(db)
@@ -33,10 +40,27 @@ func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.P
 		return nil, fmt.Errorf("failed to setup encrypter for bank sync jobs: %w", err)
 	}
 
+	allowlist, err := webhook.NewAllowlist(webhookAllowlist)
+	if err != nil {
+		return nil, err
+	}
+
 	// Register workers
 	river.AddWorker(workers, &EmailWorker{logger: logger})
-	river.AddWorker(workers, &BankSyncWorker{deps: &BankSyncWorkerDeps{DB: db, Queries: queries, FinanceManager: openfinance, Logger: logger, encrypt: encrypter}})
-	river.AddWorker(workers, &ExportWorker{logger: logger})
+	bankSyncDeps := &BankSyncWorkerDeps{DB: db, Queries: queries, FinanceManager: openfinance, Events: bus, Logger: logger, encrypt: encrypter}
+	river.AddWorker(workers, &BankSyncWorker{deps: bankSyncDeps})
+	river.AddWorker(workers, &BankSyncSchedulerWorker{deps: bankSyncDeps})
+	river.AddWorker(workers, &ExportWorker{deps: &ExportWorkerDeps{Queries: queries, Storage: store, Bucket: exportBucket, Logger: logger}})
+	river.AddWorker(workers, &AttachmentCleanupWorker{deps: &AttachmentCleanupWorkerDeps{Storage: store, Logger: logger}})
+	river.AddWorker(workers, &PendingAttachmentCleanupWorker{deps: &PendingAttachmentCleanupWorkerDeps{DB: db, Queries: queries, Logger: logger}})
+	river.AddWorker(workers, &RuleApplicationWorker{deps: &RuleApplicationWorkerDeps{
+		DB:           db,
+		Queries:      queries,
+		Transactions: trscRepo.NewRepository(db),
+		Evaluator:    rules.NewRuleEvaluator(),
+		Events:       bus,
+		Logger:       logger,
+	}})
 
 	river.AddWorker(workers, &ExchangeRatesSyncWorker{deps: &ExchangeRatesWorkerDeps{DB: db, Queries: queries, Logger: logger}})
 	river.AddWorker(workers, &HistoricalExchangeRateWorker{deps: &ExchangeRatesWorkerDeps{DB: db, Queries: queries, Logger: logger}})
@@ -45,6 +69,16 @@ func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.P
 	river.AddWorker(workers, &RecurringTransactionWorker{deps: &RecurringTransactionWorkerDeps{DB: db, Queries: queries, Logger: logger}})
 	river.AddWorker(workers, &DailyRecurringProcessorWorker{deps: &RecurringTransactionWorkerDeps{DB: db, Queries: queries, Logger: logger}})
 
+	// Add budget workers
+	river.AddWorker(workers, &BudgetRolloverWorker{deps: &BudgetRolloverWorkerDeps{DB: db, Queries: queries, Logger: logger}})
+
+	river.AddWorker(workers, &ProviderWebhookPruneWorker{deps: &ProviderWebhookPruneWorkerDeps{Queries: queries, Logger: logger}})
+	river.AddWorker(workers, &WebhookDeliveryWorker{deps: &WebhookDeliveryWorkerDeps{
+		Queries:    queries,
+		HTTPClient: webhook.NewClient(webhookDeliveryTimeout, allowlist),
+		Logger:     logger,
+	}})
+
 	// Parse cron schedule for 6 AM UTC daily
 	schedule, err := cron.ParseStandard("0 6 * * *")
 	if err != nil {
@@ -57,6 +91,30 @@ func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.P
 		return nil, fmt.Errorf("failed to parse recurring transaction cron schedule: %w", err)
 	}
 
+	// Parse cron schedule for daily budget rollovers at 1 AM UTC
+	rolloverSchedule, err := cron.ParseStandard("0 1 * * *")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse budget rollover cron schedule: %w", err)
+	}
+
+	// Look for connections due for a bank sync every hour
+	bankSyncSchedule, err := cron.ParseStandard("30 * * * *")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bank sync cron schedule: %w", err)
+	}
+
+	// Remove abandoned attachment uploads every hour
+	pendingAttachmentSchedule, err := cron.ParseStandard("15 * * * *")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pending attachment cleanup cron schedule: %w", err)
+	}
+
+	// Prune provider webhook deliveries daily at 3 AM UTC
+	providerWebhookPruneSchedule, err := cron.ParseStandard("0 3 * * *")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse provider webhook prune cron schedule: %w", err)
+	}
+
 	periodicJobs := []*river.PeriodicJob{
 		river.NewPeriodicJob(
 			schedule,
@@ -92,6 +150,73 @@ func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.P
 				RunOnStart: false, // Don't run on startup
 			},
 		),
+		river.NewPeriodicJob(
+			rolloverSchedule,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return BudgetRolloverJob{
+						ProcessDate: time.Now().UTC().Truncate(24 * time.Hour),
+					}, &river.InsertOpts{
+						Queue: "budgets",
+						UniqueOpts: river.UniqueOpts{
+							ByArgs:   true,
+							ByPeriod: 24 * time.Hour,
+						},
+					}
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true, // Catch up on periods closed while the server was down
+			},
+		),
+		river.NewPeriodicJob(
+			bankSyncSchedule,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return BankSyncSchedulerJob{
+						ScheduledAt: time.Now().UTC().Truncate(time.Hour),
+					}, &river.InsertOpts{
+						Queue: "sync",
+						UniqueOpts: river.UniqueOpts{
+							ByArgs:   true,
+							ByPeriod: time.Hour,
+						},
+					}
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true,
+			},
+		),
+		river.NewPeriodicJob(
+			providerWebhookPruneSchedule,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return ProviderWebhookPruneJob{
+						ProcessDate: time.Now().UTC().Truncate(24 * time.Hour),
+					}, &river.InsertOpts{
+						Queue: "webhooks",
+						UniqueOpts: river.UniqueOpts{
+							ByArgs:   true,
+							ByPeriod: 24 * time.Hour,
+						},
+					}
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: false,
+			},
+		),
+		river.NewPeriodicJob(
+			pendingAttachmentSchedule,
+			func() (river.JobArgs, *river.InsertOpts) {
+				return PendingAttachmentCleanupJob{
+						ProcessDate: time.Now().UTC().Truncate(time.Hour),
+					}, &river.InsertOpts{
+						UniqueOpts: river.UniqueOpts{
+							ByArgs:   true,
+							ByPeriod: time.Hour,
+						},
+					}
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true,
+			},
+		),
 	}
 
 	riverClient, err := river.NewClient(riverpgxv5.New(db), &river.Config{
@@ -102,6 +227,8 @@ func NewService(db *pgxpool.Pool, logger *zerolog.Logger, openfinance *finance.P
 			"exports":          {MaxWorkers: 2},
 			"exchange_rates":   {MaxWorkers: 1},
 			"recurring":        {MaxWorkers: 5}, // Queue for recurring transaction jobs
+			"budgets":          {MaxWorkers: 1},
+			"webhooks":         {MaxWorkers: 10},
 		},
 		PeriodicJobs: periodicJobs,
 		Workers:      workers,
@@ -140,27 +267,99 @@ func (s *Service) EnqueueEmail(ctx context.Context, userID int64, email, templat
 	return err
 }
 
+// A connection has at most one requested sync of each type waiting or running, the ones that
+// already finished don't prevent a new one
+var requestedBankSyncUniqueOpts = river.UniqueOpts{
+	ByArgs: true,
+	ByState: []rivertype.JobState{
+		rivertype.JobStateAvailable,
+		rivertype.JobStatePending,
+		rivertype.JobStateRetryable,
+		rivertype.JobStateRunning,
+		rivertype.JobStateScheduled,
+	},
+}
+
 func (s *Service) EnqueueBankSync(ctx context.Context, userID, connectionID uuid.UUID, syncType string) error {
 	_, err := s.client.Insert(ctx, BankSyncJob{
 		UserID:       userID,
 		ConnectionID: connectionID,
 		SyncType:     syncType,
 	}, &river.InsertOpts{
-		Queue: "sync",
+		Queue:      "sync",
+		UniqueOpts: requestedBankSyncUniqueOpts,
 	})
 	return err
 }
 
-func (s *Service) EnqueueExport(ctx context.Context, userID int64, exportType string, from, to time.Time) error {
-	job := ExportJob{
-		UserID:     userID,
-		ExportType: exportType,
-	}
-	job.DateRange.From = from
-	job.DateRange.To = to
+// EnqueueBankSyncTx enqueues a bank sync as part of tx, the job only runs if tx commits
+func (s *Service) EnqueueBankSyncTx(ctx context.Context, tx pgx.Tx, userID, connectionID uuid.UUID, syncType string) error {
+	_, err := s.client.InsertTx(ctx, tx, BankSyncJob{
+		UserID:       userID,
+		ConnectionID: connectionID,
+		SyncType:     syncType,
+	}, &river.InsertOpts{
+		Queue:      "sync",
+		UniqueOpts: requestedBankSyncUniqueOpts,
+	})
+	return err
+}
+
+// EnqueueWebhookDeliveryTx queues the delivery of a stored webhook event as part of tx
+func (s *Service) EnqueueWebhookDeliveryTx(ctx context.Context, tx pgx.Tx, eventID uuid.UUID) error {
+	_, err := s.client.InsertTx(ctx, tx, WebhookDeliveryJob{
+		EventID: eventID,
+	}, &river.InsertOpts{
+		Queue:       "webhooks",
+		MaxAttempts: webhookDeliveryMaxAttempts,
+	})
+	return err
+}
+
+func (s *Service) EnqueueExport(ctx context.Context, exportID, userID uuid.UUID) error {
+	_, err := s.client.Insert(ctx, ExportJob{
+		ExportID: exportID,
+		UserID:   userID,
+	}, &river.InsertOpts{
+		Queue:       "exports",
+		MaxAttempts: exportMaxAttempts,
+	})
+	return err
+}
+
+// EnqueueExportTx enqueues an export as part of tx, the job only runs if tx commits
+func (s *Service) EnqueueExportTx(ctx context.Context, tx pgx.Tx, exportID, userID uuid.UUID) error {
+	_, err := s.client.InsertTx(ctx, tx, ExportJob{
+		ExportID: exportID,
+		UserID:   userID,
+	}, &river.InsertOpts{
+		Queue:       "exports",
+		MaxAttempts: exportMaxAttempts,
+	})
+	return err
+}
 
-	_, err := s.client.Insert(ctx, job, &river.InsertOpts{
-		Queue: "exports",
+// EnqueueAttachmentCleanupTx queues the removal of attachment files as part of tx, the files
+// are only removed once the rows pointing to them are gone
+func (s *Service) EnqueueAttachmentCleanupTx(ctx context.Context, tx pgx.Tx, bucket string, keys []string) error {
+	_, err := s.client.InsertTx(ctx, tx, AttachmentCleanupJob{
+		Bucket: bucket,
+		Keys:   keys,
+	}, &river.InsertOpts{
+		MaxAttempts: attachmentCleanupMaxAttempts,
+	})
+	return err
+}
+
+// EnqueueRuleApplication queues the application of rules to the existing transactions selected
+// by filters, all active rules run when ruleIDs is empty
+func (s *Service) EnqueueRuleApplication(ctx context.Context, userID uuid.UUID, ruleIDs []uuid.UUID, filters dto.ExportFilters) error {
+	_, err := s.client.Insert(ctx, RuleApplicationJob{
+		UserID:  userID,
+		RuleIDs: ruleIDs,
+		Filters: filters,
+	}, &river.InsertOpts{
+		MaxAttempts: ruleApplicationMaxAttempts,
 	})
 	return err
 }
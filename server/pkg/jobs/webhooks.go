@@ -0,0 +1,289 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/Fantasy-Programming/nuts/server/internal/repository/dto"
+	"github.com/Fantasy-Programming/nuts/server/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
+)
+
+const (
+	WebhookEventStatusPending  = "pending"
+	WebhookEventStatusRetrying = "retrying"
+	WebhookEventStatusSent     = "sent"
+	WebhookEventStatusFailed   = "failed"
+
+	// With the backoff below the last attempt happens about a day after the first one
+	webhookDeliveryMaxAttempts = 14
+	webhookDeliveryTimeout     = 10 * time.Second
+
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+
+	// Failed attempts in a row, across events, after which a subscription is disabled
+	webhookMaxConsecutiveFailures = 25
+
+	// How much of a response body is kept in the delivery log
+	webhookResponseBodyLimit = 1024
+)
+
+// WebhookDeliveryJob delivers a stored webhook event to its subscription endpoint
+type WebhookDeliveryJob struct {
+	EventID uuid.UUID `json:"event_id"`
+}
+
+func (WebhookDeliveryJob) Kind() string { return "webhook_delivery" }
+
+type WebhookDeliveryWorkerDeps struct {
+	Queries    *repository.Queries
+	HTTPClient *http.Client
+	Logger     *zerolog.Logger
+}
+
+type WebhookDeliveryWorker struct {
+	river.WorkerDefaults[WebhookDeliveryJob]
+	deps *WebhookDeliveryWorkerDeps
+}
+
+// NextRetry backs off exponentially so that an endpoint that is down for a while isn't hammered
+func (w *WebhookDeliveryWorker) NextRetry(job *river.Job[WebhookDeliveryJob]) time.Time {
+	return time.Now().Add(webhookRetryDelay(job.Attempt))
+}
+
+func (w *WebhookDeliveryWorker) Timeout(job *river.Job[WebhookDeliveryJob]) time.Duration {
+	return webhookDeliveryTimeout + 10*time.Second
+}
+
+func (w *WebhookDeliveryWorker) Work(ctx context.Context, job *river.Job[WebhookDeliveryJob]) error {
+	logger := w.deps.Logger.With().
+		Str("job_kind", job.Kind).
+		Int64("job_id", job.ID).
+		Str("event_id", job.Args.EventID.String()).
+		Logger()
+
+	event, err := w.deps.Queries.GetWebhookEventById(ctx, job.Args.EventID)
+	if err != nil {
+		// The event went away with its subscription
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get webhook event: %w", err)
+	}
+
+	if event.Status != nil && (*event.Status == WebhookEventStatusSent || *event.Status == WebhookEventStatusFailed) {
+		return nil
+	}
+
+	subscription, err := w.deps.Queries.GetWebhookSubscriptionById(ctx, event.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	if !subscription.Active {
+		logger.Info().Str("subscription_id", subscription.ID.String()).Msg("Skipping delivery to inactive webhook subscription")
+		return w.recordAttempt(ctx, event.ID, WebhookEventStatusFailed, nil, errors.New("subscription is disabled"))
+	}
+
+	result, deliveryErr := w.deliver(ctx, subscription, event)
+
+	var responseStatus *int32
+	if result.statusCode != 0 {
+		code := int32(result.statusCode)
+		responseStatus = &code
+	}
+
+	// The delivery log is for inspection only, losing an entry mustn't fail the delivery
+	if err := w.logAttempt(ctx, subscription, event, result, responseStatus, deliveryErr); err != nil {
+		logger.Error().Err(err).Msg("Failed to log webhook delivery attempt")
+	}
+
+	if deliveryErr == nil {
+		if err := w.recordAttempt(ctx, event.ID, WebhookEventStatusSent, responseStatus, nil); err != nil {
+			return err
+		}
+
+		if subscription.ConsecutiveFailures > 0 {
+			if err := w.deps.Queries.ResetWebhookSubscriptionFailures(ctx, subscription.ID); err != nil {
+				logger.Error().Err(err).Msg("Failed to reset webhook subscription failures")
+			}
+		}
+
+		return nil
+	}
+
+	reason := fmt.Sprintf("disabled after %d consecutive failed deliveries", webhookMaxConsecutiveFailures)
+	updated, err := w.deps.Queries.RecordWebhookSubscriptionFailure(ctx, repository.RecordWebhookSubscriptionFailureParams{
+		ID:             subscription.ID,
+		MaxFailures:    webhookMaxConsecutiveFailures,
+		DisabledReason: &reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record webhook subscription failure: %w", err)
+	}
+
+	disabled := !updated.Active
+	if disabled {
+		logger.Warn().
+			Str("subscription_id", subscription.ID.String()).
+			Int32("consecutive_failures", updated.ConsecutiveFailures).
+			Msg("Disabled failing webhook subscription")
+	}
+
+	// An endpoint refused by the SSRF guard won't be accepted on a later attempt either
+	blocked := errors.Is(deliveryErr, webhook.ErrBlockedAddress)
+
+	status := WebhookEventStatusRetrying
+	if disabled || blocked || job.Attempt >= job.MaxAttempts {
+		status = WebhookEventStatusFailed
+	}
+
+	if err := w.recordAttempt(ctx, event.ID, status, responseStatus, deliveryErr); err != nil {
+		return err
+	}
+
+	logger.Warn().Err(deliveryErr).Int("attempt", job.Attempt).Msg("Webhook delivery failed")
+
+	if disabled || blocked {
+		return river.JobCancel(deliveryErr)
+	}
+	return deliveryErr
+}
+
+// deliveryResult describes a delivery attempt for the delivery log
+type deliveryResult struct {
+	requestHeaders dto.WebhookHeaders
+	statusCode     int
+	body           string
+	duration       time.Duration
+}
+
+// deliver posts the event to the subscription endpoint
+func (w *WebhookDeliveryWorker) deliver(ctx context.Context, subscription repository.WebhookSubscription, event repository.WebhookEvent) (deliveryResult, error) {
+	result := deliveryResult{requestHeaders: dto.WebhookHeaders{}}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookDeliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.EndpointUrl, bytes.NewReader(event.Payload))
+	if err != nil {
+		return result, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NUTS-Webhook-Service/1.0")
+	req.Header.Set(webhook.EventHeader, event.EventType)
+	req.Header.Set(webhook.DeliveryHeader, event.ID.String())
+
+	// Signed at every attempt, so that the timestamp stays fresh across retries
+	now := time.Now()
+	req.Header.Set(webhook.SignatureHeader, webhook.SignatureHeaderValue(now, event.ID.String(), event.Payload, signingSecrets(subscription, now)...))
+
+	for name := range req.Header {
+		result.requestHeaders[name] = req.Header.Get(name)
+	}
+
+	resp, err := w.deps.HTTPClient.Do(req)
+	result.duration = time.Since(now)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+
+	result.statusCode = resp.StatusCode
+	result.body = strings.ToValidUTF8(string(body), "")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("endpoint responded with status %d: %s", resp.StatusCode, strings.TrimSpace(result.body))
+	}
+
+	return result, nil
+}
+
+// logAttempt adds an attempt to the delivery log of the subscription
+func (w *WebhookDeliveryWorker) logAttempt(ctx context.Context, subscription repository.WebhookSubscription, event repository.WebhookEvent, result deliveryResult, responseStatus *int32, deliveryErr error) error {
+	attempt := int32(1)
+	if event.Attempts != nil {
+		attempt = *event.Attempts + 1
+	}
+
+	var body, errorMessage *string
+	if result.statusCode != 0 {
+		body = &result.body
+	}
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		errorMessage = &msg
+	}
+
+	return w.deps.Queries.CreateWebhookDeliveryAttempt(ctx, repository.CreateWebhookDeliveryAttemptParams{
+		EventID:        event.ID,
+		SubscriptionID: subscription.ID,
+		Attempt:        attempt,
+		RequestUrl:     subscription.EndpointUrl,
+		RequestHeaders: result.requestHeaders,
+		ResponseStatus: responseStatus,
+		ResponseBody:   body,
+		ErrorMessage:   errorMessage,
+		DurationMs:     int32(result.duration.Milliseconds()),
+	})
+}
+
+func (w *WebhookDeliveryWorker) recordAttempt(ctx context.Context, eventID uuid.UUID, status string, responseStatus *int32, deliveryErr error) error {
+	var lastError *string
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		lastError = &msg
+	}
+
+	_, err := w.deps.Queries.RecordWebhookEventAttempt(ctx, repository.RecordWebhookEventAttemptParams{
+		ID:             eventID,
+		Status:         &status,
+		ResponseStatus: responseStatus,
+		LastError:      lastError,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// webhookRetryDelay doubles the delay after every attempt, with some jitter so that the retries
+// of events that failed together are spread out
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryMaxDelay
+	if attempt < 20 {
+		delay = min(webhookRetryBaseDelay<<max(attempt-1, 0), webhookRetryMaxDelay)
+	}
+
+	return delay + rand.N(delay/10+1)
+}
+
+// signingSecrets returns the secrets deliveries are signed with, the previous secret of a
+// rotation is kept until it expires
+func signingSecrets(subscription repository.WebhookSubscription, now time.Time) []string {
+	secrets := []string{subscription.Secret}
+
+	if subscription.PreviousSecret != nil && subscription.PreviousSecretExpiresAt != nil && now.Before(*subscription.PreviousSecretExpiresAt) {
+		secrets = append(secrets, *subscription.PreviousSecret)
+	}
+
+	return secrets
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantasy-Programming/nuts/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{attempt: 1, base: 30 * time.Second},
+		{attempt: 2, base: time.Minute},
+		{attempt: 3, base: 2 * time.Minute},
+		{attempt: 6, base: 16 * time.Minute},
+		{attempt: 10, base: 256 * time.Minute},
+		{attempt: 11, base: webhookRetryMaxDelay},
+		{attempt: 100, base: webhookRetryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		delay := webhookRetryDelay(tt.attempt)
+		assert.GreaterOrEqual(t, delay, tt.base, "attempt %d", tt.attempt)
+		assert.LessOrEqual(t, delay, tt.base+tt.base/10, "attempt %d", tt.attempt)
+	}
+}
+
+func TestSigningSecrets(t *testing.T) {
+	now := time.Now()
+	previous := "whsec_previous"
+	expiresAt := now.Add(time.Hour)
+	expiredAt := now.Add(-time.Hour)
+
+	subscription := repository.WebhookSubscription{Secret: "whsec_current"}
+	assert.Equal(t, []string{"whsec_current"}, signingSecrets(subscription, now))
+
+	subscription.PreviousSecret = &previous
+	subscription.PreviousSecretExpiresAt = &expiresAt
+	assert.Equal(t, []string{"whsec_current", "whsec_previous"}, signingSecrets(subscription, now))
+
+	subscription.PreviousSecretExpiresAt = &expiredAt
+	assert.Equal(t, []string{"whsec_current"}, signingSecrets(subscription, now))
+}
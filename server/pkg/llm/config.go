@@ -16,6 +16,12 @@ type Config struct {
 	RemoteProvider string `required:"false" split_words:"true" default:"gemini"`
 	RemoteAPIKey   string `required:"false" split_words:"true"`
 	RemoteModel    string `required:"false" split_words:"true" default:"gemini-1.5-flash"`
+
+	// Vision tells the model reads images and documents, receipts are sent to it directly
+	Vision bool `required:"false" default:"false"`
+
+	// Tesseract binary used to read receipts when the model only reads text, OCR is off when empty
+	TesseractPath string `required:"false" split_words:"true"`
 	
 	// General settings
 	MaxTokens     int     `required:"false" split_words:"true" default:"1000"`
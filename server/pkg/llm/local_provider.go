@@ -3,6 +3,7 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -31,10 +32,11 @@ func NewLocalProvider(config Config, logger *zerolog.Logger) *LocalProvider {
 
 // OllamaRequest represents the request structure for Ollama API
 type OllamaRequest struct {
-	Model       string  `json:"model"`
-	Prompt      string  `json:"prompt"`
-	Stream      bool    `json:"stream"`
-	Options     Options `json:"options,omitempty"`
+	Model   string   `json:"model"`
+	Prompt  string   `json:"prompt"`
+	Stream  bool     `json:"stream"`
+	Images  []string `json:"images,omitempty"` // Base64 encoded, for vision models
+	Options Options  `json:"options,omitempty"`
 }
 
 type Options struct {
@@ -58,7 +60,7 @@ type OllamaResponse struct {
 
 // GenerateCompletion sends a prompt to the local Ollama instance
 func (p *LocalProvider) GenerateCompletion(ctx context.Context, prompt string) (string, error) {
-	request := OllamaRequest{
+	return p.generate(ctx, OllamaRequest{
 		Model:  p.config.LocalModel,
 		Prompt: prompt,
 		Stream: false,
@@ -66,8 +68,39 @@ func (p *LocalProvider) GenerateCompletion(ctx context.Context, prompt string) (
 			Temperature: &p.config.Temperature,
 			NumPredict:  &p.config.MaxTokens,
 		},
+	})
+}
+
+// SupportsDocument reports whether the local model reads the MIME type, Ollama vision models
+// read images only
+func (p *LocalProvider) SupportsDocument(mimeType string) bool {
+	if !p.config.Vision {
+		return false
+	}
+
+	switch mimeType {
+	case "image/jpeg", "image/png":
+		return true
+	default:
+		return false
 	}
+}
+
+// GenerateCompletionWithDocument sends a prompt and an image to the local Ollama instance
+func (p *LocalProvider) GenerateCompletionWithDocument(ctx context.Context, prompt string, doc Document) (string, error) {
+	return p.generate(ctx, OllamaRequest{
+		Model:  p.config.LocalModel,
+		Prompt: prompt,
+		Stream: false,
+		Images: []string{base64.StdEncoding.EncodeToString(doc.Data)},
+		Options: Options{
+			Temperature: &p.config.Temperature,
+			NumPredict:  &p.config.MaxTokens,
+		},
+	})
+}
 
+func (p *LocalProvider) generate(ctx context.Context, request OllamaRequest) (string, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TesseractOCR reads the text of images with the tesseract command line
+type TesseractOCR struct {
+	path string
+}
+
+// NewTesseractOCR creates an OCR running the tesseract binary at path
+func NewTesseractOCR(path string) *TesseractOCR {
+	return &TesseractOCR{path: path}
+}
+
+// SupportsDocument reports whether tesseract reads the MIME type, it doesn't read PDF documents
+func (t *TesseractOCR) SupportsDocument(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// ExtractText sends the image on the standard input of tesseract and returns the text it read
+func (t *TesseractOCR) ExtractText(ctx context.Context, doc Document) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, t.path, "stdin", "stdout")
+	cmd.Stdin = bytes.NewReader(doc.Data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	text := strings.TrimSpace(stdout.String())
+	if text == "" {
+		return "", fmt.Errorf("no text found in document")
+	}
+
+	return text, nil
+}
@@ -0,0 +1,269 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ReceiptSourceVision = "vision"
+	ReceiptSourceOCR    = "ocr"
+)
+
+// ErrUnsupportedDocument is returned when neither the model nor the OCR read the document
+var ErrUnsupportedDocument = errors.New("receipt can't be read by the configured model")
+
+// ParseReceipt reads a receipt into transaction data. Multimodal models read the document
+// directly, text models get the text the OCR read from it
+func (s *NeuralInputService) ParseReceipt(ctx context.Context, req ReceiptRequest) (*ReceiptResponse, error) {
+	var (
+		response string
+		source   string
+		err      error
+	)
+
+	multimodal, ok := s.provider.(MultimodalProvider)
+
+	switch {
+	case ok && multimodal.SupportsDocument(req.Document.MimeType):
+		source = ReceiptSourceVision
+		response, err = multimodal.GenerateCompletionWithDocument(ctx, s.buildReceiptPrompt(req, ""), req.Document)
+	case s.ocr != nil && s.ocr.SupportsDocument(req.Document.MimeType):
+		text, ocrErr := s.ocr.ExtractText(ctx, req.Document)
+		if ocrErr != nil {
+			return nil, fmt.Errorf("failed to read receipt: %w", ocrErr)
+		}
+
+		source = ReceiptSourceOCR
+		response, err = s.provider.GenerateCompletion(ctx, s.buildReceiptPrompt(req, text))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDocument, req.Document.MimeType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate completion: %w", err)
+	}
+
+	transaction, err := s.parseReceiptResponse(response)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("response", response).
+			Msg("Failed to parse LLM receipt response")
+		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+
+	modelInfo := s.provider.GetModelInfo()
+
+	return &ReceiptResponse{
+		Transaction: transaction,
+		ParsedAt:    time.Now(),
+		Model:       modelInfo.Name,
+		Provider:    modelInfo.Type,
+		Source:      source,
+	}, nil
+}
+
+// buildReceiptPrompt asks for the receipt as a single transaction, text holds what the OCR read
+// and is empty when the document is sent to the model
+func (s *NeuralInputService) buildReceiptPrompt(req ReceiptRequest, text string) string {
+	baseCurrency := "USD"
+	if req.BaseCurrency != nil {
+		baseCurrency = *req.BaseCurrency
+	}
+
+	userTimezone := "UTC"
+	if req.UserTimezone != nil {
+		userTimezone = *req.UserTimezone
+	}
+
+	input := "The receipt is attached."
+	if text != "" {
+		input = fmt.Sprintf("TEXT READ FROM THE RECEIPT:\n\"\"\"\n%s\n\"\"\"", text)
+	}
+
+	return fmt.Sprintf(`You are a receipt parser. Read the following receipt into structured transaction data.
+
+CONTEXT:
+- User's base currency: %s
+- User's timezone: %s
+- Current date/time: %s
+
+%s
+
+INSTRUCTIONS:
+1. The receipt is a single transaction, usually an expense
+2. Extract:
+   - Amount: the total paid, tax and tip included (positive number, no currency symbols)
+   - Merchant name, as printed on the receipt
+   - Transaction date/time (ISO format, or null if not printed)
+   - Currency code (3-letter ISO code, default to %s)
+   - Tax amount and tip amount (null when not printed)
+   - Line items: description, amount of the line, quantity and unit price when printed
+   - Payment medium (credit_card, debit_card, cash, etc.) when printed
+   - Location (address or city of the merchant) when printed
+   - Category hint (general category like "food", "groceries", "transport", etc.)
+   - Confidence score (0.0 to 1.0 based on how readable the receipt is)
+3. Don't invent values: use null for anything not on the receipt
+4. Ignore subtotals, change given and loyalty points
+
+RESPONSE FORMAT:
+Return ONLY a valid JSON object with this exact structure:
+{
+  "amount": "23.40",
+  "type": "expense",
+  "description": "Groceries at Fresh Market",
+  "category_hint": "groceries",
+  "merchant_name": "Fresh Market",
+  "transaction_datetime": "2024-01-15T18:04:00Z",
+  "currency_code": "USD",
+  "payment_medium": "credit_card",
+  "location": "12 Main St, Springfield",
+  "tax_amount": "1.90",
+  "tip_amount": null,
+  "line_items": [
+    {"description": "Milk 1L", "amount": "2.50", "quantity": 1, "unit_price": "2.50"},
+    {"description": "Apples", "amount": "19.00", "quantity": 2, "unit_price": "9.50"}
+  ],
+  "confidence": 0.9
+}
+
+Important: Respond with ONLY the JSON object, no other text or formatting.`,
+		baseCurrency,
+		userTimezone,
+		time.Now().Format(time.RFC3339),
+		input,
+		baseCurrency)
+}
+
+// parseReceiptResponse parses the LLM response into a TransactionData with its tax, tip and
+// line items
+func (s *NeuralInputService) parseReceiptResponse(response string) (TransactionData, error) {
+	response = trimCodeFence(response)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &raw); err != nil {
+		// Some models answer with the array format of ParseTransactions
+		var list []map[string]interface{}
+		if listErr := json.Unmarshal([]byte(response), &list); listErr != nil || len(list) == 0 {
+			return TransactionData{}, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+		}
+		raw = list[0]
+	}
+
+	// Models often answer numbers instead of strings, and receipts are expenses
+	if amount, ok := raw["amount"].(float64); ok {
+		raw["amount"] = decimal.NewFromFloat(amount).String()
+	}
+
+	if _, ok := raw["type"].(string); !ok {
+		raw["type"] = "expense"
+	}
+
+	transaction, err := s.convertRawTransaction(raw)
+	if err != nil {
+		return transaction, err
+	}
+
+	transaction.TaxAmount = decimalField(raw["tax_amount"])
+	transaction.TipAmount = decimalField(raw["tip_amount"])
+
+	if items, ok := raw["line_items"].([]interface{}); ok {
+		for _, item := range items {
+			rawItem, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			description, _ := rawItem["description"].(string)
+			amount := decimalField(rawItem["amount"])
+			if description == "" || amount == nil {
+				continue
+			}
+
+			lineItem := LineItem{
+				Description: description,
+				Amount:      *amount,
+				UnitPrice:   decimalField(rawItem["unit_price"]),
+			}
+
+			if quantity, ok := rawItem["quantity"].(float64); ok && quantity > 0 {
+				lineItem.Quantity = int(quantity)
+			}
+
+			transaction.LineItems = append(transaction.LineItems, lineItem)
+		}
+	}
+
+	// Line items that don't add up to the total were likely misread
+	if len(transaction.LineItems) > 0 && !lineItemsMatchTotal(transaction) {
+		transaction.Confidence = min(transaction.Confidence, 0.6)
+	}
+
+	return transaction, nil
+}
+
+// lineItemsMatchTotal checks the items add up to the total, receipts print prices with or
+// without tax and tip
+func lineItemsMatchTotal(transaction TransactionData) bool {
+	sum := decimal.Zero
+	for _, item := range transaction.LineItems {
+		sum = sum.Add(item.Amount)
+	}
+
+	tax, tip := decimal.Zero, decimal.Zero
+	if transaction.TaxAmount != nil {
+		tax = *transaction.TaxAmount
+	}
+	if transaction.TipAmount != nil {
+		tip = *transaction.TipAmount
+	}
+
+	tolerance := decimal.NewFromFloat(0.01)
+	for _, total := range []decimal.Decimal{sum, sum.Add(tax), sum.Add(tip), sum.Add(tax).Add(tip)} {
+		if total.Sub(transaction.Amount).Abs().LessThanOrEqual(tolerance) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decimalField reads an amount sent as a string or a number, nil when missing or invalid
+func decimalField(v interface{}) *decimal.Decimal {
+	var d decimal.Decimal
+
+	switch value := v.(type) {
+	case string:
+		parsed, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil {
+			return nil
+		}
+		d = parsed
+	case float64:
+		d = decimal.NewFromFloat(value)
+	default:
+		return nil
+	}
+
+	return &d
+}
+
+// trimCodeFence removes the markdown code block models wrap their JSON in
+func trimCodeFence(response string) string {
+	response = strings.TrimSpace(response)
+
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(response, "```json")
+		response = strings.TrimPrefix(response, "```")
+		response = strings.TrimSuffix(response, "```")
+	}
+
+	return strings.TrimSpace(response)
+}
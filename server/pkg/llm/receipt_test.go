@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMultimodalProvider records the document it was sent
+type MockMultimodalProvider struct {
+	MockProvider
+	mimeTypes []string
+	document  *Document
+}
+
+func (m *MockMultimodalProvider) SupportsDocument(mimeType string) bool {
+	for _, t := range m.mimeTypes {
+		if t == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockMultimodalProvider) GenerateCompletionWithDocument(ctx context.Context, prompt string, doc Document) (string, error) {
+	m.document = &doc
+	return m.response, m.err
+}
+
+type MockOCR struct {
+	text string
+}
+
+func (m *MockOCR) SupportsDocument(mimeType string) bool {
+	return mimeType == "image/png"
+}
+
+func (m *MockOCR) ExtractText(ctx context.Context, doc Document) (string, error) {
+	return m.text, nil
+}
+
+const receiptResponse = "```json\n" + `{
+	"amount": 23.40,
+	"description": "Groceries at Fresh Market",
+	"merchant_name": "Fresh Market",
+	"currency_code": "EUR",
+	"tax_amount": "1.90",
+	"tip_amount": null,
+	"line_items": [
+		{"description": "Milk", "amount": "2.50", "quantity": 1, "unit_price": "2.50"},
+		{"description": "Apples", "amount": 19.00, "quantity": 2, "unit_price": "9.50"},
+		{"description": "", "amount": "1.00"}
+	],
+	"confidence": 0.9
+}` + "\n```"
+
+func TestNeuralInputService_ParseReceipt(t *testing.T) {
+	logger := zerolog.Nop()
+	modelInfo := ModelInfo{Name: "test-model", Provider: "test", Type: "remote"}
+
+	t.Run("multimodal model reads the document", func(t *testing.T) {
+		provider := &MockMultimodalProvider{
+			MockProvider: MockProvider{response: receiptResponse, modelInfo: modelInfo},
+			mimeTypes:    []string{"image/jpeg"},
+		}
+		service := &NeuralInputService{provider: provider, logger: &logger}
+
+		result, err := service.ParseReceipt(context.Background(), ReceiptRequest{
+			Document: Document{MimeType: "image/jpeg", Data: []byte("receipt")},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, ReceiptSourceVision, result.Source)
+		require.NotNil(t, provider.document)
+		assert.Equal(t, "image/jpeg", provider.document.MimeType)
+
+		txn := result.Transaction
+		assert.Equal(t, "expense", txn.Type)
+		assert.True(t, txn.Amount.Equal(decimal.RequireFromString("23.40")))
+		assert.Equal(t, "Fresh Market", *txn.MerchantName)
+		assert.Equal(t, "EUR", txn.CurrencyCode)
+		require.NotNil(t, txn.TaxAmount)
+		assert.True(t, txn.TaxAmount.Equal(decimal.RequireFromString("1.90")))
+		assert.Nil(t, txn.TipAmount)
+
+		require.Len(t, txn.LineItems, 2)
+		assert.Equal(t, "Apples", txn.LineItems[1].Description)
+		assert.Equal(t, 2, txn.LineItems[1].Quantity)
+		assert.True(t, txn.LineItems[1].Amount.Equal(decimal.NewFromInt(19)))
+		assert.Equal(t, 0.9, txn.Confidence)
+	})
+
+	t.Run("text model reads the OCR text", func(t *testing.T) {
+		service := &NeuralInputService{
+			provider: &MockProvider{response: receiptResponse, modelInfo: modelInfo},
+			ocr:      &MockOCR{text: "FRESH MARKET TOTAL 23.40"},
+			logger:   &logger,
+		}
+
+		result, err := service.ParseReceipt(context.Background(), ReceiptRequest{
+			Document: Document{MimeType: "image/png", Data: []byte("receipt")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ReceiptSourceOCR, result.Source)
+	})
+
+	t.Run("unreadable documents", func(t *testing.T) {
+		service := &NeuralInputService{
+			provider: &MockProvider{response: receiptResponse, modelInfo: modelInfo},
+			ocr:      &MockOCR{},
+			logger:   &logger,
+		}
+
+		_, err := service.ParseReceipt(context.Background(), ReceiptRequest{
+			Document: Document{MimeType: "application/pdf", Data: []byte("%PDF")},
+		})
+		assert.ErrorIs(t, err, ErrUnsupportedDocument)
+	})
+}
+
+func TestNeuralInputService_parseReceiptResponse(t *testing.T) {
+	logger := zerolog.Nop()
+	service := &NeuralInputService{logger: &logger}
+
+	t.Run("line items not matching the total lower the confidence", func(t *testing.T) {
+		txn, err := service.parseReceiptResponse(`{
+			"amount": "50.00",
+			"type": "expense",
+			"line_items": [{"description": "Pizza", "amount": "12.00"}],
+			"confidence": 0.95
+		}`)
+		require.NoError(t, err)
+		assert.Equal(t, 0.6, txn.Confidence)
+	})
+
+	t.Run("line items matching the total with tip", func(t *testing.T) {
+		txn, err := service.parseReceiptResponse(`[{
+			"amount": "15.00",
+			"type": "expense",
+			"tip_amount": "3.00",
+			"line_items": [{"description": "Pizza", "amount": "12.00"}],
+			"confidence": 0.95
+		}]`)
+		require.NoError(t, err)
+		assert.Equal(t, 0.95, txn.Confidence)
+	})
+
+	t.Run("missing amount", func(t *testing.T) {
+		_, err := service.parseReceiptResponse(`{"merchant_name": "Cafe"}`)
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -40,7 +41,14 @@ type GeminiContent struct {
 }
 
 type GeminiPart struct {
-	Text string `json:"text"`
+	Text       string            `json:"text,omitempty"`
+	InlineData *GeminiInlineData `json:"inline_data,omitempty"`
+}
+
+// GeminiInlineData holds a base64 encoded document sent along the prompt
+type GeminiInlineData struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
 }
 
 type GeminiGenerationConfig struct {
@@ -70,6 +78,29 @@ type OpenAIMessage struct {
 	Content string `json:"content"`
 }
 
+// OpenAIVisionRequest sends images along the prompt, their messages hold a list of parts
+type OpenAIVisionRequest struct {
+	Model       string                `json:"model"`
+	Messages    []OpenAIVisionMessage `json:"messages"`
+	Temperature float32               `json:"temperature,omitempty"`
+	MaxTokens   int                   `json:"max_tokens,omitempty"`
+}
+
+type OpenAIVisionMessage struct {
+	Role    string              `json:"role"`
+	Content []OpenAIContentPart `json:"content"`
+}
+
+type OpenAIContentPart struct {
+	Type     string          `json:"type"` // text or image_url
+	Text     string          `json:"text,omitempty"`
+	ImageURL *OpenAIImageURL `json:"image_url,omitempty"`
+}
+
+type OpenAIImageURL struct {
+	URL string `json:"url"`
+}
+
 // OpenAIResponse represents the response from OpenAI-compatible APIs
 type OpenAIResponse struct {
 	Choices []OpenAIChoice `json:"choices"`
@@ -91,22 +122,83 @@ type OpenAIUsage struct {
 func (p *RemoteProvider) GenerateCompletion(ctx context.Context, prompt string) (string, error) {
 	switch p.config.RemoteProvider {
 	case "gemini":
-		return p.generateGeminiCompletion(ctx, prompt)
+		return p.generateGeminiCompletion(ctx, []GeminiPart{{Text: prompt}})
 	case "openai", "claude", "openrouter":
-		return p.generateOpenAICompatibleCompletion(ctx, prompt)
+		return p.generateOpenAICompatibleCompletion(ctx, OpenAIRequest{
+			Model: p.config.RemoteModel,
+			Messages: []OpenAIMessage{
+				{
+					Role:    "user",
+					Content: prompt,
+				},
+			},
+			Temperature: p.config.Temperature,
+			MaxTokens:   p.config.MaxTokens,
+		})
 	default:
 		return "", fmt.Errorf("unsupported remote provider: %s", p.config.RemoteProvider)
 	}
 }
 
+// SupportsDocument reports whether the remote model reads the MIME type. Gemini reads images
+// and PDF documents, OpenAI-compatible APIs read images
+func (p *RemoteProvider) SupportsDocument(mimeType string) bool {
+	if !p.config.Vision {
+		return false
+	}
+
+	switch p.config.RemoteProvider {
+	case "gemini":
+		switch mimeType {
+		case "image/jpeg", "image/png", "image/webp", "application/pdf":
+			return true
+		}
+	case "openai", "openrouter":
+		switch mimeType {
+		case "image/jpeg", "image/png", "image/gif", "image/webp":
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateCompletionWithDocument sends a prompt and a document to the configured remote provider
+func (p *RemoteProvider) GenerateCompletionWithDocument(ctx context.Context, prompt string, doc Document) (string, error) {
+	data := base64.StdEncoding.EncodeToString(doc.Data)
+
+	switch p.config.RemoteProvider {
+	case "gemini":
+		return p.generateGeminiCompletion(ctx, []GeminiPart{
+			{InlineData: &GeminiInlineData{MimeType: doc.MimeType, Data: data}},
+			{Text: prompt},
+		})
+	case "openai", "openrouter":
+		return p.generateOpenAICompatibleCompletion(ctx, OpenAIVisionRequest{
+			Model: p.config.RemoteModel,
+			Messages: []OpenAIVisionMessage{
+				{
+					Role: "user",
+					Content: []OpenAIContentPart{
+						{Type: "text", Text: prompt},
+						{Type: "image_url", ImageURL: &OpenAIImageURL{URL: "data:" + doc.MimeType + ";base64," + data}},
+					},
+				},
+			},
+			Temperature: p.config.Temperature,
+			MaxTokens:   p.config.MaxTokens,
+		})
+	default:
+		return "", fmt.Errorf("documents aren't supported by remote provider: %s", p.config.RemoteProvider)
+	}
+}
+
 // generateGeminiCompletion handles Gemini API requests
-func (p *RemoteProvider) generateGeminiCompletion(ctx context.Context, prompt string) (string, error) {
+func (p *RemoteProvider) generateGeminiCompletion(ctx context.Context, parts []GeminiPart) (string, error) {
 	request := GeminiRequest{
 		Contents: []GeminiContent{
 			{
-				Parts: []GeminiPart{
-					{Text: prompt},
-				},
+				Parts: parts,
 			},
 		},
 		GenerationConfig: GeminiGenerationConfig{
@@ -158,19 +250,7 @@ func (p *RemoteProvider) generateGeminiCompletion(ctx context.Context, prompt st
 }
 
 // generateOpenAICompatibleCompletion handles OpenAI-compatible API requests
-func (p *RemoteProvider) generateOpenAICompatibleCompletion(ctx context.Context, prompt string) (string, error) {
-	request := OpenAIRequest{
-		Model: p.config.RemoteModel,
-		Messages: []OpenAIMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: p.config.Temperature,
-		MaxTokens:   p.config.MaxTokens,
-	}
-
+func (p *RemoteProvider) generateOpenAICompatibleCompletion(ctx context.Context, request any) (string, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal openai request: %w", err)
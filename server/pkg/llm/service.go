@@ -14,6 +14,7 @@ import (
 // NeuralInputService implements the Service interface
 type NeuralInputService struct {
 	provider Provider
+	ocr      OCR
 	config   Config
 	logger   *zerolog.Logger
 }
@@ -31,8 +32,14 @@ func NewNeuralInputService(config Config, logger *zerolog.Logger) (*NeuralInputS
 		return nil, fmt.Errorf("unsupported provider type: %s", config.Provider)
 	}
 
+	var ocr OCR
+	if config.TesseractPath != "" {
+		ocr = NewTesseractOCR(config.TesseractPath)
+	}
+
 	return &NeuralInputService{
 		provider: provider,
+		ocr:      ocr,
 		config:   config,
 		logger:   logger,
 	}, nil
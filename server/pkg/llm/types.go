@@ -9,17 +9,28 @@ import (
 
 // TransactionData represents a parsed transaction from neural input
 type TransactionData struct {
-	Amount              decimal.Decimal `json:"amount"`
-	Type                string          `json:"type"` // "income", "expense", "transfer"
-	Description         *string         `json:"description,omitempty"`
-	CategoryHint        *string         `json:"category_hint,omitempty"` // Suggested category name/type
-	MerchantName        *string         `json:"merchant_name,omitempty"`
-	TransactionDatetime *time.Time      `json:"transaction_datetime,omitempty"`
-	CurrencyCode        string          `json:"currency_code"`
-	PaymentMedium       *string         `json:"payment_medium,omitempty"` // credit_card, cash, etc.
-	Location            *string         `json:"location,omitempty"`
-	Note                *string         `json:"note,omitempty"`
-	Confidence          float64         `json:"confidence"` // 0.0 to 1.0, how confident the AI is
+	Amount              decimal.Decimal  `json:"amount"`
+	Type                string           `json:"type"` // "income", "expense", "transfer"
+	Description         *string          `json:"description,omitempty"`
+	CategoryHint        *string          `json:"category_hint,omitempty"` // Suggested category name/type
+	MerchantName        *string          `json:"merchant_name,omitempty"`
+	TransactionDatetime *time.Time       `json:"transaction_datetime,omitempty"`
+	CurrencyCode        string           `json:"currency_code"`
+	PaymentMedium       *string          `json:"payment_medium,omitempty"` // credit_card, cash, etc.
+	Location            *string          `json:"location,omitempty"`
+	Note                *string          `json:"note,omitempty"`
+	TaxAmount           *decimal.Decimal `json:"tax_amount,omitempty"`
+	TipAmount           *decimal.Decimal `json:"tip_amount,omitempty"`
+	LineItems           []LineItem       `json:"line_items,omitempty"` // Itemized receipts
+	Confidence          float64          `json:"confidence"`           // 0.0 to 1.0, how confident the AI is
+}
+
+// LineItem is an item of a receipt
+type LineItem struct {
+	Description string           `json:"description"`
+	Amount      decimal.Decimal  `json:"amount"`
+	Quantity    int              `json:"quantity,omitempty"`
+	UnitPrice   *decimal.Decimal `json:"unit_price,omitempty"`
 }
 
 // NeuralInputRequest represents the user's ambiguous input
@@ -38,6 +49,28 @@ type NeuralInputResponse struct {
 	Provider     string            `json:"provider"` // local or remote
 }
 
+// Document is a file sent along a prompt, such as the photo or PDF of a receipt
+type Document struct {
+	MimeType string
+	Data     []byte
+}
+
+// ReceiptRequest holds a receipt to extract a transaction from
+type ReceiptRequest struct {
+	Document     Document
+	UserTimezone *string
+	BaseCurrency *string
+}
+
+// ReceiptResponse contains the transaction read from a receipt
+type ReceiptResponse struct {
+	Transaction TransactionData `json:"transaction"`
+	ParsedAt    time.Time       `json:"parsed_at"`
+	Model       string          `json:"model"`
+	Provider    string          `json:"provider"`
+	Source      string          `json:"source"` // vision when the model read the document, ocr otherwise
+}
+
 // Provider defines the interface for LLM providers
 type Provider interface {
 	// GenerateCompletion sends a prompt to the LLM and returns the response
@@ -47,6 +80,23 @@ type Provider interface {
 	GetModelInfo() ModelInfo
 }
 
+// MultimodalProvider is implemented by the providers whose model reads documents along the prompt
+type MultimodalProvider interface {
+	Provider
+
+	// SupportsDocument reports whether the model reads documents of the given MIME type
+	SupportsDocument(mimeType string) bool
+
+	// GenerateCompletionWithDocument sends a prompt and a document to the LLM
+	GenerateCompletionWithDocument(ctx context.Context, prompt string, doc Document) (string, error)
+}
+
+// OCR extracts the text of a document, for models that only read text
+type OCR interface {
+	SupportsDocument(mimeType string) bool
+	ExtractText(ctx context.Context, doc Document) (string, error)
+}
+
 // ModelInfo contains metadata about a model
 type ModelInfo struct {
 	Name     string `json:"name"`
@@ -58,4 +108,7 @@ type ModelInfo struct {
 type Service interface {
 	// ParseTransactions takes ambiguous input and returns structured transaction data
 	ParseTransactions(ctx context.Context, req NeuralInputRequest) (*NeuralInputResponse, error)
+
+	// ParseReceipt reads a receipt image or document into transaction data
+	ParseReceipt(ctx context.Context, req ReceiptRequest) (*ReceiptResponse, error)
 }
\ No newline at end of file
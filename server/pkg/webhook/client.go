@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a webhook endpoint, or one of its redirects, points to an
+// address inside our network
+var ErrBlockedAddress = errors.New("webhook: destination address is not allowed")
+
+const maxRedirects = 5
+
+// blockedPrefixes are the ranges a webhook may never reach unless allowlisted: loopback,
+// link-local (which holds the cloud metadata endpoints), private, shared, documentation and reserved
+// networks, and the IPv6 transition ranges that embed an IPv4 address
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT, also Alibaba Cloud metadata
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"), // AWS, GCP and Azure metadata live here
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"), // Documentation
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 can map back to any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo tunnels to an IPv4 address
+	netip.MustParsePrefix("2002::/16"),      // 6to4 embeds an IPv4 address
+	netip.MustParsePrefix("fc00::/7"),       // Unique local, also the AWS IPv6 metadata endpoint
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// IsBlockedAddress reports whether ip belongs to a network webhooks must not reach
+func IsBlockedAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allowlist lets trusted destinations through the guard, for self-hosted setups that deliver
+// webhooks inside their own network
+type Allowlist struct {
+	hosts    map[string]struct{}
+	prefixes []netip.Prefix
+}
+
+// NewAllowlist parses entries that are either a hostname, an IP address or a CIDR range
+func NewAllowlist(entries []string) (*Allowlist, error) {
+	list := &Allowlist{hosts: make(map[string]struct{})}
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook allowlist range %q: %w", entry, err)
+			}
+			list.prefixes = append(list.prefixes, prefix.Masked())
+			continue
+		}
+
+		if ip, err := netip.ParseAddr(entry); err == nil {
+			list.prefixes = append(list.prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+
+		list.hosts[entry] = struct{}{}
+	}
+
+	return list, nil
+}
+
+func (a *Allowlist) allowsHost(host string) bool {
+	if a == nil {
+		return false
+	}
+
+	_, ok := a.hosts[strings.ToLower(strings.TrimSuffix(host, "."))]
+	return ok
+}
+
+func (a *Allowlist) allowsAddr(ip netip.Addr) bool {
+	if a == nil {
+		return false
+	}
+
+	ip = ip.Unmap()
+	for _, prefix := range a.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// guard resolves webhook hosts itself and only dials addresses it has checked, so a DNS answer
+// can't change between the check and the connection
+type guard struct {
+	allowlist *Allowlist
+	resolver  *net.Resolver
+	dialer    *net.Dialer
+}
+
+func (g *guard) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.allowlist.allowsHost(host) {
+		return g.dialer.DialContext(ctx, network, addr)
+	}
+
+	var ips []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		ips = []netip.Addr{ip}
+	} else {
+		resolved, err := g.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		ips = resolved
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("webhook: no address found for %s", host)
+	}
+
+	// Refuse the host if any of its addresses is blocked rather than picking a public one, a
+	// record mixing both is a rebinding attempt
+	for _, ip := range ips {
+		if IsBlockedAddress(ip) && !g.allowlist.allowsAddr(ip) {
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, ip.Unmap())
+		}
+	}
+
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+
+	return nil, dialErr
+}
+
+// checkURL rejects what can be refused without a lookup: other schemes and literal addresses
+func (g *guard) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrBlockedAddress, u.Scheme)
+	}
+
+	host := u.Hostname()
+	if g.allowlist.allowsHost(host) {
+		return nil
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil && IsBlockedAddress(ip) && !g.allowlist.allowsAddr(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip.Unmap())
+	}
+
+	return nil
+}
+
+func (g *guard) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("webhook: stopped after %d redirects", maxRedirects)
+	}
+
+	return g.checkURL(req.URL)
+}
+
+// NewClient returns an HTTP client for delivering webhooks to user supplied URLs. Every
+// connection, including the ones made for redirects, is checked after DNS resolution and refused
+// with ErrBlockedAddress when it targets a blocked address outside of the allowlist.
+// Environment proxies are ignored since they would connect on our behalf.
+func NewClient(timeout time.Duration, allowlist *Allowlist) *http.Client {
+	g := &guard{
+		allowlist: allowlist,
+		resolver:  net.DefaultResolver,
+		dialer:    &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           g.dialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     &checkedTransport{guard: g, next: transport},
+		CheckRedirect: g.checkRedirect,
+	}
+}
+
+// checkedTransport refuses blocked literal addresses before a connection is attempted, so the
+// error doesn't depend on whether a pooled connection exists
+type checkedTransport struct {
+	guard *guard
+	next  http.RoundTripper
+}
+
+func (t *checkedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	return t.next.RoundTrip(req)
+}
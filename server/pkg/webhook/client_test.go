@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockedAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.20.0.1", true},
+		{"192.168.1.10", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"192.0.2.1", true},
+		{"198.51.100.7", true},
+		{"203.0.113.200", true},
+		{"64:ff9b:1::a00:1", true},
+		{"2001:0:4136:e378:8000:63bf:3fff:fdd2", true},
+		{"2002:a9fe:a9fe::1", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.blocked, IsBlockedAddress(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+}
+
+func TestNewAllowlist(t *testing.T) {
+	list, err := NewAllowlist([]string{"Hooks.Internal", "10.0.0.0/8", "192.168.1.5", " "})
+	require.NoError(t, err)
+
+	assert.True(t, list.allowsHost("hooks.internal."), "hostnames are allowed regardless of case and trailing dot")
+	assert.True(t, list.allowsAddr(netip.MustParseAddr("10.9.8.7")), "address inside the allowed range")
+	assert.True(t, list.allowsAddr(netip.MustParseAddr("::ffff:192.168.1.5")), "mapped form of an allowed address")
+	assert.False(t, list.allowsAddr(netip.MustParseAddr("192.168.1.6")))
+
+	_, err = NewAllowlist([]string{"10.0.0.0/33"})
+	assert.Error(t, err, "invalid range")
+}
+
+func TestClientBlocksInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second, nil).Post(srv.URL, "application/json", strings.NewReader("{}"))
+	require.ErrorIs(t, err, ErrBlockedAddress, "delivery to a loopback address")
+
+	allowlist, err := NewAllowlist([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+
+	resp, err := NewClient(time.Second, allowlist).Post(srv.URL, "application/json", strings.NewReader("{}"))
+	require.NoError(t, err, "allowlisted delivery")
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestClientChecksHostnamesAfterResolution(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	u.Host = "localhost:" + u.Port()
+
+	_, err := NewClient(time.Second, nil).Get(u.String())
+	assert.ErrorIs(t, err, ErrBlockedAddress, "hostname resolving to loopback")
+}
+
+func TestClientChecksRedirects(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "redirect to an internal address was followed")
+	}))
+	defer internal.Close()
+
+	public := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer public.Close()
+
+	// Only the first hop is trusted, by name, the redirect goes to a literal loopback address
+	u, _ := url.Parse(public.URL)
+	u.Host = "localhost:" + u.Port()
+
+	allowlist, err := NewAllowlist([]string{"localhost"})
+	require.NoError(t, err)
+
+	_, err = NewClient(time.Second, allowlist).Get(u.String())
+	assert.ErrorIs(t, err, ErrBlockedAddress, "redirect to a loopback address")
+}
@@ -0,0 +1,123 @@
+// Package webhook signs and verifies the webhooks delivered by NUTS, and provides the HTTP
+// client that delivers them without reaching into our own network.
+//
+// Every delivery carries an X-NUTS-Delivery header with its unique ID and an X-NUTS-Signature
+// header of the form
+//
+//	t=<unix timestamp>,v1=<hex signature>[,v1=<hex signature>]
+//
+// where each v1 signature is the HMAC-SHA256, keyed with a subscription secret, of
+//
+//	<timestamp>.<delivery id>.<raw body>
+//
+// Several v1 signatures are sent while a secret is rotated, one per valid secret. Receivers
+// should reject deliveries whose timestamp is too old and remember the delivery IDs they
+// processed to drop replays.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-NUTS-Signature"
+	DeliveryHeader  = "X-NUTS-Delivery"
+	EventHeader     = "X-NUTS-Event"
+
+	// DefaultTolerance is how far the signature timestamp may drift from the receiver clock
+	DefaultTolerance = 5 * time.Minute
+
+	schemeV1 = "v1"
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature or delivery id")
+	ErrInvalidHeader    = errors.New("webhook: malformed signature header")
+	ErrTimestampExpired = errors.New("webhook: timestamp outside of the tolerance")
+	ErrNoMatch          = errors.New("webhook: no signature matches the secret")
+)
+
+// ComputeSignature returns the hex encoded v1 signature of a delivery
+func ComputeSignature(secret string, timestamp time.Time, deliveryID string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(deliveryID))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue builds the X-NUTS-Signature header, with one signature per secret
+func SignatureHeaderValue(timestamp time.Time, deliveryID string, body []byte, secrets ...string) string {
+	var b strings.Builder
+
+	b.WriteString("t=")
+	b.WriteString(strconv.FormatInt(timestamp.Unix(), 10))
+
+	for _, secret := range secrets {
+		b.WriteString("," + schemeV1 + "=")
+		b.WriteString(ComputeSignature(secret, timestamp, deliveryID, body))
+	}
+
+	return b.String()
+}
+
+// Verify checks the signature of a received delivery against a secret, with the default tolerance
+func Verify(header http.Header, body []byte, secret string) error {
+	return VerifyAt(header.Get(SignatureHeader), header.Get(DeliveryHeader), body, secret, DefaultTolerance, time.Now())
+}
+
+// VerifyAt checks a signature header as of now. It succeeds when any of the v1 signatures
+// matches, so that receivers keep working while the sender rotates its secret.
+func VerifyAt(signature, deliveryID string, body []byte, secret string, tolerance time.Duration, now time.Time) error {
+	if signature == "" || deliveryID == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp int64
+	var signatures []string
+
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidHeader
+		}
+
+		switch key {
+		case "t":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidHeader
+			}
+			timestamp = ts
+		case schemeV1:
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidHeader
+	}
+
+	signedAt := time.Unix(timestamp, 0)
+	if now.Sub(signedAt).Abs() > tolerance {
+		return ErrTimestampExpired
+	}
+
+	expected := []byte(ComputeSignature(secret, signedAt, deliveryID, body))
+	for _, candidate := range signatures {
+		if hmac.Equal(expected, []byte(candidate)) {
+			return nil
+		}
+	}
+
+	return ErrNoMatch
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Known vectors, any other implementation of the scheme has to produce the same signatures
+const (
+	vectorDeliveryID = "3f1c6b0e-8f7a-4d2b-9c4e-2a5d6e7f8a9b"
+	vectorBody       = `{"type":"webhook.test"}`
+
+	vectorCurrent  = "3edc269098f4f7637f20ba283345873f10756fe3b3d90dd7edd4259152fdf6fe"
+	vectorPrevious = "075d4506a4b9a6b62a2446070e5eceb2f602e416782aea9a50288feb4c9e4fb0"
+)
+
+var vectorTimestamp = time.Unix(1754000000, 0)
+
+func TestComputeSignature(t *testing.T) {
+	assert.Equal(t, vectorCurrent, ComputeSignature("whsec_current", vectorTimestamp, vectorDeliveryID, []byte(vectorBody)))
+	assert.Equal(t, vectorPrevious, ComputeSignature("whsec_previous", vectorTimestamp, vectorDeliveryID, []byte(vectorBody)))
+}
+
+func TestSignatureHeaderValue(t *testing.T) {
+	assert.Equal(t,
+		"t=1754000000,v1="+vectorCurrent+",v1="+vectorPrevious,
+		SignatureHeaderValue(vectorTimestamp, vectorDeliveryID, []byte(vectorBody), "whsec_current", "whsec_previous"),
+	)
+}
+
+func TestVerifyAt(t *testing.T) {
+	rotating := "t=1754000000,v1=" + vectorCurrent + ",v1=" + vectorPrevious
+	now := vectorTimestamp.Add(time.Minute)
+
+	tests := []struct {
+		name       string
+		signature  string
+		deliveryID string
+		body       string
+		secret     string
+		now        time.Time
+		want       error
+	}{
+		{name: "current secret", signature: "t=1754000000,v1=" + vectorCurrent, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now},
+		{name: "rotating, new secret", signature: rotating, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now},
+		{name: "rotating, previous secret", signature: rotating, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_previous", now: now},
+		{name: "unknown scheme ignored", signature: "t=1754000000,v0=abc,v1=" + vectorCurrent, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now},
+		{name: "wrong secret", signature: rotating, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_other", now: now, want: ErrNoMatch},
+		{name: "tampered body", signature: rotating, deliveryID: vectorDeliveryID, body: `{"type":"account.deleted"}`, secret: "whsec_current", now: now, want: ErrNoMatch},
+		{name: "other delivery", signature: rotating, deliveryID: "b7a3c2d1-0000-4000-8000-000000000000", body: vectorBody, secret: "whsec_current", now: now, want: ErrNoMatch},
+		{name: "replayed later", signature: rotating, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: vectorTimestamp.Add(DefaultTolerance + time.Second), want: ErrTimestampExpired},
+		{name: "from the future", signature: rotating, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: vectorTimestamp.Add(-DefaultTolerance - time.Second), want: ErrTimestampExpired},
+		{name: "missing delivery id", signature: rotating, body: vectorBody, secret: "whsec_current", now: now, want: ErrMissingSignature},
+		{name: "missing signature", deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now, want: ErrMissingSignature},
+		{name: "legacy format", signature: "sha256=" + vectorCurrent, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now, want: ErrInvalidHeader},
+		{name: "no timestamp", signature: "v1=" + vectorCurrent, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now, want: ErrInvalidHeader},
+		{name: "bad timestamp", signature: "t=yesterday,v1=" + vectorCurrent, deliveryID: vectorDeliveryID, body: vectorBody, secret: "whsec_current", now: now, want: ErrInvalidHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyAt(tt.signature, tt.deliveryID, []byte(tt.body), tt.secret, DefaultTolerance, tt.now)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(vectorBody)
+
+	header := http.Header{}
+	header.Set(DeliveryHeader, vectorDeliveryID)
+	header.Set(SignatureHeader, SignatureHeaderValue(time.Now(), vectorDeliveryID, body, "whsec_current"))
+
+	assert.NoError(t, Verify(header, body, "whsec_current"))
+	assert.ErrorIs(t, Verify(header, body, "whsec_previous"), ErrNoMatch)
+}